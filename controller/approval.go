@@ -0,0 +1,97 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"sigs.k8s.io/external-dns/plan"
+)
+
+// Approver decides whether the changes calculated by a synchronization may be applied.
+type Approver interface {
+	// Approve returns an error if the changes must not be applied.
+	Approve(ctx context.Context, changes *plan.Changes) error
+}
+
+// approvalResponse is the optional body of the response of an approval webhook.
+type approvalResponse struct {
+	Approved *bool  `json:"approved"`
+	Reason   string `json:"reason"`
+}
+
+// webhookApprover is an Approver posting the changes to a webhook, enabling change-management
+// gates in front of production zones.
+type webhookApprover struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookApprover returns an Approver posting the changes, in the JSON format of
+// plan.OutputFormatJSON, to the given URL. The changes are approved if the webhook responds
+// with 200 OK and either no body or a body of {"approved": true}.
+func NewWebhookApprover(url string, timeout time.Duration) Approver {
+	return &webhookApprover{
+		url:    url,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+func (a *webhookApprover) Approve(ctx context.Context, changes *plan.Changes) error {
+	var body bytes.Buffer
+	if err := changes.Write(&body, plan.OutputFormatJSON); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.url, &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call the approval webhook %s: %w", a.url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("changes not approved by the approval webhook %s: unexpected status %s", a.url, resp.Status)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read the response of the approval webhook %s: %w", a.url, err)
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil
+	}
+	var approval approvalResponse
+	if err := json.Unmarshal(data, &approval); err != nil {
+		return fmt.Errorf("failed to decode the response of the approval webhook %s: %w", a.url, err)
+	}
+	if approval.Approved != nil && !*approval.Approved {
+		return fmt.Errorf("changes not approved by the approval webhook %s: %s", a.url, approval.Reason)
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/internal/testutils"
+	"sigs.k8s.io/external-dns/plan"
+	"sigs.k8s.io/external-dns/registry"
+)
+
+func TestWebhookApprover(t *testing.T) {
+	for _, tc := range []struct {
+		title       string
+		status      int
+		body        string
+		expectError bool
+	}{
+		{title: "approved without body", status: http.StatusOK},
+		{title: "approved", status: http.StatusOK, body: `{"approved": true}`},
+		{title: "denied", status: http.StatusOK, body: `{"approved": false, "reason": "change freeze"}`, expectError: true},
+		{title: "error status", status: http.StatusForbidden, expectError: true},
+		{title: "invalid body", status: http.StatusOK, body: "approved", expectError: true},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			var received map[string]interface{}
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodPost, r.Method)
+				assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+				w.WriteHeader(tc.status)
+				w.Write([]byte(tc.body))
+			}))
+			defer server.Close()
+
+			changes := &plan.Changes{
+				Create: []*endpoint.Endpoint{{DNSName: "new.example.org", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"192.0.2.1"}}},
+			}
+			err := NewWebhookApprover(server.URL, time.Second).Approve(context.Background(), changes)
+			if tc.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Len(t, received["create"], 1)
+		})
+	}
+}
+
+func TestRunOnceWithoutApprovalSkipsChanges(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"approved": false}`))
+	}))
+	defer server.Close()
+
+	source := new(testutils.MockSource)
+	source.On("Endpoints").Return([]*endpoint.Endpoint{
+		{DNSName: "new.example.org", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"192.0.2.1"}},
+	}, nil)
+
+	provider := &filteredMockProvider{}
+	r, err := registry.NewNoopRegistry(provider)
+	require.NoError(t, err)
+
+	ctrl := &Controller{
+		Source:             source,
+		Registry:           r,
+		Policy:             &plan.SyncPolicy{},
+		ManagedRecordTypes: []string{endpoint.RecordTypeA},
+		Approver:           NewWebhookApprover(server.URL, time.Second),
+	}
+
+	assert.Error(t, ctrl.RunOnce(context.Background()))
+	assert.Empty(t, provider.ApplyChangesCalls)
+}
+
+// recordingApprover approves the changes unless rejected, recording the changes it is asked for.
+type recordingApprover struct {
+	rejected bool
+	changes  []*plan.Changes
+}
+
+func (a *recordingApprover) Approve(ctx context.Context, changes *plan.Changes) error {
+	a.changes = append(a.changes, changes)
+	if a.rejected {
+		return errors.New("changes rejected")
+	}
+	return nil
+}
+
+func TestRunOnceApprovesMissingRecords(t *testing.T) {
+	missing := &endpoint.Endpoint{DNSName: "a-app.example.org", RecordType: endpoint.RecordTypeTXT, Targets: endpoint.Targets{"\"heritage=external-dns,external-dns/owner=owner\""}}
+
+	for _, rejected := range []bool{false, true} {
+		source := new(testutils.MockSource)
+		source.On("Endpoints").Return([]*endpoint.Endpoint{}, nil)
+
+		provider := &filteredMockProvider{}
+		noop, err := registry.NewNoopRegistry(provider)
+		require.NoError(t, err)
+
+		var out bytes.Buffer
+		approver := &recordingApprover{rejected: rejected}
+		ctrl := &Controller{
+			Source:              source,
+			Registry:            &noopRegistryWithMissing{NoopRegistry: noop, missingRecords: []*endpoint.Endpoint{missing}},
+			Policy:              &plan.SyncPolicy{},
+			ManagedRecordTypes:  []string{endpoint.RecordTypeA},
+			Approver:            approver,
+			ChangesOutput:       &out,
+			ChangesOutputFormat: plan.OutputFormatJSON,
+		}
+
+		err = ctrl.RunOnce(context.Background())
+		require.Len(t, approver.changes, 1)
+		assert.Equal(t, []*endpoint.Endpoint{missing}, approver.changes[0].Create)
+		assert.Contains(t, out.String(), "a-app.example.org")
+		if rejected {
+			assert.Error(t, err)
+			assert.Empty(t, provider.ApplyChangesCalls)
+		} else {
+			assert.NoError(t, err)
+			assert.Len(t, provider.ApplyChangesCalls, 1)
+		}
+	}
+}
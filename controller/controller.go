@@ -18,6 +18,9 @@ package controller
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"io"
 	"sync"
 	"time"
 
@@ -80,6 +83,14 @@ var (
 			Help:      "Number of reconcile loops ending up with no changes on the DNS provider side.",
 		},
 	)
+	controllerFailedChangesTotal = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "external_dns",
+			Subsystem: "controller",
+			Name:      "failed_changes_total",
+			Help:      "Number of changes the DNS provider reported as not applied.",
+		},
+	)
 	deprecatedRegistryErrors = prometheus.NewCounter(
 		prometheus.CounterOpts{
 			Subsystem: "registry",
@@ -129,6 +140,7 @@ func init() {
 	prometheus.MustRegister(deprecatedRegistryErrors)
 	prometheus.MustRegister(deprecatedSourceErrors)
 	prometheus.MustRegister(controllerNoChangesTotal)
+	prometheus.MustRegister(controllerFailedChangesTotal)
 	prometheus.MustRegister(registryARecords)
 	prometheus.MustRegister(sourceARecords)
 	prometheus.MustRegister(verifiedARecords)
@@ -145,6 +157,17 @@ type Controller struct {
 	Registry registry.Registry
 	// The policy that defines which changes to DNS records are allowed
 	Policy plan.Policy
+	// The ConflictResolver decides which resource gets a DNS name wanted by several resources
+	ConflictResolver plan.ConflictResolver
+	// The number of consecutive synchronizations a record must be missing before being deleted
+	DeletionGracePeriod int
+	// ChangesOutput, if set, receives the changes calculated by every synchronization in ChangesOutputFormat
+	ChangesOutput       io.Writer
+	ChangesOutputFormat string
+	// Approver, if set, must approve the changes of every synchronization before they are applied
+	Approver Approver
+	// DryRun, if set, has the changes validated by the provider, when it supports it, before they are applied
+	DryRun bool
 	// The interval between individual synchronizations
 	Interval time.Duration
 	// The DomainFilter defines which DNS records to keep or exclude
@@ -157,21 +180,41 @@ type Controller struct {
 	ManagedRecordTypes []string
 	// MinEventSyncInterval is used as window for batching events
 	MinEventSyncInterval time.Duration
+	// InternalRegistry, if set, manages the internal view of split-horizon zones next to Registry
+	InternalRegistry registry.Registry
+	// SplitHorizonRouter decides which endpoints are managed by the InternalRegistry
+	SplitHorizonRouter *SplitHorizonRouter
 }
 
 // RunOnce runs a single iteration of a reconciliation loop.
 func (c *Controller) RunOnce(ctx context.Context) error {
-	records, err := c.Registry.Records(ctx)
-	if err != nil {
+	records, externalErr := c.Registry.Records(ctx)
+	if externalErr != nil {
 		registryErrorsTotal.Inc()
 		deprecatedRegistryErrors.Inc()
-		return err
+		if c.InternalRegistry == nil {
+			return externalErr
+		}
 	}
 
-	missingRecords := c.Registry.MissingRecords()
+	// The internal and external views are reconciled independently, so that a failing
+	// provider doesn't hold back the other one.
+	var internalRecords []*endpoint.Endpoint
+	var internalErr error
+	if c.InternalRegistry != nil {
+		internalRecords, internalErr = c.InternalRegistry.Records(ctx)
+		if internalErr != nil {
+			registryErrorsTotal.Inc()
+			deprecatedRegistryErrors.Inc()
+			if externalErr != nil {
+				return splitHorizonError(externalErr, internalErr)
+			}
+		}
+	}
 
-	registryEndpointsTotal.Set(float64(len(records)))
-	regARecords := filterARecords(records)
+	allRecords := append(append([]*endpoint.Endpoint{}, records...), internalRecords...)
+	registryEndpointsTotal.Set(float64(len(allRecords)))
+	regARecords := filterARecords(allRecords)
 	registryARecords.Set(float64(len(regARecords)))
 	ctx = context.WithValue(ctx, provider.RecordsContextKey, records)
 
@@ -184,9 +227,34 @@ func (c *Controller) RunOnce(ctx context.Context) error {
 	sourceEndpointsTotal.Set(float64(len(endpoints)))
 	srcARecords := filterARecords(endpoints)
 	sourceARecords.Set(float64(len(srcARecords)))
-	vRecords := fetchMatchingARecords(endpoints, records)
+	vRecords := fetchMatchingARecords(endpoints, allRecords)
 	verifiedARecords.Set(float64(len(vRecords)))
-	endpoints = c.Registry.AdjustEndpoints(endpoints)
+
+	if c.InternalRegistry == nil {
+		if err := c.reconcile(ctx, c.Registry, records, externalOnly(endpoints)); err != nil {
+			return err
+		}
+	} else {
+		internal, external := c.SplitHorizonRouter.Split(endpoints)
+		if externalErr == nil {
+			externalErr = c.reconcile(ctx, c.Registry, records, external)
+		}
+		if internalErr == nil {
+			internalErr = c.reconcile(context.WithValue(ctx, provider.RecordsContextKey, internalRecords), c.InternalRegistry, internalRecords, internal)
+		}
+		if err := splitHorizonError(externalErr, internalErr); err != nil {
+			return err
+		}
+	}
+
+	lastSyncTimestamp.SetToCurrentTime()
+	return nil
+}
+
+// reconcile moves the records of a registry towards the desired endpoints.
+func (c *Controller) reconcile(ctx context.Context, r registry.Registry, records, endpoints []*endpoint.Endpoint) error {
+	missingRecords := r.MissingRecords()
+	endpoints = r.AdjustEndpoints(withOwnerOverrides(endpoints))
 
 	if len(missingRecords) > 0 {
 		// Add missing records before the actual plan is applied.
@@ -195,16 +263,13 @@ func (c *Controller) RunOnce(ctx context.Context) error {
 		missingRecordsPlan := &plan.Plan{
 			Policies:           []plan.Policy{c.Policy},
 			Missing:            missingRecords,
-			DomainFilter:       endpoint.MatchAllDomainFilters{c.DomainFilter, c.Registry.GetDomainFilter()},
-			PropertyComparator: c.Registry.PropertyValuesEqual,
+			DomainFilter:       endpoint.MatchAllDomainFilters{c.DomainFilter, r.GetDomainFilter()},
+			PropertyComparator: r.PropertyValuesEqual,
 			ManagedRecords:     c.ManagedRecordTypes,
 		}
 		missingRecordsPlan = missingRecordsPlan.Calculate()
 		if missingRecordsPlan.Changes.HasChanges() {
-			err = c.Registry.ApplyChanges(ctx, missingRecordsPlan.Changes)
-			if err != nil {
-				registryErrorsTotal.Inc()
-				deprecatedRegistryErrors.Inc()
+			if err := c.applyChanges(ctx, r, missingRecordsPlan.Changes); err != nil {
 				return err
 			}
 			log.Info("All missing records are created")
@@ -212,32 +277,113 @@ func (c *Controller) RunOnce(ctx context.Context) error {
 	}
 
 	plan := &plan.Plan{
-		Policies:           []plan.Policy{c.Policy},
-		Current:            records,
-		Desired:            endpoints,
-		DomainFilter:       endpoint.MatchAllDomainFilters{c.DomainFilter, c.Registry.GetDomainFilter()},
-		PropertyComparator: c.Registry.PropertyValuesEqual,
-		ManagedRecords:     c.ManagedRecordTypes,
+		Policies:            []plan.Policy{c.Policy},
+		Current:             records,
+		Desired:             endpoints,
+		DomainFilter:        endpoint.MatchAllDomainFilters{c.DomainFilter, r.GetDomainFilter()},
+		PropertyComparator:  r.PropertyValuesEqual,
+		ManagedRecords:      c.ManagedRecordTypes,
+		ConflictResolver:    c.ConflictResolver,
+		DeletionGracePeriod: c.DeletionGracePeriod,
 	}
 
 	plan = plan.Calculate()
 
-	if plan.Changes.HasChanges() {
-		err = c.Registry.ApplyChanges(ctx, plan.Changes)
-		if err != nil {
-			registryErrorsTotal.Inc()
-			deprecatedRegistryErrors.Inc()
-			return err
-		}
-	} else {
+	if err := c.applyChanges(ctx, r, plan.Changes); err != nil {
+		return err
+	}
+	if !plan.Changes.HasChanges() {
 		controllerNoChangesTotal.Inc()
 		log.Info("All records are already up to date")
 	}
 
-	lastSyncTimestamp.SetToCurrentTime()
 	return nil
 }
 
+// applyChanges writes the changes to ChangesOutput, then has them validated in dry-run mode and
+// approved before they are applied to the registry. The changes creating the missing records
+// of the registry go through the same steps as the ones of the plan.
+func (c *Controller) applyChanges(ctx context.Context, r registry.Registry, changes *plan.Changes) error {
+	if c.ChangesOutput != nil {
+		if err := changes.Write(c.ChangesOutput, c.ChangesOutputFormat); err != nil {
+			return fmt.Errorf("failed to write the changes: %w", err)
+		}
+	}
+
+	if !changes.HasChanges() {
+		return nil
+	}
+	if c.DryRun {
+		if err := validateChanges(ctx, r, changes); err != nil {
+			return err
+		}
+	}
+	if c.Approver != nil {
+		if err := c.Approver.Approve(ctx, changes); err != nil {
+			return err
+		}
+	}
+	if err := r.ApplyChanges(ctx, changes); err != nil {
+		registryErrorsTotal.Inc()
+		deprecatedRegistryErrors.Inc()
+		logFailedChanges(err)
+		return err
+	}
+	return nil
+}
+
+// validateChanges validates the changes with the provider of the registry, if it supports it,
+// logging the changes it would reject.
+func validateChanges(ctx context.Context, r registry.Registry, changes *plan.Changes) error {
+	validator, ok := r.(provider.ChangesValidator)
+	if !ok {
+		log.Debug("The registry doesn't support validating changes")
+		return nil
+	}
+	err := validator.ValidateChanges(ctx, changes)
+	if errors.Is(err, provider.ErrChangesValidationNotSupported) {
+		log.Debug("The provider doesn't support validating changes")
+		return nil
+	}
+	if err != nil {
+		var changesErr *provider.ChangesError
+		if errors.As(err, &changesErr) {
+			for _, ep := range changesErr.Failed.Create {
+				log.Warnf("The provider would reject creating %s", ep)
+			}
+			for _, ep := range changesErr.Failed.UpdateNew {
+				log.Warnf("The provider would reject updating %s", ep)
+			}
+			for _, ep := range changesErr.Failed.Delete {
+				log.Warnf("The provider would reject deleting %s", ep)
+			}
+		}
+		return fmt.Errorf("the provider rejected the changes: %w", err)
+	}
+	log.Info("The provider validated the changes")
+	return nil
+}
+
+// logFailedChanges logs the changes reported as not applied by a partially failed ApplyChanges.
+// The applied changes are kept, the failed ones are planned again by the next synchronization.
+func logFailedChanges(err error) {
+	var changesErr *provider.ChangesError
+	if !errors.As(err, &changesErr) {
+		return
+	}
+
+	for _, ep := range changesErr.Failed.Create {
+		log.Warnf("Failed to create %s, it will be retried with the next synchronization", ep)
+	}
+	for _, ep := range changesErr.Failed.UpdateNew {
+		log.Warnf("Failed to update %s, it will be retried with the next synchronization", ep)
+	}
+	for _, ep := range changesErr.Failed.Delete {
+		log.Warnf("Failed to delete %s, it will be retried with the next synchronization", ep)
+	}
+	controllerFailedChangesTotal.Add(float64(len(changesErr.Failed.Create) + len(changesErr.Failed.UpdateNew) + len(changesErr.Failed.Delete)))
+}
+
 // Checks and returns the intersection of A records in endpoint and registry.
 func fetchMatchingARecords(endpoints []*endpoint.Endpoint, registryRecords []*endpoint.Endpoint) []string {
 	aRecords := filterARecords(endpoints)
@@ -17,6 +17,7 @@ limitations under the License.
 package controller
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"math"
@@ -25,6 +26,7 @@ import (
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 
 	"sigs.k8s.io/external-dns/endpoint"
 	"sigs.k8s.io/external-dns/internal/testutils"
@@ -50,6 +52,7 @@ type filteredMockProvider struct {
 	RecordsStore      []*endpoint.Endpoint
 	RecordsCallCount  int
 	ApplyChangesCalls []*plan.Changes
+	ApplyChangesErr   error
 }
 
 type errorMockProvider struct {
@@ -69,7 +72,7 @@ func (p *filteredMockProvider) Records(ctx context.Context) ([]*endpoint.Endpoin
 // ApplyChanges stores all calls for later check
 func (p *filteredMockProvider) ApplyChanges(ctx context.Context, changes *plan.Changes) error {
 	p.ApplyChangesCalls = append(p.ApplyChangesCalls, changes)
-	return nil
+	return p.ApplyChangesErr
 }
 
 // Records returns the desired mock endpoints.
@@ -628,3 +631,278 @@ func TestMissingRecordsApply(t *testing.T) {
 			},
 		})
 }
+
+func TestRunOnceSplitHorizon(t *testing.T) {
+	source := new(testutils.MockSource)
+	source.On("Endpoints").Return([]*endpoint.Endpoint{
+		{
+			DNSName:    "app.example.org",
+			RecordType: endpoint.RecordTypeA,
+			Targets:    endpoint.Targets{"10.0.0.1"},
+			Labels:     endpoint.Labels{endpoint.SplitHorizonLabelKey: endpoint.SplitHorizonInternal},
+		},
+		{
+			DNSName:    "app.example.org",
+			RecordType: endpoint.RecordTypeA,
+			Targets:    endpoint.Targets{"203.0.113.1"},
+			Labels:     endpoint.Labels{endpoint.SplitHorizonLabelKey: endpoint.SplitHorizonExternal},
+		},
+		{
+			DNSName:    "db.internal.example.org",
+			RecordType: endpoint.RecordTypeA,
+			Targets:    endpoint.Targets{"10.0.0.2"},
+		},
+		{
+			DNSName:    "www.example.org",
+			RecordType: endpoint.RecordTypeCNAME,
+			Targets:    endpoint.Targets{"app.example.org"},
+		},
+	}, nil)
+
+	external := &filteredMockProvider{
+		RecordsStore: []*endpoint.Endpoint{
+			{DNSName: "www.example.org", RecordType: endpoint.RecordTypeCNAME, Targets: endpoint.Targets{"app.example.org"}},
+		},
+	}
+	internal := &filteredMockProvider{}
+
+	r, err := registry.NewNoopRegistry(external)
+	require.NoError(t, err)
+	internalRegistry, err := registry.NewNoopRegistry(internal)
+	require.NoError(t, err)
+
+	ctrl := &Controller{
+		Source:             source,
+		Registry:           r,
+		InternalRegistry:   internalRegistry,
+		SplitHorizonRouter: NewSplitHorizonRouter([]string{"internal.example.org"}, nil),
+		Policy:             &plan.SyncPolicy{},
+		ManagedRecordTypes: []string{endpoint.RecordTypeA, endpoint.RecordTypeCNAME},
+	}
+
+	require.NoError(t, ctrl.RunOnce(context.Background()))
+
+	require.Len(t, external.ApplyChangesCalls, 1)
+	assert.Equal(t, []*endpoint.Endpoint{
+		{DNSName: "app.example.org", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"203.0.113.1"}, Labels: endpoint.Labels{}},
+	}, external.ApplyChangesCalls[0].Create)
+	assert.Empty(t, external.ApplyChangesCalls[0].Delete)
+
+	require.Len(t, internal.ApplyChangesCalls, 1)
+	assert.ElementsMatch(t, []*endpoint.Endpoint{
+		{DNSName: "app.example.org", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"10.0.0.1"}, Labels: endpoint.Labels{}},
+		{DNSName: "db.internal.example.org", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"10.0.0.2"}},
+	}, internal.ApplyChangesCalls[0].Create)
+}
+
+func TestRunOnceSplitHorizonReconcilesBothViews(t *testing.T) {
+	source := new(testutils.MockSource)
+	source.On("Endpoints").Return([]*endpoint.Endpoint{
+		{DNSName: "app.example.org", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"203.0.113.1"}},
+		{DNSName: "db.internal.example.org", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"10.0.0.2"}},
+	}, nil)
+
+	external := &filteredMockProvider{ApplyChangesErr: errors.New("external failure")}
+	internal := &filteredMockProvider{}
+
+	r, err := registry.NewNoopRegistry(external)
+	require.NoError(t, err)
+	internalRegistry, err := registry.NewNoopRegistry(internal)
+	require.NoError(t, err)
+
+	ctrl := &Controller{
+		Source:             source,
+		Registry:           r,
+		InternalRegistry:   internalRegistry,
+		SplitHorizonRouter: NewSplitHorizonRouter([]string{"internal.example.org"}, nil),
+		Policy:             &plan.SyncPolicy{},
+		ManagedRecordTypes: []string{endpoint.RecordTypeA},
+	}
+
+	err = ctrl.RunOnce(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "external failure")
+	require.Len(t, internal.ApplyChangesCalls, 1, "expected the internal view to be reconciled despite the external failure")
+	assert.Equal(t, "db.internal.example.org", internal.ApplyChangesCalls[0].Create[0].DNSName)
+
+	internal.ApplyChangesErr = errors.New("internal failure")
+	err = ctrl.RunOnce(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "external failure")
+	assert.Contains(t, err.Error(), "internal failure")
+}
+
+func TestRunOnceDropsInternalEndpointsWithoutInternalRegistry(t *testing.T) {
+	source := new(testutils.MockSource)
+	source.On("Endpoints").Return([]*endpoint.Endpoint{
+		{
+			DNSName:    "app.example.org",
+			RecordType: endpoint.RecordTypeA,
+			Targets:    endpoint.Targets{"10.0.0.1"},
+			Labels:     endpoint.Labels{endpoint.SplitHorizonLabelKey: endpoint.SplitHorizonInternal},
+		},
+		{
+			DNSName:    "app.example.org",
+			RecordType: endpoint.RecordTypeA,
+			Targets:    endpoint.Targets{"203.0.113.1"},
+			Labels:     endpoint.Labels{endpoint.SplitHorizonLabelKey: endpoint.SplitHorizonExternal},
+		},
+	}, nil)
+
+	external := &filteredMockProvider{}
+	r, err := registry.NewNoopRegistry(external)
+	require.NoError(t, err)
+
+	ctrl := &Controller{
+		Source:             source,
+		Registry:           r,
+		Policy:             &plan.SyncPolicy{},
+		ManagedRecordTypes: []string{endpoint.RecordTypeA},
+	}
+
+	require.NoError(t, ctrl.RunOnce(context.Background()))
+
+	require.Len(t, external.ApplyChangesCalls, 1)
+	assert.Equal(t, []*endpoint.Endpoint{
+		{DNSName: "app.example.org", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"203.0.113.1"}, Labels: endpoint.Labels{}},
+	}, external.ApplyChangesCalls[0].Create)
+}
+
+func TestRunOnceWritesChanges(t *testing.T) {
+	source := new(testutils.MockSource)
+	source.On("Endpoints").Return([]*endpoint.Endpoint{
+		{DNSName: "new.example.org", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"192.0.2.1"}},
+	}, nil)
+
+	provider := &filteredMockProvider{}
+	r, err := registry.NewNoopRegistry(provider)
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	ctrl := &Controller{
+		Source:              source,
+		Registry:            r,
+		Policy:              &plan.SyncPolicy{},
+		ManagedRecordTypes:  []string{endpoint.RecordTypeA},
+		ChangesOutput:       &out,
+		ChangesOutputFormat: plan.OutputFormatJSON,
+	}
+
+	require.NoError(t, ctrl.RunOnce(context.Background()))
+	assert.JSONEq(t, `{"create":[{"dnsName":"new.example.org","recordType":"A","targets":["192.0.2.1"]}],"update":[],"delete":[]}`, out.String())
+}
+
+func TestRunOnceWithOwnerOverride(t *testing.T) {
+	source := new(testutils.MockSource)
+	source.On("Endpoints").Return([]*endpoint.Endpoint{
+		{
+			DNSName:          "new.example.org",
+			RecordType:       endpoint.RecordTypeA,
+			Targets:          endpoint.Targets{"192.0.2.1"},
+			ProviderSpecific: endpoint.ProviderSpecific{{Name: "external-dns.alpha.kubernetes.io/owner", Value: "pinned-owner"}},
+		},
+	}, nil)
+
+	provider := &filteredMockProvider{}
+	r, err := registry.NewNoopRegistry(provider)
+	require.NoError(t, err)
+
+	ctrl := &Controller{
+		Source:             source,
+		Registry:           r,
+		Policy:             &plan.SyncPolicy{},
+		ManagedRecordTypes: []string{endpoint.RecordTypeA},
+	}
+
+	require.NoError(t, ctrl.RunOnce(context.Background()))
+	require.Len(t, provider.ApplyChangesCalls, 1)
+	assert.Equal(t, []*endpoint.Endpoint{
+		{
+			DNSName:          "new.example.org",
+			RecordType:       endpoint.RecordTypeA,
+			Targets:          endpoint.Targets{"192.0.2.1"},
+			Labels:           endpoint.Labels{endpoint.OwnerOverrideLabelKey: "pinned-owner"},
+			ProviderSpecific: endpoint.ProviderSpecific{},
+		},
+	}, provider.ApplyChangesCalls[0].Create)
+}
+
+// partialFailureMockProvider reports the creation of the records as failed.
+type partialFailureMockProvider struct {
+	filteredMockProvider
+}
+
+func (p *partialFailureMockProvider) ApplyChanges(ctx context.Context, changes *plan.Changes) error {
+	return &provider.ChangesError{Failed: &plan.Changes{Create: changes.Create}, Err: errors.New("boom")}
+}
+
+func TestRunOnceReportsFailedChanges(t *testing.T) {
+	source := new(testutils.MockSource)
+	source.On("Endpoints").Return([]*endpoint.Endpoint{
+		{DNSName: "new.example.org", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"192.0.2.1"}},
+		{DNSName: "other.example.org", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"192.0.2.2"}},
+	}, nil)
+
+	r, err := registry.NewNoopRegistry(&partialFailureMockProvider{})
+	require.NoError(t, err)
+
+	ctrl := &Controller{
+		Source:             source,
+		Registry:           r,
+		Policy:             &plan.SyncPolicy{},
+		ManagedRecordTypes: []string{endpoint.RecordTypeA},
+	}
+
+	before := testutil.ToFloat64(controllerFailedChangesTotal)
+	err = ctrl.RunOnce(context.Background())
+	var changesErr *provider.ChangesError
+	require.True(t, errors.As(err, &changesErr))
+	assert.Equal(t, before+2, testutil.ToFloat64(controllerFailedChangesTotal))
+}
+
+// validatingMockProvider rejects the creation of the records when validating the changes.
+type validatingMockProvider struct {
+	filteredMockProvider
+	validated int
+}
+
+func (p *validatingMockProvider) ValidateChanges(ctx context.Context, changes *plan.Changes) error {
+	p.validated++
+	return &provider.ChangesError{Failed: &plan.Changes{Create: changes.Create}, Err: errors.New("unsupported record")}
+}
+
+func TestRunOnceDryRunValidatesChanges(t *testing.T) {
+	source := new(testutils.MockSource)
+	source.On("Endpoints").Return([]*endpoint.Endpoint{
+		{DNSName: "new.example.org", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"192.0.2.1"}},
+	}, nil)
+
+	p := &validatingMockProvider{}
+	r, err := registry.NewNoopRegistry(p)
+	require.NoError(t, err)
+
+	ctrl := &Controller{
+		Source:             source,
+		Registry:           r,
+		Policy:             &plan.SyncPolicy{},
+		ManagedRecordTypes: []string{endpoint.RecordTypeA},
+	}
+
+	require.NoError(t, ctrl.RunOnce(context.Background()))
+	assert.Equal(t, 0, p.validated, "expected no validation without dry-run")
+	assert.Len(t, p.ApplyChangesCalls, 1)
+
+	ctrl.DryRun = true
+	err = ctrl.RunOnce(context.Background())
+	var changesErr *provider.ChangesError
+	require.True(t, errors.As(err, &changesErr))
+	assert.Equal(t, 1, p.validated)
+	assert.Len(t, p.ApplyChangesCalls, 1, "expected the rejected changes not to be applied")
+
+	// providers unable to validate the changes are left to log them
+	nonValidating := &filteredMockProvider{}
+	ctrl.Registry, err = registry.NewNoopRegistry(nonValidating)
+	require.NoError(t, err)
+	require.NoError(t, ctrl.RunOnce(context.Background()))
+	assert.Len(t, nonValidating.ApplyChangesCalls, 1)
+}
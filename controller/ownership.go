@@ -0,0 +1,57 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/source"
+)
+
+// withOwnerOverrides moves the owner annotation of the originating resources of the endpoints to
+// the owner-override label, so that the registry registers the records with the pinned owner id.
+func withOwnerOverrides(endpoints []*endpoint.Endpoint) []*endpoint.Endpoint {
+	result := make([]*endpoint.Endpoint, 0, len(endpoints))
+	for _, ep := range endpoints {
+		owner, annotated := "", false
+		for _, property := range ep.ProviderSpecific {
+			if property.Name == source.OwnerKey {
+				owner, annotated = property.Value, true
+			}
+		}
+		if !annotated {
+			result = append(result, ep)
+			continue
+		}
+
+		ep = ep.DeepCopy()
+		providerSpecific := endpoint.ProviderSpecific{}
+		for _, property := range ep.ProviderSpecific {
+			if property.Name != source.OwnerKey {
+				providerSpecific = append(providerSpecific, property)
+			}
+		}
+		ep.ProviderSpecific = providerSpecific
+		if owner != "" {
+			if ep.Labels == nil {
+				ep.Labels = endpoint.NewLabels()
+			}
+			ep.Labels[endpoint.OwnerOverrideLabelKey] = owner
+		}
+		result = append(result, ep)
+	}
+	return result
+}
@@ -0,0 +1,181 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/source"
+)
+
+// SplitHorizonRouter decides which provider, internal or external, a desired endpoint is managed by.
+// The routing is decided by, in order of precedence:
+// * the split-horizon label set by a transformer,
+// * the split-horizon annotation of the originating resource,
+// * the source the endpoint originates from,
+// * the domain suffix of the endpoint.
+// Endpoints not matching any rule are routed to the external provider.
+type SplitHorizonRouter struct {
+	internalDomains endpoint.DomainFilter
+	internalSources map[string]struct{}
+}
+
+// NewSplitHorizonRouter returns a router sending endpoints below the given domains
+// or originating from the given source types to the internal provider.
+func NewSplitHorizonRouter(internalDomains, internalSources []string) *SplitHorizonRouter {
+	sources := make(map[string]struct{}, len(internalSources))
+	for _, s := range internalSources {
+		sources[strings.ToLower(s)] = struct{}{}
+	}
+
+	return &SplitHorizonRouter{
+		internalDomains: endpoint.NewDomainFilter(internalDomains),
+		internalSources: sources,
+	}
+}
+
+// Route returns the split-horizon view of an endpoint.
+func (r *SplitHorizonRouter) Route(ep *endpoint.Endpoint) string {
+	if horizon, ok := explicitSplitHorizon(ep); ok {
+		return horizon
+	}
+
+	if r == nil {
+		return endpoint.SplitHorizonExternal
+	}
+	if resource, ok := ep.Labels[endpoint.ResourceLabelKey]; ok {
+		kind := strings.SplitN(resource, "/", 2)[0]
+		if _, ok := r.internalSources[strings.ToLower(kind)]; ok {
+			return endpoint.SplitHorizonInternal
+		}
+	}
+	if r.internalDomains.IsConfigured() && r.internalDomains.Match(ep.DNSName) {
+		return endpoint.SplitHorizonInternal
+	}
+
+	return endpoint.SplitHorizonExternal
+}
+
+// Split partitions endpoints into those managed by the internal and by the external provider.
+// Endpoints routed to both views are returned in both lists.
+func (r *SplitHorizonRouter) Split(endpoints []*endpoint.Endpoint) (internal, external []*endpoint.Endpoint) {
+	for _, ep := range endpoints {
+		horizon := r.Route(ep)
+		ep = withoutSplitHorizon(ep)
+
+		switch horizon {
+		case endpoint.SplitHorizonInternal:
+			internal = append(internal, ep)
+		case endpoint.SplitHorizonBoth:
+			internal = append(internal, ep.DeepCopy())
+			external = append(external, ep)
+		default:
+			external = append(external, ep)
+		}
+	}
+
+	return internal, external
+}
+
+// externalOnly returns the endpoints to manage without an internal provider, i.e. without those
+// labelled or annotated for the internal view only: e.g. those labelled by a transformer keep the
+// private targets rewritten in their external counterpart, which must not reach the external provider.
+func externalOnly(endpoints []*endpoint.Endpoint) []*endpoint.Endpoint {
+	external := make([]*endpoint.Endpoint, 0, len(endpoints))
+	for _, ep := range endpoints {
+		if horizon, ok := explicitSplitHorizon(ep); ok && horizon == endpoint.SplitHorizonInternal {
+			log.Debugf("Skipping endpoint %s intended for the internal view, no internal provider is configured", ep)
+			continue
+		}
+		external = append(external, withoutSplitHorizon(ep))
+	}
+	return external
+}
+
+// explicitSplitHorizon returns the split-horizon view set by the label of a transformer or,
+// failing that, by the annotation of the originating resource.
+func explicitSplitHorizon(ep *endpoint.Endpoint) (string, bool) {
+	if horizon, ok := ep.Labels[endpoint.SplitHorizonLabelKey]; ok {
+		return parseSplitHorizon(ep, horizon), true
+	}
+	for _, property := range ep.ProviderSpecific {
+		if property.Name == source.SplitHorizonKey {
+			return parseSplitHorizon(ep, property.Value), true
+		}
+	}
+	return "", false
+}
+
+// splitHorizonError combines the errors of the external and internal views, if any.
+func splitHorizonError(externalErr, internalErr error) error {
+	switch {
+	case externalErr != nil && internalErr != nil:
+		return fmt.Errorf("external view: %v; internal view: %w", externalErr, internalErr)
+	case externalErr != nil:
+		return fmt.Errorf("external view: %w", externalErr)
+	case internalErr != nil:
+		return fmt.Errorf("internal view: %w", internalErr)
+	}
+	return nil
+}
+
+func parseSplitHorizon(ep *endpoint.Endpoint, horizon string) string {
+	switch strings.ToLower(horizon) {
+	case endpoint.SplitHorizonInternal:
+		return endpoint.SplitHorizonInternal
+	case endpoint.SplitHorizonBoth:
+		return endpoint.SplitHorizonBoth
+	case endpoint.SplitHorizonExternal:
+		return endpoint.SplitHorizonExternal
+	default:
+		log.Warnf("Unknown split-horizon %q for endpoint %s, routing it to the external provider", horizon, ep)
+		return endpoint.SplitHorizonExternal
+	}
+}
+
+// withoutSplitHorizon returns the endpoint stripped of its routing information,
+// so that it is neither stored in the registry nor passed to the provider.
+func withoutSplitHorizon(ep *endpoint.Endpoint) *endpoint.Endpoint {
+	_, labelled := ep.Labels[endpoint.SplitHorizonLabelKey]
+	annotated := false
+	for _, property := range ep.ProviderSpecific {
+		if property.Name == source.SplitHorizonKey {
+			annotated = true
+		}
+	}
+	if !labelled && !annotated {
+		return ep
+	}
+
+	ep = ep.DeepCopy()
+	delete(ep.Labels, endpoint.SplitHorizonLabelKey)
+	if annotated {
+		providerSpecific := endpoint.ProviderSpecific{}
+		for _, property := range ep.ProviderSpecific {
+			if property.Name != source.SplitHorizonKey {
+				providerSpecific = append(providerSpecific, property)
+			}
+		}
+		ep.ProviderSpecific = providerSpecific
+	}
+
+	return ep
+}
@@ -0,0 +1,139 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/internal/testutils"
+	"sigs.k8s.io/external-dns/plan"
+	"sigs.k8s.io/external-dns/registry"
+	"sigs.k8s.io/external-dns/source"
+)
+
+func TestSplitHorizonRouterRoute(t *testing.T) {
+	router := NewSplitHorizonRouter([]string{"internal.example.org"}, []string{"Node"})
+
+	for _, tc := range []struct {
+		title    string
+		endpoint *endpoint.Endpoint
+		expected string
+	}{
+		{
+			title:    "no rule matches",
+			endpoint: endpoint.NewEndpoint("www.example.org", endpoint.RecordTypeA, "203.0.113.1"),
+			expected: endpoint.SplitHorizonExternal,
+		},
+		{
+			title:    "domain suffix",
+			endpoint: endpoint.NewEndpoint("db.internal.example.org", endpoint.RecordTypeA, "10.0.0.1"),
+			expected: endpoint.SplitHorizonInternal,
+		},
+		{
+			title: "source",
+			endpoint: &endpoint.Endpoint{
+				DNSName: "node1.example.org",
+				Labels:  endpoint.Labels{endpoint.ResourceLabelKey: "node/node1"},
+			},
+			expected: endpoint.SplitHorizonInternal,
+		},
+		{
+			title: "label",
+			endpoint: &endpoint.Endpoint{
+				DNSName: "db.internal.example.org",
+				Labels:  endpoint.Labels{endpoint.SplitHorizonLabelKey: endpoint.SplitHorizonExternal},
+			},
+			expected: endpoint.SplitHorizonExternal,
+		},
+		{
+			title:    "annotation",
+			endpoint: endpoint.NewEndpoint("www.example.org", endpoint.RecordTypeA, "203.0.113.1").WithProviderSpecific(source.SplitHorizonKey, "Both"),
+			expected: endpoint.SplitHorizonBoth,
+		},
+		{
+			title:    "unknown annotation value",
+			endpoint: endpoint.NewEndpoint("db.internal.example.org", endpoint.RecordTypeA, "10.0.0.1").WithProviderSpecific(source.SplitHorizonKey, "private"),
+			expected: endpoint.SplitHorizonExternal,
+		},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			assert.Equal(t, tc.expected, router.Route(tc.endpoint))
+		})
+	}
+}
+
+func TestSplitHorizonRouterSplit(t *testing.T) {
+	both := endpoint.NewEndpoint("www.example.org", endpoint.RecordTypeA, "203.0.113.1").
+		WithProviderSpecific(source.SplitHorizonKey, endpoint.SplitHorizonBoth).
+		WithProviderSpecific("alias", "false")
+	internal := endpoint.NewEndpoint("db.internal.example.org", endpoint.RecordTypeA, "10.0.0.1")
+	external := endpoint.NewEndpoint("api.example.org", endpoint.RecordTypeA, "203.0.113.2")
+
+	var router *SplitHorizonRouter
+	internalEndpoints, externalEndpoints := router.Split([]*endpoint.Endpoint{both, internal, external})
+	expectedBoth := endpoint.NewEndpoint("www.example.org", endpoint.RecordTypeA, "203.0.113.1").WithProviderSpecific("alias", "false")
+	assert.Equal(t, []*endpoint.Endpoint{expectedBoth}, internalEndpoints)
+	assert.Equal(t, []*endpoint.Endpoint{expectedBoth, internal, external}, externalEndpoints)
+	assert.Len(t, both.ProviderSpecific, 2, "the source endpoint must not be modified")
+
+	router = NewSplitHorizonRouter([]string{"internal.example.org"}, nil)
+	internalEndpoints, externalEndpoints = router.Split([]*endpoint.Endpoint{both, internal, external})
+	assert.Equal(t, []*endpoint.Endpoint{expectedBoth, internal}, internalEndpoints)
+	assert.Equal(t, []*endpoint.Endpoint{expectedBoth, external}, externalEndpoints)
+}
+
+func TestRunOnceDropsAnnotatedInternalEndpointsWithoutInternalRegistry(t *testing.T) {
+	src := new(testutils.MockSource)
+	src.On("Endpoints").Return([]*endpoint.Endpoint{
+		{
+			DNSName:          "private.example.org",
+			RecordType:       endpoint.RecordTypeA,
+			Targets:          endpoint.Targets{"10.0.0.1"},
+			ProviderSpecific: endpoint.ProviderSpecific{{Name: source.SplitHorizonKey, Value: "internal"}},
+		},
+		{
+			DNSName:          "public.example.org",
+			RecordType:       endpoint.RecordTypeA,
+			Targets:          endpoint.Targets{"203.0.113.1"},
+			ProviderSpecific: endpoint.ProviderSpecific{{Name: source.SplitHorizonKey, Value: "both"}},
+		},
+	}, nil)
+
+	external := &filteredMockProvider{}
+	r, err := registry.NewNoopRegistry(external)
+	require.NoError(t, err)
+
+	ctrl := &Controller{
+		Source:             src,
+		Registry:           r,
+		Policy:             &plan.SyncPolicy{},
+		ManagedRecordTypes: []string{endpoint.RecordTypeA},
+	}
+
+	require.NoError(t, ctrl.RunOnce(context.Background()))
+
+	require.Len(t, external.ApplyChangesCalls, 1)
+	created := external.ApplyChangesCalls[0].Create
+	require.Len(t, created, 1)
+	assert.Equal(t, "public.example.org", created[0].DNSName)
+	assert.Empty(t, created[0].ProviderSpecific)
+}
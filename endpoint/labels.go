@@ -41,6 +41,27 @@ const (
 
 	// DualstackLabelKey is the name of the label that identifies dualstack endpoints
 	DualstackLabelKey = "dualstack"
+
+	// MissingSyncsLabelKey is the name of the label that counts the consecutive synchronizations
+	// a record has been missing from the desired records for, when deletions are delayed
+	MissingSyncsLabelKey = "missing-syncs"
+
+	// OwnerOverrideLabelKey is the name of the label that pins the owner id a record is registered with,
+	// instead of the owner id of the registry
+	OwnerOverrideLabelKey = "owner-override"
+
+	// OutdatedOwnershipLabelKey is the name of the label set by registries on records whose ownership
+	// information is outdated, e.g. encrypted with a previous key, forcing the records to be updated
+	OutdatedOwnershipLabelKey = "outdated-ownership"
+
+	// SplitHorizonLabelKey is the name of the label that identifies the view, internal or external, an endpoint is intended for
+	SplitHorizonLabelKey = "split-horizon"
+	// SplitHorizonInternal is the SplitHorizonLabelKey value of endpoints intended for internal zones
+	SplitHorizonInternal = "internal"
+	// SplitHorizonExternal is the SplitHorizonLabelKey value of endpoints intended for public zones
+	SplitHorizonExternal = "external"
+	// SplitHorizonBoth is the SplitHorizonLabelKey value of endpoints intended for both internal and public zones
+	SplitHorizonBoth = "both"
 )
 
 // Labels store metadata related to the endpoint
@@ -0,0 +1,47 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoint
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseMXTarget parses the target of an MX record, "priority exchange", and returns it in
+// canonical form: the exchange name in lowercase without trailing dot. The root exchange name
+// of a null MX record, RFC 7505, is kept as is.
+func ParseMXTarget(target string) (string, error) {
+	fields := strings.Fields(target)
+	if len(fields) != 2 {
+		return "", fmt.Errorf("invalid MX target %q: expected priority exchange", target)
+	}
+
+	priority, err := strconv.ParseUint(fields[0], 10, 16)
+	if err != nil {
+		return "", fmt.Errorf("invalid MX target %q: invalid priority %q", target, fields[0])
+	}
+	exchange := fields[1]
+	if exchange != "." {
+		exchange = strings.ToLower(strings.TrimSuffix(exchange, "."))
+		if exchange == "" || strings.Contains(exchange, "..") {
+			return "", fmt.Errorf("invalid MX target %q: invalid exchange %q", target, fields[1])
+		}
+	}
+
+	return strconv.FormatUint(priority, 10) + " " + exchange, nil
+}
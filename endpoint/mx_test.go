@@ -0,0 +1,49 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoint
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMXTarget(t *testing.T) {
+	for _, tc := range []struct {
+		target      string
+		expected    string
+		expectError bool
+	}{
+		{target: "10 mx1.example.org.", expected: "10 mx1.example.org"},
+		{target: "010 MX1.Example.org", expected: "10 mx1.example.org"},
+		{target: "0 .", expected: "0 ."},
+		{target: "mx1.example.org", expectError: true},
+		{target: "10 mx1.example.org mx2.example.org", expectError: true},
+		{target: "65536 mx1.example.org", expectError: true},
+		{target: "10 mx1..example.org", expectError: true},
+	} {
+		t.Run(tc.target, func(t *testing.T) {
+			target, err := ParseMXTarget(tc.target)
+			if tc.expectError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, target)
+		})
+	}
+}
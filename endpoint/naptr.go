@@ -0,0 +1,106 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoint
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseNAPTRTarget parses the target of a NAPTR record, RFC 3403,
+// `order preference "flags" "service" "regexp" replacement`, and returns it in canonical form:
+// the character strings quoted and the replacement name in lowercase without trailing dot,
+// unless it is the root name.
+func ParseNAPTRTarget(target string) (string, error) {
+	fields, err := splitNAPTRFields(target)
+	if err != nil {
+		return "", fmt.Errorf("invalid NAPTR target %q: %w", target, err)
+	}
+	if len(fields) != 6 {
+		return "", fmt.Errorf(`invalid NAPTR target %q: expected order preference "flags" "service" "regexp" replacement`, target)
+	}
+
+	for i, field := range []string{"order", "preference"} {
+		if _, err := strconv.ParseUint(fields[i], 10, 16); err != nil {
+			return "", fmt.Errorf("invalid NAPTR target %q: invalid %s %q", target, field, fields[i])
+		}
+	}
+	flags := strings.ToUpper(fields[2])
+	for _, flag := range flags {
+		if (flag < 'A' || flag > 'Z') && (flag < '0' || flag > '9') {
+			return "", fmt.Errorf("invalid NAPTR target %q: invalid flags %q", target, fields[2])
+		}
+	}
+	replacement := fields[5]
+	if replacement != "." {
+		replacement = strings.ToLower(strings.TrimSuffix(replacement, "."))
+		if replacement == "" || strings.Contains(replacement, "..") {
+			return "", fmt.Errorf("invalid NAPTR target %q: invalid replacement %q", target, fields[5])
+		}
+	}
+	if fields[4] != "" && replacement != "." {
+		return "", fmt.Errorf("invalid NAPTR target %q: regexp and replacement are mutually exclusive", target)
+	}
+
+	order, _ := strconv.ParseUint(fields[0], 10, 16)
+	preference, _ := strconv.ParseUint(fields[1], 10, 16)
+	return fmt.Sprintf("%d %d %q %q %q %s", order, preference, flags, fields[3], fields[4], replacement), nil
+}
+
+// splitNAPTRFields splits the target of a NAPTR record in fields, unquoting the character strings.
+func splitNAPTRFields(target string) ([]string, error) {
+	var (
+		fields  []string
+		current strings.Builder
+		inField bool
+		quoted  bool
+	)
+	for i := 0; i < len(target); i++ {
+		c := target[i]
+		switch {
+		case quoted && c == '\\' && i+1 < len(target):
+			i++
+			current.WriteByte(target[i])
+		case c == '"':
+			if quoted {
+				fields = append(fields, current.String())
+				current.Reset()
+				inField = false
+			} else if inField {
+				return nil, fmt.Errorf("unexpected quote")
+			}
+			quoted = !quoted
+		case !quoted && (c == ' ' || c == '\t'):
+			if inField {
+				fields = append(fields, current.String())
+				current.Reset()
+				inField = false
+			}
+		default:
+			inField = true
+			current.WriteByte(c)
+		}
+	}
+	if quoted {
+		return nil, fmt.Errorf("unterminated quote")
+	}
+	if inField {
+		fields = append(fields, current.String())
+	}
+	return fields, nil
+}
@@ -0,0 +1,52 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoint
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseNAPTRTarget(t *testing.T) {
+	for _, tc := range []struct {
+		target      string
+		expected    string
+		expectError bool
+	}{
+		{target: `100 10 "u" "E2U+sip" "!^.*$!sip:info@example.org!" .`, expected: `100 10 "U" "E2U+sip" "!^.*$!sip:info@example.org!" .`},
+		{target: `10 0 "s" "SIP+D2U" "" _sip._udp.Example.org.`, expected: `10 0 "S" "SIP+D2U" "" _sip._udp.example.org`},
+		{target: `10 0 "" "" "" .`, expected: `10 0 "" "" "" .`},
+		{target: `10 0 "u" "E2U+sip" "!^(.*)$!sip:\"\\1\"@example.org!" .`, expected: `10 0 "U" "E2U+sip" "!^(.*)$!sip:\"\\1\"@example.org!" .`},
+		{target: `10 0 "s" "SIP+D2U" "" `, expectError: true},
+		{target: `x 0 "s" "SIP+D2U" "" .`, expectError: true},
+		{target: `10 70000 "s" "SIP+D2U" "" .`, expectError: true},
+		{target: `10 0 "s+" "SIP+D2U" "" .`, expectError: true},
+		{target: `10 0 "u" "E2U+sip" "!^.*$!sip:info@example.org!" sip.example.org`, expectError: true},
+		{target: `10 0 "u" "E2U+sip" "!^.*$!sip:info@example.org! .`, expectError: true},
+	} {
+		t.Run(tc.target, func(t *testing.T) {
+			target, err := ParseNAPTRTarget(tc.target)
+			if tc.expectError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, target)
+		})
+	}
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoint
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/net/idna"
+)
+
+// NormalizeDNSName returns the canonical form of a DNS name: without surrounding spaces and trailing
+// dot, in lowercase, and with its internationalized labels converted to punycode, so that names
+// read from a provider and names generated by a source compare equal. Labels which cannot be
+// converted are kept in lowercase.
+func NormalizeDNSName(dnsName string) string {
+	name := strings.TrimSuffix(strings.TrimSpace(dnsName), ".")
+	if name == "" {
+		return name
+	}
+
+	labels := strings.Split(name, ".")
+	for i, label := range labels {
+		labels[i] = toASCIILabel(label)
+	}
+	return strings.Join(labels, ".")
+}
+
+// ToASCIIName converts the internationalized labels of a DNS name to punycode, keeping the case
+// and the trailing dot of the name. Names made of ASCII labels only are returned unchanged.
+func ToASCIIName(dnsName string) string {
+	if isASCII(dnsName) {
+		return dnsName
+	}
+
+	labels := strings.Split(dnsName, ".")
+	for i, label := range labels {
+		if !isASCII(label) {
+			labels[i] = toASCIILabel(label)
+		}
+	}
+	return strings.Join(labels, ".")
+}
+
+// toASCIILabel converts a single label to lowercase ASCII. Underscore and wildcard labels are ASCII
+// already and never reach the IDNA conversion, which would reject them.
+func toASCIILabel(label string) string {
+	if isASCII(label) {
+		return strings.ToLower(label)
+	}
+	ascii, err := idna.Lookup.ToASCII(label)
+	if err != nil {
+		log.Debugf("Failed to convert label %q to punycode: %v", label, err)
+		return strings.ToLower(label)
+	}
+	return ascii
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
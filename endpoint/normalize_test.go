@@ -0,0 +1,58 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoint
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeDNSName(t *testing.T) {
+	for _, tc := range []struct {
+		dnsName  string
+		expected string
+	}{
+		{dnsName: "Foo.Example.ORG.", expected: "foo.example.org"},
+		{dnsName: "  foo.example.org  ", expected: "foo.example.org"},
+		{dnsName: "_acme-challenge.*.example.org", expected: "_acme-challenge.*.example.org"},
+		{dnsName: "bücher.example.org", expected: "xn--bcher-kva.example.org"},
+		{dnsName: "Bücher.example.org.", expected: "xn--bcher-kva.example.org"},
+		{dnsName: "xn--bcher-kva.example.org", expected: "xn--bcher-kva.example.org"},
+		{dnsName: "münchen.köln.de", expected: "xn--mnchen-3ya.xn--kln-sna.de"},
+		{dnsName: "", expected: ""},
+	} {
+		t.Run(tc.dnsName, func(t *testing.T) {
+			assert.Equal(t, tc.expected, NormalizeDNSName(tc.dnsName))
+		})
+	}
+}
+
+func TestToASCIIName(t *testing.T) {
+	for _, tc := range []struct {
+		dnsName  string
+		expected string
+	}{
+		{dnsName: "Foo.Example.org.", expected: "Foo.Example.org."},
+		{dnsName: "bücher.Example.org.", expected: "xn--bcher-kva.Example.org."},
+		{dnsName: "_sip._udp.bücher.example.org", expected: "_sip._udp.xn--bcher-kva.example.org"},
+	} {
+		t.Run(tc.dnsName, func(t *testing.T) {
+			assert.Equal(t, tc.expected, ToASCIIName(tc.dnsName))
+		})
+	}
+}
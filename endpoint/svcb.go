@@ -0,0 +1,142 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoint
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// svcbParamKeys are the SvcParamKeys of RFC 9460 with their numeric value, which defines
+// the canonical order of the parameters
+var svcbParamKeys = map[string]int{
+	"mandatory":       0,
+	"alpn":            1,
+	"no-default-alpn": 2,
+	"port":            3,
+	"ipv4hint":        4,
+	"ech":             5,
+	"ipv6hint":        6,
+}
+
+// ParseSVCBTarget parses the target of an HTTPS or SVCB record, "priority target [key=value...]",
+// and returns it in canonical form: the target name without trailing dot, unquoted values and
+// the parameters sorted by key, so that semantically equal targets compare equal.
+func ParseSVCBTarget(target string) (string, error) {
+	fields := strings.Fields(target)
+	if len(fields) < 2 {
+		return "", fmt.Errorf("invalid SVCB target %q: expected priority target [key=value...]", target)
+	}
+
+	priority, err := strconv.ParseUint(fields[0], 10, 16)
+	if err != nil {
+		return "", fmt.Errorf("invalid SVCB target %q: invalid priority %q", target, fields[0])
+	}
+	name := fields[1]
+	if name != "." {
+		name = strings.ToLower(strings.TrimSuffix(name, "."))
+	}
+	if priority == 0 && len(fields) > 2 {
+		return "", fmt.Errorf("invalid SVCB target %q: parameters are not allowed in alias mode", target)
+	}
+
+	type param struct {
+		order int
+		text  string
+	}
+	var params []param
+	seen := map[string]bool{}
+	for _, field := range fields[2:] {
+		key, value, hasValue := strings.Cut(field, "=")
+		key = strings.ToLower(key)
+		value = strings.Trim(value, `"`)
+		if seen[key] {
+			return "", fmt.Errorf("invalid SVCB target %q: duplicate parameter %s", target, key)
+		}
+		seen[key] = true
+
+		order, known := svcbParamKeys[key]
+		if !known {
+			n, err := strconv.ParseUint(strings.TrimPrefix(key, "key"), 10, 16)
+			if !strings.HasPrefix(key, "key") || err != nil {
+				return "", fmt.Errorf("invalid SVCB target %q: unknown parameter %s", target, key)
+			}
+			order = int(n)
+		}
+		if err := validateSVCBParam(key, value, hasValue); err != nil {
+			return "", fmt.Errorf("invalid SVCB target %q: %w", target, err)
+		}
+
+		text := key
+		if hasValue {
+			text = key + "=" + value
+		}
+		params = append(params, param{order: order, text: text})
+	}
+	sort.Slice(params, func(i, j int) bool { return params[i].order < params[j].order })
+
+	canonical := []string{strconv.FormatUint(priority, 10), name}
+	for _, p := range params {
+		canonical = append(canonical, p.text)
+	}
+	return strings.Join(canonical, " "), nil
+}
+
+func validateSVCBParam(key, value string, hasValue bool) error {
+	switch key {
+	case "no-default-alpn":
+		if hasValue {
+			return fmt.Errorf("parameter %s does not take a value", key)
+		}
+		return nil
+	case "port":
+		if _, err := strconv.ParseUint(value, 10, 16); err != nil {
+			return fmt.Errorf("invalid port %q", value)
+		}
+	case "ipv4hint", "ipv6hint":
+		for _, hint := range strings.Split(value, ",") {
+			ip := net.ParseIP(hint)
+			if ip == nil || (key == "ipv4hint") != (ip.To4() != nil) {
+				return fmt.Errorf("invalid %s %q", key, hint)
+			}
+		}
+	case "ech":
+		if _, err := base64.StdEncoding.DecodeString(value); err != nil {
+			return fmt.Errorf("invalid ech %q", value)
+		}
+	case "mandatory":
+		for _, k := range strings.Split(value, ",") {
+			if _, known := svcbParamKeys[k]; !known && !strings.HasPrefix(k, "key") {
+				return fmt.Errorf("invalid mandatory key %q", k)
+			}
+		}
+	case "alpn":
+		for _, id := range strings.Split(value, ",") {
+			if id == "" {
+				return fmt.Errorf("invalid alpn %q", value)
+			}
+		}
+	}
+	if value == "" && key != "no-default-alpn" {
+		return fmt.Errorf("parameter %s requires a value", key)
+	}
+	return nil
+}
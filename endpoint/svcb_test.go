@@ -0,0 +1,57 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoint
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSVCBTarget(t *testing.T) {
+	for _, tc := range []struct {
+		target      string
+		expected    string
+		expectError bool
+	}{
+		{target: "0 svc.example.org.", expected: "0 svc.example.org"},
+		{target: "1 .", expected: "1 ."},
+		{target: `1 . port=443 ALPN="h2,h3" no-default-alpn`, expected: "1 . alpn=h2,h3 no-default-alpn port=443"},
+		{target: "1 SVC.example.org ipv6hint=2001:db8::1 ipv4hint=192.0.2.1,192.0.2.2 key65000=foo", expected: "1 svc.example.org ipv4hint=192.0.2.1,192.0.2.2 ipv6hint=2001:db8::1 key65000=foo"},
+		{target: "1 . mandatory=alpn alpn=h2 ech=AEn+DQBFKwAgACB/", expected: "1 . mandatory=alpn alpn=h2 ech=AEn+DQBFKwAgACB/"},
+		{target: "1", expectError: true},
+		{target: "x .", expectError: true},
+		{target: "0 . alpn=h2", expectError: true},
+		{target: "1 . port=https", expectError: true},
+		{target: "1 . ipv4hint=2001:db8::1", expectError: true},
+		{target: "1 . ipv6hint=192.0.2.1", expectError: true},
+		{target: "1 . alpn=h2 alpn=h3", expectError: true},
+		{target: "1 . alpn", expectError: true},
+		{target: "1 . no-default-alpn=true", expectError: true},
+		{target: "1 . foo=bar", expectError: true},
+	} {
+		t.Run(tc.target, func(t *testing.T) {
+			target, err := ParseSVCBTarget(tc.target)
+			if tc.expectError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, target)
+		})
+	}
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoint
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// tlsaFields are the numeric fields of a TLSA record with their largest valid value, RFC 6698 and RFC 7218
+var tlsaFields = []struct {
+	name string
+	max  uint64
+}{
+	{name: "usage", max: 3},
+	{name: "selector", max: 1},
+	{name: "matching type", max: 2},
+}
+
+// tlsaDigestLengths are the lengths in bytes of the certificate association data of the digest matching types
+var tlsaDigestLengths = map[uint64]int{
+	1: 32, // SHA-256
+	2: 64, // SHA-512
+}
+
+// ParseTLSATarget parses the target of a TLSA record, "usage selector matching-type certdata",
+// and returns it in canonical form: the certificate association data as a single lowercase
+// hex string, so that semantically equal targets compare equal.
+func ParseTLSATarget(target string) (string, error) {
+	fields := strings.Fields(target)
+	if len(fields) < 4 {
+		return "", fmt.Errorf("invalid TLSA target %q: expected usage selector matching-type certdata", target)
+	}
+
+	values := make([]uint64, len(tlsaFields))
+	for i, field := range tlsaFields {
+		v, err := strconv.ParseUint(fields[i], 10, 8)
+		if err != nil || v > field.max {
+			return "", fmt.Errorf("invalid TLSA target %q: invalid %s %q", target, field.name, fields[i])
+		}
+		values[i] = v
+	}
+
+	// The certificate association data may be split in several fields, as in zone files.
+	certData := strings.ToLower(strings.Join(fields[3:], ""))
+	data, err := hex.DecodeString(certData)
+	if err != nil || len(data) == 0 {
+		return "", fmt.Errorf("invalid TLSA target %q: certificate association data must be hex encoded", target)
+	}
+	if length, ok := tlsaDigestLengths[values[2]]; ok && len(data) != length {
+		return "", fmt.Errorf("invalid TLSA target %q: expected %d bytes of certificate association data for matching type %d, got %d", target, length, values[2], len(data))
+	}
+
+	return fmt.Sprintf("%d %d %d %s", values[0], values[1], values[2], certData), nil
+}
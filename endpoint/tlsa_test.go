@@ -0,0 +1,56 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoint
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTLSATarget(t *testing.T) {
+	sha256 := "0d6fce3368bf9e6e78bc3f0153b31e676e3d91e1b1bc5a3d75d5e3bc7b0c1f22"
+	for _, tc := range []struct {
+		target      string
+		expected    string
+		expectError bool
+	}{
+		{target: "3 1 1 " + sha256, expected: "3 1 1 " + sha256},
+		{target: "3 1 1 " + strings.ToUpper(sha256[:32]) + " " + sha256[32:], expected: "3 1 1 " + sha256},
+		{target: "2 0 0 30820122300d06092a864886f70d01010105", expected: "2 0 0 30820122300d06092a864886f70d01010105"},
+		{target: "3 1 2 " + sha256 + sha256, expected: "3 1 2 " + sha256 + sha256},
+		{target: "3 1 1", expectError: true},
+		{target: "4 1 1 " + sha256, expectError: true},
+		{target: "3 2 1 " + sha256, expectError: true},
+		{target: "3 1 3 " + sha256, expectError: true},
+		{target: "DANE-EE 1 1 " + sha256, expectError: true},
+		{target: "3 1 1 " + sha256[:62], expectError: true},
+		{target: "3 1 2 " + sha256, expectError: true},
+		{target: "3 1 0 xyz", expectError: true},
+	} {
+		t.Run(tc.target, func(t *testing.T) {
+			target, err := ParseTLSATarget(tc.target)
+			if tc.expectError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, target)
+		})
+	}
+}
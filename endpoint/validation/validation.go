@@ -0,0 +1,171 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validation
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+const (
+	// ModeWarn drops the invalid endpoints with a warning
+	ModeWarn = "warn"
+	// ModeFail fails the synchronization when an endpoint is invalid
+	ModeFail = "fail"
+
+	// maxNameLength is the maximum length of a domain name in text form, without the trailing dot
+	maxNameLength = 253
+	// maxLabelLength is the maximum length of a single label of a domain name
+	maxLabelLength = 63
+	// MaxTTL is the largest valid TTL, RFC 2181
+	MaxTTL = 1<<31 - 1
+)
+
+// targetValidators check the syntax of a single target of the given record types, their
+// errors name the offending target.
+var targetValidators = map[string]func(target string) error{
+	endpoint.RecordTypeA:     validateIPv4,
+	"AAAA":                   validateIPv6,
+	endpoint.RecordTypeCNAME: ValidateName,
+	endpoint.RecordTypeNS:    ValidateName,
+	endpoint.RecordTypePTR:   ValidateName,
+	endpoint.RecordTypeSRV:   validateSRV,
+	endpoint.RecordTypeMX:    parsed(endpoint.ParseMXTarget),
+	endpoint.RecordTypeHTTPS: parsed(endpoint.ParseSVCBTarget),
+	endpoint.RecordTypeSVCB:  parsed(endpoint.ParseSVCBTarget),
+	endpoint.RecordTypeTLSA:  parsed(endpoint.ParseTLSATarget),
+	endpoint.RecordTypeNAPTR: parsed(endpoint.ParseNAPTRTarget),
+}
+
+// ValidateEndpoint checks that an endpoint can be published as is: its name is a valid domain name,
+// its TTL is within bounds and its targets are valid for its record type. The targets of record
+// types unknown to the validation are only required to be non-empty.
+func ValidateEndpoint(ep *endpoint.Endpoint) error {
+	var problems []string
+
+	if err := ValidateName(ep.DNSName); err != nil {
+		problems = append(problems, err.Error())
+	}
+	if ep.RecordTTL < 0 || ep.RecordTTL > MaxTTL {
+		problems = append(problems, fmt.Sprintf("TTL %d out of range [0, %d]", ep.RecordTTL, MaxTTL))
+	}
+	if len(ep.Targets) == 0 {
+		problems = append(problems, "no targets")
+	}
+
+	validate := targetValidators[ep.RecordType]
+	for _, target := range ep.Targets {
+		switch {
+		case strings.TrimSpace(target) == "":
+			problems = append(problems, "empty target")
+		case validate != nil:
+			if err := validate(target); err != nil {
+				problems = append(problems, err.Error())
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid endpoint %s %s: %s", ep.DNSName, ep.RecordType, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// ValidateName checks that name is a valid domain name: at most 253 characters, made of labels
+// of 1 to 63 letters, digits, hyphens and underscores, the hyphens being neither leading nor trailing.
+// The first label may be the "*" wildcard. A single trailing dot is accepted. Internationalized
+// names are checked in their punycode form, the one they are published in.
+func ValidateName(name string) error {
+	name = endpoint.ToASCIIName(strings.TrimSuffix(name, "."))
+	if name == "" {
+		return fmt.Errorf("empty name")
+	}
+	if len(name) > maxNameLength {
+		return fmt.Errorf("name %q longer than %d characters", name, maxNameLength)
+	}
+
+	for i, label := range strings.Split(name, ".") {
+		if i == 0 && label == "*" {
+			continue
+		}
+		if err := validateLabel(label); err != nil {
+			return fmt.Errorf("invalid name %q: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func validateLabel(label string) error {
+	if len(label) == 0 {
+		return fmt.Errorf("empty label")
+	}
+	if len(label) > maxLabelLength {
+		return fmt.Errorf("label %q longer than %d characters", label, maxLabelLength)
+	}
+	if label[0] == '-' || label[len(label)-1] == '-' {
+		return fmt.Errorf("label %q starts or ends with a hyphen", label)
+	}
+	for _, c := range label {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_') {
+			return fmt.Errorf("label %q contains invalid character %q", label, c)
+		}
+	}
+	return nil
+}
+
+func validateIPv4(target string) error {
+	if ip := net.ParseIP(target); ip == nil || ip.To4() == nil {
+		return fmt.Errorf("invalid A target %q: not an IPv4 address", target)
+	}
+	return nil
+}
+
+func validateIPv6(target string) error {
+	if ip := net.ParseIP(target); ip == nil || ip.To4() != nil {
+		return fmt.Errorf("invalid AAAA target %q: not an IPv6 address", target)
+	}
+	return nil
+}
+
+// validateSRV checks "priority weight port target".
+func validateSRV(target string) error {
+	fields := strings.Fields(target)
+	if len(fields) != 4 {
+		return fmt.Errorf("invalid SRV target %q: expected priority weight port target", target)
+	}
+	for i, field := range []string{"priority", "weight", "port"} {
+		if _, err := strconv.ParseUint(fields[i], 10, 16); err != nil {
+			return fmt.Errorf("invalid SRV target %q: invalid %s %q", target, field, fields[i])
+		}
+	}
+	if fields[3] == "." {
+		return nil
+	}
+	return ValidateName(fields[3])
+}
+
+// parsed adapts the target parsers of the endpoint package to validators.
+func parsed(parse func(string) (string, error)) func(string) error {
+	return func(target string) error {
+		_, err := parse(target)
+		return err
+	}
+}
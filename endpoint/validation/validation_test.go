@@ -0,0 +1,97 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validation
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+func TestValidateEndpoint(t *testing.T) {
+	for _, tc := range []struct {
+		title       string
+		endpoint    *endpoint.Endpoint
+		expectError bool
+	}{
+		{title: "a", endpoint: endpoint.NewEndpoint("foo.example.org", endpoint.RecordTypeA, "192.0.2.1", "192.0.2.2")},
+		{title: "aaaa", endpoint: endpoint.NewEndpoint("foo.example.org", "AAAA", "2001:db8::1")},
+		{title: "cname", endpoint: endpoint.NewEndpoint("foo.example.org", endpoint.RecordTypeCNAME, "lb.example.org.")},
+		{title: "wildcard", endpoint: endpoint.NewEndpoint("*.example.org.", endpoint.RecordTypeCNAME, "lb.example.org")},
+		{title: "srv", endpoint: &endpoint.Endpoint{DNSName: "_sip._udp.example.org", RecordType: endpoint.RecordTypeSRV, Targets: endpoint.Targets{"10 5 5060 sip.example.org", "0 0 0 ."}}},
+		{title: "txt", endpoint: endpoint.NewEndpoint("foo.example.org", endpoint.RecordTypeTXT, `"heritage=external-dns"`)},
+		{title: "mx", endpoint: endpoint.NewEndpoint("example.org", endpoint.RecordTypeMX, "10 mx.example.org")},
+		{title: "ttl", endpoint: endpoint.NewEndpointWithTTL("foo.example.org", endpoint.RecordTypeA, MaxTTL, "192.0.2.1")},
+		{title: "a with hostname target", endpoint: endpoint.NewEndpoint("foo.example.org", endpoint.RecordTypeA, "lb.example.org"), expectError: true},
+		{title: "a with ipv6 target", endpoint: endpoint.NewEndpoint("foo.example.org", endpoint.RecordTypeA, "2001:db8::1"), expectError: true},
+		{title: "aaaa with ipv4 target", endpoint: endpoint.NewEndpoint("foo.example.org", "AAAA", "192.0.2.1"), expectError: true},
+		{title: "cname with invalid target", endpoint: endpoint.NewEndpoint("foo.example.org", endpoint.RecordTypeCNAME, "lb..example.org"), expectError: true},
+		{title: "srv with invalid port", endpoint: endpoint.NewEndpoint("_sip._udp.example.org", endpoint.RecordTypeSRV, "10 5 65536 sip.example.org"), expectError: true},
+		{title: "mx without priority", endpoint: endpoint.NewEndpoint("example.org", endpoint.RecordTypeMX, "mx.example.org"), expectError: true},
+		{title: "no targets", endpoint: endpoint.NewEndpoint("foo.example.org", endpoint.RecordTypeA), expectError: true},
+		{title: "empty target", endpoint: endpoint.NewEndpoint("foo.example.org", endpoint.RecordTypeTXT, " "), expectError: true},
+		{title: "negative ttl", endpoint: endpoint.NewEndpointWithTTL("foo.example.org", endpoint.RecordTypeA, -1, "192.0.2.1"), expectError: true},
+		{title: "ttl too large", endpoint: endpoint.NewEndpointWithTTL("foo.example.org", endpoint.RecordTypeA, MaxTTL+1, "192.0.2.1"), expectError: true},
+		{title: "invalid name", endpoint: endpoint.NewEndpoint("foo bar.example.org", endpoint.RecordTypeA, "192.0.2.1"), expectError: true},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			err := ValidateEndpoint(tc.endpoint)
+			if tc.expectError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestValidateName(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		expectError bool
+	}{
+		{name: "example.org"},
+		{name: "example.org."},
+		{name: "_acme-challenge.example.org"},
+		{name: "*.example.org"},
+		{name: "xn--bcher-kva.example.org"},
+		{name: "bücher.example.org"},
+		{name: strings.Repeat("a", 63) + ".example.org"},
+		{name: strings.Repeat("a.", 126) + "a"},
+		{name: "", expectError: true},
+		{name: ".", expectError: true},
+		{name: "foo..example.org", expectError: true},
+		{name: "foo.*.example.org", expectError: true},
+		{name: "-foo.example.org", expectError: true},
+		{name: "foo-.example.org", expectError: true},
+		{name: "foo\x00.example.org", expectError: true},
+		{name: strings.Repeat("a", 64) + ".example.org", expectError: true},
+		{name: strings.Repeat("a.", 127) + "a", expectError: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateName(tc.name)
+			if tc.expectError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
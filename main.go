@@ -18,6 +18,7 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"os"
 	"os/signal"
@@ -54,13 +55,16 @@ import (
 	"sigs.k8s.io/external-dns/provider/ibmcloud"
 	"sigs.k8s.io/external-dns/provider/infoblox"
 	"sigs.k8s.io/external-dns/provider/inmemory"
+	"sigs.k8s.io/external-dns/provider/knot"
 	"sigs.k8s.io/external-dns/provider/linode"
+	"sigs.k8s.io/external-dns/provider/mikrotik"
 	"sigs.k8s.io/external-dns/provider/ns1"
 	"sigs.k8s.io/external-dns/provider/oci"
 	"sigs.k8s.io/external-dns/provider/ovh"
 	"sigs.k8s.io/external-dns/provider/pdns"
 	"sigs.k8s.io/external-dns/provider/rcode0"
 	"sigs.k8s.io/external-dns/provider/rdns"
+	"sigs.k8s.io/external-dns/provider/resolverfile"
 	"sigs.k8s.io/external-dns/provider/rfc2136"
 	"sigs.k8s.io/external-dns/provider/safedns"
 	"sigs.k8s.io/external-dns/provider/scaleway"
@@ -121,6 +125,46 @@ func main() {
 		PublishHostIP:                  cfg.PublishHostIP,
 		AlwaysPublishNotReadyAddresses: cfg.AlwaysPublishNotReadyAddresses,
 		ConnectorServer:                cfg.ConnectorSourceServer,
+		RemoteSourceURL:                cfg.RemoteSourceURL,
+		RemoteSourceAuthHeader:         cfg.RemoteSourceAuthHeader,
+		PluginSourceURL:                cfg.PluginSourceURL,
+		HostSourceDomain:               cfg.HostSourceDomain,
+		HostSourceInterfaces:           cfg.HostSourceInterfaces,
+		HostSourceUnitDir:              cfg.HostSourceUnitDir,
+		LibvirtSocket:                  cfg.LibvirtSocket,
+		LibvirtURI:                     cfg.LibvirtURI,
+		LibvirtAddressSource:           cfg.LibvirtAddressSource,
+		ProxmoxURL:                     cfg.ProxmoxURL,
+		ProxmoxToken:                   cfg.ProxmoxToken,
+		ProxmoxInsecure:                cfg.ProxmoxInsecure,
+		ProxmoxNodes:                   cfg.ProxmoxNodes,
+		ProxmoxPools:                   cfg.ProxmoxPools,
+		ProxmoxTagPrefix:               cfg.ProxmoxTagPrefix,
+		NetBoxURL:                      cfg.NetBoxURL,
+		NetBoxToken:                    cfg.NetBoxToken,
+		NetBoxTags:                     cfg.NetBoxTags,
+		NetBoxCustomFields:             cfg.NetBoxCustomFields,
+		NetBoxCreatePTR:                cfg.NetBoxCreatePTR,
+		MQTTBroker:                     cfg.MQTTBroker,
+		MQTTTopic:                      cfg.MQTTTopic,
+		MQTTClientID:                   cfg.MQTTClientID,
+		MQTTUsername:                   cfg.MQTTUsername,
+		MQTTPassword:                   cfg.MQTTPassword,
+		PrometheusSDURL:                cfg.PrometheusSDURL,
+		PrometheusSDAuthHeader:         cfg.PrometheusSDAuthHeader,
+		PrometheusSDHostnameLabel:      cfg.PrometheusSDHostnameLabel,
+		PrometheusSDTTLLabel:           cfg.PrometheusSDTTLLabel,
+		ComposeFiles:                   cfg.ComposeFiles,
+		ComposeDefaultTargets:          cfg.ComposeDefaultTargets,
+		PodmanSocket:                   cfg.PodmanSocket,
+		PodmanDefaultTargets:           cfg.PodmanDefaultTargets,
+		ContainerdNerdctl:              cfg.ContainerdNerdctl,
+		ContainerdAddress:              cfg.ContainerdAddress,
+		ContainerdNamespaces:           cfg.ContainerdNamespaces,
+		ContainerdDefaultTargets:       cfg.ContainerdDefaultTargets,
+		DNSEndpointPaths:               cfg.DNSEndpointPaths,
+		SourceDomainFilters:            cfg.SourceDomainFilters,
+		SourceExcludeDomains:           cfg.SourceExcludeDomains,
 		CRDSourceAPIVersion:            cfg.CRDSourceAPIVersion,
 		CRDSourceKind:                  cfg.CRDSourceKind,
 		KubeConfig:                     cfg.KubeConfig,
@@ -137,6 +181,8 @@ func main() {
 		OCPRouterName:                  cfg.OCPRouterName,
 	}
 
+	source.SetAnnotationPrefixAliases(cfg.AnnotationPrefixAliases)
+
 	// Lookup all the selected sources by names and pass them the desired configuration.
 	sources, err := source.ByNames(ctx, &source.SingletonClientGenerator{
 		KubeConfig:   cfg.KubeConfig,
@@ -157,9 +203,23 @@ func main() {
 	targetFilter := endpoint.NewTargetNetFilterWithExclusions(cfg.TargetNetFilter, cfg.ExcludeTargetNets)
 
 	// Combine multiple sources into a single, deduplicated source.
-	endpointsSource := source.NewDedupSource(source.NewMultiSource(sources, sourceCfg.DefaultTargets))
+	endpointsSource := source.NewDedupSource(source.NewMultiSource(sources, sourceCfg.DefaultTargets, cfg.SourceConflictStrategy))
 	endpointsSource = source.NewTargetFilterSource(endpointsSource, targetFilter)
 
+	// Transform the endpoints before they are planned
+	transformers, err := source.BuildTransformers(cfg.Transformers, cfg.TransformerConfig)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if len(transformers) > 0 {
+		endpointsSource = source.NewTransformerSource(endpointsSource, transformers)
+	}
+
+	// Validate the endpoints before they are planned
+	if cfg.EndpointValidation != "" {
+		endpointsSource = source.NewValidationSource(endpointsSource, cfg.EndpointValidation)
+	}
+
 	// RegexDomainFilter overrides DomainFilter
 	var domainFilter endpoint.DomainFilter
 	if cfg.RegexDomainFilter.String() != "" {
@@ -167,13 +227,140 @@ func main() {
 	} else {
 		domainFilter = endpoint.NewDomainFilterWithExclusions(cfg.DomainFilter, cfg.ExcludeDomains)
 	}
+
+	p, err := buildProvider(ctx, cfg, cfg.Provider, endpointsSource, domainFilter)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	r, err := buildRegistry(cfg, p)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// A second provider maintains the internal view of split-horizon zones
+	var internalRegistry registry.Registry
+	if cfg.InternalProvider != "" {
+		internalCfg, internalDomainFilter := *cfg, domainFilter
+		if len(cfg.InternalDomainFilter) > 0 {
+			internalDomainFilter = endpoint.NewDomainFilterWithExclusions(cfg.InternalDomainFilter, cfg.ExcludeDomains)
+		}
+		if len(cfg.InternalZoneIDFilter) > 0 {
+			internalCfg.ZoneIDFilter = cfg.InternalZoneIDFilter
+		}
+		if cfg.InternalAWSZoneType != "" {
+			internalCfg.AWSZoneType = cfg.InternalAWSZoneType
+		}
+
+		internalProvider, err := buildProvider(ctx, &internalCfg, cfg.InternalProvider, endpointsSource, internalDomainFilter)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		internalRegistry, err = buildRegistry(&internalCfg, internalProvider)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	policy, exists := plan.Policies[cfg.Policy]
+	if !exists {
+		log.Fatalf("unknown policy: %s", cfg.Policy)
+	}
+	if cfg.WeightedTargetsProperty != "" {
+		policy = &plan.WeightedPolicy{Policy: policy, WeightsProperty: source.TargetWeightsKey, WeightProperty: cfg.WeightedTargetsProperty}
+	}
+	if cfg.MaxCreatesPerSync > 0 || cfg.MaxUpdatesPerSync > 0 || cfg.MaxDeletesPerSync > 0 {
+		policy = &plan.ChangeLimitPolicy{Policy: policy, MaxCreates: cfg.MaxCreatesPerSync, MaxUpdates: cfg.MaxUpdatesPerSync, MaxDeletes: cfg.MaxDeletesPerSync}
+	}
+
+	conflictResolver, exists := plan.ConflictResolvers[cfg.ConflictResolver]
+	if !exists {
+		conflictResolver = plan.NewPreferSourcePriority(cfg.ConflictSourcePriority)
+	}
+
+	ctrl := controller.Controller{
+		Source:               endpointsSource,
+		Registry:             r,
+		Policy:               policy,
+		ConflictResolver:     conflictResolver,
+		DeletionGracePeriod:  cfg.DeletionGracePeriod,
+		Interval:             cfg.Interval,
+		DomainFilter:         domainFilter,
+		ManagedRecordTypes:   cfg.ManagedDNSRecordTypes,
+		MinEventSyncInterval: cfg.MinEventSyncInterval,
+		DryRun:               cfg.DryRun,
+		InternalRegistry:     internalRegistry,
+		SplitHorizonRouter:   controller.NewSplitHorizonRouter(cfg.SplitHorizonInternalDomains, cfg.SplitHorizonInternalSources),
+	}
+	if cfg.ApprovalWebhookURL != "" {
+		ctrl.Approver = controller.NewWebhookApprover(cfg.ApprovalWebhookURL, cfg.ApprovalWebhookTimeout)
+	}
+	if cfg.DryRunOutput != "" {
+		ctrl.ChangesOutput = os.Stdout
+		ctrl.ChangesOutputFormat = cfg.DryRunOutput
+		if cfg.DryRunOutputFile != "" {
+			f, err := os.Create(cfg.DryRunOutputFile)
+			if err != nil {
+				log.Fatalf("failed to create the dry-run output file: %v", err)
+			}
+			defer f.Close()
+			ctrl.ChangesOutput = f
+		}
+	}
+
+	if cfg.TXTOwnerTransferFrom != "" {
+		for _, r := range []registry.Registry{r, internalRegistry} {
+			if r == nil {
+				continue
+			}
+			changes, err := r.(*registry.TXTRegistry).TransferOwnership(ctx, cfg.TXTOwnerTransferFrom, cfg.DryRun)
+			if err != nil {
+				log.Fatalf("failed to transfer the ownership of the records: %v", err)
+			}
+			log.Infof("Transferred the ownership of %d TXT records from %s to %s", len(changes.UpdateNew), cfg.TXTOwnerTransferFrom, cfg.TXTOwnerID)
+			if ctrl.ChangesOutput != nil {
+				if err := changes.Write(ctrl.ChangesOutput, ctrl.ChangesOutputFormat); err != nil {
+					log.Fatal(err)
+				}
+			}
+		}
+
+		os.Exit(0)
+	}
+
+	if cfg.Once {
+		err := ctrl.RunOnce(ctx)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		os.Exit(0)
+	}
+
+	if cfg.UpdateEvents {
+		// Add RunOnce as the handler function that will be called when ingress/service sources have changed.
+		// Note that k8s Informers will perform an initial list operation, which results in the handler
+		// function initially being called for every Service/Ingress that exists
+		ctrl.Source.AddEventHandler(ctx, func() { ctrl.ScheduleRunOnce(time.Now()) })
+	}
+
+	ctrl.ScheduleRunOnce(time.Now())
+	ctrl.Run(ctx)
+}
+
+// buildProvider creates the DNS provider with the given name from the configuration.
+func buildProvider(ctx context.Context, cfg *externaldns.Config, name string, endpointsSource source.Source, domainFilter endpoint.DomainFilter) (provider.Provider, error) {
 	zoneNameFilter := endpoint.NewDomainFilter(cfg.ZoneNameFilter)
 	zoneIDFilter := provider.NewZoneIDFilter(cfg.ZoneIDFilter)
 	zoneTypeFilter := provider.NewZoneTypeFilter(cfg.AWSZoneType)
 	zoneTagFilter := provider.NewZoneTagFilter(cfg.AWSZoneTagFilter)
 
-	var p provider.Provider
-	switch cfg.Provider {
+	var (
+		p   provider.Provider
+		err error
+	)
+	switch name {
 	case "akamai":
 		p, err = akamai.NewAkamaiProvider(
 			akamai.AkamaiConfig{
@@ -215,9 +402,9 @@ func main() {
 		}
 		p, err = awssd.NewAWSSDProvider(domainFilter, cfg.AWSZoneType, cfg.AWSAssumeRole, cfg.AWSAssumeRoleExternalID, cfg.DryRun, cfg.AWSSDServiceCleanup, cfg.TXTOwnerID)
 	case "azure-dns", "azure":
-		p, err = azure.NewAzureProvider(cfg.AzureConfigFile, domainFilter, zoneNameFilter, zoneIDFilter, cfg.AzureResourceGroup, cfg.AzureUserAssignedIdentityClientID, cfg.DryRun)
+		p, err = azure.NewAzureProvider(cfg.AzureConfigFile, domainFilter, zoneNameFilter, zoneIDFilter, cfg.AzureZones, cfg.AzureZoneRules, cfg.AzureResourceGroup, cfg.AzureUserAssignedIdentityClientID, cfg.DryRun)
 	case "azure-private-dns":
-		p, err = azure.NewAzurePrivateDNSProvider(cfg.AzureConfigFile, domainFilter, zoneIDFilter, cfg.AzureResourceGroup, cfg.AzureUserAssignedIdentityClientID, cfg.DryRun)
+		p, err = azure.NewAzurePrivateDNSProvider(cfg.AzureConfigFile, domainFilter, zoneIDFilter, cfg.AzureZones, cfg.AzureZoneRules, cfg.AzureResourceGroup, cfg.AzureUserAssignedIdentityClientID, cfg.DryRun)
 	case "bluecat":
 		p, err = bluecat.NewBluecatProvider(cfg.BluecatConfigFile, cfg.BluecatDNSConfiguration, cfg.BluecatDNSServerName, cfg.BluecatDNSDeployType, cfg.BluecatDNSView, cfg.BluecatGatewayHost, cfg.BluecatRootZone, cfg.TXTPrefix, cfg.TXTSuffix, domainFilter, zoneIDFilter, cfg.DryRun, cfg.BluecatSkipTLSVerify)
 	case "vinyldns":
@@ -310,7 +497,7 @@ func main() {
 			p, err = oci.NewOCIProvider(*config, domainFilter, zoneIDFilter, cfg.DryRun)
 		}
 	case "rfc2136":
-		p, err = rfc2136.NewRfc2136Provider(cfg.RFC2136Host, cfg.RFC2136Port, cfg.RFC2136Zone, cfg.RFC2136Insecure, cfg.RFC2136TSIGKeyName, cfg.RFC2136TSIGSecret, cfg.RFC2136TSIGSecretAlg, cfg.RFC2136TAXFR, domainFilter, cfg.DryRun, cfg.RFC2136MinTTL, cfg.RFC2136GSSTSIG, cfg.RFC2136KerberosUsername, cfg.RFC2136KerberosPassword, cfg.RFC2136KerberosRealm, cfg.RFC2136BatchChangeSize, nil)
+		p, err = rfc2136.NewRfc2136Provider(cfg.RFC2136Host, cfg.RFC2136Port, cfg.RFC2136Zone, cfg.RFC2136ZoneConfigs, cfg.RFC2136Insecure, cfg.RFC2136TSIGKeyName, cfg.RFC2136TSIGSecret, cfg.RFC2136TSIGSecretAlg, cfg.RFC2136TAXFR, domainFilter, cfg.DryRun, cfg.RFC2136MinTTL, cfg.RFC2136GSSTSIG, cfg.RFC2136KerberosUsername, cfg.RFC2136KerberosPassword, cfg.RFC2136KerberosKeytab, cfg.RFC2136KerberosRealm, cfg.RFC2136BatchChangeSize, nil)
 	case "ns1":
 		p, err = ns1.NewNS1Provider(
 			ns1.NS1Config{
@@ -334,62 +521,76 @@ func main() {
 		p, err = ibmcloud.NewIBMCloudProvider(cfg.IBMCloudConfigFile, domainFilter, zoneIDFilter, endpointsSource, cfg.IBMCloudProxied, cfg.DryRun)
 	case "safedns":
 		p, err = safedns.NewSafeDNSProvider(domainFilter, cfg.DryRun)
+	case "mikrotik":
+		p, err = mikrotik.NewMikroTikProvider(
+			mikrotik.MikroTikConfig{
+				BaseURL:       cfg.MikroTikURL,
+				Username:      cfg.MikroTikUsername,
+				Password:      cfg.MikroTikPassword,
+				SkipTLSVerify: cfg.MikroTikSkipTLSVerify,
+				DomainFilter:  domainFilter,
+				DryRun:        cfg.DryRun,
+			},
+		)
+	case "resolver-file":
+		p, err = resolverfile.NewResolverFileProvider(
+			resolverfile.ResolverFileConfig{
+				Path:          cfg.ResolverFilePath,
+				Format:        cfg.ResolverFileFormat,
+				ReloadPIDFile: cfg.ResolverFileReloadPIDFile,
+				ReloadCommand: cfg.ResolverFileReloadCommand,
+				DryRun:        cfg.DryRun,
+			},
+		)
+	case "knot":
+		p, err = knot.NewKnotProvider(
+			knot.KnotConfig{
+				SocketPath:   cfg.KnotSocket,
+				Zones:        cfg.KnotZones,
+				DomainFilter: domainFilter,
+				Timeout:      cfg.KnotTimeout,
+				DryRun:       cfg.DryRun,
+			},
+		)
 	default:
-		log.Fatalf("unknown dns provider: %s", cfg.Provider)
+		err = fmt.Errorf("unknown dns provider: %s", name)
 	}
-	if err != nil {
-		log.Fatal(err)
+	if err == nil && (cfg.ProviderQPS > 0 || cfg.ProviderRetries > 0) {
+		p = provider.NewRateLimitedProvider(p, cfg.ProviderQPS, cfg.ProviderBurst, cfg.ProviderRetries, cfg.ProviderRetryBackoff)
 	}
+	if err == nil && cfg.ProviderCacheTime > 0 {
+		p = provider.NewCachedProvider(p, cfg.ProviderCacheTime)
+	}
+	return p, err
+}
 
-	var r registry.Registry
+// buildRegistry creates the configured registry on top of the given provider.
+func buildRegistry(cfg *externaldns.Config, p provider.Provider) (registry.Registry, error) {
+	var (
+		r   registry.Registry
+		err error
+	)
 	switch cfg.Registry {
 	case "noop":
 		r, err = registry.NewNoopRegistry(p)
 	case "txt":
-		r, err = registry.NewTXTRegistry(p, cfg.TXTPrefix, cfg.TXTSuffix, cfg.TXTOwnerID, cfg.TXTCacheInterval, cfg.TXTWildcardReplacement, cfg.ManagedDNSRecordTypes)
+		var keys [][]byte
+		if cfg.TXTEncryptAESKey != "" {
+			for _, k := range append([]string{cfg.TXTEncryptAESKey}, cfg.TXTDecryptAESKeys...) {
+				key, err := registry.ParseTXTEncryptionKey(k)
+				if err != nil {
+					return nil, err
+				}
+				keys = append(keys, key)
+			}
+		}
+		r, err = registry.NewTXTRegistry(p, cfg.TXTPrefix, cfg.TXTSuffix, cfg.TXTOwnerID, cfg.TXTOwnerGroup, cfg.TXTCacheInterval, cfg.TXTWildcardReplacement, cfg.ManagedDNSRecordTypes, keys, cfg.TXTFormatV4)
 	case "aws-sd":
 		r, err = registry.NewAWSSDRegistry(p.(*awssd.AWSSDProvider), cfg.TXTOwnerID)
 	default:
-		log.Fatalf("unknown registry: %s", cfg.Registry)
-	}
-
-	if err != nil {
-		log.Fatal(err)
+		err = fmt.Errorf("unknown registry: %s", cfg.Registry)
 	}
-
-	policy, exists := plan.Policies[cfg.Policy]
-	if !exists {
-		log.Fatalf("unknown policy: %s", cfg.Policy)
-	}
-
-	ctrl := controller.Controller{
-		Source:               endpointsSource,
-		Registry:             r,
-		Policy:               policy,
-		Interval:             cfg.Interval,
-		DomainFilter:         domainFilter,
-		ManagedRecordTypes:   cfg.ManagedDNSRecordTypes,
-		MinEventSyncInterval: cfg.MinEventSyncInterval,
-	}
-
-	if cfg.Once {
-		err := ctrl.RunOnce(ctx)
-		if err != nil {
-			log.Fatal(err)
-		}
-
-		os.Exit(0)
-	}
-
-	if cfg.UpdateEvents {
-		// Add RunOnce as the handler function that will be called when ingress/service sources have changed.
-		// Note that k8s Informers will perform an initial list operation, which results in the handler
-		// function initially being called for every Service/Ingress that exists
-		ctrl.Source.AddEventHandler(ctx, func() { ctrl.ScheduleRunOnce(time.Now()) })
-	}
-
-	ctrl.ScheduleRunOnce(time.Now())
-	ctrl.Run(ctx)
+	return r, err
 }
 
 func handleSigterm(cancel func()) {
@@ -48,12 +48,19 @@ type Config struct {
 	KubeConfig                        string
 	RequestTimeout                    time.Duration
 	DefaultTargets                    []string
+	SourceConflictStrategy            string
+	SourceDomainFilters               []string
+	SourceExcludeDomains              []string
+	Transformers                      []string
+	TransformerConfig                 string
+	EndpointValidation                string
 	ContourLoadBalancerService        string
 	GlooNamespace                     string
 	SkipperRouteGroupVersion          string
 	Sources                           []string
 	Namespace                         string
 	AnnotationFilter                  string
+	AnnotationPrefixAliases           []string
 	LabelFilter                       string
 	FQDNTemplate                      string
 	CombineFQDNAndAnnotation          bool
@@ -67,7 +74,56 @@ type Config struct {
 	PublishHostIP                     bool
 	AlwaysPublishNotReadyAddresses    bool
 	ConnectorSourceServer             string
+	RemoteSourceURL                   string
+	RemoteSourceAuthHeader            string `secure:"yes"`
+	PluginSourceURL                   string
+	HostSourceDomain                  string
+	HostSourceInterfaces              []string
+	HostSourceUnitDir                 string
+	LibvirtSocket                     string
+	LibvirtURI                        string
+	LibvirtAddressSource              string
+	ProxmoxURL                        string
+	ProxmoxToken                      string `secure:"yes"`
+	ProxmoxInsecure                   bool
+	ProxmoxNodes                      []string
+	ProxmoxPools                      []string
+	ProxmoxTagPrefix                  string
+	NetBoxURL                         string
+	NetBoxToken                       string `secure:"yes"`
+	NetBoxTags                        []string
+	NetBoxCustomFields                []string
+	NetBoxCreatePTR                   bool
+	MQTTBroker                        string
+	MQTTTopic                         string
+	MQTTClientID                      string
+	MQTTUsername                      string
+	MQTTPassword                      string `secure:"yes"`
+	PrometheusSDURL                   string
+	PrometheusSDAuthHeader            string `secure:"yes"`
+	PrometheusSDHostnameLabel         string
+	PrometheusSDTTLLabel              string
+	ComposeFiles                      []string
+	ComposeDefaultTargets             []string
+	PodmanSocket                      string
+	PodmanDefaultTargets              []string
+	ContainerdNerdctl                 string
+	ContainerdAddress                 string
+	ContainerdNamespaces              []string
+	ContainerdDefaultTargets          []string
+	DNSEndpointPaths                  []string
 	Provider                          string
+	InternalProvider                  string
+	ProviderQPS                       float64
+	ProviderBurst                     int
+	ProviderRetries                   int
+	ProviderRetryBackoff              time.Duration
+	ProviderCacheTime                 time.Duration
+	SplitHorizonInternalDomains       []string
+	SplitHorizonInternalSources       []string
+	InternalDomainFilter              []string
+	InternalZoneIDFilter              []string
+	InternalAWSZoneType               string
 	GoogleProject                     string
 	GoogleBatchChangeSize             int
 	GoogleBatchChangeInterval         time.Duration
@@ -97,6 +153,8 @@ type Config struct {
 	AzureResourceGroup                string
 	AzureSubscriptionID               string
 	AzureUserAssignedIdentityClientID string
+	AzureZones                        []string
+	AzureZoneRules                    []string
 	BluecatDNSConfiguration           string
 	BluecatConfigFile                 string
 	BluecatDNSView                    string
@@ -141,20 +199,36 @@ type Config struct {
 	TLSClientCert                     string
 	TLSClientCertKey                  string
 	Policy                            string
+	WeightedTargetsProperty           string
+	ConflictResolver                  string
+	ConflictSourcePriority            []string
+	MaxCreatesPerSync                 int
+	MaxUpdatesPerSync                 int
+	MaxDeletesPerSync                 int
+	DeletionGracePeriod               int
 	Registry                          string
 	TXTOwnerID                        string
+	TXTOwnerGroup                     []string
 	TXTPrefix                         string
 	TXTSuffix                         string
 	Interval                          time.Duration
 	MinEventSyncInterval              time.Duration
 	Once                              bool
 	DryRun                            bool
+	DryRunOutput                      string
+	DryRunOutputFile                  string
+	ApprovalWebhookURL                string
+	ApprovalWebhookTimeout            time.Duration
 	UpdateEvents                      bool
 	LogFormat                         string
 	MetricsAddress                    string
 	LogLevel                          string
 	TXTCacheInterval                  time.Duration
 	TXTWildcardReplacement            string
+	TXTOwnerTransferFrom              string
+	TXTEncryptAESKey                  string   `secure:"yes"`
+	TXTDecryptAESKeys                 []string `secure:"yes"`
+	TXTFormatV4                       bool
 	ExoscaleEndpoint                  string
 	ExoscaleAPIKey                    string `secure:"yes"`
 	ExoscaleAPISecret                 string `secure:"yes"`
@@ -166,12 +240,14 @@ type Config struct {
 	CFPassword                        string
 	RFC2136Host                       string
 	RFC2136Port                       int
-	RFC2136Zone                       string
+	RFC2136Zone                       []string
+	RFC2136ZoneConfigs                []string `secure:"yes"`
 	RFC2136Insecure                   bool
 	RFC2136GSSTSIG                    bool
 	RFC2136KerberosRealm              string
 	RFC2136KerberosUsername           string
 	RFC2136KerberosPassword           string `secure:"yes"`
+	RFC2136KerberosKeytab             string
 	RFC2136TSIGKeyName                string
 	RFC2136TSIGSecret                 string `secure:"yes"`
 	RFC2136TSIGSecretAlg              string
@@ -192,6 +268,17 @@ type Config struct {
 	OCPRouterName                     string
 	IBMCloudProxied                   bool
 	IBMCloudConfigFile                string
+	MikroTikURL                       string
+	MikroTikUsername                  string
+	MikroTikPassword                  string `secure:"yes"`
+	MikroTikSkipTLSVerify             bool
+	ResolverFilePath                  string
+	ResolverFileFormat                string
+	ResolverFileReloadPIDFile         string
+	ResolverFileReloadCommand         string
+	KnotSocket                        string
+	KnotZones                         []string
+	KnotTimeout                       time.Duration
 }
 
 var defaultConfig = &Config{
@@ -199,6 +286,7 @@ var defaultConfig = &Config{
 	KubeConfig:                  "",
 	RequestTimeout:              time.Second * 30,
 	DefaultTargets:              []string{},
+	SourceConflictStrategy:      "none",
 	ContourLoadBalancerService:  "heptio-contour/contour",
 	GlooNamespace:               "gloo-system",
 	SkipperRouteGroupVersion:    "zalando.org/v1",
@@ -217,7 +305,42 @@ var defaultConfig = &Config{
 	PublishInternal:             false,
 	PublishHostIP:               false,
 	ConnectorSourceServer:       "localhost:8080",
+	RemoteSourceURL:             "",
+	RemoteSourceAuthHeader:      "",
+	PluginSourceURL:             "",
+	HostSourceDomain:            "",
+	HostSourceUnitDir:           "",
+	LibvirtSocket:               "/var/run/libvirt/libvirt-sock",
+	LibvirtURI:                  "qemu:///system",
+	LibvirtAddressSource:        "lease",
+	ProxmoxURL:                  "",
+	ProxmoxToken:                "",
+	ProxmoxInsecure:             false,
+	ProxmoxTagPrefix:            "dns.",
+	NetBoxURL:                   "",
+	NetBoxToken:                 "",
+	NetBoxCreatePTR:             false,
+	MQTTBroker:                  "",
+	MQTTTopic:                   "external-dns/endpoints/#",
+	MQTTClientID:                "external-dns",
+	MQTTUsername:                "",
+	MQTTPassword:                "",
+	PrometheusSDURL:             "",
+	PrometheusSDAuthHeader:      "",
+	PrometheusSDHostnameLabel:   "dns_name",
+	PrometheusSDTTLLabel:        "dns_ttl",
+	PodmanSocket:                "unix:///run/podman/podman.sock",
+	ContainerdNerdctl:           "nerdctl",
+	ContainerdAddress:           "/run/containerd/containerd.sock",
+	ContainerdNamespaces:        []string{"default"},
 	Provider:                    "",
+	InternalProvider:            "",
+	InternalAWSZoneType:         "",
+	ProviderQPS:                 0,
+	ProviderBurst:               1,
+	ProviderRetries:             0,
+	ProviderRetryBackoff:        time.Second,
+	ProviderCacheTime:           0,
 	GoogleProject:               "",
 	GoogleBatchChangeSize:       1000,
 	GoogleBatchChangeInterval:   time.Second,
@@ -243,6 +366,8 @@ var defaultConfig = &Config{
 	AzureConfigFile:             "/etc/kubernetes/azure.json",
 	AzureResourceGroup:          "",
 	AzureSubscriptionID:         "",
+	AzureZones:                  nil,
+	AzureZoneRules:              nil,
 	BluecatConfigFile:           "/etc/kubernetes/bluecat.json",
 	BluecatDNSDeployType:        "no-deploy",
 	CloudflareProxied:           false,
@@ -277,16 +402,27 @@ var defaultConfig = &Config{
 	TLSClientCert:               "",
 	TLSClientCertKey:            "",
 	Policy:                      "sync",
+	ConflictResolver:            "per-resource",
+	MaxCreatesPerSync:           0,
+	MaxUpdatesPerSync:           0,
+	MaxDeletesPerSync:           0,
+	DeletionGracePeriod:         0,
 	Registry:                    "txt",
 	TXTOwnerID:                  "default",
 	TXTPrefix:                   "",
 	TXTSuffix:                   "",
 	TXTCacheInterval:            0,
 	TXTWildcardReplacement:      "",
+	TXTOwnerTransferFrom:        "",
+	TXTEncryptAESKey:            "",
 	MinEventSyncInterval:        5 * time.Second,
 	Interval:                    time.Minute,
 	Once:                        false,
 	DryRun:                      false,
+	DryRunOutput:                "",
+	DryRunOutputFile:            "",
+	ApprovalWebhookURL:          "",
+	ApprovalWebhookTimeout:      10 * time.Second,
 	UpdateEvents:                false,
 	LogFormat:                   "text",
 	MetricsAddress:              ":7979",
@@ -302,12 +438,14 @@ var defaultConfig = &Config{
 	CFPassword:                  "",
 	RFC2136Host:                 "",
 	RFC2136Port:                 0,
-	RFC2136Zone:                 "",
+	RFC2136Zone:                 nil,
+	RFC2136ZoneConfigs:          nil,
 	RFC2136Insecure:             false,
 	RFC2136GSSTSIG:              false,
 	RFC2136KerberosRealm:        "",
 	RFC2136KerberosUsername:     "",
 	RFC2136KerberosPassword:     "",
+	RFC2136KerberosKeytab:       "",
 	RFC2136TSIGKeyName:          "",
 	RFC2136TSIGSecret:           "",
 	RFC2136TSIGSecretAlg:        "",
@@ -326,6 +464,16 @@ var defaultConfig = &Config{
 	GoDaddyOTE:                  false,
 	IBMCloudProxied:             false,
 	IBMCloudConfigFile:          "/etc/kubernetes/ibmcloud.json",
+	MikroTikURL:                 "",
+	MikroTikUsername:            "",
+	MikroTikPassword:            "",
+	MikroTikSkipTLSVerify:       false,
+	ResolverFilePath:            "",
+	ResolverFileFormat:          "dnsmasq",
+	ResolverFileReloadPIDFile:   "",
+	ResolverFileReloadCommand:   "",
+	KnotSocket:                  "/run/knot/knot.sock",
+	KnotTimeout:                 10 * time.Second,
 }
 
 // NewConfig returns new Config object
@@ -341,12 +489,18 @@ func (cfg *Config) String() string {
 	for i := 0; i < t.NumField(); i++ {
 		f := t.Field(i)
 		if val, ok := f.Tag.Lookup("secure"); ok && val == "yes" {
-			if f.Type.Kind() != reflect.String {
-				continue
-			}
 			v := reflect.ValueOf(&temp).Elem().Field(i)
-			if v.String() != "" {
-				v.SetString(passwordMask)
+			switch {
+			case f.Type.Kind() == reflect.String:
+				if v.String() != "" {
+					v.SetString(passwordMask)
+				}
+			case f.Type.Kind() == reflect.Slice && f.Type.Elem().Kind() == reflect.String:
+				masked := make([]string, v.Len())
+				for j := range masked {
+					masked[j] = passwordMask
+				}
+				v.Set(reflect.ValueOf(masked))
 			}
 		}
 	}
@@ -389,9 +543,10 @@ func (cfg *Config) ParseFlags(args []string) error {
 	app.Flag("skipper-routegroup-groupversion", "The resource version for skipper routegroup").Default(source.DefaultRoutegroupVersion).StringVar(&cfg.SkipperRouteGroupVersion)
 
 	// Flags related to processing source
-	app.Flag("source", "The resource types that are queried for endpoints; specify multiple times for multiple sources (required, options: service, ingress, node, fake, connector, gateway-httproute, gateway-tlsroute, gateway-tcproute, gateway-udproute, istio-gateway, istio-virtualservice, cloudfoundry, contour-ingressroute, contour-httpproxy, gloo-proxy, crd, empty, skipper-routegroup, openshift-route, ambassador-host, kong-tcpingress)").Required().PlaceHolder("source").EnumsVar(&cfg.Sources, "service", "ingress", "node", "pod", "gateway-httproute", "gateway-tlsroute", "gateway-tcproute", "gateway-udproute", "istio-gateway", "istio-virtualservice", "cloudfoundry", "contour-ingressroute", "contour-httpproxy", "gloo-proxy", "fake", "connector", "crd", "empty", "skipper-routegroup", "openshift-route", "ambassador-host", "kong-tcpingress")
+	app.Flag("source", "The resource types that are queried for endpoints; specify multiple times for multiple sources (required, options: service, ingress, node, fake, connector, remote, host, libvirt, proxmox, netbox, mqtt, prometheus-sd, compose, podman, containerd, dnsendpoint-file, plugin, gateway-httproute, gateway-tlsroute, gateway-tcproute, gateway-udproute, istio-gateway, istio-virtualservice, cloudfoundry, contour-ingressroute, contour-httpproxy, gloo-proxy, crd, empty, skipper-routegroup, openshift-route, ambassador-host, kong-tcpingress)").Required().PlaceHolder("source").EnumsVar(&cfg.Sources, "service", "ingress", "node", "pod", "gateway-httproute", "gateway-tlsroute", "gateway-tcproute", "gateway-udproute", "istio-gateway", "istio-virtualservice", "cloudfoundry", "contour-ingressroute", "contour-httpproxy", "gloo-proxy", "fake", "connector", "remote", "host", "libvirt", "proxmox", "netbox", "mqtt", "prometheus-sd", "compose", "podman", "containerd", "dnsendpoint-file", "plugin", "crd", "empty", "skipper-routegroup", "openshift-route", "ambassador-host", "kong-tcpingress")
 	app.Flag("openshift-router-name", "if source is openshift-route then you can pass the ingress controller name. Based on this name external-dns will select the respective router from the route status and map that routerCanonicalHostname to the route host while creating a CNAME record.").StringVar(&cfg.OCPRouterName)
 	app.Flag("namespace", "Limit sources of endpoints to a specific namespace (default: all namespaces)").Default(defaultConfig.Namespace).StringVar(&cfg.Namespace)
+	app.Flag("annotation-prefix-alias", "An alternate annotation prefix, e.g. dns.example.io, accepted in place of external-dns.alpha.kubernetes.io on all the sources; annotations with the standard prefix take precedence; specify multiple times for multiple prefixes (optional)").StringsVar(&cfg.AnnotationPrefixAliases)
 	app.Flag("annotation-filter", "Filter sources managed by external-dns via annotation using label selector semantics (default: all sources)").Default(defaultConfig.AnnotationFilter).StringVar(&cfg.AnnotationFilter)
 	app.Flag("label-filter", "Filter sources managed by external-dns via label selector when listing all resources; currently supported by source types CRD, ingress, service and openshift-route").Default(defaultConfig.LabelFilter).StringVar(&cfg.LabelFilter)
 	app.Flag("fqdn-template", "A templated string that's used to generate DNS names from sources that don't define a hostname themselves, or to add a hostname suffix when paired with the fake source (optional). Accepts comma separated list for multiple global FQDN.").Default(defaultConfig.FQDNTemplate).StringVar(&cfg.FQDNTemplate)
@@ -406,16 +561,71 @@ func (cfg *Config) ParseFlags(args []string) error {
 	app.Flag("publish-host-ip", "Allow external-dns to publish host-ip for headless services (optional)").BoolVar(&cfg.PublishHostIP)
 	app.Flag("always-publish-not-ready-addresses", "Always publish also not ready addresses for headless services (optional)").BoolVar(&cfg.AlwaysPublishNotReadyAddresses)
 	app.Flag("connector-source-server", "The server to connect for connector source, valid only when using connector source").Default(defaultConfig.ConnectorSourceServer).StringVar(&cfg.ConnectorSourceServer)
+	app.Flag("remote-source-url", "The HTTP(S) URL returning a JSON list of endpoints, valid only when using remote source").Default(defaultConfig.RemoteSourceURL).StringVar(&cfg.RemoteSourceURL)
+	app.Flag("remote-source-auth-header", "The value of the Authorization header sent to the remote source URL, e.g. `Bearer <token>` (optional)").Default(defaultConfig.RemoteSourceAuthHeader).StringVar(&cfg.RemoteSourceAuthHeader)
+	app.Flag("plugin-source-url", "The HTTP(S) URL of an out-of-tree source plugin serving endpoints over the source plugin protocol, valid only when using plugin source").Default(defaultConfig.PluginSourceURL).StringVar(&cfg.PluginSourceURL)
+	app.Flag("host-source-domain", "The domain appended to the local hostname, valid only when using host source (optional, default: publish the hostname only if it is fully qualified)").Default(defaultConfig.HostSourceDomain).StringVar(&cfg.HostSourceDomain)
+	app.Flag("host-source-interface", "Limit the addresses published by the host source to a network interface; specify multiple times for multiple interfaces (optional, default: all non-loopback interfaces)").StringsVar(&cfg.HostSourceInterfaces)
+	app.Flag("host-source-unit-dir", "A directory of *.conf drop-in files holding annotations for additional records, valid only when using host source (optional)").Default(defaultConfig.HostSourceUnitDir).StringVar(&cfg.HostSourceUnitDir)
+	app.Flag("libvirt-socket", "The unix socket of the libvirt daemon, valid only when using libvirt source (default: /var/run/libvirt/libvirt-sock)").Default(defaultConfig.LibvirtSocket).StringVar(&cfg.LibvirtSocket)
+	app.Flag("libvirt-uri", "The libvirt connection URI, valid only when using libvirt source (default: qemu:///system)").Default(defaultConfig.LibvirtURI).StringVar(&cfg.LibvirtURI)
+	app.Flag("libvirt-address-source", "Where the libvirt source reads the domain addresses from, valid only when using libvirt source (default: lease, options: lease, agent)").Default(defaultConfig.LibvirtAddressSource).EnumVar(&cfg.LibvirtAddressSource, "lease", "agent")
+	app.Flag("proxmox-url", "The URL of the Proxmox VE API, e.g. https://pve.example.org:8006, valid only when using proxmox source").Default(defaultConfig.ProxmoxURL).StringVar(&cfg.ProxmoxURL)
+	app.Flag("proxmox-token", "The Proxmox VE API token in the form USER@REALM!TOKENID=SECRET, valid only when using proxmox source").Default(defaultConfig.ProxmoxToken).StringVar(&cfg.ProxmoxToken)
+	app.Flag("proxmox-insecure", "When using the proxmox source, skip verification of the Proxmox VE API certificate (default: false)").BoolVar(&cfg.ProxmoxInsecure)
+	app.Flag("proxmox-node", "Limit the proxmox source to guests running on a node; specify multiple times for multiple nodes (optional, default: all nodes)").StringsVar(&cfg.ProxmoxNodes)
+	app.Flag("proxmox-pool", "Limit the proxmox source to guests belonging to a pool; specify multiple times for multiple pools (optional, default: all pools)").StringsVar(&cfg.ProxmoxPools)
+	app.Flag("proxmox-tag-prefix", "The prefix of the guest tags holding a hostname for the proxmox source; set to an empty string to only use the fqdn-template (default: dns.)").Default(defaultConfig.ProxmoxTagPrefix).StringVar(&cfg.ProxmoxTagPrefix)
+	app.Flag("netbox-url", "The URL of the NetBox instance, e.g. https://netbox.example.org, valid only when using netbox source").Default(defaultConfig.NetBoxURL).StringVar(&cfg.NetBoxURL)
+	app.Flag("netbox-token", "The NetBox API token, valid only when using netbox source").Default(defaultConfig.NetBoxToken).StringVar(&cfg.NetBoxToken)
+	app.Flag("netbox-tag", "Limit the netbox source to IP addresses having a tag (slug); specify multiple times for multiple tags (optional, default: all IP addresses)").StringsVar(&cfg.NetBoxTags)
+	app.Flag("netbox-custom-field", "Limit the netbox source to IP addresses having a custom field value in the form name=value; specify multiple times for multiple custom fields (optional)").StringsVar(&cfg.NetBoxCustomFields)
+	app.Flag("netbox-create-ptr", "When using the netbox source, also publish a PTR record for each IP address (default: false)").BoolVar(&cfg.NetBoxCreatePTR)
+	app.Flag("mqtt-broker", "The URL of the MQTT broker, e.g. tcp://mqtt.example.org:1883 or ssl://mqtt.example.org:8883, valid only when using mqtt source").Default(defaultConfig.MQTTBroker).StringVar(&cfg.MQTTBroker)
+	app.Flag("mqtt-topic", "The topic filter the mqtt source subscribes to; agents publish the JSON encoded list of their endpoints as a retained message on a topic matching it (default: external-dns/endpoints/#)").Default(defaultConfig.MQTTTopic).StringVar(&cfg.MQTTTopic)
+	app.Flag("mqtt-client-id", "The client ID used to connect to the MQTT broker, valid only when using mqtt source (default: external-dns)").Default(defaultConfig.MQTTClientID).StringVar(&cfg.MQTTClientID)
+	app.Flag("mqtt-username", "The username used to connect to the MQTT broker, valid only when using mqtt source").Default(defaultConfig.MQTTUsername).StringVar(&cfg.MQTTUsername)
+	app.Flag("mqtt-password", "The password used to connect to the MQTT broker, valid only when using mqtt source").Default(defaultConfig.MQTTPassword).StringVar(&cfg.MQTTPassword)
+	app.Flag("prometheus-sd-url", "The URL of a Prometheus HTTP service discovery endpoint, valid only when using prometheus-sd source").Default(defaultConfig.PrometheusSDURL).StringVar(&cfg.PrometheusSDURL)
+	app.Flag("prometheus-sd-auth-header", "The value of the Authorization header sent to the Prometheus HTTP service discovery endpoint, e.g. 'Bearer <token>', valid only when using prometheus-sd source").Default(defaultConfig.PrometheusSDAuthHeader).StringVar(&cfg.PrometheusSDAuthHeader)
+	app.Flag("prometheus-sd-hostname-label", "The target group label holding a comma separated list of hostnames for the prometheus-sd source (default: dns_name)").Default(defaultConfig.PrometheusSDHostnameLabel).StringVar(&cfg.PrometheusSDHostnameLabel)
+	app.Flag("prometheus-sd-ttl-label", "The target group label holding the TTL of the records for the prometheus-sd source (default: dns_ttl)").Default(defaultConfig.PrometheusSDTTLLabel).StringVar(&cfg.PrometheusSDTTLLabel)
+	app.Flag("compose-file", "A Compose file read by the compose source, without a running Docker daemon; specify multiple times for multiple files").StringsVar(&cfg.ComposeFiles)
+	app.Flag("compose-default-target", "The target of the services without a target label for the compose source, e.g. the address of the Docker host; specify multiple times for multiple targets").StringsVar(&cfg.ComposeDefaultTargets)
+	app.Flag("podman-socket", "The address of the Podman API, either unix://<path> or tcp://<host>:<port>, valid only when using podman source; rootless Podman listens on unix://$XDG_RUNTIME_DIR/podman/podman.sock (default: unix:///run/podman/podman.sock)").Default(defaultConfig.PodmanSocket).StringVar(&cfg.PodmanSocket)
+	app.Flag("containerd-nerdctl", "The nerdctl binary the containerd source reads the containers with, as containerd doesn't know their addresses (default: nerdctl)").Default(defaultConfig.ContainerdNerdctl).StringVar(&cfg.ContainerdNerdctl)
+	app.Flag("containerd-address", "The containerd socket, valid only when using containerd source (default: /run/containerd/containerd.sock)").Default(defaultConfig.ContainerdAddress).StringVar(&cfg.ContainerdAddress)
+	app.Flag("containerd-namespace", "A containerd namespace whose containers are read by the containerd source; specify multiple times for multiple namespaces (default: default)").Default("default").StringsVar(&cfg.ContainerdNamespaces)
+	app.Flag("containerd-default-target", "The target of the containers without a target label nor IPv4 address for the containerd source, e.g. the address of the host; specify multiple times for multiple targets").StringsVar(&cfg.ContainerdDefaultTargets)
+	app.Flag("podman-default-target", "The target of the containers and pods without a target label nor IPv4 address for the podman source, e.g. the address of the Podman host; specify multiple times for multiple targets").StringsVar(&cfg.PodmanDefaultTargets)
+	app.Flag("dnsendpoint-path", "A file or directory of DNSEndpoint manifests read by the dnsendpoint-file source, matching crd-source-apiversion and crd-source-kind; specify multiple times for multiple paths").StringsVar(&cfg.DNSEndpointPaths)
 	app.Flag("crd-source-apiversion", "API version of the CRD for crd source, e.g. `externaldns.k8s.io/v1alpha1`, valid only when using crd source").Default(defaultConfig.CRDSourceAPIVersion).StringVar(&cfg.CRDSourceAPIVersion)
 	app.Flag("crd-source-kind", "Kind of the CRD for the crd source in API group and version specified by crd-source-apiversion").Default(defaultConfig.CRDSourceKind).StringVar(&cfg.CRDSourceKind)
 	app.Flag("service-type-filter", "The service types to take care about (default: all, expected: ClusterIP, NodePort, LoadBalancer or ExternalName)").StringsVar(&cfg.ServiceTypeFilter)
 	app.Flag("managed-record-types", "Comma separated list of record types to manage (default: A, CNAME) (supported records: CNAME, A, NS").Default("A", "CNAME").StringsVar(&cfg.ManagedDNSRecordTypes)
 	app.Flag("default-targets", "Set globally default IP address that will apply as a target instead of source addresses. Specify multiple times for multiple targets (optional)").StringsVar(&cfg.DefaultTargets)
+	app.Flag("source-conflict-strategy", "How to resolve endpoints with the same DNS name, record type and set identifier returned by multiple sources; the sources are prioritized in the order they are specified (default: none, options: none, first-wins, merge-targets, error)").Default(defaultConfig.SourceConflictStrategy).EnumVar(&cfg.SourceConflictStrategy, "none", "first-wins", "merge-targets", "error")
+	app.Flag("source-domain-filter", "Limit the endpoints of a source to the domains matching the filter, in the form <source>=<domain>, e.g. ingress=example.org; applied before the global domain filter; specify multiple times for multiple sources and domains (optional)").StringsVar(&cfg.SourceDomainFilters)
+	app.Flag("source-exclude-domains", "Exclude the domains matching the filter from the endpoints of a source, in the form <source>=<domain>; specify multiple times for multiple sources and domains (optional)").StringsVar(&cfg.SourceExcludeDomains)
+	app.Flag("transformer", "Transform the endpoints of all sources before they are planned, in the form <type>[=<argument>]; specify multiple times to chain multiple steps, applied in order (optional, options: dedup, lowercase, suffix=<domain>, rewrite-target=<from>=<to>, coerce-type=<from>=<to>, nat=<public-ip>, nat=<cidr>=<public-ip>)").StringsVar(&cfg.Transformers)
+	app.Flag("transformer-config", "A YAML file holding a list of transformer steps, each with a type and its suffix, from, to, mappings and keepInternal arguments; applied before the steps given by --transformer (optional)").Default(defaultConfig.TransformerConfig).StringVar(&cfg.TransformerConfig)
+	app.Flag("endpoint-validation", "Validate the names, targets and TTLs of the endpoints of all sources before they are planned; warn drops the invalid endpoints with a warning, fail refuses to synchronize while any endpoint is invalid (default: disabled, options: warn, fail)").Default(defaultConfig.EndpointValidation).EnumVar(&cfg.EndpointValidation, "", "warn", "fail")
 	app.Flag("target-net-filter", "Limit possible targets by a net filter; specify multiple times for multiple possible nets (optional)").StringsVar(&cfg.TargetNetFilter)
 	app.Flag("exclude-target-net", "Exclude target nets (optional)").StringsVar(&cfg.ExcludeTargetNets)
 
 	// Flags related to providers
-	app.Flag("provider", "The DNS provider where the DNS records will be created (required, options: aws, aws-sd, godaddy, google, azure, azure-dns, azure-private-dns, bluecat, cloudflare, rcodezero, digitalocean, dnsimple, akamai, infoblox, dyn, designate, coredns, skydns, ibmcloud, inmemory, ovh, pdns, oci, exoscale, linode, rfc2136, ns1, transip, vinyldns, rdns, scaleway, vultr, ultradns, gandi, safedns)").Required().PlaceHolder("provider").EnumVar(&cfg.Provider, "aws", "aws-sd", "google", "azure", "azure-dns", "azure-private-dns", "alibabacloud", "cloudflare", "rcodezero", "digitalocean", "dnsimple", "akamai", "infoblox", "dyn", "designate", "coredns", "skydns", "ibmcloud", "inmemory", "ovh", "pdns", "oci", "exoscale", "linode", "rfc2136", "ns1", "transip", "vinyldns", "rdns", "scaleway", "vultr", "ultradns", "godaddy", "bluecat", "gandi", "safedns")
+	app.Flag("provider", "The DNS provider where the DNS records will be created (required, options: aws, aws-sd, godaddy, google, azure, azure-dns, azure-private-dns, bluecat, cloudflare, rcodezero, digitalocean, dnsimple, akamai, infoblox, dyn, designate, coredns, skydns, ibmcloud, inmemory, ovh, pdns, oci, exoscale, linode, rfc2136, ns1, transip, vinyldns, rdns, scaleway, vultr, ultradns, gandi, safedns, mikrotik, resolver-file, knot)").Required().PlaceHolder("provider").EnumVar(&cfg.Provider, "aws", "aws-sd", "google", "azure", "azure-dns", "azure-private-dns", "alibabacloud", "cloudflare", "rcodezero", "digitalocean", "dnsimple", "akamai", "infoblox", "dyn", "designate", "coredns", "skydns", "ibmcloud", "inmemory", "ovh", "pdns", "oci", "exoscale", "linode", "rfc2136", "ns1", "transip", "vinyldns", "rdns", "scaleway", "vultr", "ultradns", "godaddy", "bluecat", "gandi", "safedns", "mikrotik", "resolver-file", "knot")
+	app.Flag("internal-provider", "A second DNS provider maintaining the internal view of split-horizon zones, configured with the same flags as the provider (optional, options: same as provider)").Default(defaultConfig.InternalProvider).EnumVar(&cfg.InternalProvider, "", "aws", "aws-sd", "google", "azure", "azure-dns", "azure-private-dns", "alibabacloud", "cloudflare", "rcodezero", "digitalocean", "dnsimple", "akamai", "infoblox", "dyn", "designate", "coredns", "skydns", "ibmcloud", "inmemory", "ovh", "pdns", "oci", "exoscale", "linode", "rfc2136", "ns1", "transip", "vinyldns", "rdns", "scaleway", "vultr", "ultradns", "godaddy", "bluecat", "gandi", "safedns", "mikrotik", "resolver-file", "knot")
+	app.Flag("provider-qps", "Limit the calls to the DNS provider to this number per second, 0 for no limit (default: 0)").Default(strconv.FormatFloat(defaultConfig.ProviderQPS, 'f', -1, 64)).Float64Var(&cfg.ProviderQPS)
+	app.Flag("provider-burst", "When limiting the calls to the DNS provider, the number of calls allowed in a burst (default: 1)").Default(strconv.Itoa(defaultConfig.ProviderBurst)).IntVar(&cfg.ProviderBurst)
+	app.Flag("provider-retries", "Retry the calls to the DNS provider failing with a timeout, an HTTP 429 or an HTTP 5xx status up to this number of times (default: 0)").Default(strconv.Itoa(defaultConfig.ProviderRetries)).IntVar(&cfg.ProviderRetries)
+	app.Flag("provider-retry-backoff", "The initial backoff between two retries of a call to the DNS provider, doubled by every retry and jittered (default: 1s)").Default(defaultConfig.ProviderRetryBackoff.String()).DurationVar(&cfg.ProviderRetryBackoff)
+	app.Flag("provider-cache-time", "Cache the records listed from the DNS provider for this duration, the cache is invalidated whenever changes are applied (default: 0s, disabled)").Default(defaultConfig.ProviderCacheTime.String()).DurationVar(&cfg.ProviderCacheTime)
+	app.Flag("split-horizon-internal-domain", "When using an internal provider, route the records below this domain suffix to it; specify multiple times for multiple domains (optional)").StringsVar(&cfg.SplitHorizonInternalDomains)
+	app.Flag("split-horizon-internal-source", "When using an internal provider, route the records of this source type (e.g. service, ingress, crd) to it; specify multiple times for multiple sources (optional)").StringsVar(&cfg.SplitHorizonInternalSources)
+	app.Flag("internal-domain-filter", "When using an internal provider, limit its target zones by a domain suffix instead of --domain-filter; specify multiple times for multiple domains (optional)").StringsVar(&cfg.InternalDomainFilter)
+	app.Flag("internal-zone-id-filter", "When using an internal provider, filter its target zones by hosted zone id instead of --zone-id-filter; specify multiple times for multiple zones (optional)").StringsVar(&cfg.InternalZoneIDFilter)
+	app.Flag("internal-aws-zone-type", "When using an internal AWS provider, filter for zones of this type instead of --aws-zone-type (optional, options: public, private)").Default(defaultConfig.InternalAWSZoneType).EnumVar(&cfg.InternalAWSZoneType, "", "public", "private")
 	app.Flag("domain-filter", "Limit possible target zones by a domain suffix; specify multiple times for multiple domains (optional)").Default("").StringsVar(&cfg.DomainFilter)
 	app.Flag("exclude-domains", "Exclude subdomains (optional)").Default("").StringsVar(&cfg.ExcludeDomains)
 	app.Flag("regex-domain-filter", "Limit possible domains and target zones by a Regex filter; Overrides domain-filter (optional)").Default(defaultConfig.RegexDomainFilter.String()).RegexpVar(&cfg.RegexDomainFilter)
@@ -443,6 +653,8 @@ func (cfg *Config) ParseFlags(args []string) error {
 	app.Flag("azure-resource-group", "When using the Azure provider, override the Azure resource group to use (required when --provider=azure-private-dns)").Default(defaultConfig.AzureResourceGroup).StringVar(&cfg.AzureResourceGroup)
 	app.Flag("azure-subscription-id", "When using the Azure provider, specify the Azure configuration file (required when --provider=azure-private-dns)").Default(defaultConfig.AzureSubscriptionID).StringVar(&cfg.AzureSubscriptionID)
 	app.Flag("azure-user-assigned-identity-client-id", "When using the Azure provider, override the client id of user assigned identity in config file (optional)").Default("").StringVar(&cfg.AzureUserAssignedIdentityClientID)
+	app.Flag("azure-zone", "When using the Azure providers, only manage the given zone; specify multiple times for multiple zones (optional, default: all the zones of the resource group)").StringsVar(&cfg.AzureZones)
+	app.Flag("azure-zone-rule", "When using the Azure providers, manage the records whose name ends with the suffix in the zone, rather than in the zone with the longest matching suffix, in the form <suffix>=<zone>; the rule with the longest suffix wins; specify multiple times for multiple rules (optional)").StringsVar(&cfg.AzureZoneRules)
 
 	// Flags related to BlueCat provider
 	app.Flag("bluecat-dns-configuration", "When using the Bluecat provider, specify the Bluecat DNS configuration string (optional when --provider=bluecat)").Default("").StringVar(&cfg.BluecatDNSConfiguration)
@@ -497,6 +709,20 @@ func (cfg *Config) ParseFlags(args []string) error {
 	app.Flag("godaddy-api-secret", "When using the GoDaddy provider, specify the API secret (required when --provider=godaddy)").Default(defaultConfig.GoDaddySecretKey).StringVar(&cfg.GoDaddySecretKey)
 	app.Flag("godaddy-api-ttl", "TTL (in seconds) for records. This value will be used if the provided TTL for a service/ingress is not provided.").Int64Var(&cfg.GoDaddyTTL)
 	app.Flag("godaddy-api-ote", "When using the GoDaddy provider, use OTE api (optional, default: false, when --provider=godaddy)").BoolVar(&cfg.GoDaddyOTE)
+	// MikroTik flags
+	app.Flag("mikrotik-url", "When using the MikroTik provider, the URL of the REST API of the RouterOS device, e.g. https://192.168.88.1/rest (required when --provider=mikrotik)").Default(defaultConfig.MikroTikURL).StringVar(&cfg.MikroTikURL)
+	app.Flag("mikrotik-username", "When using the MikroTik provider, the user to log in to the RouterOS device with (required when --provider=mikrotik)").Default(defaultConfig.MikroTikUsername).StringVar(&cfg.MikroTikUsername)
+	app.Flag("mikrotik-password", "When using the MikroTik provider, the password of the user (optional)").Default(defaultConfig.MikroTikPassword).StringVar(&cfg.MikroTikPassword)
+	app.Flag("mikrotik-skip-tls-verify", "When using the MikroTik provider, skip the verification of the certificate of the RouterOS device, commonly self-signed (default: disabled)").BoolVar(&cfg.MikroTikSkipTLSVerify)
+	// Resolver file flags
+	app.Flag("resolver-file-path", "When using the resolver-file provider, the file the records are rendered into, e.g. included by the configuration of the resolver (required when --provider=resolver-file)").Default(defaultConfig.ResolverFilePath).StringVar(&cfg.ResolverFilePath)
+	app.Flag("resolver-file-format", "When using the resolver-file provider, the format of the file; hosts files only hold A and AAAA records (default: dnsmasq, options: dnsmasq, unbound, hosts)").Default(defaultConfig.ResolverFileFormat).EnumVar(&cfg.ResolverFileFormat, "dnsmasq", "unbound", "hosts")
+	app.Flag("resolver-file-reload-pid-file", "When using the resolver-file provider, the pid file of the resolver, sent a SIGHUP once the file is written (optional)").Default(defaultConfig.ResolverFileReloadPIDFile).StringVar(&cfg.ResolverFileReloadPIDFile)
+	app.Flag("resolver-file-reload-command", "When using the resolver-file provider, a command run with sh -c once the file is written, e.g. unbound-control reload (optional)").Default(defaultConfig.ResolverFileReloadCommand).StringVar(&cfg.ResolverFileReloadCommand)
+	// Knot DNS flags
+	app.Flag("knot-socket", "When using the Knot DNS provider, the control socket of the server (default: /run/knot/knot.sock)").Default(defaultConfig.KnotSocket).StringVar(&cfg.KnotSocket)
+	app.Flag("knot-zone", "When using the Knot DNS provider, a zone to manage; specify multiple times for multiple zones (default: all the zones of the server)").StringsVar(&cfg.KnotZones)
+	app.Flag("knot-timeout", "When using the Knot DNS provider, the timeout of a session on the control socket (default: 10s)").Default(defaultConfig.KnotTimeout.String()).DurationVar(&cfg.KnotTimeout)
 
 	// Flags related to TLS communication
 	app.Flag("tls-ca", "When using TLS communication, the path to the certificate authority to verify server communications (optionally specify --tls-client-cert for two-way TLS)").Default(defaultConfig.TLSCA).StringVar(&cfg.TLSCA)
@@ -510,7 +736,8 @@ func (cfg *Config) ParseFlags(args []string) error {
 	// Flags related to RFC2136 provider
 	app.Flag("rfc2136-host", "When using the RFC2136 provider, specify the host of the DNS server").Default(defaultConfig.RFC2136Host).StringVar(&cfg.RFC2136Host)
 	app.Flag("rfc2136-port", "When using the RFC2136 provider, specify the port of the DNS server").Default(strconv.Itoa(defaultConfig.RFC2136Port)).IntVar(&cfg.RFC2136Port)
-	app.Flag("rfc2136-zone", "When using the RFC2136 provider, specify the zone entry of the DNS server to use").Default(defaultConfig.RFC2136Zone).StringVar(&cfg.RFC2136Zone)
+	app.Flag("rfc2136-zone", "When using the RFC2136 provider, specify the zone entry of the DNS server to use; specify multiple times for multiple zones").StringsVar(&cfg.RFC2136Zone)
+	app.Flag("rfc2136-zone-config", "When using the RFC2136 provider, add a zone or override the server and key used for it, in the form <zone>[,host=<host>][,port=<port>][,tsig-keyname=<name>][,tsig-secret=<secret>|,tsig-secret-file=<path>][,tsig-secret-alg=<alg>][,kerberos-realm=<realm>]; specify multiple times for multiple zones").StringsVar(&cfg.RFC2136ZoneConfigs)
 	app.Flag("rfc2136-insecure", "When using the RFC2136 provider, specify whether to attach TSIG or not (default: false, requires --rfc2136-tsig-keyname and rfc2136-tsig-secret)").Default(strconv.FormatBool(defaultConfig.RFC2136Insecure)).BoolVar(&cfg.RFC2136Insecure)
 	app.Flag("rfc2136-tsig-keyname", "When using the RFC2136 provider, specify the TSIG key to attached to DNS messages (required when --rfc2136-insecure=false)").Default(defaultConfig.RFC2136TSIGKeyName).StringVar(&cfg.RFC2136TSIGKeyName)
 	app.Flag("rfc2136-tsig-secret", "When using the RFC2136 provider, specify the TSIG (base64) value to attached to DNS messages (required when --rfc2136-insecure=false)").Default(defaultConfig.RFC2136TSIGSecret).StringVar(&cfg.RFC2136TSIGSecret)
@@ -519,7 +746,8 @@ func (cfg *Config) ParseFlags(args []string) error {
 	app.Flag("rfc2136-min-ttl", "When using the RFC2136 provider, specify minimal TTL (in duration format) for records. This value will be used if the provided TTL for a service/ingress is lower than this").Default(defaultConfig.RFC2136MinTTL.String()).DurationVar(&cfg.RFC2136MinTTL)
 	app.Flag("rfc2136-gss-tsig", "When using the RFC2136 provider, specify whether to use secure updates with GSS-TSIG using Kerberos (default: false, requires --rfc2136-kerberos-realm, --rfc2136-kerberos-username, and rfc2136-kerberos-password)").Default(strconv.FormatBool(defaultConfig.RFC2136GSSTSIG)).BoolVar(&cfg.RFC2136GSSTSIG)
 	app.Flag("rfc2136-kerberos-username", "When using the RFC2136 provider with GSS-TSIG, specify the username of the user with permissions to update DNS records (required when --rfc2136-gss-tsig=true)").Default(defaultConfig.RFC2136KerberosUsername).StringVar(&cfg.RFC2136KerberosUsername)
-	app.Flag("rfc2136-kerberos-password", "When using the RFC2136 provider with GSS-TSIG, specify the password of the user with permissions to update DNS records (required when --rfc2136-gss-tsig=true)").Default(defaultConfig.RFC2136KerberosPassword).StringVar(&cfg.RFC2136KerberosPassword)
+	app.Flag("rfc2136-kerberos-password", "When using the RFC2136 provider with GSS-TSIG, specify the password of the user with permissions to update DNS records (required when --rfc2136-gss-tsig=true, unless --rfc2136-kerberos-keytab is specified)").Default(defaultConfig.RFC2136KerberosPassword).StringVar(&cfg.RFC2136KerberosPassword)
+	app.Flag("rfc2136-kerberos-keytab", "When using the RFC2136 provider with GSS-TSIG, specify the path of a keytab used instead of --rfc2136-kerberos-password to authenticate the user").Default(defaultConfig.RFC2136KerberosKeytab).StringVar(&cfg.RFC2136KerberosKeytab)
 	app.Flag("rfc2136-kerberos-realm", "When using the RFC2136 provider with GSS-TSIG, specify the realm of the user with permissions to update DNS records (required when --rfc2136-gss-tsig=true)").Default(defaultConfig.RFC2136KerberosRealm).StringVar(&cfg.RFC2136KerberosRealm)
 	app.Flag("rfc2136-batch-change-size", "When using the RFC2136 provider, set the maximum number of changes that will be applied in each batch.").Default(strconv.Itoa(defaultConfig.RFC2136BatchChangeSize)).IntVar(&cfg.RFC2136BatchChangeSize)
 
@@ -529,13 +757,25 @@ func (cfg *Config) ParseFlags(args []string) error {
 
 	// Flags related to policies
 	app.Flag("policy", "Modify how DNS records are synchronized between sources and providers (default: sync, options: sync, upsert-only, create-only)").Default(defaultConfig.Policy).EnumVar(&cfg.Policy, "sync", "upsert-only", "create-only")
+	app.Flag("weighted-targets-property", "Distribute the targets of the A, AAAA and CNAME records with multiple targets into weighted record sets, one per target, storing the weight of each set in the given provider specific property, e.g. aws/weight for the AWS provider; the weights are read from the target-weights annotation and default to 1 (default: disabled)").Default(defaultConfig.WeightedTargetsProperty).StringVar(&cfg.WeightedTargetsProperty)
+	app.Flag("conflict-resolver", "How to resolve a DNS name wanted by several resources (default: per-resource, options: per-resource, prefer-longer-ttl, prefer-alphabetical, prefer-source-priority, manual-deny)").Default(defaultConfig.ConflictResolver).EnumVar(&cfg.ConflictResolver, "per-resource", "prefer-longer-ttl", "prefer-alphabetical", "prefer-source-priority", "manual-deny")
+	app.Flag("conflict-source-priority", "The resource kinds preferred by the prefer-source-priority conflict resolver, e.g. ingress or service, highest priority first; specify multiple times for multiple kinds (required when --conflict-resolver=prefer-source-priority)").StringsVar(&cfg.ConflictSourcePriority)
+	app.Flag("max-creates-per-sync", "The maximum number of records created in a single synchronization, the remaining ones are created in the next synchronizations (default: 0, unlimited)").Default(strconv.Itoa(defaultConfig.MaxCreatesPerSync)).IntVar(&cfg.MaxCreatesPerSync)
+	app.Flag("max-updates-per-sync", "The maximum number of records updated in a single synchronization, the remaining ones are updated in the next synchronizations (default: 0, unlimited)").Default(strconv.Itoa(defaultConfig.MaxUpdatesPerSync)).IntVar(&cfg.MaxUpdatesPerSync)
+	app.Flag("max-deletes-per-sync", "The maximum number of records deleted in a single synchronization, the remaining ones are deleted in the next synchronizations (default: 0, unlimited)").Default(strconv.Itoa(defaultConfig.MaxDeletesPerSync)).IntVar(&cfg.MaxDeletesPerSync)
+	app.Flag("deletion-grace-period", "The number of consecutive synchronizations a record must be missing from the sources before being deleted, counted in the labels of the TXT registry; protects against flapping sources causing delete and create storms (default: 0, delete right away)").Default(strconv.Itoa(defaultConfig.DeletionGracePeriod)).IntVar(&cfg.DeletionGracePeriod)
 
 	// Flags related to the registry
 	app.Flag("registry", "The registry implementation to use to keep track of DNS record ownership (default: txt, options: txt, noop, aws-sd)").Default(defaultConfig.Registry).EnumVar(&cfg.Registry, "txt", "noop", "aws-sd")
 	app.Flag("txt-owner-id", "When using the TXT registry, a name that identifies this instance of ExternalDNS (default: default)").Default(defaultConfig.TXTOwnerID).StringVar(&cfg.TXTOwnerID)
+	app.Flag("txt-owner-group", "When using the TXT registry, the owner id of an instance of ExternalDNS sharing the ownership of its records with this one, e.g. the other instance of an active/passive pair; the records of the group are managed as if owned by this instance and written with --txt-owner-id; specify multiple times for multiple owner ids (optional)").StringsVar(&cfg.TXTOwnerGroup)
 	app.Flag("txt-prefix", "When using the TXT registry, a custom string that's prefixed to each ownership DNS record (optional). Could contain record type template like '%{record_type}-prefix-'. Mutual exclusive with txt-suffix!").Default(defaultConfig.TXTPrefix).StringVar(&cfg.TXTPrefix)
 	app.Flag("txt-suffix", "When using the TXT registry, a custom string that's suffixed to the host portion of each ownership DNS record (optional). Could contain record type template like '-%{record_type}-suffix'. Mutual exclusive with txt-prefix!").Default(defaultConfig.TXTSuffix).StringVar(&cfg.TXTSuffix)
 	app.Flag("txt-wildcard-replacement", "When using the TXT registry, a custom string that's used instead of an asterisk for TXT records corresponding to wildcard DNS records (optional)").Default(defaultConfig.TXTWildcardReplacement).StringVar(&cfg.TXTWildcardReplacement)
+	app.Flag("txt-owner-transfer-from", "When using the TXT registry, rewrite the ownership of the records owned by the given owner id to --txt-owner-id and exit, leaving the records themselves untouched, to migrate records between instances of ExternalDNS; combine with --dry-run to only print the changes (optional)").Default(defaultConfig.TXTOwnerTransferFrom).StringVar(&cfg.TXTOwnerTransferFrom)
+	app.Flag("txt-encrypt-aes-key", "When using the TXT registry, encrypt the labels stored in the TXT records with AES-256-GCM using the given 32 bytes key, raw or base64 encoded; the records of this instance stored in plain text or with a key given to --txt-decrypt-aes-key are encrypted with this key during the next synchronization (optional)").Default(defaultConfig.TXTEncryptAESKey).StringVar(&cfg.TXTEncryptAESKey)
+	app.Flag("txt-decrypt-aes-key", "When using --txt-encrypt-aes-key, a previous key the TXT records may still be encrypted with, for the rotation of the key; specify multiple times for multiple keys (optional)").StringsVar(&cfg.TXTDecryptAESKeys)
+	app.Flag("txt-format-v4", "When using the TXT registry, store the labels of all the records of a name in a single compressed TXT record, the v4 format; the records of this instance stored in the previous formats are migrated during the next synchronization (default: disabled)").BoolVar(&cfg.TXTFormatV4)
 
 	// Flags related to the main control loop
 	app.Flag("txt-cache-interval", "The interval between cache synchronizations in duration format (default: disabled)").Default(defaultConfig.TXTCacheInterval.String()).DurationVar(&cfg.TXTCacheInterval)
@@ -543,6 +783,10 @@ func (cfg *Config) ParseFlags(args []string) error {
 	app.Flag("min-event-sync-interval", "The minimum interval between two consecutive synchronizations triggered from kubernetes events in duration format (default: 5s)").Default(defaultConfig.MinEventSyncInterval.String()).DurationVar(&cfg.MinEventSyncInterval)
 	app.Flag("once", "When enabled, exits the synchronization loop after the first iteration (default: disabled)").BoolVar(&cfg.Once)
 	app.Flag("dry-run", "When enabled, prints DNS record changes rather than actually performing them (default: disabled)").BoolVar(&cfg.DryRun)
+	app.Flag("dry-run-output", "Write the DNS record changes calculated by every synchronization, with the old and new values of the updated records, in the given format; usually combined with --dry-run and --once to review a plan before applying it (default: disabled, options: json, yaml, table)").Default(defaultConfig.DryRunOutput).EnumVar(&cfg.DryRunOutput, "", "json", "yaml", "table")
+	app.Flag("dry-run-output-file", "When using --dry-run-output, the file the changes are written to (default: stdout)").Default(defaultConfig.DryRunOutputFile).StringVar(&cfg.DryRunOutputFile)
+	app.Flag("approval-webhook-url", "The URL the changes calculated by every synchronization are POSTed to, in the JSON format of --dry-run-output, before being applied; the changes are only applied if the webhook responds with 200 OK and an empty body or {\"approved\": true} (default: disabled)").Default(defaultConfig.ApprovalWebhookURL).StringVar(&cfg.ApprovalWebhookURL)
+	app.Flag("approval-webhook-timeout", "When using --approval-webhook-url, the timeout of the requests to the approval webhook in duration format (default: 10s)").Default(defaultConfig.ApprovalWebhookTimeout.String()).DurationVar(&cfg.ApprovalWebhookTimeout)
 	app.Flag("events", "When enabled, in addition to running every interval, the reconciliation loop will get triggered when supported sources change (default: disabled)").BoolVar(&cfg.UpdateEvents)
 
 	// Miscellaneous flags
@@ -43,6 +43,8 @@ var (
 		FQDNTemplate:                "",
 		Compatibility:               "",
 		Provider:                    "google",
+		ProviderBurst:               1,
+		ProviderRetryBackoff:        time.Second,
 		GoogleProject:               "",
 		GoogleBatchChangeSize:       1000,
 		GoogleBatchChangeInterval:   time.Second,
@@ -100,6 +102,7 @@ var (
 		PDNSServer:                  "http://localhost:8081",
 		PDNSAPIKey:                  "",
 		Policy:                      "sync",
+		ConflictResolver:            "per-resource",
 		Registry:                    "txt",
 		TXTOwnerID:                  "default",
 		TXTPrefix:                   "",
@@ -108,11 +111,25 @@ var (
 		MinEventSyncInterval:        5 * time.Second,
 		Once:                        false,
 		DryRun:                      false,
+		ApprovalWebhookTimeout:      10 * time.Second,
 		UpdateEvents:                false,
 		LogFormat:                   "text",
 		MetricsAddress:              ":7979",
 		LogLevel:                    logrus.InfoLevel.String(),
 		ConnectorSourceServer:       "localhost:8080",
+		LibvirtSocket:               "/var/run/libvirt/libvirt-sock",
+		LibvirtURI:                  "qemu:///system",
+		LibvirtAddressSource:        "lease",
+		ProxmoxTagPrefix:            "dns.",
+		MQTTTopic:                   "external-dns/endpoints/#",
+		MQTTClientID:                "external-dns",
+		PrometheusSDHostnameLabel:   "dns_name",
+		PrometheusSDTTLLabel:        "dns_ttl",
+		PodmanSocket:                "unix:///run/podman/podman.sock",
+		ContainerdNerdctl:           "nerdctl",
+		ContainerdAddress:           "/run/containerd/containerd.sock",
+		ContainerdNamespaces:        []string{"default"},
+		SourceConflictStrategy:      "none",
 		ExoscaleEndpoint:            "https://api.exoscale.ch/dns",
 		ExoscaleAPIKey:              "",
 		ExoscaleAPISecret:           "",
@@ -127,6 +144,9 @@ var (
 		OCPRouterName:               "default",
 		IBMCloudProxied:             false,
 		IBMCloudConfigFile:          "/etc/kubernetes/ibmcloud.json",
+		ResolverFileFormat:          "dnsmasq",
+		KnotSocket:                  "/run/knot/knot.sock",
+		KnotTimeout:                 10 * time.Second,
 	}
 
 	overriddenConfig = &Config{
@@ -144,6 +164,11 @@ var (
 		FQDNTemplate:                "{{.Name}}.service.example.com",
 		Compatibility:               "mate",
 		Provider:                    "google",
+		ProviderQPS:                 2.5,
+		ProviderBurst:               5,
+		ProviderRetries:             3,
+		ProviderRetryBackoff:        2 * time.Second,
+		ProviderCacheTime:           time.Minute,
 		GoogleProject:               "project",
 		GoogleBatchChangeSize:       100,
 		GoogleBatchChangeInterval:   time.Second * 2,
@@ -207,6 +232,7 @@ var (
 		TLSClientCert:               "/path/to/cert.pem",
 		TLSClientCertKey:            "/path/to/key.pem",
 		Policy:                      "upsert-only",
+		ConflictResolver:            "manual-deny",
 		Registry:                    "noop",
 		TXTOwnerID:                  "owner-1",
 		TXTPrefix:                   "associated-txt-record",
@@ -215,11 +241,25 @@ var (
 		MinEventSyncInterval:        50 * time.Second,
 		Once:                        true,
 		DryRun:                      true,
+		ApprovalWebhookTimeout:      time.Minute,
 		UpdateEvents:                true,
 		LogFormat:                   "json",
 		MetricsAddress:              "127.0.0.1:9099",
 		LogLevel:                    logrus.DebugLevel.String(),
 		ConnectorSourceServer:       "localhost:8081",
+		LibvirtSocket:               "/run/libvirt/libvirt-sock",
+		LibvirtURI:                  "qemu:///session",
+		LibvirtAddressSource:        "agent",
+		ProxmoxTagPrefix:            "external-dns.",
+		MQTTTopic:                   "dns/+",
+		MQTTClientID:                "external-dns-1",
+		PrometheusSDHostnameLabel:   "hostname",
+		PrometheusSDTTLLabel:        "ttl",
+		PodmanSocket:                "unix:///run/user/1000/podman/podman.sock",
+		ContainerdNerdctl:           "/usr/local/bin/nerdctl",
+		ContainerdAddress:           "/run/k3s/containerd/containerd.sock",
+		ContainerdNamespaces:        []string{"default", "buildkit"},
+		SourceConflictStrategy:      "first-wins",
 		ExoscaleEndpoint:            "https://api.foo.ch/dns",
 		ExoscaleAPIKey:              "1",
 		ExoscaleAPISecret:           "2",
@@ -235,6 +275,10 @@ var (
 		RFC2136BatchChangeSize:      100,
 		IBMCloudProxied:             true,
 		IBMCloudConfigFile:          "ibmcloud.json",
+		ResolverFileFormat:          "unbound",
+		KnotSocket:                  "/var/run/knot.sock",
+		KnotZones:                   []string{"example.org"},
+		KnotTimeout:                 30 * time.Second,
 	}
 )
 
@@ -274,6 +318,11 @@ func TestParseFlags(t *testing.T) {
 				"--ignore-ingress-rules-spec",
 				"--compatibility=mate",
 				"--provider=google",
+				"--provider-qps=2.5",
+				"--provider-burst=5",
+				"--provider-retries=3",
+				"--provider-retry-backoff=2s",
+				"--provider-cache-time=1m",
 				"--google-project=project",
 				"--google-batch-change-size=100",
 				"--google-batch-change-interval=2s",
@@ -343,6 +392,8 @@ func TestParseFlags(t *testing.T) {
 				"--aws-sd-service-cleanup",
 				"--no-aws-evaluate-target-health",
 				"--policy=upsert-only",
+				"--conflict-resolver=manual-deny",
+				"--approval-webhook-timeout=1m",
 				"--registry=noop",
 				"--txt-owner-id=owner-1",
 				"--txt-prefix=associated-txt-record",
@@ -356,6 +407,20 @@ func TestParseFlags(t *testing.T) {
 				"--metrics-address=127.0.0.1:9099",
 				"--log-level=debug",
 				"--connector-source-server=localhost:8081",
+				"--libvirt-socket=/run/libvirt/libvirt-sock",
+				"--libvirt-uri=qemu:///session",
+				"--libvirt-address-source=agent",
+				"--proxmox-tag-prefix=external-dns.",
+				"--mqtt-topic=dns/+",
+				"--mqtt-client-id=external-dns-1",
+				"--prometheus-sd-hostname-label=hostname",
+				"--prometheus-sd-ttl-label=ttl",
+				"--podman-socket=unix:///run/user/1000/podman/podman.sock",
+				"--containerd-nerdctl=/usr/local/bin/nerdctl",
+				"--containerd-address=/run/k3s/containerd/containerd.sock",
+				"--containerd-namespace=default",
+				"--containerd-namespace=buildkit",
+				"--source-conflict-strategy=first-wins",
 				"--exoscale-endpoint=https://api.foo.ch/dns",
 				"--exoscale-apikey=1",
 				"--exoscale-apisecret=2",
@@ -373,6 +438,10 @@ func TestParseFlags(t *testing.T) {
 				"--rfc2136-batch-change-size=100",
 				"--ibmcloud-proxied",
 				"--ibmcloud-config-file=ibmcloud.json",
+				"--resolver-file-format=unbound",
+				"--knot-socket=/var/run/knot.sock",
+				"--knot-zone=example.org",
+				"--knot-timeout=30s",
 			},
 			envVars:  map[string]string{},
 			expected: overriddenConfig,
@@ -395,6 +464,11 @@ func TestParseFlags(t *testing.T) {
 				"EXTERNAL_DNS_IGNORE_INGRESS_RULES_SPEC":       "1",
 				"EXTERNAL_DNS_COMPATIBILITY":                   "mate",
 				"EXTERNAL_DNS_PROVIDER":                        "google",
+				"EXTERNAL_DNS_PROVIDER_QPS":                    "2.5",
+				"EXTERNAL_DNS_PROVIDER_BURST":                  "5",
+				"EXTERNAL_DNS_PROVIDER_RETRIES":                "3",
+				"EXTERNAL_DNS_PROVIDER_RETRY_BACKOFF":          "2s",
+				"EXTERNAL_DNS_PROVIDER_CACHE_TIME":             "1m",
 				"EXTERNAL_DNS_GOOGLE_PROJECT":                  "project",
 				"EXTERNAL_DNS_GOOGLE_BATCH_CHANGE_SIZE":        "100",
 				"EXTERNAL_DNS_GOOGLE_BATCH_CHANGE_INTERVAL":    "2s",
@@ -458,6 +532,8 @@ func TestParseFlags(t *testing.T) {
 				"EXTERNAL_DNS_AWS_ZONES_CACHE_DURATION":        "10s",
 				"EXTERNAL_DNS_AWS_SD_SERVICE_CLEANUP":          "true",
 				"EXTERNAL_DNS_POLICY":                          "upsert-only",
+				"EXTERNAL_DNS_CONFLICT_RESOLVER":               "manual-deny",
+				"EXTERNAL_DNS_APPROVAL_WEBHOOK_TIMEOUT":        "1m",
 				"EXTERNAL_DNS_REGISTRY":                        "noop",
 				"EXTERNAL_DNS_TXT_OWNER_ID":                    "owner-1",
 				"EXTERNAL_DNS_TXT_PREFIX":                      "associated-txt-record",
@@ -471,6 +547,19 @@ func TestParseFlags(t *testing.T) {
 				"EXTERNAL_DNS_METRICS_ADDRESS":                 "127.0.0.1:9099",
 				"EXTERNAL_DNS_LOG_LEVEL":                       "debug",
 				"EXTERNAL_DNS_CONNECTOR_SOURCE_SERVER":         "localhost:8081",
+				"EXTERNAL_DNS_LIBVIRT_SOCKET":                  "/run/libvirt/libvirt-sock",
+				"EXTERNAL_DNS_LIBVIRT_URI":                     "qemu:///session",
+				"EXTERNAL_DNS_LIBVIRT_ADDRESS_SOURCE":          "agent",
+				"EXTERNAL_DNS_PROXMOX_TAG_PREFIX":              "external-dns.",
+				"EXTERNAL_DNS_MQTT_TOPIC":                      "dns/+",
+				"EXTERNAL_DNS_MQTT_CLIENT_ID":                  "external-dns-1",
+				"EXTERNAL_DNS_PROMETHEUS_SD_HOSTNAME_LABEL":    "hostname",
+				"EXTERNAL_DNS_PROMETHEUS_SD_TTL_LABEL":         "ttl",
+				"EXTERNAL_DNS_PODMAN_SOCKET":                   "unix:///run/user/1000/podman/podman.sock",
+				"EXTERNAL_DNS_CONTAINERD_NERDCTL":              "/usr/local/bin/nerdctl",
+				"EXTERNAL_DNS_CONTAINERD_ADDRESS":              "/run/k3s/containerd/containerd.sock",
+				"EXTERNAL_DNS_CONTAINERD_NAMESPACE":            "default\nbuildkit",
+				"EXTERNAL_DNS_SOURCE_CONFLICT_STRATEGY":        "first-wins",
 				"EXTERNAL_DNS_EXOSCALE_ENDPOINT":               "https://api.foo.ch/dns",
 				"EXTERNAL_DNS_EXOSCALE_APIKEY":                 "1",
 				"EXTERNAL_DNS_EXOSCALE_APISECRET":              "2",
@@ -486,6 +575,10 @@ func TestParseFlags(t *testing.T) {
 				"EXTERNAL_DNS_RFC2136_BATCH_CHANGE_SIZE":       "100",
 				"EXTERNAL_DNS_IBMCLOUD_PROXIED":                "1",
 				"EXTERNAL_DNS_IBMCLOUD_CONFIG_FILE":            "ibmcloud.json",
+				"EXTERNAL_DNS_RESOLVER_FILE_FORMAT":            "unbound",
+				"EXTERNAL_DNS_KNOT_SOCKET":                     "/var/run/knot.sock",
+				"EXTERNAL_DNS_KNOT_ZONE":                       "example.org",
+				"EXTERNAL_DNS_KNOT_TIMEOUT":                    "30s",
 			},
 			expected: overriddenConfig,
 		},
@@ -526,6 +619,9 @@ func TestPasswordsNotLogged(t *testing.T) {
 		InfobloxWapiPassword: "infoblox-pass",
 		PDNSAPIKey:           "pdns-api-key",
 		RFC2136TSIGSecret:    "tsig-secret",
+		RFC2136ZoneConfigs:   []string{"example.org,tsig-secret=zone-tsig-secret"},
+		TXTEncryptAESKey:     "txt-encrypt-key",
+		TXTDecryptAESKeys:    []string{"txt-decrypt-key"},
 	}
 
 	s := cfg.String()
@@ -534,4 +630,7 @@ func TestPasswordsNotLogged(t *testing.T) {
 	assert.False(t, strings.Contains(s, "infoblox-pass"))
 	assert.False(t, strings.Contains(s, "pdns-api-key"))
 	assert.False(t, strings.Contains(s, "tsig-secret"))
+	assert.False(t, strings.Contains(s, "zone-tsig-secret"))
+	assert.False(t, strings.Contains(s, "txt-encrypt-key"))
+	assert.False(t, strings.Contains(s, "txt-decrypt-key"))
 }
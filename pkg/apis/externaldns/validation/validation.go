@@ -19,6 +19,7 @@ package validation
 import (
 	"errors"
 	"fmt"
+	"strings"
 
 	"k8s.io/apimachinery/pkg/labels"
 
@@ -84,6 +85,19 @@ func ValidateConfig(cfg *externaldns.Config) error {
 		}
 	}
 
+	if cfg.Provider == "mikrotik" {
+		if cfg.MikroTikURL == "" {
+			return errors.New("no MikroTik REST API url specified")
+		}
+		if cfg.MikroTikUsername == "" {
+			return errors.New("no MikroTik username specified")
+		}
+	}
+
+	if cfg.Provider == "resolver-file" && cfg.ResolverFilePath == "" {
+		return errors.New("no resolver file path specified")
+	}
+
 	if cfg.Provider == "rfc2136" {
 		if cfg.RFC2136MinTTL < 0 {
 			return errors.New("TTL specified for rfc2136 is negative")
@@ -94,8 +108,8 @@ func ValidateConfig(cfg *externaldns.Config) error {
 		}
 
 		if cfg.RFC2136GSSTSIG {
-			if cfg.RFC2136KerberosPassword == "" || cfg.RFC2136KerberosUsername == "" || cfg.RFC2136KerberosRealm == "" {
-				return errors.New("--rfc2136-kerberos-realm, --rfc2136-kerberos-username, and --rfc2136-kerberos-password are required when specifying --rfc2136-gss-tsig option")
+			if (cfg.RFC2136KerberosPassword == "" && cfg.RFC2136KerberosKeytab == "") || cfg.RFC2136KerberosUsername == "" || cfg.RFC2136KerberosRealm == "" {
+				return errors.New("--rfc2136-kerberos-realm, --rfc2136-kerberos-username, and --rfc2136-kerberos-password or --rfc2136-kerberos-keytab are required when specifying --rfc2136-gss-tsig option")
 			}
 		}
 
@@ -104,6 +118,101 @@ func ValidateConfig(cfg *externaldns.Config) error {
 		}
 	}
 
+	for _, source := range cfg.Sources {
+		if source == "remote" && cfg.RemoteSourceURL == "" {
+			return errors.New("--remote-source-url is required when using the remote source")
+		}
+		if source == "proxmox" && cfg.ProxmoxURL == "" {
+			return errors.New("--proxmox-url is required when using the proxmox source")
+		}
+		if source == "netbox" && cfg.NetBoxURL == "" {
+			return errors.New("--netbox-url is required when using the netbox source")
+		}
+		if source == "mqtt" && cfg.MQTTBroker == "" {
+			return errors.New("--mqtt-broker is required when using the mqtt source")
+		}
+		if source == "prometheus-sd" && cfg.PrometheusSDURL == "" {
+			return errors.New("--prometheus-sd-url is required when using the prometheus-sd source")
+		}
+		if source == "compose" && len(cfg.ComposeFiles) == 0 {
+			return errors.New("--compose-file is required when using the compose source")
+		}
+		if source == "dnsendpoint-file" && len(cfg.DNSEndpointPaths) == 0 {
+			return errors.New("--dnsendpoint-path is required when using the dnsendpoint-file source")
+		}
+		if source == "plugin" && cfg.PluginSourceURL == "" {
+			return errors.New("--plugin-source-url is required when using the plugin source")
+		}
+	}
+
+	for _, filter := range append(append([]string{}, cfg.SourceDomainFilters...), cfg.SourceExcludeDomains...) {
+		source, domain, found := strings.Cut(filter, "=")
+		if !found || domain == "" {
+			return fmt.Errorf("invalid source domain filter %q: expected <source>=<domain>", filter)
+		}
+		if !contains(cfg.Sources, source) {
+			return fmt.Errorf("invalid source domain filter %q: source %s is not enabled", filter, source)
+		}
+	}
+
+	if cfg.InternalProvider != "" {
+		// the same provider would otherwise manage the same zones for both views
+		if cfg.InternalProvider == cfg.Provider && len(cfg.InternalDomainFilter) == 0 && len(cfg.InternalZoneIDFilter) == 0 && cfg.InternalAWSZoneType == "" {
+			return errors.New("--internal-domain-filter, --internal-zone-id-filter or --internal-aws-zone-type is required when --internal-provider is the same as --provider")
+		}
+		if cfg.Registry == "aws-sd" {
+			return errors.New("--internal-provider is not supported with the aws-sd registry")
+		}
+	} else if len(cfg.SplitHorizonInternalDomains) > 0 || len(cfg.SplitHorizonInternalSources) > 0 {
+		return errors.New("--internal-provider is required when routing records to an internal provider")
+	} else if len(cfg.InternalDomainFilter) > 0 || len(cfg.InternalZoneIDFilter) > 0 || cfg.InternalAWSZoneType != "" {
+		return errors.New("--internal-provider is required when filtering the zones of an internal provider")
+	}
+
+	if cfg.MaxCreatesPerSync < 0 || cfg.MaxUpdatesPerSync < 0 || cfg.MaxDeletesPerSync < 0 {
+		return errors.New("--max-creates-per-sync, --max-updates-per-sync and --max-deletes-per-sync must not be negative")
+	}
+
+	if cfg.DeletionGracePeriod < 0 {
+		return errors.New("--deletion-grace-period must not be negative")
+	}
+	if cfg.DeletionGracePeriod > 1 && cfg.Registry != "txt" {
+		return errors.New("--deletion-grace-period requires the txt registry, which stores the synchronizations records are missing for")
+	}
+
+	if len(cfg.TXTDecryptAESKeys) > 0 && cfg.TXTEncryptAESKey == "" {
+		return errors.New("--txt-decrypt-aes-key requires --txt-encrypt-aes-key")
+	}
+	if cfg.TXTEncryptAESKey != "" && cfg.Registry != "txt" {
+		return errors.New("--txt-encrypt-aes-key requires the txt registry")
+	}
+	if cfg.TXTFormatV4 && cfg.Registry != "txt" {
+		return errors.New("--txt-format-v4 requires the txt registry")
+	}
+	if cfg.ProviderQPS < 0 || cfg.ProviderRetries < 0 || cfg.ProviderRetryBackoff < 0 || cfg.ProviderCacheTime < 0 {
+		return errors.New("--provider-qps, --provider-retries, --provider-retry-backoff and --provider-cache-time cannot be negative")
+	}
+	// the aws-sd registry requires the AWS Cloud Map provider itself, not a provider wrapping it
+	if usesAWSSDRegistry(cfg) && (cfg.ProviderQPS > 0 || cfg.ProviderRetries > 0) {
+		return errors.New("--provider-qps and --provider-retries are not supported with the aws-sd registry")
+	}
+	if usesAWSSDRegistry(cfg) && cfg.ProviderCacheTime > 0 {
+		return errors.New("--provider-cache-time is not supported with the aws-sd registry")
+	}
+
+	if cfg.TXTOwnerTransferFrom != "" {
+		if cfg.Registry != "txt" {
+			return errors.New("--txt-owner-transfer-from requires the txt registry")
+		}
+		if cfg.TXTOwnerTransferFrom == cfg.TXTOwnerID {
+			return errors.New("--txt-owner-transfer-from must differ from --txt-owner-id")
+		}
+	}
+
+	if cfg.ConflictResolver == "prefer-source-priority" && len(cfg.ConflictSourcePriority) == 0 {
+		return errors.New("--conflict-source-priority is required with the prefer-source-priority conflict resolver")
+	}
+
 	if cfg.IgnoreHostnameAnnotation && cfg.FQDNTemplate == "" {
 		return errors.New("FQDN Template must be set if ignoring annotations")
 	}
@@ -118,3 +227,18 @@ func ValidateConfig(cfg *externaldns.Config) error {
 	}
 	return nil
 }
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// usesAWSSDRegistry tells whether the aws-sd registry is used, which is also the case for
+// the aws-sd provider unless the noop registry is set.
+func usesAWSSDRegistry(cfg *externaldns.Config) bool {
+	return cfg.Registry == "aws-sd" || (cfg.Provider == "aws-sd" && cfg.Registry != "noop")
+}
@@ -18,6 +18,7 @@ package validation
 
 import (
 	"testing"
+	"time"
 
 	"sigs.k8s.io/external-dns/pkg/apis/externaldns"
 
@@ -125,6 +126,151 @@ func TestValidateBadIgnoreHostnameAnnotationsConfig(t *testing.T) {
 	assert.Error(t, ValidateConfig(cfg))
 }
 
+func TestValidateRemoteSourceConfig(t *testing.T) {
+	cfg := newValidConfig(t)
+	cfg.Sources = []string{"remote"}
+	assert.Error(t, ValidateConfig(cfg))
+
+	cfg.RemoteSourceURL = "https://inventory.example.org/endpoints"
+	assert.NoError(t, ValidateConfig(cfg))
+}
+
+func TestValidateProxmoxSourceConfig(t *testing.T) {
+	cfg := newValidConfig(t)
+	cfg.Sources = []string{"proxmox"}
+	assert.Error(t, ValidateConfig(cfg))
+
+	cfg.ProxmoxURL = "https://pve.example.org:8006"
+	assert.NoError(t, ValidateConfig(cfg))
+}
+
+func TestValidateNetBoxSourceConfig(t *testing.T) {
+	cfg := newValidConfig(t)
+	cfg.Sources = []string{"netbox"}
+	assert.Error(t, ValidateConfig(cfg))
+
+	cfg.NetBoxURL = "https://netbox.example.org"
+	assert.NoError(t, ValidateConfig(cfg))
+}
+
+func TestValidateMQTTSourceConfig(t *testing.T) {
+	cfg := newValidConfig(t)
+	cfg.Sources = []string{"mqtt"}
+	assert.Error(t, ValidateConfig(cfg))
+
+	cfg.MQTTBroker = "tcp://mqtt.example.org:1883"
+	assert.NoError(t, ValidateConfig(cfg))
+}
+
+func TestValidatePrometheusSDSourceConfig(t *testing.T) {
+	cfg := newValidConfig(t)
+	cfg.Sources = []string{"prometheus-sd"}
+	assert.Error(t, ValidateConfig(cfg))
+
+	cfg.PrometheusSDURL = "http://consul-exporter.example.org/sd"
+	assert.NoError(t, ValidateConfig(cfg))
+}
+
+func TestValidateComposeSourceConfig(t *testing.T) {
+	cfg := newValidConfig(t)
+	cfg.Sources = []string{"compose"}
+	assert.Error(t, ValidateConfig(cfg))
+
+	cfg.ComposeFiles = []string{"docker-compose.yml"}
+	assert.NoError(t, ValidateConfig(cfg))
+}
+
+func TestValidateDNSEndpointFileSourceConfig(t *testing.T) {
+	cfg := newValidConfig(t)
+	cfg.Sources = []string{"dnsendpoint-file"}
+	assert.Error(t, ValidateConfig(cfg))
+
+	cfg.DNSEndpointPaths = []string{"/etc/external-dns/endpoints"}
+	assert.NoError(t, ValidateConfig(cfg))
+}
+
+func TestValidatePluginSourceConfig(t *testing.T) {
+	cfg := newValidConfig(t)
+	cfg.Sources = []string{"plugin"}
+	assert.Error(t, ValidateConfig(cfg))
+
+	cfg.PluginSourceURL = "http://localhost:8888"
+	assert.NoError(t, ValidateConfig(cfg))
+}
+
+func TestValidateSourceDomainFilters(t *testing.T) {
+	cfg := newValidConfig(t)
+	cfg.Sources = []string{"service", "ingress"}
+	cfg.SourceDomainFilters = []string{"ingress=example.org"}
+	cfg.SourceExcludeDomains = []string{"service=internal.example.org"}
+	assert.NoError(t, ValidateConfig(cfg))
+
+	cfg.SourceDomainFilters = []string{"example.org"}
+	assert.Error(t, ValidateConfig(cfg))
+
+	cfg.SourceDomainFilters = []string{"ingress="}
+	assert.Error(t, ValidateConfig(cfg))
+
+	cfg.SourceDomainFilters = []string{"node=example.org"}
+	assert.Error(t, ValidateConfig(cfg))
+}
+
+func TestValidateInternalProvider(t *testing.T) {
+	cfg := newValidConfig(t)
+	cfg.SplitHorizonInternalDomains = []string{"internal.example.org"}
+	assert.Error(t, ValidateConfig(cfg))
+
+	cfg.InternalProvider = "coredns"
+	assert.NoError(t, ValidateConfig(cfg))
+
+	cfg.InternalProvider = cfg.Provider
+	assert.Error(t, ValidateConfig(cfg))
+
+	cfg.InternalAWSZoneType = "private"
+	assert.NoError(t, ValidateConfig(cfg))
+
+	cfg.InternalProvider = ""
+	cfg.SplitHorizonInternalDomains = nil
+	assert.Error(t, ValidateConfig(cfg))
+
+	cfg.InternalProvider = "coredns"
+	cfg.InternalAWSZoneType = ""
+	cfg.Registry = "aws-sd"
+	assert.Error(t, ValidateConfig(cfg))
+}
+
+func TestValidateConflictResolver(t *testing.T) {
+	cfg := newValidConfig(t)
+	cfg.ConflictResolver = "prefer-source-priority"
+	assert.Error(t, ValidateConfig(cfg))
+
+	cfg.ConflictSourcePriority = []string{"ingress", "service"}
+	assert.NoError(t, ValidateConfig(cfg))
+}
+
+func TestValidateChangeLimits(t *testing.T) {
+	cfg := newValidConfig(t)
+	cfg.MaxCreatesPerSync = 100
+	cfg.MaxDeletesPerSync = 10
+	assert.NoError(t, ValidateConfig(cfg))
+
+	cfg.MaxUpdatesPerSync = -1
+	assert.Error(t, ValidateConfig(cfg))
+}
+
+func TestValidateDeletionGracePeriod(t *testing.T) {
+	cfg := newValidConfig(t)
+	cfg.DeletionGracePeriod = 3
+	cfg.Registry = "txt"
+	assert.NoError(t, ValidateConfig(cfg))
+
+	cfg.Registry = "noop"
+	assert.Error(t, ValidateConfig(cfg))
+
+	cfg.DeletionGracePeriod = -1
+	assert.Error(t, ValidateConfig(cfg))
+}
+
 func TestValidateBadRfc2136Config(t *testing.T) {
 	cfg := externaldns.NewConfig()
 
@@ -271,6 +417,17 @@ func TestValidateGoodRfc2136GssTsigConfig(t *testing.T) {
 			RFC2136MinTTL:           3600,
 			RFC2136BatchChangeSize:  50,
 		},
+		{
+			LogFormat:               "json",
+			Sources:                 []string{"test-source"},
+			Provider:                "rfc2136",
+			RFC2136GSSTSIG:          true,
+			RFC2136KerberosRealm:    "test-realm",
+			RFC2136KerberosUsername: "test-user",
+			RFC2136KerberosKeytab:   "/etc/krb5.keytab",
+			RFC2136MinTTL:           3600,
+			RFC2136BatchChangeSize:  50,
+		},
 	}
 
 	for _, cfg := range validRfc2136GssTsigConfigs {
@@ -279,3 +436,128 @@ func TestValidateGoodRfc2136GssTsigConfig(t *testing.T) {
 		assert.Nil(t, err)
 	}
 }
+
+func TestValidateTXTOwnerTransferFrom(t *testing.T) {
+	cfg := newValidConfig(t)
+	cfg.Registry = "txt"
+	cfg.TXTOwnerID = "new-owner"
+	cfg.TXTOwnerTransferFrom = "old-owner"
+	assert.NoError(t, ValidateConfig(cfg))
+
+	cfg.TXTOwnerTransferFrom = "new-owner"
+	assert.Error(t, ValidateConfig(cfg))
+
+	cfg.TXTOwnerTransferFrom = "old-owner"
+	cfg.Registry = "noop"
+	assert.Error(t, ValidateConfig(cfg))
+}
+
+func TestValidateTXTEncryption(t *testing.T) {
+	cfg := newValidConfig(t)
+	cfg.Registry = "txt"
+	cfg.TXTEncryptAESKey = "0123456789abcdef0123456789abcdef"
+	cfg.TXTDecryptAESKeys = []string{"fedcba9876543210fedcba9876543210"}
+	assert.NoError(t, ValidateConfig(cfg))
+
+	cfg.Registry = "noop"
+	assert.Error(t, ValidateConfig(cfg))
+
+	cfg.Registry = "txt"
+	cfg.TXTEncryptAESKey = ""
+	assert.Error(t, ValidateConfig(cfg))
+}
+
+func TestValidateTXTFormatV4(t *testing.T) {
+	cfg := newValidConfig(t)
+	cfg.Registry = "txt"
+	cfg.TXTFormatV4 = true
+	assert.NoError(t, ValidateConfig(cfg))
+
+	cfg.Registry = "noop"
+	assert.Error(t, ValidateConfig(cfg))
+}
+
+func TestValidateProviderRateLimit(t *testing.T) {
+	cfg := newValidConfig(t)
+	cfg.ProviderQPS = 2.5
+	cfg.ProviderRetries = 3
+	assert.NoError(t, ValidateConfig(cfg))
+
+	cfg.ProviderQPS = -1
+	assert.Error(t, ValidateConfig(cfg))
+
+	cfg.ProviderQPS = 0
+	cfg.ProviderRetries = -1
+	assert.Error(t, ValidateConfig(cfg))
+}
+
+func TestValidateProviderRateLimitWithAWSSDRegistry(t *testing.T) {
+	for _, ti := range []struct {
+		title       string
+		provider    string
+		registry    string
+		qps         float64
+		retries     int
+		expectError bool
+	}{
+		{title: "aws-sd registry without rate limit", provider: "aws-sd", registry: "aws-sd"},
+		{title: "aws-sd registry with qps", provider: "aws-sd", registry: "aws-sd", qps: 2, expectError: true},
+		{title: "aws-sd registry with retries", provider: "aws-sd", registry: "aws-sd", retries: 3, expectError: true},
+		{title: "aws-sd provider switching to the aws-sd registry", provider: "aws-sd", registry: "txt", qps: 2, expectError: true},
+		{title: "aws-sd provider with the noop registry", provider: "aws-sd", registry: "noop", qps: 2, retries: 3},
+	} {
+		t.Run(ti.title, func(t *testing.T) {
+			cfg := newValidConfig(t)
+			cfg.Provider = ti.provider
+			cfg.Registry = ti.registry
+			cfg.ProviderQPS = ti.qps
+			cfg.ProviderRetries = ti.retries
+			if ti.expectError {
+				assert.Error(t, ValidateConfig(cfg))
+			} else {
+				assert.NoError(t, ValidateConfig(cfg))
+			}
+		})
+	}
+}
+
+func TestValidateProviderCacheTimeWithAWSSDRegistry(t *testing.T) {
+	cfg := newValidConfig(t)
+	cfg.ProviderCacheTime = time.Minute
+	assert.NoError(t, ValidateConfig(cfg))
+
+	cfg.Provider = "aws-sd"
+	cfg.Registry = "aws-sd"
+	assert.Error(t, ValidateConfig(cfg))
+
+	// switched to the aws-sd registry when building the provider
+	cfg.Registry = "txt"
+	assert.Error(t, ValidateConfig(cfg))
+
+	cfg.Registry = "noop"
+	assert.NoError(t, ValidateConfig(cfg))
+}
+
+func TestValidateMikroTikConfig(t *testing.T) {
+	cfg := newValidConfig(t)
+	cfg.Provider = "mikrotik"
+	cfg.MikroTikURL = "https://192.168.88.1/rest"
+	cfg.MikroTikUsername = "admin"
+	assert.NoError(t, ValidateConfig(cfg))
+
+	cfg.MikroTikUsername = ""
+	assert.Error(t, ValidateConfig(cfg))
+
+	cfg.MikroTikUsername = "admin"
+	cfg.MikroTikURL = ""
+	assert.Error(t, ValidateConfig(cfg))
+}
+
+func TestValidateResolverFileConfig(t *testing.T) {
+	cfg := newValidConfig(t)
+	cfg.Provider = "resolver-file"
+	assert.Error(t, ValidateConfig(cfg))
+
+	cfg.ResolverFilePath = "/etc/dnsmasq.d/external-dns.conf"
+	assert.NoError(t, ValidateConfig(cfg))
+}
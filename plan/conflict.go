@@ -18,6 +18,9 @@ package plan
 
 import (
 	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
 
 	"sigs.k8s.io/external-dns/endpoint"
 )
@@ -29,6 +32,15 @@ type ConflictResolver interface {
 	ResolveUpdate(current *endpoint.Endpoint, candidates []*endpoint.Endpoint) *endpoint.Endpoint
 }
 
+// ConflictResolvers is a registry of available conflict resolvers, prefer-source-priority
+// is built from its list of resource kinds with NewPreferSourcePriority instead.
+var ConflictResolvers = map[string]ConflictResolver{
+	"per-resource":        PerResource{},
+	"prefer-longer-ttl":   PreferLongerTTL{},
+	"prefer-alphabetical": PreferAlphabetical{},
+	"manual-deny":         ManualDeny{},
+}
+
 // PerResource allows only one resource to own a given dns name
 type PerResource struct{}
 
@@ -67,4 +79,142 @@ func (s PerResource) less(x, y *endpoint.Endpoint) bool {
 	return x.Targets.IsLess(y.Targets)
 }
 
+// PreferLongerTTL gives a dns name to the candidate with the longest TTL, candidates without
+// a configured TTL coming last. Ties are resolved like PerResource.
+type PreferLongerTTL struct{}
+
+// ResolveCreate takes the candidate with the longest TTL.
+func (s PreferLongerTTL) ResolveCreate(candidates []*endpoint.Endpoint) *endpoint.Endpoint {
+	return resolveBy(candidates, func(x, y *endpoint.Endpoint) int {
+		switch {
+		case x.RecordTTL > y.RecordTTL:
+			return -1
+		case x.RecordTTL < y.RecordTTL:
+			return 1
+		}
+		return 0
+	})
+}
+
+// ResolveUpdate takes the candidate with the longest TTL, regardless of the current record.
+func (s PreferLongerTTL) ResolveUpdate(current *endpoint.Endpoint, candidates []*endpoint.Endpoint) *endpoint.Endpoint {
+	return s.ResolveCreate(candidates)
+}
+
+// PreferAlphabetical gives a dns name to the candidate whose resource comes first in alphabetical
+// order, e.g. "ingress/default/a" before "service/default/a", candidates without resource coming
+// last. Ties are resolved like PerResource.
+type PreferAlphabetical struct{}
+
+// ResolveCreate takes the candidate whose resource comes first in alphabetical order.
+func (s PreferAlphabetical) ResolveCreate(candidates []*endpoint.Endpoint) *endpoint.Endpoint {
+	return resolveBy(candidates, func(x, y *endpoint.Endpoint) int {
+		xResource, yResource := x.Labels[endpoint.ResourceLabelKey], y.Labels[endpoint.ResourceLabelKey]
+		switch {
+		case xResource == yResource:
+			return 0
+		case yResource == "":
+			return -1
+		case xResource == "":
+			return 1
+		}
+		return strings.Compare(xResource, yResource)
+	})
+}
+
+// ResolveUpdate takes the candidate whose resource comes first in alphabetical order, regardless of the current record.
+func (s PreferAlphabetical) ResolveUpdate(current *endpoint.Endpoint, candidates []*endpoint.Endpoint) *endpoint.Endpoint {
+	return s.ResolveCreate(candidates)
+}
+
+// PreferSourcePriority gives a dns name to the candidate whose resource kind, the prefix of its
+// resource label, comes first in a list of kinds. The kinds not listed come last, ties are
+// resolved like PerResource.
+type PreferSourcePriority struct {
+	priorities map[string]int
+}
+
+// NewPreferSourcePriority returns a PreferSourcePriority resolver preferring the given resource
+// kinds, e.g. "ingress" and "service", in order.
+func NewPreferSourcePriority(kinds []string) PreferSourcePriority {
+	priorities := make(map[string]int, len(kinds))
+	for i, kind := range kinds {
+		if _, ok := priorities[strings.ToLower(kind)]; !ok {
+			priorities[strings.ToLower(kind)] = i
+		}
+	}
+	return PreferSourcePriority{priorities: priorities}
+}
+
+// ResolveCreate takes the candidate of the resource kind with the highest priority.
+func (s PreferSourcePriority) ResolveCreate(candidates []*endpoint.Endpoint) *endpoint.Endpoint {
+	return resolveBy(candidates, func(x, y *endpoint.Endpoint) int {
+		return s.priority(x) - s.priority(y)
+	})
+}
+
+// ResolveUpdate takes the candidate of the resource kind with the highest priority, regardless of the current record.
+func (s PreferSourcePriority) ResolveUpdate(current *endpoint.Endpoint, candidates []*endpoint.Endpoint) *endpoint.Endpoint {
+	return s.ResolveCreate(candidates)
+}
+
+func (s PreferSourcePriority) priority(ep *endpoint.Endpoint) int {
+	kind := strings.SplitN(ep.Labels[endpoint.ResourceLabelKey], "/", 2)[0]
+	if priority, ok := s.priorities[strings.ToLower(kind)]; ok {
+		return priority
+	}
+	return len(s.priorities)
+}
+
+// ManualDeny refuses to resolve conflicts: a dns name wanted by several resources is neither
+// created nor updated until the conflict is resolved by hand, it keeps its current record.
+// Candidates of a single resource are resolved like PerResource.
+type ManualDeny struct{}
+
+// ResolveCreate returns nil when the candidates come from several resources.
+func (s ManualDeny) ResolveCreate(candidates []*endpoint.Endpoint) *endpoint.Endpoint {
+	if s.conflicting(candidates) {
+		return nil
+	}
+	return PerResource{}.ResolveCreate(candidates)
+}
+
+// ResolveUpdate returns the current record when the candidates come from several resources.
+func (s ManualDeny) ResolveUpdate(current *endpoint.Endpoint, candidates []*endpoint.Endpoint) *endpoint.Endpoint {
+	if s.conflicting(candidates) {
+		return current
+	}
+	return PerResource{}.ResolveUpdate(current, candidates)
+}
+
+func (s ManualDeny) conflicting(candidates []*endpoint.Endpoint) bool {
+	for _, ep := range candidates[1:] {
+		if ep.Labels[endpoint.ResourceLabelKey] != candidates[0].Labels[endpoint.ResourceLabelKey] {
+			resources := make([]string, 0, len(candidates))
+			for _, c := range candidates {
+				resources = append(resources, c.Labels[endpoint.ResourceLabelKey])
+			}
+			log.Warnf("Not managing %s, it is wanted by several resources: %s", candidates[0].DNSName, strings.Join(resources, ", "))
+			return true
+		}
+	}
+	return false
+}
+
+// resolveBy returns the candidate which comes first according to compare, falling back on
+// the order of PerResource for the candidates which compare equal.
+func resolveBy(candidates []*endpoint.Endpoint, compare func(x, y *endpoint.Endpoint) int) *endpoint.Endpoint {
+	var min *endpoint.Endpoint
+	for _, ep := range candidates {
+		if min == nil {
+			min = ep
+			continue
+		}
+		if c := compare(ep, min); c < 0 || (c == 0 && (PerResource{}).less(ep, min)) {
+			min = ep
+		}
+	}
+	return min
+}
+
 // TODO: with cross-resource/cross-cluster setup alternative variations of ConflictResolver can be used
@@ -24,7 +24,13 @@ import (
 	"sigs.k8s.io/external-dns/endpoint"
 )
 
-var _ ConflictResolver = PerResource{}
+var (
+	_ ConflictResolver = PerResource{}
+	_ ConflictResolver = PreferLongerTTL{}
+	_ ConflictResolver = PreferAlphabetical{}
+	_ ConflictResolver = PreferSourcePriority{}
+	_ ConflictResolver = ManualDeny{}
+)
 
 type ResolverSuite struct {
 	// resolvers
@@ -133,6 +139,42 @@ func (suite *ResolverSuite) TestStrictResolver() {
 	suite.Equal(suite.bar127A, suite.perResource.ResolveUpdate(suite.legacyBar192A, []*endpoint.Endpoint{suite.bar127A, suite.bar192A}), " legacy record's resource value will not match, should pick minimum")
 }
 
+func (suite *ResolverSuite) TestPreferLongerTTLResolver() {
+	resolver := PreferLongerTTL{}
+	bar192ALongTTL := suite.bar192A.DeepCopy()
+	bar192ALongTTL.RecordTTL = 300
+
+	suite.Equal(bar192ALongTTL, resolver.ResolveCreate([]*endpoint.Endpoint{suite.bar127A, bar192ALongTTL}), "should pick the longest ttl")
+	suite.Equal(bar192ALongTTL, resolver.ResolveUpdate(suite.bar127A, []*endpoint.Endpoint{suite.bar127A, bar192ALongTTL}), "should pick the longest ttl over the current resource")
+	suite.Equal(suite.bar127A, resolver.ResolveCreate([]*endpoint.Endpoint{suite.bar192A, suite.bar127A}), "should pick min one on equal ttls")
+}
+
+func (suite *ResolverSuite) TestPreferAlphabeticalResolver() {
+	resolver := PreferAlphabetical{}
+	suite.Equal(suite.fooA5, resolver.ResolveCreate([]*endpoint.Endpoint{suite.fooV1Cname, suite.fooA5}), "should pick the first resource")
+	suite.Equal(suite.fooV2Cname, resolver.ResolveUpdate(suite.fooV2CnameDuplicate, []*endpoint.Endpoint{suite.fooV2CnameDuplicate, suite.fooV2Cname}), "should pick the first resource over the current resource")
+	suite.Equal(suite.bar127A, resolver.ResolveCreate([]*endpoint.Endpoint{suite.bar192A, suite.legacyBar192A, suite.bar127A}), "should pick the resource before the unlabelled one")
+}
+
+func (suite *ResolverSuite) TestPreferSourcePriorityResolver() {
+	serviceFoo := suite.fooV2Cname.DeepCopy()
+	serviceFoo.Labels[endpoint.ResourceLabelKey] = "service/default/foo"
+	crdFoo := suite.fooA5.DeepCopy()
+	crdFoo.Labels[endpoint.ResourceLabelKey] = "crd/default/foo"
+
+	resolver := NewPreferSourcePriority([]string{"Service", "ingress"})
+	suite.Equal(serviceFoo, resolver.ResolveCreate([]*endpoint.Endpoint{suite.fooV1Cname, serviceFoo, crdFoo}), "should pick the first kind")
+	suite.Equal(suite.fooV1Cname, resolver.ResolveUpdate(crdFoo, []*endpoint.Endpoint{crdFoo, suite.fooV1Cname}), "should pick a listed kind over the current resource")
+	suite.Equal(suite.fooA5, resolver.ResolveCreate([]*endpoint.Endpoint{suite.fooV1Cname, suite.fooA5}), "should pick min one for the same kind")
+}
+
+func (suite *ResolverSuite) TestManualDenyResolver() {
+	resolver := ManualDeny{}
+	suite.Nil(resolver.ResolveCreate([]*endpoint.Endpoint{suite.bar127A, suite.bar192A}), "should not create a conflicting name")
+	suite.Equal(suite.bar192A, resolver.ResolveUpdate(suite.bar192A, []*endpoint.Endpoint{suite.bar127A, suite.bar192A}), "should keep the current record of a conflicting name")
+	suite.Equal(suite.bar127AAnother, resolver.ResolveCreate([]*endpoint.Endpoint{suite.bar127A, suite.bar127AAnother}), "should pick min one for a single resource")
+}
+
 func TestConflictResolver(t *testing.T) {
 	suite.Run(t, new(ResolverSuite))
 }
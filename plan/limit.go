@@ -0,0 +1,67 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plan
+
+import (
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// ChangeLimitPolicy bounds the number of changes applied in a single synchronization, so that
+// a large diff is applied in batches over several synchronizations instead of all at once.
+// The changes beyond the limits are dropped, to be calculated again on the next synchronization.
+// The changes are first processed by the wrapped Policy. A limit of zero means no limit.
+type ChangeLimitPolicy struct {
+	Policy
+	MaxCreates int
+	MaxUpdates int
+	MaxDeletes int
+}
+
+// Apply applies the wrapped policy, then truncates each kind of changes to its limit.
+func (p *ChangeLimitPolicy) Apply(changes *Changes) *Changes {
+	changes = p.Policy.Apply(changes)
+
+	limited := &Changes{
+		Create:    limitChanges("creations", changes.Create, p.MaxCreates),
+		UpdateOld: limitChanges("updates", changes.UpdateOld, p.MaxUpdates),
+		Delete:    limitChanges("deletions", changes.Delete, p.MaxDeletes),
+	}
+	// UpdateNew is parallel to UpdateOld, the deferred updates are already logged.
+	limited.UpdateNew = changes.UpdateNew
+	if len(limited.UpdateNew) > len(limited.UpdateOld) {
+		limited.UpdateNew = limited.UpdateNew[:len(limited.UpdateOld)]
+	}
+	return limited
+}
+
+// ApplyDesired lets the wrapped policy rewrite the desired records, if it does.
+func (p *ChangeLimitPolicy) ApplyDesired(desired []*endpoint.Endpoint) []*endpoint.Endpoint {
+	if desiredPolicy, ok := p.Policy.(DesiredPolicy); ok {
+		return desiredPolicy.ApplyDesired(desired)
+	}
+	return desired
+}
+
+func limitChanges(kind string, changes []*endpoint.Endpoint, limit int) []*endpoint.Endpoint {
+	if limit <= 0 || len(changes) <= limit {
+		return changes
+	}
+	log.Infof("Deferring %d of %d %s to the next synchronizations, at most %d are applied at once", len(changes)-limit, len(changes), kind, limit)
+	return changes[:limit]
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plan
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+func TestChangeLimitPolicy(t *testing.T) {
+	records := func(names ...string) []*endpoint.Endpoint {
+		eps := []*endpoint.Endpoint{}
+		for _, name := range names {
+			eps = append(eps, &endpoint.Endpoint{DNSName: name, Targets: endpoint.Targets{"v1"}})
+		}
+		return eps
+	}
+	changes := &Changes{
+		Create:    records("a", "b", "c"),
+		UpdateOld: records("d", "e", "f"),
+		UpdateNew: records("d", "e", "f"),
+		Delete:    records("g", "h", "i"),
+	}
+
+	for _, tc := range []struct {
+		title    string
+		policy   *ChangeLimitPolicy
+		expected *Changes
+	}{
+		{
+			title:    "no limits",
+			policy:   &ChangeLimitPolicy{Policy: &SyncPolicy{}},
+			expected: changes,
+		},
+		{
+			title:  "limits",
+			policy: &ChangeLimitPolicy{Policy: &SyncPolicy{}, MaxCreates: 2, MaxUpdates: 1, MaxDeletes: 5},
+			expected: &Changes{
+				Create:    records("a", "b"),
+				UpdateOld: records("d"),
+				UpdateNew: records("d"),
+				Delete:    records("g", "h", "i"),
+			},
+		},
+		{
+			title:  "limits after the wrapped policy",
+			policy: &ChangeLimitPolicy{Policy: &UpsertOnlyPolicy{}, MaxCreates: 1, MaxDeletes: 1},
+			expected: &Changes{
+				Create:    records("a"),
+				UpdateOld: records("d", "e", "f"),
+				UpdateNew: records("d", "e", "f"),
+				Delete:    records(),
+			},
+		},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			limited := tc.policy.Apply(changes)
+
+			validateEntries(t, limited.Create, tc.expected.Create)
+			validateEntries(t, limited.UpdateOld, tc.expected.UpdateOld)
+			validateEntries(t, limited.UpdateNew, tc.expected.UpdateNew)
+			validateEntries(t, limited.Delete, tc.expected.Delete)
+		})
+	}
+}
+
+func TestChangeLimitPolicyApplyDesired(t *testing.T) {
+	desired := []*endpoint.Endpoint{{DNSName: "foo", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"192.0.2.1", "192.0.2.2"}}}
+
+	assert.Equal(t, desired, (&ChangeLimitPolicy{Policy: &SyncPolicy{}}).ApplyDesired(desired))
+
+	weighted := &ChangeLimitPolicy{Policy: &WeightedPolicy{Policy: &SyncPolicy{}, WeightsProperty: testWeightsProperty, WeightProperty: "aws/weight"}}
+	assert.Len(t, weighted.ApplyDesired(desired), 2)
+}
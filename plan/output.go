@@ -0,0 +1,150 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plan
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v2"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+const (
+	// OutputFormatJSON writes the changes as a JSON document
+	OutputFormatJSON = "json"
+	// OutputFormatYAML writes the changes as a YAML document
+	OutputFormatYAML = "yaml"
+	// OutputFormatTable writes the changes as a human readable table
+	OutputFormatTable = "table"
+)
+
+// changesOutput is the machine readable form of Changes.
+type changesOutput struct {
+	Create []changeRecord `json:"create" yaml:"create"`
+	Update []changeUpdate `json:"update" yaml:"update"`
+	Delete []changeRecord `json:"delete" yaml:"delete"`
+}
+
+type changeUpdate struct {
+	Old changeRecord `json:"old" yaml:"old"`
+	New changeRecord `json:"new" yaml:"new"`
+}
+
+type changeRecord struct {
+	DNSName          string            `json:"dnsName" yaml:"dnsName"`
+	RecordType       string            `json:"recordType" yaml:"recordType"`
+	SetIdentifier    string            `json:"setIdentifier,omitempty" yaml:"setIdentifier,omitempty"`
+	TTL              int64             `json:"ttl,omitempty" yaml:"ttl,omitempty"`
+	Targets          []string          `json:"targets" yaml:"targets"`
+	ProviderSpecific map[string]string `json:"providerSpecific,omitempty" yaml:"providerSpecific,omitempty"`
+}
+
+func newChangeRecord(ep *endpoint.Endpoint) changeRecord {
+	record := changeRecord{
+		DNSName:       ep.DNSName,
+		RecordType:    ep.RecordType,
+		SetIdentifier: ep.SetIdentifier,
+		TTL:           int64(ep.RecordTTL),
+		Targets:       append([]string{}, ep.Targets...),
+	}
+	if len(ep.ProviderSpecific) > 0 {
+		record.ProviderSpecific = map[string]string{}
+		for _, property := range ep.ProviderSpecific {
+			record.ProviderSpecific[property.Name] = property.Value
+		}
+	}
+	return record
+}
+
+func newChangeRecords(eps []*endpoint.Endpoint) []changeRecord {
+	records := make([]changeRecord, 0, len(eps))
+	for _, ep := range eps {
+		records = append(records, newChangeRecord(ep))
+	}
+	sort.SliceStable(records, func(i, j int) bool { return records[i].less(records[j]) })
+	return records
+}
+
+func (r changeRecord) less(o changeRecord) bool {
+	if r.DNSName != o.DNSName {
+		return r.DNSName < o.DNSName
+	}
+	if r.RecordType != o.RecordType {
+		return r.RecordType < o.RecordType
+	}
+	return r.SetIdentifier < o.SetIdentifier
+}
+
+// Write writes the changes in the given format, sorted by name, record type and set identifier,
+// so that the output of a plan can be reviewed before it is applied.
+func (changes *Changes) Write(w io.Writer, format string) error {
+	output := changesOutput{
+		Create: newChangeRecords(changes.Create),
+		Update: make([]changeUpdate, 0, len(changes.UpdateNew)),
+		Delete: newChangeRecords(changes.Delete),
+	}
+	for i := range changes.UpdateNew {
+		update := changeUpdate{New: newChangeRecord(changes.UpdateNew[i])}
+		if i < len(changes.UpdateOld) {
+			update.Old = newChangeRecord(changes.UpdateOld[i])
+		}
+		output.Update = append(output.Update, update)
+	}
+	sort.SliceStable(output.Update, func(i, j int) bool { return output.Update[i].New.less(output.Update[j].New) })
+
+	switch format {
+	case OutputFormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(output)
+	case OutputFormatYAML:
+		data, err := yaml.Marshal(output)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(append([]byte("---\n"), data...))
+		return err
+	case OutputFormatTable:
+		return writeChangesTable(w, output)
+	default:
+		return fmt.Errorf("unknown changes output format %q", format)
+	}
+}
+
+func writeChangesTable(w io.Writer, output changesOutput) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "ACTION\tNAME\tTYPE\tSET IDENTIFIER\tTTL\tTARGETS")
+	row := func(action string, r changeRecord, targets string) {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%d\t%s\n", action, r.DNSName, r.RecordType, r.SetIdentifier, r.TTL, targets)
+	}
+	for _, r := range output.Create {
+		row("create", r, strings.Join(r.Targets, ","))
+	}
+	for _, u := range output.Update {
+		row("update", u.New, strings.Join(u.Old.Targets, ",")+" -> "+strings.Join(u.New.Targets, ","))
+	}
+	for _, r := range output.Delete {
+		row("delete", r, strings.Join(r.Targets, ","))
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,168 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plan
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+func TestChangesWrite(t *testing.T) {
+	changes := &Changes{
+		Create: []*endpoint.Endpoint{
+			{DNSName: "foo.example.org", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"192.0.2.1"}, RecordTTL: 300},
+			{DNSName: "bar.example.org", RecordType: endpoint.RecordTypeCNAME, Targets: endpoint.Targets{"lb.example.org"}, ProviderSpecific: endpoint.ProviderSpecific{{Name: "alias", Value: "true"}}},
+		},
+		UpdateOld: []*endpoint.Endpoint{
+			{DNSName: "baz.example.org", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"192.0.2.2"}, SetIdentifier: "eu"},
+		},
+		UpdateNew: []*endpoint.Endpoint{
+			{DNSName: "baz.example.org", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"192.0.2.3", "192.0.2.4"}, SetIdentifier: "eu"},
+		},
+		Delete: []*endpoint.Endpoint{
+			{DNSName: "qux.example.org", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"192.0.2.5"}},
+		},
+	}
+
+	for _, tc := range []struct {
+		format   string
+		expected string
+	}{
+		{
+			format: OutputFormatJSON,
+			expected: `{
+  "create": [
+    {
+      "dnsName": "bar.example.org",
+      "recordType": "CNAME",
+      "targets": [
+        "lb.example.org"
+      ],
+      "providerSpecific": {
+        "alias": "true"
+      }
+    },
+    {
+      "dnsName": "foo.example.org",
+      "recordType": "A",
+      "ttl": 300,
+      "targets": [
+        "192.0.2.1"
+      ]
+    }
+  ],
+  "update": [
+    {
+      "old": {
+        "dnsName": "baz.example.org",
+        "recordType": "A",
+        "setIdentifier": "eu",
+        "targets": [
+          "192.0.2.2"
+        ]
+      },
+      "new": {
+        "dnsName": "baz.example.org",
+        "recordType": "A",
+        "setIdentifier": "eu",
+        "targets": [
+          "192.0.2.3",
+          "192.0.2.4"
+        ]
+      }
+    }
+  ],
+  "delete": [
+    {
+      "dnsName": "qux.example.org",
+      "recordType": "A",
+      "targets": [
+        "192.0.2.5"
+      ]
+    }
+  ]
+}
+`,
+		},
+		{
+			format: OutputFormatYAML,
+			expected: `---
+create:
+- dnsName: bar.example.org
+  recordType: CNAME
+  targets:
+  - lb.example.org
+  providerSpecific:
+    alias: "true"
+- dnsName: foo.example.org
+  recordType: A
+  ttl: 300
+  targets:
+  - 192.0.2.1
+update:
+- old:
+    dnsName: baz.example.org
+    recordType: A
+    setIdentifier: eu
+    targets:
+    - 192.0.2.2
+  new:
+    dnsName: baz.example.org
+    recordType: A
+    setIdentifier: eu
+    targets:
+    - 192.0.2.3
+    - 192.0.2.4
+delete:
+- dnsName: qux.example.org
+  recordType: A
+  targets:
+  - 192.0.2.5
+`,
+		},
+		{
+			format: OutputFormatTable,
+			expected: `ACTION  NAME             TYPE   SET IDENTIFIER  TTL  TARGETS
+create  bar.example.org  CNAME                  0    lb.example.org
+create  foo.example.org  A                      300  192.0.2.1
+update  baz.example.org  A      eu              0    192.0.2.2 -> 192.0.2.3,192.0.2.4
+delete  qux.example.org  A                      0    192.0.2.5
+`,
+		},
+	} {
+		t.Run(tc.format, func(t *testing.T) {
+			var out bytes.Buffer
+			require.NoError(t, changes.Write(&out, tc.format))
+			assert.Equal(t, tc.expected, out.String())
+		})
+	}
+
+	t.Run("no changes", func(t *testing.T) {
+		var out bytes.Buffer
+		require.NoError(t, (&Changes{}).Write(&out, OutputFormatJSON))
+		assert.JSONEq(t, `{"create": [], "update": [], "delete": []}`, out.String())
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		assert.Error(t, (&Changes{}).Write(&bytes.Buffer{}, "xml"))
+	})
+}
@@ -19,7 +19,6 @@ package plan
 import (
 	"fmt"
 	"strconv"
-	"strings"
 
 	"github.com/google/go-cmp/cmp"
 	log "github.com/sirupsen/logrus"
@@ -50,6 +49,12 @@ type Plan struct {
 	PropertyComparator PropertyComparator
 	// DNS record types that will be considered for management
 	ManagedRecords []string
+	// ConflictResolver decides which desired record gets a dns name wanted by several resources,
+	// PerResource if not set
+	ConflictResolver ConflictResolver
+	// DeletionGracePeriod is the number of consecutive synchronizations a record must be missing
+	// from the desired records before being deleted, the record is deleted right away if lower than 2
+	DeletionGracePeriod int
 }
 
 // Changes holds lists of actions to be executed by dns providers
@@ -82,8 +87,11 @@ type planTable struct {
 	resolver ConflictResolver
 }
 
-func newPlanTable() planTable { //TODO: make resolver configurable
-	return planTable{map[string]map[string]*planTableRow{}, PerResource{}}
+func newPlanTable(resolver ConflictResolver) planTable {
+	if resolver == nil {
+		resolver = PerResource{}
+	}
+	return planTable{map[string]map[string]*planTableRow{}, resolver}
 }
 
 // planTableRow
@@ -131,7 +139,7 @@ func (c *Changes) HasChanges() bool {
 // state. It then passes those changes to the current policy for further
 // processing. It returns a copy of Plan with the changes populated.
 func (p *Plan) Calculate() *Plan {
-	t := newPlanTable()
+	t := newPlanTable(p.ConflictResolver)
 
 	if p.DomainFilter == nil {
 		p.DomainFilter = endpoint.MatchAllDomainFilters(nil)
@@ -140,7 +148,13 @@ func (p *Plan) Calculate() *Plan {
 	for _, current := range filterRecordsForPlan(p.Current, p.DomainFilter, p.ManagedRecords) {
 		t.addCurrent(current)
 	}
-	for _, desired := range filterRecordsForPlan(p.Desired, p.DomainFilter, p.ManagedRecords) {
+	desired := toASCIIRecords(p.Desired)
+	for _, pol := range p.Policies {
+		if desiredPolicy, ok := pol.(DesiredPolicy); ok {
+			desired = desiredPolicy.ApplyDesired(desired)
+		}
+	}
+	for _, desired := range validateDesiredRecords(filterRecordsForPlan(desired, p.DomainFilter, p.ManagedRecords)) {
 		t.addCandidate(desired)
 	}
 
@@ -149,17 +163,29 @@ func (p *Plan) Calculate() *Plan {
 	for _, topRow := range t.rows {
 		for _, row := range topRow {
 			if row.current == nil { //dns name not taken
-				changes.Create = append(changes.Create, t.resolver.ResolveCreate(row.candidates))
+				if create := t.resolver.ResolveCreate(row.candidates); create != nil {
+					changes.Create = append(changes.Create, create)
+				}
 			}
 			if row.current != nil && len(row.candidates) == 0 {
-				changes.Delete = append(changes.Delete, row.current)
+				if kept := p.keepMissing(row.current); kept != nil {
+					changes.UpdateNew = append(changes.UpdateNew, kept)
+					changes.UpdateOld = append(changes.UpdateOld, row.current)
+				} else {
+					changes.Delete = append(changes.Delete, row.current)
+				}
 			}
 
 			// TODO: allows record type change, which might not be supported by all dns providers
 			if row.current != nil && len(row.candidates) > 0 { //dns name is taken
 				update := t.resolver.ResolveUpdate(row.current, row.candidates)
 				// compare "update" to "current" to figure out if actual update is required
-				if shouldUpdateTTL(update, row.current) || targetChanged(update, row.current) || p.shouldUpdateProviderSpecific(update, row.current) {
+				// a record missing from the desired records during previous synchronizations is updated
+				// to reset its count of missing synchronizations, and a record with outdated ownership
+				// information or pinned to another owner is updated for the registry to store it again
+				_, wasMissing := row.current.Labels[endpoint.MissingSyncsLabelKey]
+				_, outdated := row.current.Labels[endpoint.OutdatedOwnershipLabelKey]
+				if shouldUpdateTTL(update, row.current) || targetChanged(update, row.current) || p.shouldUpdateProviderSpecific(update, row.current) || ((wasMissing || outdated) && update != row.current) || ownerOverridden(update, row.current) {
 					inheritOwner(row.current, update)
 					changes.UpdateNew = append(changes.UpdateNew, update)
 					changes.UpdateOld = append(changes.UpdateOld, row.current)
@@ -187,6 +213,31 @@ func (p *Plan) Calculate() *Plan {
 	return plan
 }
 
+// keepMissing returns the record missing from the desired records with its count of missing
+// synchronizations incremented, or nil if the record is to be deleted.
+func (p *Plan) keepMissing(current *endpoint.Endpoint) *endpoint.Endpoint {
+	if p.DeletionGracePeriod < 2 {
+		return nil
+	}
+
+	missing, err := strconv.Atoi(current.Labels[endpoint.MissingSyncsLabelKey])
+	if err != nil {
+		missing = 0
+	}
+	missing++
+	if missing >= p.DeletionGracePeriod {
+		return nil
+	}
+
+	log.Infof("Record %s is missing from the desired records, deleting it after %d more synchronizations", current.DNSName, p.DeletionGracePeriod-missing)
+	kept := current.DeepCopy()
+	if kept.Labels == nil {
+		kept.Labels = endpoint.NewLabels()
+	}
+	kept.Labels[endpoint.MissingSyncsLabelKey] = strconv.Itoa(missing)
+	return kept
+}
+
 func inheritOwner(from, to *endpoint.Endpoint) {
 	if to.Labels == nil {
 		to.Labels = map[string]string{}
@@ -197,8 +248,107 @@ func inheritOwner(from, to *endpoint.Endpoint) {
 	to.Labels[endpoint.OwnerLabelKey] = from.Labels[endpoint.OwnerLabelKey]
 }
 
+// ownerOverridden returns whether the desired record pins an owner other than the current one.
+func ownerOverridden(desired, current *endpoint.Endpoint) bool {
+	owner, ok := desired.Labels[endpoint.OwnerOverrideLabelKey]
+	return ok && owner != current.Labels[endpoint.OwnerLabelKey]
+}
+
 func targetChanged(desired, current *endpoint.Endpoint) bool {
-	return !desired.Targets.Same(current.Targets)
+	return !canonicalTargets(desired).Same(canonicalTargets(current))
+}
+
+// canonicalTargetParsers parse the targets of the record types whose data can be written in
+// several ways into a canonical form.
+var canonicalTargetParsers = map[string]func(string) (string, error){
+	endpoint.RecordTypeHTTPS: endpoint.ParseSVCBTarget,
+	endpoint.RecordTypeSVCB:  endpoint.ParseSVCBTarget,
+	endpoint.RecordTypeTLSA:  endpoint.ParseTLSATarget,
+	endpoint.RecordTypeMX:    endpoint.ParseMXTarget,
+	endpoint.RecordTypeNAPTR: endpoint.ParseNAPTRTarget,
+	endpoint.RecordTypeCNAME: asciiName,
+	endpoint.RecordTypeNS:    asciiName,
+	endpoint.RecordTypePTR:   asciiName,
+}
+
+// asciiName canonicalizes the hostname targets, which are equal in punycode and unicode form.
+func asciiName(target string) (string, error) {
+	return endpoint.ToASCIIName(target), nil
+}
+
+// canonicalTargets returns the targets of an endpoint in a form where semantically
+// equal targets are equal, for the record types whose data can be written in several ways.
+func canonicalTargets(ep *endpoint.Endpoint) endpoint.Targets {
+	parse, ok := canonicalTargetParsers[ep.RecordType]
+	if !ok {
+		return ep.Targets
+	}
+	targets := make(endpoint.Targets, 0, len(ep.Targets))
+	for _, target := range ep.Targets {
+		if canonical, err := parse(target); err == nil {
+			target = canonical
+		}
+		targets = append(targets, target)
+	}
+	return targets
+}
+
+// toASCIIRecords returns records whose internationalized names and hostname targets are converted to
+// punycode, the form DNS providers store and return them in. Records are copied before being modified.
+func toASCIIRecords(records []*endpoint.Endpoint) []*endpoint.Endpoint {
+	converted := make([]*endpoint.Endpoint, 0, len(records))
+	for _, record := range records {
+		dnsName := endpoint.ToASCIIName(record.DNSName)
+		targets := record.Targets
+		switch record.RecordType {
+		case endpoint.RecordTypeCNAME, endpoint.RecordTypeNS, endpoint.RecordTypePTR:
+			targets = canonicalTargets(record)
+		}
+		if dnsName == record.DNSName && cmp.Equal(targets, record.Targets) {
+			converted = append(converted, record)
+			continue
+		}
+
+		record = record.DeepCopy()
+		record.DNSName = dnsName
+		record.Targets = targets
+		converted = append(converted, record)
+	}
+	return converted
+}
+
+// validateDesiredRecords drops the targets of the desired records which cannot be parsed for
+// their record type, so that they are not passed to the provider. Records left without any
+// valid target are dropped altogether.
+func validateDesiredRecords(records []*endpoint.Endpoint) []*endpoint.Endpoint {
+	validated := make([]*endpoint.Endpoint, 0, len(records))
+	for _, record := range records {
+		parse, ok := canonicalTargetParsers[record.RecordType]
+		if !ok {
+			validated = append(validated, record)
+			continue
+		}
+
+		targets := make(endpoint.Targets, 0, len(record.Targets))
+		for _, target := range record.Targets {
+			if _, err := parse(target); err != nil {
+				log.Warnf("Ignoring invalid target of desired record %s: %v", record.DNSName, err)
+				continue
+			}
+			targets = append(targets, target)
+		}
+		switch {
+		case len(targets) == 0:
+			log.Warnf("Ignoring desired %s record %s without any valid target", record.RecordType, record.DNSName)
+		case len(targets) == len(record.Targets):
+			validated = append(validated, record)
+		default:
+			record = record.DeepCopy()
+			record.Targets = targets
+			validated = append(validated, record)
+		}
+	}
+	return validated
 }
 
 func shouldUpdateTTL(desired, current *endpoint.Endpoint) bool {
@@ -267,12 +417,9 @@ func filterRecordsForPlan(records []*endpoint.Endpoint, domainFilter endpoint.Do
 
 // normalizeDNSName converts a DNS name to a canonical form, so that we can use string equality
 // it: removes space, converts to lower case, ensures there is a trailing dot
+// and converts internationalized labels to punycode
 func normalizeDNSName(dnsName string) string {
-	s := strings.TrimSpace(strings.ToLower(dnsName))
-	if !strings.HasSuffix(s, ".") {
-		s += "."
-	}
-	return s
+	return endpoint.NormalizeDNSName(dnsName) + "."
 }
 
 // CompareBoolean is an implementation of PropertyComparator for comparing boolean-line values
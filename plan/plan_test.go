@@ -17,6 +17,7 @@ limitations under the License.
 package plan
 
 import (
+	"strconv"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -414,6 +415,214 @@ func (suite *PlanTestSuite) TestSyncSecondRoundWithOwnerInherited() {
 	validateEntries(suite.T(), changes.Delete, expectedDelete)
 }
 
+func (suite *PlanTestSuite) TestSyncSecondRoundWithEquivalentSVCBTargets() {
+	current := []*endpoint.Endpoint{
+		{DNSName: "foo", RecordType: endpoint.RecordTypeHTTPS, Targets: endpoint.Targets{`1 svc.example.org. port="443" alpn="h2,h3"`}},
+	}
+	desired := []*endpoint.Endpoint{
+		{DNSName: "foo", RecordType: endpoint.RecordTypeHTTPS, Targets: endpoint.Targets{"1 svc.example.org alpn=h2,h3 port=443"}},
+	}
+
+	p := &Plan{
+		Policies:       []Policy{&SyncPolicy{}},
+		Current:        current,
+		Desired:        desired,
+		ManagedRecords: []string{endpoint.RecordTypeHTTPS},
+	}
+
+	changes := p.Calculate().Changes
+	validateEntries(suite.T(), changes.Create, []*endpoint.Endpoint{})
+	validateEntries(suite.T(), changes.UpdateNew, []*endpoint.Endpoint{})
+	validateEntries(suite.T(), changes.UpdateOld, []*endpoint.Endpoint{})
+	validateEntries(suite.T(), changes.Delete, []*endpoint.Endpoint{})
+
+	desired[0].Targets = endpoint.Targets{"1 svc.example.org alpn=h2 port=443"}
+	changes = p.Calculate().Changes
+	validateEntries(suite.T(), changes.UpdateNew, desired)
+}
+
+func (suite *PlanTestSuite) TestSyncSecondRoundWithEquivalentTLSATargets() {
+	current := []*endpoint.Endpoint{
+		{DNSName: "_443._tcp.foo", RecordType: endpoint.RecordTypeTLSA, Targets: endpoint.Targets{"3 1 1 0d6fce3368bf9e6e78bc3f0153b31e676e3d91e1b1bc5a3d75d5e3bc7b0c1f22"}},
+	}
+	desired := []*endpoint.Endpoint{
+		{DNSName: "_443._tcp.foo", RecordType: endpoint.RecordTypeTLSA, Targets: endpoint.Targets{"3 1 1 0D6FCE3368BF9E6E78BC3F0153B31E67 6E3D91E1B1BC5A3D75D5E3BC7B0C1F22"}},
+	}
+
+	p := &Plan{
+		Policies:       []Policy{&SyncPolicy{}},
+		Current:        current,
+		Desired:        desired,
+		ManagedRecords: []string{endpoint.RecordTypeTLSA},
+	}
+
+	changes := p.Calculate().Changes
+	validateEntries(suite.T(), changes.Create, []*endpoint.Endpoint{})
+	validateEntries(suite.T(), changes.UpdateNew, []*endpoint.Endpoint{})
+	validateEntries(suite.T(), changes.UpdateOld, []*endpoint.Endpoint{})
+	validateEntries(suite.T(), changes.Delete, []*endpoint.Endpoint{})
+}
+
+func (suite *PlanTestSuite) TestSyncInvalidMXTargets() {
+	current := []*endpoint.Endpoint{
+		{DNSName: "foo", RecordType: endpoint.RecordTypeMX, Targets: endpoint.Targets{"10 mx1.example.org"}},
+	}
+	desired := []*endpoint.Endpoint{
+		{DNSName: "foo", RecordType: endpoint.RecordTypeMX, Targets: endpoint.Targets{"10 MX1.example.org.", "mx2.example.org"}},
+		{DNSName: "bar", RecordType: endpoint.RecordTypeMX, Targets: endpoint.Targets{"65536 mx1.example.org"}},
+		{DNSName: "baz", RecordType: endpoint.RecordTypeMX, Targets: endpoint.Targets{"10 mx1.example.org", "20 mx2.example.org"}},
+	}
+	expectedCreate := []*endpoint.Endpoint{desired[2]}
+
+	p := &Plan{
+		Policies:       []Policy{&SyncPolicy{}},
+		Current:        current,
+		Desired:        desired,
+		ManagedRecords: []string{endpoint.RecordTypeMX},
+	}
+
+	changes := p.Calculate().Changes
+	validateEntries(suite.T(), changes.Create, expectedCreate)
+	validateEntries(suite.T(), changes.UpdateNew, []*endpoint.Endpoint{})
+	validateEntries(suite.T(), changes.UpdateOld, []*endpoint.Endpoint{})
+	validateEntries(suite.T(), changes.Delete, []*endpoint.Endpoint{})
+	suite.Equal(endpoint.Targets{"10 MX1.example.org.", "mx2.example.org"}, desired[0].Targets, "desired records must not be modified")
+}
+
+func (suite *PlanTestSuite) TestSyncInternationalizedNames() {
+	current := []*endpoint.Endpoint{
+		{DNSName: "xn--bcher-kva.example.org", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"192.0.2.1"}},
+		{DNSName: "www.xn--bcher-kva.example.org", RecordType: endpoint.RecordTypeCNAME, Targets: endpoint.Targets{"xn--bcher-kva.example.org"}},
+	}
+	desired := []*endpoint.Endpoint{
+		{DNSName: "bücher.example.org", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"192.0.2.1"}},
+		{DNSName: "www.bücher.example.org", RecordType: endpoint.RecordTypeCNAME, Targets: endpoint.Targets{"bücher.example.org"}},
+		{DNSName: "shop.bücher.example.org", RecordType: endpoint.RecordTypeCNAME, Targets: endpoint.Targets{"bücher.example.org"}},
+	}
+	expectedCreate := []*endpoint.Endpoint{
+		{DNSName: "shop.xn--bcher-kva.example.org", RecordType: endpoint.RecordTypeCNAME, Targets: endpoint.Targets{"xn--bcher-kva.example.org"}},
+	}
+
+	p := &Plan{
+		Policies:       []Policy{&SyncPolicy{}},
+		Current:        current,
+		Desired:        desired,
+		ManagedRecords: []string{endpoint.RecordTypeA, endpoint.RecordTypeCNAME},
+	}
+
+	changes := p.Calculate().Changes
+	validateEntries(suite.T(), changes.Create, expectedCreate)
+	validateEntries(suite.T(), changes.UpdateNew, []*endpoint.Endpoint{})
+	validateEntries(suite.T(), changes.UpdateOld, []*endpoint.Endpoint{})
+	validateEntries(suite.T(), changes.Delete, []*endpoint.Endpoint{})
+	suite.Equal("shop.bücher.example.org", desired[2].DNSName, "desired records must not be modified")
+}
+
+func (suite *PlanTestSuite) TestManualDenyConflictResolver() {
+	current := []*endpoint.Endpoint{suite.bar127AWithTTL}
+	desired := []*endpoint.Endpoint{suite.fooV1Cname, suite.fooV2Cname, suite.bar127A, suite.bar192A}
+
+	p := &Plan{
+		Policies:         []Policy{&SyncPolicy{}},
+		Current:          current,
+		Desired:          desired,
+		ManagedRecords:   []string{endpoint.RecordTypeA, endpoint.RecordTypeCNAME},
+		ConflictResolver: ManualDeny{},
+	}
+
+	changes := p.Calculate().Changes
+	validateEntries(suite.T(), changes.Create, []*endpoint.Endpoint{})
+	validateEntries(suite.T(), changes.UpdateNew, []*endpoint.Endpoint{})
+	validateEntries(suite.T(), changes.UpdateOld, []*endpoint.Endpoint{})
+	validateEntries(suite.T(), changes.Delete, []*endpoint.Endpoint{})
+}
+
+func (suite *PlanTestSuite) TestDeletionGracePeriod() {
+	current := suite.fooV1Cname.DeepCopy()
+	current.Labels[endpoint.OwnerLabelKey] = "pwner"
+
+	p := &Plan{
+		Policies:            []Policy{&SyncPolicy{}},
+		Current:             []*endpoint.Endpoint{current},
+		Desired:             []*endpoint.Endpoint{},
+		ManagedRecords:      []string{endpoint.RecordTypeA, endpoint.RecordTypeCNAME},
+		DeletionGracePeriod: 3,
+	}
+
+	// The record is kept with its count of missing synchronizations during the grace period.
+	for missing := 1; missing < 3; missing++ {
+		changes := p.Calculate().Changes
+		kept := current.DeepCopy()
+		kept.Labels[endpoint.MissingSyncsLabelKey] = strconv.Itoa(missing)
+		validateEntries(suite.T(), changes.Delete, []*endpoint.Endpoint{})
+		validateEntries(suite.T(), changes.UpdateOld, []*endpoint.Endpoint{current})
+		validateEntries(suite.T(), changes.UpdateNew, []*endpoint.Endpoint{kept})
+		suite.Equal(strconv.Itoa(missing), changes.UpdateNew[0].Labels[endpoint.MissingSyncsLabelKey])
+		p.Current = changes.UpdateNew
+		current = changes.UpdateNew[0]
+	}
+
+	// The record is deleted once missing for the whole grace period.
+	changes := p.Calculate().Changes
+	validateEntries(suite.T(), changes.Delete, []*endpoint.Endpoint{current})
+	validateEntries(suite.T(), changes.UpdateNew, []*endpoint.Endpoint{})
+
+	// A record back in the desired records is updated to reset its count.
+	p.Desired = []*endpoint.Endpoint{suite.fooV1Cname}
+	changes = p.Calculate().Changes
+	validateEntries(suite.T(), changes.Delete, []*endpoint.Endpoint{})
+	validateEntries(suite.T(), changes.UpdateOld, []*endpoint.Endpoint{current})
+	suite.Require().Len(changes.UpdateNew, 1)
+	suite.NotContains(changes.UpdateNew[0].Labels, endpoint.MissingSyncsLabelKey)
+	suite.Equal("pwner", changes.UpdateNew[0].Labels[endpoint.OwnerLabelKey])
+}
+
+func (suite *PlanTestSuite) TestSyncOutdatedOwnership() {
+	current := suite.fooV1Cname.DeepCopy()
+	current.Labels[endpoint.OwnerLabelKey] = "pwner"
+	current.Labels[endpoint.OutdatedOwnershipLabelKey] = "true"
+
+	p := &Plan{
+		Policies:       []Policy{&SyncPolicy{}},
+		Current:        []*endpoint.Endpoint{current},
+		Desired:        []*endpoint.Endpoint{suite.fooV1Cname},
+		ManagedRecords: []string{endpoint.RecordTypeA, endpoint.RecordTypeCNAME},
+	}
+
+	changes := p.Calculate().Changes
+	validateEntries(suite.T(), changes.Create, []*endpoint.Endpoint{})
+	validateEntries(suite.T(), changes.Delete, []*endpoint.Endpoint{})
+	validateEntries(suite.T(), changes.UpdateOld, []*endpoint.Endpoint{current})
+	suite.Require().Len(changes.UpdateNew, 1)
+	suite.NotContains(changes.UpdateNew[0].Labels, endpoint.OutdatedOwnershipLabelKey)
+	suite.Equal("pwner", changes.UpdateNew[0].Labels[endpoint.OwnerLabelKey])
+}
+
+func (suite *PlanTestSuite) TestSyncOwnerOverride() {
+	current := suite.fooV1Cname.DeepCopy()
+	current.Labels[endpoint.OwnerLabelKey] = "pwner"
+	desired := suite.fooV1Cname.DeepCopy()
+	desired.Labels[endpoint.OwnerOverrideLabelKey] = "new-owner"
+
+	p := &Plan{
+		Policies:       []Policy{&SyncPolicy{}},
+		Current:        []*endpoint.Endpoint{current},
+		Desired:        []*endpoint.Endpoint{desired},
+		ManagedRecords: []string{endpoint.RecordTypeA, endpoint.RecordTypeCNAME},
+	}
+
+	changes := p.Calculate().Changes
+	validateEntries(suite.T(), changes.UpdateOld, []*endpoint.Endpoint{current})
+	suite.Require().Len(changes.UpdateNew, 1)
+	suite.Equal("new-owner", changes.UpdateNew[0].Labels[endpoint.OwnerOverrideLabelKey])
+
+	// The record is left alone once registered with the pinned owner.
+	current.Labels[endpoint.OwnerLabelKey] = "new-owner"
+	changes = p.Calculate().Changes
+	validateEntries(suite.T(), changes.UpdateOld, []*endpoint.Endpoint{})
+	validateEntries(suite.T(), changes.UpdateNew, []*endpoint.Endpoint{})
+}
+
 func (suite *PlanTestSuite) TestIdempotency() {
 	current := []*endpoint.Endpoint{suite.fooV1Cname, suite.fooV2Cname}
 	desired := []*endpoint.Endpoint{suite.fooV1Cname, suite.fooV2Cname}
@@ -761,6 +970,10 @@ func TestNormalizeDNSName(t *testing.T) {
 			"my-example-my-example-1214.FOO-1235.BAR-foo.COM",
 			"my-example-my-example-1214.foo-1235.bar-foo.com.",
 		},
+		{
+			"Bücher.example.COM",
+			"xn--bcher-kva.example.com.",
+		},
 	}
 	for _, r := range records {
 		gotName := normalizeDNSName(r.dnsName)
@@ -16,11 +16,21 @@ limitations under the License.
 
 package plan
 
+import (
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
 // Policy allows to apply different rules to a set of changes.
 type Policy interface {
 	Apply(changes *Changes) *Changes
 }
 
+// DesiredPolicy is a Policy which also rewrites the desired records before the changes are calculated.
+type DesiredPolicy interface {
+	Policy
+	ApplyDesired(desired []*endpoint.Endpoint) []*endpoint.Endpoint
+}
+
 // Policies is a registry of available policies.
 var Policies = map[string]Policy{
 	"sync":        &SyncPolicy{},
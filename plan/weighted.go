@@ -0,0 +1,131 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plan
+
+import (
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// defaultTargetWeight is the weight of the targets without a weight of their own
+const defaultTargetWeight = "1"
+
+// WeightedPolicy distributes the targets of the desired A, AAAA and CNAME records with multiple
+// targets, or with per-target weights, into weighted record sets: one record per target, identified
+// by the target and carrying its weight, instead of a single multi-value record. It is meant for
+// the providers supporting weighted routing, e.g. AWS with the aws/weight property.
+// The changes are then processed by the wrapped Policy.
+type WeightedPolicy struct {
+	Policy
+	// WeightsProperty is the provider specific property holding the per-target weights of a record,
+	// as a comma separated list of target=weight entries
+	WeightsProperty string
+	// WeightProperty is the provider specific property the weight of a record set is stored in
+	WeightProperty string
+}
+
+// ApplyDesired splits the desired records into weighted record sets.
+func (p *WeightedPolicy) ApplyDesired(desired []*endpoint.Endpoint) []*endpoint.Endpoint {
+	result := make([]*endpoint.Endpoint, 0, len(desired))
+	for _, ep := range desired {
+		weights, weighted := p.targetWeights(ep)
+		if !isWeightedRecordType(ep.RecordType) || (!weighted && len(ep.Targets) < 2) {
+			result = append(result, withoutProperties(ep, p.WeightsProperty))
+			continue
+		}
+		if ep.SetIdentifier != "" {
+			log.Warnf("Not distributing the targets of %s into weighted record sets, it already has the set identifier %q", ep.DNSName, ep.SetIdentifier)
+			result = append(result, withoutProperties(ep, p.WeightsProperty))
+			continue
+		}
+
+		for _, target := range ep.Targets {
+			weight, ok := weights[target]
+			if !ok {
+				weight = defaultTargetWeight
+			}
+
+			set := withoutProperties(ep, p.WeightsProperty, p.WeightProperty).DeepCopy()
+			set.Targets = endpoint.Targets{target}
+			set.SetIdentifier = target
+			set.WithProviderSpecific(p.WeightProperty, weight)
+			result = append(result, set)
+		}
+	}
+	return result
+}
+
+func isWeightedRecordType(recordType string) bool {
+	return recordType == endpoint.RecordTypeA || recordType == "AAAA" || recordType == endpoint.RecordTypeCNAME
+}
+
+// targetWeights returns the per-target weights of a record, and whether the record has any.
+func (p *WeightedPolicy) targetWeights(ep *endpoint.Endpoint) (map[string]string, bool) {
+	value, ok := ep.GetProviderSpecificProperty(p.WeightsProperty)
+	if !ok {
+		return nil, false
+	}
+
+	weights := map[string]string{}
+	for _, entry := range strings.Split(value.Value, ",") {
+		target, weight, found := strings.Cut(strings.TrimSpace(entry), "=")
+		if !found {
+			log.Warnf("Ignoring invalid target weight %q of %s, expected target=weight", entry, ep.DNSName)
+			continue
+		}
+		if _, err := strconv.ParseUint(weight, 10, 32); err != nil {
+			log.Warnf("Ignoring invalid target weight %q of %s: %v", entry, ep.DNSName, err)
+			continue
+		}
+		weights[strings.TrimSuffix(target, ".")] = weight
+	}
+	return weights, true
+}
+
+// withoutProperties returns the record without the given provider specific properties,
+// copying it if any is found.
+func withoutProperties(ep *endpoint.Endpoint, names ...string) *endpoint.Endpoint {
+	found := false
+	for _, name := range names {
+		if _, ok := ep.GetProviderSpecificProperty(name); ok {
+			found = true
+		}
+	}
+	if !found {
+		return ep
+	}
+
+	ep = ep.DeepCopy()
+	providerSpecific := endpoint.ProviderSpecific{}
+	for _, property := range ep.ProviderSpecific {
+		keep := true
+		for _, name := range names {
+			if property.Name == name {
+				keep = false
+			}
+		}
+		if keep {
+			providerSpecific = append(providerSpecific, property)
+		}
+	}
+	ep.ProviderSpecific = providerSpecific
+	return ep
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plan
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+const testWeightsProperty = "external-dns.alpha.kubernetes.io/target-weights"
+
+func TestWeightedPolicyApplyDesired(t *testing.T) {
+	policy := &WeightedPolicy{Policy: &SyncPolicy{}, WeightsProperty: testWeightsProperty, WeightProperty: "aws/weight"}
+
+	for _, tc := range []struct {
+		title    string
+		desired  []*endpoint.Endpoint
+		expected []*endpoint.Endpoint
+	}{
+		{
+			title: "multiple targets without weights are distributed evenly",
+			desired: []*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"192.0.2.1", "192.0.2.2"}, RecordTTL: 60},
+			},
+			expected: []*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"192.0.2.1"}, RecordTTL: 60, SetIdentifier: "192.0.2.1", ProviderSpecific: endpoint.ProviderSpecific{{Name: "aws/weight", Value: "1"}}},
+				{DNSName: "foo.example.org", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"192.0.2.2"}, RecordTTL: 60, SetIdentifier: "192.0.2.2", ProviderSpecific: endpoint.ProviderSpecific{{Name: "aws/weight", Value: "1"}}},
+			},
+		},
+		{
+			title: "per-target weights",
+			desired: []*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: endpoint.RecordTypeCNAME, Targets: endpoint.Targets{"a.example.org", "b.example.org"}, ProviderSpecific: endpoint.ProviderSpecific{
+					{Name: testWeightsProperty, Value: "a.example.org.=90, b.example.org=x"},
+					{Name: "aws/weight", Value: "50"},
+					{Name: "aws/evaluate-target-health", Value: "true"},
+				}},
+			},
+			expected: []*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: endpoint.RecordTypeCNAME, Targets: endpoint.Targets{"a.example.org"}, SetIdentifier: "a.example.org", ProviderSpecific: endpoint.ProviderSpecific{{Name: "aws/evaluate-target-health", Value: "true"}, {Name: "aws/weight", Value: "90"}}},
+				{DNSName: "foo.example.org", RecordType: endpoint.RecordTypeCNAME, Targets: endpoint.Targets{"b.example.org"}, SetIdentifier: "b.example.org", ProviderSpecific: endpoint.ProviderSpecific{{Name: "aws/evaluate-target-health", Value: "true"}, {Name: "aws/weight", Value: "1"}}},
+			},
+		},
+		{
+			title: "single target with weights",
+			desired: []*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"192.0.2.1"}, ProviderSpecific: endpoint.ProviderSpecific{{Name: testWeightsProperty, Value: "192.0.2.1=10"}}},
+			},
+			expected: []*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"192.0.2.1"}, SetIdentifier: "192.0.2.1", ProviderSpecific: endpoint.ProviderSpecific{{Name: "aws/weight", Value: "10"}}},
+			},
+		},
+		{
+			title: "records left untouched",
+			desired: []*endpoint.Endpoint{
+				{DNSName: "single.example.org", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"192.0.2.1"}},
+				{DNSName: "txt.example.org", RecordType: endpoint.RecordTypeTXT, Targets: endpoint.Targets{"a", "b"}},
+				{DNSName: "set.example.org", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"192.0.2.1", "192.0.2.2"}, SetIdentifier: "eu", ProviderSpecific: endpoint.ProviderSpecific{{Name: testWeightsProperty, Value: "192.0.2.1=10"}}},
+			},
+			expected: []*endpoint.Endpoint{
+				{DNSName: "single.example.org", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"192.0.2.1"}},
+				{DNSName: "txt.example.org", RecordType: endpoint.RecordTypeTXT, Targets: endpoint.Targets{"a", "b"}},
+				{DNSName: "set.example.org", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"192.0.2.1", "192.0.2.2"}, SetIdentifier: "eu", ProviderSpecific: endpoint.ProviderSpecific{}},
+			},
+		},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			validateEntries(t, policy.ApplyDesired(tc.desired), tc.expected)
+		})
+	}
+}
+
+func TestWeightedPolicyCalculate(t *testing.T) {
+	current := []*endpoint.Endpoint{
+		{DNSName: "foo.example.org", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"192.0.2.1"}, SetIdentifier: "192.0.2.1", ProviderSpecific: endpoint.ProviderSpecific{{Name: "aws/weight", Value: "1"}}},
+		{DNSName: "foo.example.org", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"192.0.2.3"}, SetIdentifier: "192.0.2.3", ProviderSpecific: endpoint.ProviderSpecific{{Name: "aws/weight", Value: "1"}}},
+	}
+	desired := []*endpoint.Endpoint{
+		{DNSName: "foo.example.org", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"192.0.2.1", "192.0.2.2"}},
+	}
+
+	p := &Plan{
+		Policies:       []Policy{&WeightedPolicy{Policy: &UpsertOnlyPolicy{}, WeightsProperty: testWeightsProperty, WeightProperty: "aws/weight"}},
+		Current:        current,
+		Desired:        desired,
+		ManagedRecords: []string{endpoint.RecordTypeA},
+	}
+
+	changes := p.Calculate().Changes
+	validateEntries(t, changes.Create, []*endpoint.Endpoint{
+		{DNSName: "foo.example.org", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"192.0.2.2"}, SetIdentifier: "192.0.2.2", ProviderSpecific: endpoint.ProviderSpecific{{Name: "aws/weight", Value: "1"}}},
+	})
+	validateEntries(t, changes.UpdateNew, []*endpoint.Endpoint{})
+	// The wrapped upsert-only policy strips out the deletion of the 192.0.2.3 record set.
+	validateEntries(t, changes.Delete, []*endpoint.Endpoint{})
+	assert.Equal(t, endpoint.Targets{"192.0.2.1", "192.0.2.2"}, desired[0].Targets)
+}
@@ -596,10 +596,39 @@ func (p *AWSProvider) AdjustEndpoints(endpoints []*endpoint.Endpoint) []*endpoin
 				Value: fmt.Sprintf("%t", p.evaluateTargetHealth),
 			})
 		}
+
+		adjustFailover(ep)
 	}
 	return endpoints
 }
 
+// adjustFailover normalizes the failover role of an endpoint to the PRIMARY or SECONDARY value
+// listed by Route53, so that a lower case annotation doesn't cause an update every synchronization.
+// Invalid roles, and roles of endpoints without a set identifier, are dropped.
+func adjustFailover(ep *endpoint.Endpoint) {
+	for i, prop := range ep.ProviderSpecific {
+		if prop.Name != providerSpecificFailover {
+			continue
+		}
+
+		role := strings.ToUpper(strings.TrimSpace(prop.Value))
+		switch {
+		case role != route53.ResourceRecordSetFailoverPrimary && role != route53.ResourceRecordSetFailoverSecondary:
+			log.Warnf("Ignoring the failover role %q of %s, expected %s or %s", prop.Value, ep.DNSName, route53.ResourceRecordSetFailoverPrimary, route53.ResourceRecordSetFailoverSecondary)
+		case ep.SetIdentifier == "":
+			log.Warnf("Ignoring the failover role of %s, failover records need a set identifier", ep.DNSName)
+		default:
+			if _, ok := ep.GetProviderSpecificProperty(providerSpecificHealthCheckID); !ok && role == route53.ResourceRecordSetFailoverPrimary {
+				log.Warnf("The primary failover record of %s has no health check, Route53 considers it always healthy", ep.DNSName)
+			}
+			ep.ProviderSpecific[i].Value = role
+			return
+		}
+		ep.ProviderSpecific = append(append(endpoint.ProviderSpecific(nil), ep.ProviderSpecific[:i]...), ep.ProviderSpecific[i+1:]...)
+		return
+	}
+}
+
 // newChange returns a route53 Change and a boolean indicating if there should also be a change to a AAAA record
 // returned Change is based on the given record by the given action, e.g.
 // action=ChangeActionCreate returns a change for creation of the record and
@@ -407,6 +407,32 @@ func TestAWSAdjustEndpoints(t *testing.T) {
 	})
 }
 
+func TestAWSAdjustEndpointsFailover(t *testing.T) {
+	provider, _ := newAWSProvider(t, endpoint.NewDomainFilter([]string{"ext-dns-test-2.teapot.zalan.do."}), provider.NewZoneIDFilter([]string{}), provider.NewZoneTypeFilter(""), defaultEvaluateTargetHealth, false, []*endpoint.Endpoint{})
+
+	primary := endpoint.NewEndpoint("primary.zone-1.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeA, "8.8.8.8").WithSetIdentifier("primary").WithProviderSpecific(providerSpecificFailover, "primary").WithProviderSpecific(providerSpecificHealthCheckID, "abc-123")
+	records := []*endpoint.Endpoint{
+		primary,
+		endpoint.NewEndpoint("secondary.zone-1.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeA, "8.8.4.4").WithSetIdentifier("secondary").WithProviderSpecific(providerSpecificFailover, "SECONDARY"),
+		endpoint.NewEndpoint("invalid.zone-1.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeA, "1.1.1.1").WithSetIdentifier("invalid").WithProviderSpecific(providerSpecificFailover, "tertiary"),
+		endpoint.NewEndpoint("no-set-identifier.zone-1.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeA, "1.0.0.1").WithProviderSpecific(providerSpecificFailover, "PRIMARY"),
+	}
+
+	provider.AdjustEndpoints(records)
+
+	validateEndpoints(t, records, []*endpoint.Endpoint{
+		endpoint.NewEndpoint("primary.zone-1.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeA, "8.8.8.8").WithSetIdentifier("primary").WithProviderSpecific(providerSpecificFailover, "PRIMARY").WithProviderSpecific(providerSpecificHealthCheckID, "abc-123"),
+		endpoint.NewEndpoint("secondary.zone-1.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeA, "8.8.4.4").WithSetIdentifier("secondary").WithProviderSpecific(providerSpecificFailover, "SECONDARY"),
+		endpoint.NewEndpoint("invalid.zone-1.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeA, "1.1.1.1").WithSetIdentifier("invalid"),
+		endpoint.NewEndpoint("no-set-identifier.zone-1.ext-dns-test-2.teapot.zalan.do", endpoint.RecordTypeA, "1.0.0.1"),
+	})
+
+	change, _ := provider.newChange(route53.ChangeActionCreate, primary)
+	assert.Equal(t, "PRIMARY", aws.StringValue(change.ResourceRecordSet.Failover))
+	assert.Equal(t, "abc-123", aws.StringValue(change.ResourceRecordSet.HealthCheckId))
+	assert.Equal(t, "primary", aws.StringValue(change.ResourceRecordSet.SetIdentifier))
+}
+
 func TestAWSCreateRecords(t *testing.T) {
 	customTTL := endpoint.TTL(60)
 	provider, _ := newAWSProvider(t, endpoint.NewDomainFilter([]string{"ext-dns-test-2.teapot.zalan.do."}), provider.NewZoneIDFilter([]string{}), provider.NewZoneTypeFilter(""), defaultEvaluateTargetHealth, false, []*endpoint.Endpoint{})
@@ -54,6 +54,7 @@ type AzureProvider struct {
 	domainFilter                 endpoint.DomainFilter
 	zoneNameFilter               endpoint.DomainFilter
 	zoneIDFilter                 provider.ZoneIDFilter
+	zoneSelector                 zoneSelector
 	dryRun                       bool
 	resourceGroup                string
 	userAssignedIdentityClientID string
@@ -64,7 +65,12 @@ type AzureProvider struct {
 // NewAzureProvider creates a new Azure provider.
 //
 // Returns the provider or an error if a provider could not be created.
-func NewAzureProvider(configFile string, domainFilter endpoint.DomainFilter, zoneNameFilter endpoint.DomainFilter, zoneIDFilter provider.ZoneIDFilter, resourceGroup string, userAssignedIdentityClientID string, dryRun bool) (*AzureProvider, error) {
+func NewAzureProvider(configFile string, domainFilter endpoint.DomainFilter, zoneNameFilter endpoint.DomainFilter, zoneIDFilter provider.ZoneIDFilter, zones []string, zoneRules []string, resourceGroup string, userAssignedIdentityClientID string, dryRun bool) (*AzureProvider, error) {
+	selector, err := newZoneSelector(zones, zoneRules)
+	if err != nil {
+		return nil, err
+	}
+
 	cfg, err := getConfig(configFile, resourceGroup, userAssignedIdentityClientID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read Azure config file '%s': %v", configFile, err)
@@ -84,6 +90,7 @@ func NewAzureProvider(configFile string, domainFilter endpoint.DomainFilter, zon
 		domainFilter:                 domainFilter,
 		zoneNameFilter:               zoneNameFilter,
 		zoneIDFilter:                 zoneIDFilter,
+		zoneSelector:                 selector,
 		dryRun:                       dryRun,
 		resourceGroup:                cfg.ResourceGroup,
 		userAssignedIdentityClientID: cfg.UserAssignedIdentityID,
@@ -101,6 +108,11 @@ func (p *AzureProvider) Records(ctx context.Context) (endpoints []*endpoint.Endp
 		return nil, err
 	}
 
+	zoneNames := provider.ZoneIDName{}
+	for _, zone := range zones {
+		zoneNames.Add(*zone.Name, *zone.Name)
+	}
+
 	for _, zone := range zones {
 		err := p.iterateRecords(ctx, *zone.Name, func(recordSet dns.RecordSet) bool {
 			if recordSet.Name == nil || recordSet.Type == nil {
@@ -117,6 +129,10 @@ func (p *AzureProvider) Records(ctx context.Context) (endpoints []*endpoint.Endp
 				log.Debugf("Skipping return of record %s because it was filtered out by the specified --domain-filter", name)
 				return true
 			}
+			if p.zoneSelector.shadowed(name, *zone.Name, zoneNames) {
+				log.Debugf("Skipping return of record %s of zone %s because the zone rules select another zone for it", name, *zone.Name)
+				return true
+			}
 			targets := extractAzureTargets(&recordSet)
 			if len(targets) == 0 {
 				log.Debugf("Failed to extract targets for '%s' with type '%s'.", name, recordType)
@@ -172,7 +188,9 @@ func (p *AzureProvider) zones(ctx context.Context) ([]dns.Zone, error) {
 	for zonesIterator.NotDone() {
 		zone := zonesIterator.Value()
 
-		if zone.Name != nil && p.domainFilter.Match(*zone.Name) && p.zoneIDFilter.Match(*zone.ID) {
+		if zone.Name != nil && !p.zoneSelector.manages(*zone.Name) {
+			log.Debugf("Skipping zone %s because it is not in the managed zones", *zone.Name)
+		} else if zone.Name != nil && p.domainFilter.Match(*zone.Name) && p.zoneIDFilter.Match(*zone.ID) {
 			zones = append(zones, zone)
 		} else if zone.Name != nil && len(p.zoneNameFilter.Filters) > 0 && p.zoneNameFilter.Match(*zone.Name) {
 			// Handle zoneNameFilter
@@ -224,7 +242,7 @@ func (p *AzureProvider) mapChanges(zones []dns.Zone, changes *plan.Changes) (azu
 		}
 	}
 	mapChange := func(changeMap azureChangeMap, change *endpoint.Endpoint) {
-		zone, _ := zoneNameIDMapper.FindZone(change.DNSName)
+		zone := p.zoneSelector.zoneFor(change.DNSName, zoneNameIDMapper)
 		if zone == "" {
 			if _, ok := ignored[change.DNSName]; !ok {
 				ignored[change.DNSName] = true
@@ -48,6 +48,7 @@ type AzurePrivateDNSProvider struct {
 	provider.BaseProvider
 	domainFilter                 endpoint.DomainFilter
 	zoneIDFilter                 provider.ZoneIDFilter
+	zoneSelector                 zoneSelector
 	dryRun                       bool
 	resourceGroup                string
 	userAssignedIdentityClientID string
@@ -58,7 +59,12 @@ type AzurePrivateDNSProvider struct {
 // NewAzurePrivateDNSProvider creates a new Azure Private DNS provider.
 //
 // Returns the provider or an error if a provider could not be created.
-func NewAzurePrivateDNSProvider(configFile string, domainFilter endpoint.DomainFilter, zoneIDFilter provider.ZoneIDFilter, resourceGroup, userAssignedIdentityClientID string, dryRun bool) (*AzurePrivateDNSProvider, error) {
+func NewAzurePrivateDNSProvider(configFile string, domainFilter endpoint.DomainFilter, zoneIDFilter provider.ZoneIDFilter, zones []string, zoneRules []string, resourceGroup, userAssignedIdentityClientID string, dryRun bool) (*AzurePrivateDNSProvider, error) {
+	selector, err := newZoneSelector(zones, zoneRules)
+	if err != nil {
+		return nil, err
+	}
+
 	cfg, err := getConfig(configFile, resourceGroup, userAssignedIdentityClientID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read Azure config file '%s': %v", configFile, err)
@@ -77,6 +83,7 @@ func NewAzurePrivateDNSProvider(configFile string, domainFilter endpoint.DomainF
 	return &AzurePrivateDNSProvider{
 		domainFilter:                 domainFilter,
 		zoneIDFilter:                 zoneIDFilter,
+		zoneSelector:                 selector,
 		dryRun:                       dryRun,
 		resourceGroup:                cfg.ResourceGroup,
 		userAssignedIdentityClientID: cfg.UserAssignedIdentityID,
@@ -96,6 +103,11 @@ func (p *AzurePrivateDNSProvider) Records(ctx context.Context) (endpoints []*end
 
 	log.Debugf("Retrieving Azure Private DNS Records for resource group '%s'", p.resourceGroup)
 
+	zoneNames := provider.ZoneIDName{}
+	for _, zone := range zones {
+		zoneNames.Add(*zone.Name, *zone.Name)
+	}
+
 	for _, zone := range zones {
 		err := p.iterateRecords(ctx, *zone.Name, func(recordSet privatedns.RecordSet) {
 			var recordType string
@@ -111,6 +123,10 @@ func (p *AzurePrivateDNSProvider) Records(ctx context.Context) (endpoints []*end
 				return
 			}
 			name = formatAzureDNSName(*recordSet.Name, *zone.Name)
+			if p.zoneSelector.shadowed(name, *zone.Name, zoneNames) {
+				log.Debugf("Skipping record %s of zone %s because the zone rules select another zone for it", name, *zone.Name)
+				return
+			}
 
 			targets := extractAzurePrivateDNSTargets(&recordSet)
 			if len(targets) == 0 {
@@ -173,7 +189,9 @@ func (p *AzurePrivateDNSProvider) zones(ctx context.Context) ([]privatedns.Priva
 		zone := i.Value()
 		log.Debugf("Validating Zone: %v", *zone.Name)
 
-		if zone.Name != nil && p.domainFilter.Match(*zone.Name) && p.zoneIDFilter.Match(*zone.ID) {
+		if zone.Name != nil && !p.zoneSelector.manages(*zone.Name) {
+			log.Debugf("Skipping zone %s because it is not in the managed zones", *zone.Name)
+		} else if zone.Name != nil && p.domainFilter.Match(*zone.Name) && p.zoneIDFilter.Match(*zone.ID) {
 			zones = append(zones, zone)
 		}
 
@@ -220,7 +238,7 @@ func (p *AzurePrivateDNSProvider) mapChanges(zones []privatedns.PrivateZone, cha
 		}
 	}
 	mapChange := func(changeMap azurePrivateDNSChangeMap, change *endpoint.Endpoint) {
-		zone, _ := zoneNameIDMapper.FindZone(change.DNSName)
+		zone := p.zoneSelector.zoneFor(change.DNSName, zoneNameIDMapper)
 		if zone == "" {
 			if _, ok := ignored[change.DNSName]; !ok {
 				ignored[change.DNSName] = true
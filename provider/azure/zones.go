@@ -0,0 +1,110 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package azure
+
+import (
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/external-dns/provider"
+)
+
+// zoneSelector selects the zone of the records when the zones of the provider overlap,
+// e.g. to manage a.dev.example.com in example.com rather than in dev.example.com.
+type zoneSelector struct {
+	// zones, if not empty, are the only zones managed by the provider
+	zones map[string]bool
+	// rules select the zone of the records by the suffix of their name, the longest suffix wins
+	rules []zoneRule
+}
+
+type zoneRule struct {
+	suffix string
+	zone   string
+}
+
+// newZoneSelector returns the selector managing the given zones, or all the zones if none is
+// given, with rules in the form <suffix>=<zone>.
+func newZoneSelector(zones []string, rules []string) (zoneSelector, error) {
+	s := zoneSelector{zones: map[string]bool{}}
+	for _, zone := range zones {
+		if zone = normalizeZoneName(zone); zone != "" {
+			s.zones[zone] = true
+		}
+	}
+
+	for _, rule := range rules {
+		parts := strings.Split(rule, "=")
+		if len(parts) != 2 {
+			return zoneSelector{}, fmt.Errorf("invalid zone rule %q, expected <suffix>=<zone>", rule)
+		}
+		r := zoneRule{suffix: normalizeZoneName(parts[0]), zone: normalizeZoneName(parts[1])}
+		if r.suffix == "" || r.zone == "" {
+			return zoneSelector{}, fmt.Errorf("invalid zone rule %q, expected <suffix>=<zone>", rule)
+		}
+		if !inZone(r.suffix, r.zone) {
+			return zoneSelector{}, fmt.Errorf("invalid zone rule %q, the names ending with %s are not in zone %s", rule, r.suffix, r.zone)
+		}
+		if !s.manages(r.zone) {
+			return zoneSelector{}, fmt.Errorf("invalid zone rule %q, zone %s is not managed", rule, r.zone)
+		}
+		s.rules = append(s.rules, r)
+	}
+	return s, nil
+}
+
+// manages tells whether the zone is managed by the provider.
+func (s zoneSelector) manages(zone string) bool {
+	return len(s.zones) == 0 || s.zones[normalizeZoneName(zone)]
+}
+
+// zoneFor returns the zone of a record among the zones of the provider, the zone of the rule
+// with the longest matching suffix, or else the zone with the longest matching suffix. It returns
+// an empty string when no zone matches, or when the zone of the rule isn't found.
+func (s zoneSelector) zoneFor(name string, zones provider.ZoneIDName) string {
+	var selected zoneRule
+	for _, r := range s.rules {
+		if inZone(normalizeZoneName(name), r.suffix) && len(r.suffix) > len(selected.suffix) {
+			selected = r
+		}
+	}
+	if selected.zone == "" {
+		_, zone := zones.FindZone(name)
+		return zone
+	}
+
+	for _, zone := range zones {
+		if normalizeZoneName(zone) == selected.zone {
+			return zone
+		}
+	}
+	return ""
+}
+
+// shadowed tells whether a record listed in zone is managed in another zone according to the rules,
+// so that the plan only considers the records of the zone the changes are applied to.
+func (s zoneSelector) shadowed(name, zone string, zones provider.ZoneIDName) bool {
+	return len(s.rules) > 0 && s.zoneFor(name, zones) != zone
+}
+
+func inZone(name, zone string) bool {
+	return name == zone || strings.HasSuffix(name, "."+zone)
+}
+
+func normalizeZoneName(zone string) string {
+	return strings.ToLower(strings.TrimSuffix(strings.TrimSpace(zone), "."))
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package azure
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/services/dns/mgmt/2018-05-01/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+	"sigs.k8s.io/external-dns/provider"
+)
+
+func TestZoneSelector(t *testing.T) {
+	zones := provider.ZoneIDName{}
+	for _, zone := range []string{"example.com", "dev.example.com", "other.com"} {
+		zones.Add(zone, zone)
+	}
+
+	s, err := newZoneSelector(nil, nil)
+	require.NoError(t, err)
+	assert.True(t, s.manages("anything.com"))
+	assert.Equal(t, "dev.example.com", s.zoneFor("a.dev.example.com", zones))
+	assert.False(t, s.shadowed("a.dev.example.com", "example.com", zones), "expected no shadowing without rules")
+
+	s, err = newZoneSelector([]string{"example.com", "dev.example.com."}, []string{"shared.dev.example.com=example.com", "api.shared.dev.example.com=dev.example.com"})
+	require.NoError(t, err)
+	assert.True(t, s.manages("Dev.Example.com"))
+	assert.False(t, s.manages("other.com"))
+	assert.Equal(t, "dev.example.com", s.zoneFor("a.dev.example.com", zones))
+	assert.Equal(t, "example.com", s.zoneFor("a.shared.dev.example.com", zones))
+	assert.Equal(t, "example.com", s.zoneFor("shared.dev.example.com", zones))
+	assert.Equal(t, "dev.example.com", s.zoneFor("api.shared.dev.example.com", zones), "expected the longest suffix to win")
+	assert.True(t, s.shadowed("a.shared.dev.example.com", "dev.example.com", zones))
+	assert.False(t, s.shadowed("a.shared.dev.example.com", "example.com", zones))
+
+	_, err = newZoneSelector(nil, []string{"shared.dev.example.com=missing.com"})
+	require.Error(t, err, "expected the names of the rule to be in its zone")
+	s, err = newZoneSelector(nil, []string{"a.missing.com=missing.com"})
+	require.NoError(t, err)
+	assert.Empty(t, s.zoneFor("b.a.missing.com", zones), "expected no zone when the zone of the rule isn't found")
+
+	for _, rule := range []string{"", "example.com", "=example.com", "a.example.com=", "a=b=c"} {
+		_, err := newZoneSelector(nil, []string{rule})
+		assert.Error(t, err, rule)
+	}
+	_, err = newZoneSelector([]string{"example.com"}, []string{"a.other.com=other.com"})
+	assert.Error(t, err, "expected the zone of the rule to be managed")
+}
+
+func TestAzureZoneRules(t *testing.T) {
+	zones := []dns.Zone{
+		createMockZone("example.com", "/dnszones/example.com"),
+		createMockZone("dev.example.com", "/dnszones/dev.example.com"),
+		createMockZone("other.com", "/dnszones/other.com"),
+	}
+	p, err := newMockedAzureProvider(endpoint.NewDomainFilter([]string{""}), endpoint.NewDomainFilter([]string{""}), provider.NewZoneIDFilter([]string{""}), false, "group", "", &zones, &[]dns.RecordSet{})
+	require.NoError(t, err)
+	p.zoneSelector, err = newZoneSelector([]string{"example.com", "dev.example.com"}, []string{"shared.dev.example.com=example.com"})
+	require.NoError(t, err)
+
+	managed, err := p.zones(context.Background())
+	require.NoError(t, err)
+	require.Len(t, managed, 2)
+
+	deleted, updated := p.mapChanges(managed, &plan.Changes{
+		Create: []*endpoint.Endpoint{
+			endpoint.NewEndpoint("a.shared.dev.example.com", endpoint.RecordTypeA, "1.2.3.4"),
+			endpoint.NewEndpoint("a.dev.example.com", endpoint.RecordTypeA, "1.2.3.5"),
+			endpoint.NewEndpoint("a.other.com", endpoint.RecordTypeA, "1.2.3.6"),
+		},
+		Delete: []*endpoint.Endpoint{
+			endpoint.NewEndpoint("b.shared.dev.example.com", endpoint.RecordTypeA, "1.2.3.7"),
+		},
+	})
+	assert.Equal(t, azureChangeMap{
+		"example.com":     {endpoint.NewEndpoint("a.shared.dev.example.com", endpoint.RecordTypeA, "1.2.3.4")},
+		"dev.example.com": {endpoint.NewEndpoint("a.dev.example.com", endpoint.RecordTypeA, "1.2.3.5")},
+	}, updated)
+	assert.Equal(t, azureChangeMap{
+		"example.com": {endpoint.NewEndpoint("b.shared.dev.example.com", endpoint.RecordTypeA, "1.2.3.7")},
+	}, deleted)
+	assert.Equal(t, "a.shared.dev", p.recordSetNameForZone("example.com", updated["example.com"][0]))
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+)
+
+var cachedRecordsCallsTotal = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "external_dns",
+		Subsystem: "provider",
+		Name:      "cache_records_calls",
+		Help:      "Number of calls to the provider cache Records list, by whether they were served from the cache.",
+	},
+	[]string{"from_cache"},
+)
+
+func init() {
+	prometheus.MustRegister(cachedRecordsCallsTotal)
+}
+
+// CachedProvider wraps a Provider, caching the records it lists for RefreshDelay. The records
+// are listed again once the delay expired, or after changes were applied, so that the plan is
+// always calculated from the records as they were after the last changes.
+type CachedProvider struct {
+	Provider
+	RefreshDelay time.Duration
+
+	sync.Mutex
+	cache    []*endpoint.Endpoint
+	lastRead time.Time
+}
+
+// NewCachedProvider returns the provider with its records cached for refreshDelay.
+func NewCachedProvider(p Provider, refreshDelay time.Duration) *CachedProvider {
+	return &CachedProvider{
+		Provider:     p,
+		RefreshDelay: refreshDelay,
+	}
+}
+
+// Records returns the cached records, listing them from the wrapped provider if needed.
+func (c *CachedProvider) Records(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	c.Lock()
+	defer c.Unlock()
+
+	fromCache := !c.needRefresh()
+	cachedRecordsCallsTotal.WithLabelValues(strconv.FormatBool(fromCache)).Inc()
+	if !fromCache {
+		log.Debug("Records cache of the provider is expired, listing the records")
+		records, err := c.Provider.Records(ctx)
+		if err != nil {
+			c.cache = nil
+			return nil, err
+		}
+		c.cache = records
+		c.lastRead = time.Now()
+	}
+
+	// the callers are free to modify the records, e.g. the registry sets their labels
+	records := make([]*endpoint.Endpoint, 0, len(c.cache))
+	for _, ep := range c.cache {
+		records = append(records, ep.DeepCopy())
+	}
+	return records, nil
+}
+
+// ApplyChanges applies the changes with the wrapped provider and invalidates the cache.
+func (c *CachedProvider) ApplyChanges(ctx context.Context, changes *plan.Changes) error {
+	if !changes.HasChanges() {
+		return nil
+	}
+
+	c.Reset()
+	return c.Provider.ApplyChanges(ctx, changes)
+}
+
+// ValidateChanges validates the changes with the wrapped provider, the cache is kept.
+func (c *CachedProvider) ValidateChanges(ctx context.Context, changes *plan.Changes) error {
+	return ValidateChanges(ctx, c.Provider, changes)
+}
+
+// Reset invalidates the cache, the records are listed again by the next call to Records.
+func (c *CachedProvider) Reset() {
+	c.Lock()
+	defer c.Unlock()
+	c.cache = nil
+	c.lastRead = time.Time{}
+}
+
+func (c *CachedProvider) needRefresh() bool {
+	if c.cache == nil {
+		return true
+	}
+	return time.Since(c.lastRead) > c.RefreshDelay
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+)
+
+func TestCachedProviderRecords(t *testing.T) {
+	p := &flakyProvider{}
+	c := NewCachedProvider(p, time.Hour)
+	ctx := context.Background()
+
+	records, err := c.Records(ctx)
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	records[0].Labels["owner"] = "modified"
+
+	records, err = c.Records(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, p.calls, "expected the records to be served from the cache")
+	assert.Empty(t, records[0].Labels, "expected the cached records not to be modified by the callers")
+
+	c.RefreshDelay = 0
+	_, err = c.Records(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, p.calls, "expected the records to be listed once the cache expired")
+}
+
+func TestCachedProviderApplyChanges(t *testing.T) {
+	p := &flakyProvider{}
+	c := NewCachedProvider(p, time.Hour)
+	ctx := context.Background()
+
+	_, err := c.Records(ctx)
+	require.NoError(t, err)
+
+	require.NoError(t, c.ApplyChanges(ctx, &plan.Changes{}))
+	assert.Empty(t, p.applied, "expected no call without changes")
+	_, err = c.Records(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, p.calls, "expected the cache to be kept without changes")
+
+	require.NoError(t, c.ApplyChanges(ctx, &plan.Changes{Create: []*endpoint.Endpoint{endpoint.NewEndpoint("b.example.org", endpoint.RecordTypeA, "1.2.3.5")}}))
+	_, err = c.Records(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 3, p.calls, "expected the cache to be invalidated by the changes")
+}
+
+func TestCachedProviderError(t *testing.T) {
+	p := &flakyProvider{errs: []error{errors.New("boom")}}
+	c := NewCachedProvider(p, time.Hour)
+	ctx := context.Background()
+
+	_, err := c.Records(ctx)
+	assert.Error(t, err)
+
+	records, err := c.Records(ctx)
+	require.NoError(t, err)
+	assert.Len(t, records, 1)
+	assert.Equal(t, 2, p.calls, "expected the records to be listed again after an error")
+}
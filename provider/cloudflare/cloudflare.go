@@ -18,7 +18,9 @@ package cloudflare
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"net/http"
 	"os"
 	"strconv"
 
@@ -40,6 +42,8 @@ const (
 	cloudFlareUpdate = "UPDATE"
 	// defaultCloudFlareRecordTTL 1 = automatic
 	defaultCloudFlareRecordTTL = 1
+	// cloudFlareRecordsPerPage is the maximum number of records listed per page by the API
+	cloudFlareRecordsPerPage = 100
 )
 
 // We have to use pointers to bools now, as the upstream cloudflare-go library requires them
@@ -71,6 +75,8 @@ type cloudFlareDNS interface {
 	CreateDNSRecord(ctx context.Context, zoneID string, rr cloudflare.DNSRecord) (*cloudflare.DNSRecordResponse, error)
 	DeleteDNSRecord(ctx context.Context, zoneID, recordID string) error
 	UpdateDNSRecord(ctx context.Context, zoneID, recordID string, rr cloudflare.DNSRecord) error
+	UpdateDNSRecordComment(ctx context.Context, zoneID, recordID, comment string) error
+	DNSRecordComments(ctx context.Context, zoneID string) (map[string]string, error)
 }
 
 type zoneService struct {
@@ -99,6 +105,41 @@ func (z zoneService) DNSRecords(ctx context.Context, zoneID string, rr cloudflar
 func (z zoneService) UpdateDNSRecord(ctx context.Context, zoneID, recordID string, rr cloudflare.DNSRecord) error {
 	return z.service.UpdateDNSRecord(ctx, zoneID, recordID, rr)
 }
+
+// UpdateDNSRecordComment sets the comment of a record, which cloudflare.DNSRecord doesn't support yet.
+func (z zoneService) UpdateDNSRecordComment(ctx context.Context, zoneID, recordID, comment string) error {
+	_, err := z.service.Raw(http.MethodPatch, fmt.Sprintf("/zones/%s/dns_records/%s", zoneID, recordID), map[string]string{"comment": comment})
+	return err
+}
+
+// DNSRecordComments returns the comments of the records of a zone by record ID, which
+// cloudflare.DNSRecord doesn't support yet.
+func (z zoneService) DNSRecordComments(ctx context.Context, zoneID string) (map[string]string, error) {
+	comments := map[string]string{}
+	for page := 1; ; page++ {
+		raw, err := z.service.Raw(http.MethodGet, fmt.Sprintf("/zones/%s/dns_records?per_page=%d&page=%d", zoneID, cloudFlareRecordsPerPage, page), nil)
+		if err != nil {
+			return nil, err
+		}
+		var records []struct {
+			ID      string `json:"id"`
+			Comment string `json:"comment"`
+		}
+		if err := json.Unmarshal(raw, &records); err != nil {
+			return nil, fmt.Errorf("failed to decode the comments of the records of zone %s: %w", zoneID, err)
+		}
+		for _, record := range records {
+			if record.Comment != "" {
+				comments[record.ID] = record.Comment
+			}
+		}
+		// the raw API doesn't return the pagination info, the last page is the first one not full
+		if len(records) < cloudFlareRecordsPerPage {
+			return comments, nil
+		}
+	}
+}
+
 func (z zoneService) DeleteDNSRecord(ctx context.Context, zoneID, recordID string) error {
 	return z.service.DeleteDNSRecord(ctx, zoneID, recordID)
 }
@@ -127,6 +168,9 @@ type CloudFlareProvider struct {
 type cloudFlareChange struct {
 	Action         string
 	ResourceRecord cloudflare.DNSRecord
+	// Comment is set on the record once created or updated when SetComment is true
+	Comment    string
+	SetComment bool
 }
 
 // NewCloudFlareProvider initializes a new CloudFlare DNS based Provider.
@@ -215,11 +259,15 @@ func (p *CloudFlareProvider) Records(ctx context.Context) ([]*endpoint.Endpoint,
 		if err != nil {
 			return nil, err
 		}
+		comments, err := p.Client.DNSRecordComments(ctx, zone.ID)
+		if err != nil {
+			return nil, err
+		}
 
 		// As CloudFlare does not support "sets" of targets, but instead returns
 		// a single entry for each name/type/target, we have to group by name
 		// and record to allow the planner to calculate the correct plan. See #992.
-		endpoints = append(endpoints, groupByNameAndType(records)...)
+		endpoints = append(endpoints, groupByNameAndType(records, comments)...)
 	}
 
 	return endpoints, nil
@@ -245,7 +293,11 @@ func (p *CloudFlareProvider) ApplyChanges(ctx context.Context, changes *plan.Cha
 		}
 
 		for _, a := range leave {
-			cloudflareChanges = append(cloudflareChanges, p.newCloudFlareChange(cloudFlareUpdate, desired, a))
+			change := p.newCloudFlareChange(cloudFlareUpdate, desired, a)
+			// the comment is sent when changed only, so that removing it clears it
+			currentComment, _ := current.GetProviderSpecificProperty(source.CloudflareCommentKey)
+			change.SetComment = change.Comment != currentComment.Value
+			cloudflareChanges = append(cloudflareChanges, change)
 		}
 
 		for _, a := range remove {
@@ -313,7 +365,9 @@ func (p *CloudFlareProvider) submitChanges(ctx context.Context, changes []*cloud
 				err := p.Client.UpdateDNSRecord(ctx, zoneID, recordID, change.ResourceRecord)
 				if err != nil {
 					log.WithFields(logFields).Errorf("failed to update record: %v", err)
+					continue
 				}
+				p.setComment(ctx, zoneID, recordID, change, logFields)
 			} else if change.Action == cloudFlareDelete {
 				recordID := p.getRecordID(records, change.ResourceRecord)
 				if recordID == "" {
@@ -325,9 +379,13 @@ func (p *CloudFlareProvider) submitChanges(ctx context.Context, changes []*cloud
 					log.WithFields(logFields).Errorf("failed to delete record: %v", err)
 				}
 			} else if change.Action == cloudFlareCreate {
-				_, err := p.Client.CreateDNSRecord(ctx, zoneID, change.ResourceRecord)
+				resp, err := p.Client.CreateDNSRecord(ctx, zoneID, change.ResourceRecord)
 				if err != nil {
 					log.WithFields(logFields).Errorf("failed to create record: %v", err)
+					continue
+				}
+				if resp != nil {
+					p.setComment(ctx, zoneID, resp.Result.ID, change, logFields)
 				}
 			}
 		}
@@ -335,6 +393,17 @@ func (p *CloudFlareProvider) submitChanges(ctx context.Context, changes []*cloud
 	return nil
 }
 
+// setComment sets the comment of a created or updated record when the change sets it, an
+// empty comment clearing the one of the record.
+func (p *CloudFlareProvider) setComment(ctx context.Context, zoneID, recordID string, change *cloudFlareChange, logFields log.Fields) {
+	if !change.SetComment {
+		return
+	}
+	if err := p.Client.UpdateDNSRecordComment(ctx, zoneID, recordID, change.Comment); err != nil {
+		log.WithFields(logFields).Errorf("failed to set the comment of the record: %v", err)
+	}
+}
+
 // AdjustEndpoints modifies the endpoints as needed by the specific provider
 func (p *CloudFlareProvider) AdjustEndpoints(endpoints []*endpoint.Endpoint) []*endpoint.Endpoint {
 	adjustedEndpoints := []*endpoint.Endpoint{}
@@ -386,8 +455,12 @@ func (p *CloudFlareProvider) newCloudFlareChange(action string, endpoint *endpoi
 		ttl = int(endpoint.RecordTTL)
 	}
 
+	comment, _ := endpoint.GetProviderSpecificProperty(source.CloudflareCommentKey)
+
 	return &cloudFlareChange{
-		Action: action,
+		Action:     action,
+		Comment:    comment.Value,
+		SetComment: comment.Value != "",
 		ResourceRecord: cloudflare.DNSRecord{
 			Name:    endpoint.DNSName,
 			TTL:     ttl,
@@ -419,7 +492,10 @@ func shouldBeProxied(endpoint *endpoint.Endpoint, proxiedByDefault bool) bool {
 	return proxied
 }
 
-func groupByNameAndType(records []cloudflare.DNSRecord) []*endpoint.Endpoint {
+// groupByNameAndType returns the endpoints of the records, with the comments of the records by
+// record ID. The comment property is set even when empty, so that the plan updates the record
+// when a comment is added.
+func groupByNameAndType(records []cloudflare.DNSRecord, comments map[string]string) []*endpoint.Endpoint {
 	endpoints := []*endpoint.Endpoint{}
 
 	// group supported records by name and type
@@ -450,7 +526,8 @@ func groupByNameAndType(records []cloudflare.DNSRecord) []*endpoint.Endpoint {
 				records[0].Type,
 				endpoint.TTL(records[0].TTL),
 				targets...).
-				WithProviderSpecific(source.CloudflareProxiedKey, strconv.FormatBool(*records[0].Proxied)),
+				WithProviderSpecific(source.CloudflareProxiedKey, strconv.FormatBool(*records[0].Proxied)).
+				WithProviderSpecific(source.CloudflareCommentKey, comments[records[0].ID]),
 		)
 	}
 
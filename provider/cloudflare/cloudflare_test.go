@@ -36,12 +36,14 @@ type MockAction struct {
 	ZoneId     string
 	RecordId   string
 	RecordData cloudflare.DNSRecord
+	Comment    string
 }
 
 type mockCloudFlareClient struct {
 	User            cloudflare.User
 	Zones           map[string]string
 	Records         map[string]map[string]cloudflare.DNSRecord
+	Comments        map[string]string
 	Actions         []MockAction
 	listZonesError  error
 	dnsRecordsError error
@@ -115,7 +117,7 @@ func (m *mockCloudFlareClient) CreateDNSRecord(ctx context.Context, zoneID strin
 	if zone, ok := m.Records[zoneID]; ok {
 		zone[rr.ID] = rr
 	}
-	return nil, nil
+	return &cloudflare.DNSRecordResponse{Result: rr}, nil
 }
 
 func (m *mockCloudFlareClient) DNSRecords(ctx context.Context, zoneID string, rr cloudflare.DNSRecord) ([]cloudflare.DNSRecord, error) {
@@ -147,6 +149,33 @@ func (m *mockCloudFlareClient) UpdateDNSRecord(ctx context.Context, zoneID, reco
 	return nil
 }
 
+func (m *mockCloudFlareClient) UpdateDNSRecordComment(ctx context.Context, zoneID, recordID, comment string) error {
+	m.Actions = append(m.Actions, MockAction{
+		Name:     "Comment",
+		ZoneId:   zoneID,
+		RecordId: recordID,
+		Comment:  comment,
+	})
+	if m.Comments == nil {
+		m.Comments = map[string]string{}
+	}
+	m.Comments[recordID] = comment
+	return nil
+}
+
+func (m *mockCloudFlareClient) DNSRecordComments(ctx context.Context, zoneID string) (map[string]string, error) {
+	if m.dnsRecordsError != nil {
+		return nil, m.dnsRecordsError
+	}
+	comments := map[string]string{}
+	for id := range m.Records[zoneID] {
+		if comment := m.Comments[id]; comment != "" {
+			comments[id] = comment
+		}
+	}
+	return comments, nil
+}
+
 func (m *mockCloudFlareClient) DeleteDNSRecord(ctx context.Context, zoneID, recordID string) error {
 	m.Actions = append(m.Actions, MockAction{
 		Name:     "Delete",
@@ -434,6 +463,104 @@ func TestCloudflareProxiedOverrideTrue(t *testing.T) {
 	)
 }
 
+func TestCloudflareComment(t *testing.T) {
+	endpoints := []*endpoint.Endpoint{
+		{
+			RecordType: "A",
+			DNSName:    "bar.com",
+			Targets:    endpoint.Targets{"127.0.0.1"},
+			ProviderSpecific: endpoint.ProviderSpecific{
+				endpoint.ProviderSpecificProperty{
+					Name:  "external-dns.alpha.kubernetes.io/cloudflare-proxied",
+					Value: "true",
+				},
+				endpoint.ProviderSpecificProperty{
+					Name:  "external-dns.alpha.kubernetes.io/cloudflare-comment",
+					Value: "managed by the web stack",
+				},
+			},
+		},
+	}
+
+	AssertActions(t, &CloudFlareProvider{}, endpoints, []MockAction{
+		{
+			Name:   "Create",
+			ZoneId: "001",
+			RecordData: cloudflare.DNSRecord{
+				Type:    "A",
+				Name:    "bar.com",
+				Content: "127.0.0.1",
+				TTL:     1,
+				Proxied: proxyEnabled,
+			},
+		},
+		{
+			Name:    "Comment",
+			ZoneId:  "001",
+			Comment: "managed by the web stack",
+		},
+	},
+		[]string{endpoint.RecordTypeA, endpoint.RecordTypeCNAME},
+	)
+}
+
+func TestCloudflareCommentUpdate(t *testing.T) {
+	for _, tc := range []struct {
+		Name            string
+		CurrentComment  string
+		DesiredComment  string
+		ExpectedActions []MockAction
+	}{
+		{
+			Name:           "comment added",
+			DesiredComment: "managed by the web stack",
+			ExpectedActions: []MockAction{
+				{Name: "Update", ZoneId: "001", RecordId: "1234567890", RecordData: cloudflare.DNSRecord{Type: "A", Name: "bar.com", Content: "127.0.0.1", TTL: 1, Proxied: proxyDisabled}},
+				{Name: "Comment", ZoneId: "001", RecordId: "1234567890", Comment: "managed by the web stack"},
+			},
+		},
+		{
+			Name:           "comment changed",
+			CurrentComment: "managed by the api stack",
+			DesiredComment: "managed by the web stack",
+			ExpectedActions: []MockAction{
+				{Name: "Update", ZoneId: "001", RecordId: "1234567890", RecordData: cloudflare.DNSRecord{Type: "A", Name: "bar.com", Content: "127.0.0.1", TTL: 1, Proxied: proxyDisabled}},
+				{Name: "Comment", ZoneId: "001", RecordId: "1234567890", Comment: "managed by the web stack"},
+			},
+		},
+		{
+			Name:           "comment removed",
+			CurrentComment: "managed by the web stack",
+			ExpectedActions: []MockAction{
+				{Name: "Update", ZoneId: "001", RecordId: "1234567890", RecordData: cloudflare.DNSRecord{Type: "A", Name: "bar.com", Content: "127.0.0.1", TTL: 1, Proxied: proxyDisabled}},
+				{Name: "Comment", ZoneId: "001", RecordId: "1234567890", Comment: ""},
+			},
+		},
+		{
+			Name:            "comment unchanged",
+			CurrentComment:  "managed by the web stack",
+			DesiredComment:  "managed by the web stack",
+			ExpectedActions: []MockAction{},
+		},
+	} {
+		t.Run(tc.Name, func(t *testing.T) {
+			client := NewMockCloudFlareClientWithRecords(map[string][]cloudflare.DNSRecord{
+				"001": {{ID: "1234567890", ZoneID: "001", Name: "bar.com", Type: endpoint.RecordTypeA, TTL: 1, Content: "127.0.0.1", Proxied: proxyDisabled}},
+			})
+			client.Comments = map[string]string{"1234567890": tc.CurrentComment}
+			client.Actions = []MockAction{}
+
+			desired := endpoint.NewEndpoint("bar.com", endpoint.RecordTypeA, "127.0.0.1").
+				WithProviderSpecific("external-dns.alpha.kubernetes.io/cloudflare-proxied", "false")
+			if tc.DesiredComment != "" {
+				desired = desired.WithProviderSpecific("external-dns.alpha.kubernetes.io/cloudflare-comment", tc.DesiredComment)
+			}
+
+			AssertActions(t, &CloudFlareProvider{Client: client}, []*endpoint.Endpoint{desired}, tc.ExpectedActions, []string{endpoint.RecordTypeA})
+		})
+	}
+}
+
 func TestCloudflareProxiedOverrideFalse(t *testing.T) {
 	endpoints := []*endpoint.Endpoint{
 		{
@@ -799,6 +926,10 @@ func TestCloudflareGroupByNameAndType(t *testing.T) {
 							Name:  "external-dns.alpha.kubernetes.io/cloudflare-proxied",
 							Value: "false",
 						},
+						{
+							Name:  "external-dns.alpha.kubernetes.io/cloudflare-comment",
+							Value: "",
+						},
 					},
 				},
 			},
@@ -833,6 +964,10 @@ func TestCloudflareGroupByNameAndType(t *testing.T) {
 							Name:  "external-dns.alpha.kubernetes.io/cloudflare-proxied",
 							Value: "false",
 						},
+						{
+							Name:  "external-dns.alpha.kubernetes.io/cloudflare-comment",
+							Value: "",
+						},
 					},
 				},
 			},
@@ -881,6 +1016,10 @@ func TestCloudflareGroupByNameAndType(t *testing.T) {
 							Name:  "external-dns.alpha.kubernetes.io/cloudflare-proxied",
 							Value: "false",
 						},
+						{
+							Name:  "external-dns.alpha.kubernetes.io/cloudflare-comment",
+							Value: "",
+						},
 					},
 				},
 				{
@@ -894,6 +1033,10 @@ func TestCloudflareGroupByNameAndType(t *testing.T) {
 							Name:  "external-dns.alpha.kubernetes.io/cloudflare-proxied",
 							Value: "false",
 						},
+						{
+							Name:  "external-dns.alpha.kubernetes.io/cloudflare-comment",
+							Value: "",
+						},
 					},
 				},
 			},
@@ -935,6 +1078,10 @@ func TestCloudflareGroupByNameAndType(t *testing.T) {
 							Name:  "external-dns.alpha.kubernetes.io/cloudflare-proxied",
 							Value: "false",
 						},
+						{
+							Name:  "external-dns.alpha.kubernetes.io/cloudflare-comment",
+							Value: "",
+						},
 					},
 				},
 				{
@@ -948,6 +1095,10 @@ func TestCloudflareGroupByNameAndType(t *testing.T) {
 							Name:  "external-dns.alpha.kubernetes.io/cloudflare-proxied",
 							Value: "false",
 						},
+						{
+							Name:  "external-dns.alpha.kubernetes.io/cloudflare-comment",
+							Value: "",
+						},
 					},
 				},
 			},
@@ -989,6 +1140,10 @@ func TestCloudflareGroupByNameAndType(t *testing.T) {
 							Name:  "external-dns.alpha.kubernetes.io/cloudflare-proxied",
 							Value: "false",
 						},
+						{
+							Name:  "external-dns.alpha.kubernetes.io/cloudflare-comment",
+							Value: "",
+						},
 					},
 				},
 			},
@@ -996,7 +1151,7 @@ func TestCloudflareGroupByNameAndType(t *testing.T) {
 	}
 
 	for _, tc := range testCases {
-		assert.ElementsMatch(t, groupByNameAndType(tc.Records), tc.ExpectedEndpoints)
+		assert.ElementsMatch(t, groupByNameAndType(tc.Records, nil), tc.ExpectedEndpoints)
 	}
 }
 
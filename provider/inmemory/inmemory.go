@@ -19,6 +19,7 @@ package inmemory
 import (
 	"context"
 	"errors"
+	"fmt"
 	"strings"
 
 	log "github.com/sirupsen/logrus"
@@ -150,6 +151,28 @@ func (im *InMemoryProvider) Records(ctx context.Context) ([]*endpoint.Endpoint,
 func (im *InMemoryProvider) ApplyChanges(ctx context.Context, changes *plan.Changes) error {
 	defer im.OnApplyChanges(ctx, changes)
 
+	for zoneID, change := range im.perZoneChanges(changes) {
+		err := im.client.ApplyChanges(ctx, zoneID, change)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ValidateChanges runs the error checking of ApplyChanges without modifying the records
+func (im *InMemoryProvider) ValidateChanges(ctx context.Context, changes *plan.Changes) error {
+	for zoneID, change := range im.perZoneChanges(changes) {
+		if err := im.client.validateChangeBatch(zoneID, change); err != nil {
+			return fmt.Errorf("invalid changes of zone %s: %w", zoneID, err)
+		}
+	}
+	return nil
+}
+
+// perZoneChanges splits the changes by zone, dropping the changes of records outside of the zones
+func (im *InMemoryProvider) perZoneChanges(changes *plan.Changes) map[string]*inMemoryChange {
 	perZoneChanges := map[string]*plan.Changes{}
 
 	zones := im.Zones()
@@ -186,20 +209,16 @@ func (im *InMemoryProvider) ApplyChanges(ctx context.Context, changes *plan.Chan
 		perZoneChanges[zoneID].Delete = append(perZoneChanges[zoneID].Delete, ep)
 	}
 
+	result := map[string]*inMemoryChange{}
 	for zoneID := range perZoneChanges {
-		change := &inMemoryChange{
+		result[zoneID] = &inMemoryChange{
 			Create:    convertToInMemoryRecord(perZoneChanges[zoneID].Create),
 			UpdateNew: convertToInMemoryRecord(perZoneChanges[zoneID].UpdateNew),
 			UpdateOld: convertToInMemoryRecord(perZoneChanges[zoneID].UpdateOld),
 			Delete:    convertToInMemoryRecord(perZoneChanges[zoneID].Delete),
 		}
-		err := im.client.ApplyChanges(ctx, zoneID, change)
-		if err != nil {
-			return err
-		}
 	}
-
-	return nil
+	return result
 }
 
 func convertToInMemoryRecord(endpoints []*endpoint.Endpoint) []*inMemoryRecord {
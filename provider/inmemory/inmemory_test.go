@@ -30,7 +30,8 @@ import (
 )
 
 var (
-	_ provider.Provider = &InMemoryProvider{}
+	_ provider.Provider         = &InMemoryProvider{}
+	_ provider.ChangesValidator = &InMemoryProvider{}
 )
 
 func TestInMemoryProvider(t *testing.T) {
@@ -38,6 +39,7 @@ func TestInMemoryProvider(t *testing.T) {
 	t.Run("Records", testInMemoryRecords)
 	t.Run("validateChangeBatch", testInMemoryValidateChangeBatch)
 	t.Run("ApplyChanges", testInMemoryApplyChanges)
+	t.Run("ValidateChanges", testInMemoryValidateChanges)
 	t.Run("NewInMemoryProvider", testNewInMemoryProvider)
 	t.Run("CreateZone", testInMemoryCreateZone)
 }
@@ -821,3 +823,18 @@ func testInMemoryCreateZone(t *testing.T) {
 	err = im.CreateZone("zone")
 	assert.EqualError(t, err, ErrZoneAlreadyExists.Error())
 }
+
+func testInMemoryValidateChanges(t *testing.T) {
+	im := NewInMemoryProvider(InMemoryInitZones([]string{"example.org"}))
+	ctx := context.Background()
+	create := &plan.Changes{Create: []*endpoint.Endpoint{endpoint.NewEndpoint("foo.example.org", endpoint.RecordTypeA, "1.2.3.4")}}
+
+	require.NoError(t, im.ValidateChanges(ctx, create))
+	records, err := im.Records(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, records, "expected the validated changes not to be applied")
+
+	require.NoError(t, im.ApplyChanges(ctx, create))
+	err = im.ValidateChanges(ctx, create)
+	assert.ErrorIs(t, err, ErrRecordAlreadyExists)
+}
@@ -0,0 +1,194 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package knot
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"time"
+)
+
+// The control protocol of Knot DNS, spoken by knotc over the control socket: a message is a
+// type code followed, for the data messages, by items made of a code, a big endian 16 bits
+// length and the value. A command is a data message followed by a block message, and its
+// response the data messages sent by the server up to a block message.
+const (
+	ctlTypeEnd   byte = 0
+	ctlTypeData  byte = 1
+	ctlTypeExtra byte = 2
+	ctlTypeBlock byte = 3
+
+	// ctlDataCodeOffset is the offset of the codes of the items, which follow the type codes
+	ctlDataCodeOffset byte = 0x10
+)
+
+// The items of the data messages
+const (
+	ctlIdxCommand byte = iota
+	ctlIdxFlags
+	ctlIdxError
+	ctlIdxSection
+	ctlIdxItem
+	ctlIdxID
+	ctlIdxZone
+	ctlIdxOwner
+	ctlIdxTTL
+	ctlIdxType
+	ctlIdxData
+	ctlIdxFilter
+	ctlIdxCount
+)
+
+// ctlData are the items of a data message, indexed by their ctlIdx.
+type ctlData map[byte]string
+
+// ctlConn is a session on the control socket of Knot DNS.
+type ctlConn struct {
+	conn net.Conn
+	r    *bufio.Reader
+	w    *bufio.Writer
+}
+
+func dialCtl(socketPath string, timeout time.Duration) (*ctlConn, error) {
+	conn, err := net.DialTimeout("unix", socketPath, timeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to the Knot DNS control socket: %w", err)
+	}
+	if err := conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return newCtlConn(conn), nil
+}
+
+func newCtlConn(conn net.Conn) *ctlConn {
+	return &ctlConn{conn: conn, r: bufio.NewReader(conn), w: bufio.NewWriter(conn)}
+}
+
+// command runs a command and returns its response, failing with the error reported by the server.
+// The items missing in the extra messages of the response are those of the previous message.
+func (c *ctlConn) command(command string, data ctlData) ([]ctlData, error) {
+	request := ctlData{ctlIdxCommand: command}
+	for idx, value := range data {
+		request[idx] = value
+	}
+	if err := c.send(ctlTypeData, request); err != nil {
+		return nil, err
+	}
+	if err := c.send(ctlTypeBlock, nil); err != nil {
+		return nil, err
+	}
+	if err := c.w.Flush(); err != nil {
+		return nil, err
+	}
+
+	var response []ctlData
+	var previous ctlData
+	for {
+		typ, data, err := c.receive()
+		if err != nil {
+			return nil, err
+		}
+		switch typ {
+		case ctlTypeBlock:
+			for _, data := range response {
+				if msg, ok := data[ctlIdxError]; ok {
+					return nil, fmt.Errorf("knot: %s failed: %s", command, msg)
+				}
+			}
+			return response, nil
+		case ctlTypeEnd:
+			return nil, errors.New("knot: the control session was closed by the server")
+		case ctlTypeExtra:
+			for idx, value := range previous {
+				if _, ok := data[idx]; !ok && idx != ctlIdxError {
+					data[idx] = value
+				}
+			}
+		}
+		response = append(response, data)
+		previous = data
+	}
+}
+
+// Close ends the session.
+func (c *ctlConn) Close() error {
+	if err := c.send(ctlTypeEnd, nil); err == nil {
+		c.w.Flush()
+	}
+	return c.conn.Close()
+}
+
+func (c *ctlConn) send(typ byte, data ctlData) error {
+	if err := c.w.WriteByte(typ); err != nil {
+		return err
+	}
+	for idx := byte(0); idx < ctlIdxCount; idx++ {
+		value, ok := data[idx]
+		if !ok {
+			continue
+		}
+		if len(value) > 0xffff {
+			return fmt.Errorf("knot: control item of %d bytes too long", len(value))
+		}
+		c.w.WriteByte(ctlDataCodeOffset + idx)
+		binary.Write(c.w, binary.BigEndian, uint16(len(value)))
+		if _, err := c.w.WriteString(value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *ctlConn) receive() (byte, ctlData, error) {
+	typ, err := c.r.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+	if typ >= ctlDataCodeOffset {
+		return 0, nil, fmt.Errorf("knot: unexpected control item %#x", typ)
+	}
+	if typ != ctlTypeData && typ != ctlTypeExtra {
+		return typ, nil, nil
+	}
+
+	data := ctlData{}
+	for {
+		// the items of a message last until the type code of the next message
+		next, err := c.r.Peek(1)
+		if err != nil {
+			return 0, nil, err
+		}
+		if next[0] < ctlDataCodeOffset {
+			return typ, data, nil
+		}
+		code, _ := c.r.ReadByte()
+		var length uint16
+		if err := binary.Read(c.r, binary.BigEndian, &length); err != nil {
+			return 0, nil, err
+		}
+		value := make([]byte, length)
+		if _, err := io.ReadFull(c.r, value); err != nil {
+			return 0, nil, err
+		}
+		data[code-ctlDataCodeOffset] = string(value)
+	}
+}
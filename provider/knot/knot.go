@@ -0,0 +1,287 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package knot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+	"sigs.k8s.io/external-dns/provider"
+)
+
+// supportedRecordTypes are the record types managed by the provider, with whether their
+// data ends with a domain name, which Knot DNS would otherwise consider relative to the zone.
+var supportedRecordTypes = map[string]bool{
+	endpoint.RecordTypeA:     false,
+	"AAAA":                   false,
+	endpoint.RecordTypeTXT:   false,
+	endpoint.RecordTypeCNAME: true,
+	endpoint.RecordTypeNS:    true,
+	endpoint.RecordTypeSRV:   true,
+	endpoint.RecordTypeMX:    true,
+}
+
+// KnotConfig is the configuration of the Knot DNS provider.
+type KnotConfig struct {
+	// SocketPath is the control socket of the server
+	SocketPath string
+	// Zones are the zones managed by the provider, all the zones of the server when empty
+	Zones        []string
+	DomainFilter endpoint.DomainFilter
+	Timeout      time.Duration
+	DryRun       bool
+}
+
+// KnotProvider is an implementation of Provider for Knot DNS, updating the zones through
+// transactions on the control socket of the server.
+type KnotProvider struct {
+	provider.BaseProvider
+
+	config KnotConfig
+	dial   func() (*ctlConn, error)
+}
+
+// NewKnotProvider creates a new Knot DNS provider.
+func NewKnotProvider(config KnotConfig) (*KnotProvider, error) {
+	if config.SocketPath == "" {
+		return nil, errors.New("no Knot DNS control socket specified")
+	}
+
+	return &KnotProvider{
+		config: config,
+		dial: func() (*ctlConn, error) {
+			return dialCtl(config.SocketPath, config.Timeout)
+		},
+	}, nil
+}
+
+// Records returns the records of the managed zones.
+func (p *KnotProvider) Records(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	conn, err := p.dial()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	zones, err := p.zones(conn)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoints := []*endpoint.Endpoint{}
+	byKey := map[string]*endpoint.Endpoint{}
+	for _, zone := range zones {
+		rrs, err := conn.command("zone-read", ctlData{ctlIdxZone: zone})
+		if err != nil {
+			return nil, err
+		}
+		for _, rr := range rrs {
+			recordType := rr[ctlIdxType]
+			name := strings.TrimSuffix(rr[ctlIdxOwner], ".")
+			if _, ok := supportedRecordTypes[recordType]; !ok || !p.config.DomainFilter.Match(name) {
+				continue
+			}
+			target := fromKnotData(recordType, rr[ctlIdxData])
+
+			key := strings.ToLower(name) + "::" + recordType
+			if ep, ok := byKey[key]; ok {
+				ep.Targets = append(ep.Targets, target)
+				continue
+			}
+			ttl, _ := strconv.ParseInt(rr[ctlIdxTTL], 10, 64)
+			ep := endpoint.NewEndpointWithTTL(name, recordType, endpoint.TTL(ttl), target)
+			byKey[key] = ep
+			endpoints = append(endpoints, ep)
+		}
+	}
+
+	return endpoints, nil
+}
+
+// ApplyChanges applies the changes in a transaction per zone, aborted when any of its changes fails.
+func (p *KnotProvider) ApplyChanges(ctx context.Context, changes *plan.Changes) error {
+	conn, err := p.dial()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	zones, err := p.zones(conn)
+	if err != nil {
+		return err
+	}
+	zoneNames := provider.ZoneIDName{}
+	for _, zone := range zones {
+		zoneNames.Add(zone, strings.TrimSuffix(zone, "."))
+	}
+
+	type zoneChanges struct {
+		unset []ctlData
+		set   []ctlData
+	}
+	byZone := map[string]*zoneChanges{}
+	add := func(ep *endpoint.Endpoint, set bool) {
+		zone, _ := zoneNames.FindZone(strings.TrimSuffix(ep.DNSName, "."))
+		if zone == "" {
+			log.Warnf("No zone found for %s, skipping it", ep.DNSName)
+			return
+		}
+		if byZone[zone] == nil {
+			byZone[zone] = &zoneChanges{}
+		}
+		for _, target := range ep.Targets {
+			data := ctlData{
+				ctlIdxZone:  zone,
+				ctlIdxOwner: fqdn(ep.DNSName),
+				ctlIdxType:  ep.RecordType,
+				ctlIdxData:  toKnotData(ep.RecordType, target),
+			}
+			if set {
+				if ep.RecordTTL.IsConfigured() {
+					data[ctlIdxTTL] = strconv.FormatInt(int64(ep.RecordTTL), 10)
+				}
+				byZone[zone].set = append(byZone[zone].set, data)
+			} else {
+				byZone[zone].unset = append(byZone[zone].unset, data)
+			}
+		}
+	}
+	for _, ep := range changes.Delete {
+		add(ep, false)
+	}
+	for _, ep := range changes.UpdateOld {
+		add(ep, false)
+	}
+	for _, ep := range changes.Create {
+		add(ep, true)
+	}
+	for _, ep := range changes.UpdateNew {
+		add(ep, true)
+	}
+
+	sorted := make([]string, 0, len(byZone))
+	for zone := range byZone {
+		sorted = append(sorted, zone)
+	}
+	sort.Strings(sorted)
+
+	for _, zone := range sorted {
+		zc := byZone[zone]
+		for _, data := range zc.unset {
+			log.Infof("Removing %s %s %s from zone %s", data[ctlIdxOwner], data[ctlIdxType], data[ctlIdxData], zone)
+		}
+		for _, data := range zc.set {
+			log.Infof("Adding %s %s %s to zone %s", data[ctlIdxOwner], data[ctlIdxType], data[ctlIdxData], zone)
+		}
+		if p.config.DryRun {
+			continue
+		}
+		if err := p.transaction(conn, zone, zc.unset, zc.set); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// transaction applies the changes to a zone atomically.
+func (p *KnotProvider) transaction(conn *ctlConn, zone string, unset, set []ctlData) error {
+	if _, err := conn.command("zone-begin", ctlData{ctlIdxZone: zone}); err != nil {
+		return err
+	}
+	apply := func() error {
+		for _, data := range unset {
+			if _, err := conn.command("zone-unset", data); err != nil {
+				return err
+			}
+		}
+		for _, data := range set {
+			if _, err := conn.command("zone-set", data); err != nil {
+				return err
+			}
+		}
+		_, err := conn.command("zone-commit", ctlData{ctlIdxZone: zone})
+		return err
+	}
+	if err := apply(); err != nil {
+		if _, abortErr := conn.command("zone-abort", ctlData{ctlIdxZone: zone}); abortErr != nil {
+			log.Errorf("Failed to abort the transaction of zone %s: %v", zone, abortErr)
+		}
+		return fmt.Errorf("failed to update zone %s: %w", zone, err)
+	}
+	return nil
+}
+
+// zones returns the managed zones, as fully qualified names.
+func (p *KnotProvider) zones(conn *ctlConn) ([]string, error) {
+	if len(p.config.Zones) > 0 {
+		zones := make([]string, 0, len(p.config.Zones))
+		for _, zone := range p.config.Zones {
+			zones = append(zones, fqdn(zone))
+		}
+		return zones, nil
+	}
+
+	statuses, err := conn.command("zone-status", nil)
+	if err != nil {
+		return nil, err
+	}
+	seen := map[string]bool{}
+	var zones []string
+	for _, status := range statuses {
+		zone := status[ctlIdxZone]
+		if zone == "" || seen[zone] {
+			continue
+		}
+		seen[zone] = true
+		zones = append(zones, zone)
+	}
+	return zones, nil
+}
+
+// toKnotData returns the data of a record in the presentation format of Knot DNS.
+func toKnotData(recordType, target string) string {
+	if supportedRecordTypes[recordType] {
+		return fqdn(target)
+	}
+	if recordType == endpoint.RecordTypeTXT && !strings.HasPrefix(target, `"`) {
+		return strconv.Quote(target)
+	}
+	return target
+}
+
+func fromKnotData(recordType, data string) string {
+	if supportedRecordTypes[recordType] && data != "." {
+		return strings.TrimSuffix(data, ".")
+	}
+	return data
+}
+
+func fqdn(name string) string {
+	if strings.HasSuffix(name, ".") {
+		return name
+	}
+	return name + "."
+}
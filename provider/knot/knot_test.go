@@ -0,0 +1,232 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package knot
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"net"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+)
+
+type fakeRR struct {
+	owner, ttl, recordType, data string
+}
+
+// fakeKnot serves the zone commands of the control socket of Knot DNS.
+type fakeKnot struct {
+	sync.Mutex
+	zones    map[string][]fakeRR
+	commands []string
+}
+
+func (f *fakeKnot) serve(conn net.Conn) {
+	defer conn.Close()
+	c := newCtlConn(conn)
+	var txn map[string][]fakeRR
+
+	for {
+		typ, data, err := c.receive()
+		if err != nil || typ == ctlTypeEnd {
+			return
+		}
+		if typ != ctlTypeData {
+			continue
+		}
+
+		f.Lock()
+		command, zone := data[ctlIdxCommand], data[ctlIdxZone]
+		f.commands = append(f.commands, command)
+		var response []ctlData
+		switch command {
+		case "zone-status":
+			for zone := range f.zones {
+				response = append(response, ctlData{ctlIdxZone: zone, ctlIdxType: "serial", ctlIdxData: "1"})
+			}
+		case "zone-read":
+			for i, rr := range f.zones[zone] {
+				item := ctlData{ctlIdxZone: zone, ctlIdxOwner: rr.owner, ctlIdxTTL: rr.ttl, ctlIdxType: rr.recordType, ctlIdxData: rr.data}
+				if i > 0 && f.zones[zone][i-1].owner == rr.owner && f.zones[zone][i-1].recordType == rr.recordType {
+					item = ctlData{ctlIdxData: rr.data}
+				}
+				response = append(response, item)
+			}
+		case "zone-begin":
+			txn = map[string][]fakeRR{zone: append([]fakeRR{}, f.zones[zone]...)}
+		case "zone-set":
+			txn[zone] = append(txn[zone], fakeRR{owner: data[ctlIdxOwner], ttl: data[ctlIdxTTL], recordType: data[ctlIdxType], data: data[ctlIdxData]})
+		case "zone-unset":
+			found := false
+			for i, rr := range txn[zone] {
+				if rr.owner == data[ctlIdxOwner] && rr.recordType == data[ctlIdxType] && rr.data == data[ctlIdxData] {
+					txn[zone] = append(txn[zone][:i], txn[zone][i+1:]...)
+					found = true
+					break
+				}
+			}
+			if !found {
+				response = append(response, ctlData{ctlIdxZone: zone, ctlIdxError: "no such record in zone found"})
+			}
+		case "zone-commit":
+			f.zones[zone] = txn[zone]
+			txn = nil
+		case "zone-abort":
+			txn = nil
+		}
+		f.Unlock()
+
+		for i, item := range response {
+			typ := ctlTypeData
+			if i > 0 {
+				typ = ctlTypeExtra
+			}
+			c.send(typ, item)
+		}
+		c.send(ctlTypeBlock, nil)
+		c.w.Flush()
+	}
+}
+
+func newTestProvider(t *testing.T, zones map[string][]fakeRR, zoneFilter []string) (*KnotProvider, *fakeKnot) {
+	knot := &fakeKnot{zones: zones}
+	socket := filepath.Join(t.TempDir(), "knot.sock")
+	listener, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go knot.serve(conn)
+		}
+	}()
+
+	p, err := NewKnotProvider(KnotConfig{SocketPath: socket, Zones: zoneFilter, Timeout: 5 * time.Second})
+	require.NoError(t, err)
+	return p, knot
+}
+
+func TestCtlEncoding(t *testing.T) {
+	var b bytes.Buffer
+	c := &ctlConn{w: bufio.NewWriter(&b)}
+	require.NoError(t, c.send(ctlTypeData, ctlData{ctlIdxZone: "a.", ctlIdxCommand: "zone-read"}))
+	require.NoError(t, c.send(ctlTypeBlock, nil))
+	require.NoError(t, c.w.Flush())
+
+	encoded := append(append([]byte{0x01, 0x10, 0x00, 0x09}, "zone-read"...), 0x16, 0x00, 0x02, 'a', '.', 0x03)
+	assert.Equal(t, encoded, b.Bytes())
+
+	c = &ctlConn{r: bufio.NewReader(bytes.NewReader(encoded))}
+	typ, data, err := c.receive()
+	require.NoError(t, err)
+	assert.Equal(t, ctlTypeData, typ)
+	assert.Equal(t, ctlData{ctlIdxCommand: "zone-read", ctlIdxZone: "a."}, data)
+	typ, _, err = c.receive()
+	require.NoError(t, err)
+	assert.Equal(t, ctlTypeBlock, typ)
+}
+
+func TestKnotRecords(t *testing.T) {
+	p, _ := newTestProvider(t, map[string][]fakeRR{
+		"example.org.": {
+			{owner: "example.org.", ttl: "3600", recordType: "SOA", data: "ns.example.org. hostmaster.example.org. 1 3600 600 86400 300"},
+			{owner: "example.org.", ttl: "3600", recordType: "NS", data: "ns.example.org."},
+			{owner: "www.example.org.", ttl: "300", recordType: "A", data: "192.0.2.1"},
+			{owner: "www.example.org.", ttl: "300", recordType: "A", data: "192.0.2.2"},
+			{owner: "www.example.org.", ttl: "300", recordType: "TXT", data: "\"heritage=external-dns,external-dns/owner=default\""},
+			{owner: "_http._tcp.example.org.", ttl: "300", recordType: "SRV", data: "10 5 80 www.example.org."},
+		},
+		"example.com.": {
+			{owner: "app.example.com.", ttl: "60", recordType: "CNAME", data: "www.example.org."},
+		},
+	}, []string{"example.org"})
+
+	records, err := p.Records(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []*endpoint.Endpoint{
+		endpoint.NewEndpointWithTTL("example.org", endpoint.RecordTypeNS, 3600, "ns.example.org"),
+		endpoint.NewEndpointWithTTL("www.example.org", endpoint.RecordTypeA, 300, "192.0.2.1", "192.0.2.2"),
+		endpoint.NewEndpointWithTTL("www.example.org", endpoint.RecordTypeTXT, 300, "\"heritage=external-dns,external-dns/owner=default\""),
+		endpoint.NewEndpointWithTTL("_http._tcp.example.org", endpoint.RecordTypeSRV, 300, "10 5 80 www.example.org"),
+	}, records)
+}
+
+func TestKnotApplyChanges(t *testing.T) {
+	p, knot := newTestProvider(t, map[string][]fakeRR{
+		"example.org.": {
+			{owner: "www.example.org.", ttl: "300", recordType: "A", data: "192.0.2.1"},
+			{owner: "old.example.org.", ttl: "300", recordType: "CNAME", data: "www.example.org."},
+		},
+		"sub.example.org.": {},
+	}, nil)
+
+	err := p.ApplyChanges(context.Background(), &plan.Changes{
+		Create: []*endpoint.Endpoint{
+			endpoint.NewEndpointWithTTL("app.sub.example.org", endpoint.RecordTypeCNAME, 60, "www.example.org"),
+			endpoint.NewEndpoint("app.sub.example.org", endpoint.RecordTypeTXT, "heritage=external-dns"),
+			endpoint.NewEndpoint("www.example.net", endpoint.RecordTypeA, "192.0.2.9"),
+		},
+		UpdateOld: []*endpoint.Endpoint{endpoint.NewEndpointWithTTL("www.example.org", endpoint.RecordTypeA, 300, "192.0.2.1")},
+		UpdateNew: []*endpoint.Endpoint{endpoint.NewEndpointWithTTL("www.example.org", endpoint.RecordTypeA, 600, "192.0.2.2")},
+		Delete:    []*endpoint.Endpoint{endpoint.NewEndpoint("old.example.org", endpoint.RecordTypeCNAME, "www.example.org")},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []fakeRR{{owner: "www.example.org.", ttl: "600", recordType: "A", data: "192.0.2.2"}}, knot.zones["example.org."])
+	assert.Equal(t, []fakeRR{
+		{owner: "app.sub.example.org.", ttl: "60", recordType: "CNAME", data: "www.example.org."},
+		{owner: "app.sub.example.org.", recordType: "TXT", data: "\"heritage=external-dns\""},
+	}, knot.zones["sub.example.org."])
+}
+
+func TestKnotApplyChangesAbort(t *testing.T) {
+	zone := []fakeRR{{owner: "www.example.org.", ttl: "300", recordType: "A", data: "192.0.2.1"}}
+	p, knot := newTestProvider(t, map[string][]fakeRR{"example.org.": zone}, nil)
+
+	err := p.ApplyChanges(context.Background(), &plan.Changes{
+		Create: []*endpoint.Endpoint{endpoint.NewEndpoint("new.example.org", endpoint.RecordTypeA, "192.0.2.2")},
+		Delete: []*endpoint.Endpoint{endpoint.NewEndpoint("missing.example.org", endpoint.RecordTypeA, "192.0.2.3")},
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no such record")
+	assert.Equal(t, zone, knot.zones["example.org."])
+	assert.Equal(t, []string{"zone-status", "zone-begin", "zone-unset", "zone-abort"}, knot.commands)
+}
+
+func TestKnotDryRun(t *testing.T) {
+	zone := []fakeRR{{owner: "www.example.org.", ttl: "300", recordType: "A", data: "192.0.2.1"}}
+	p, knot := newTestProvider(t, map[string][]fakeRR{"example.org.": zone}, nil)
+	p.config.DryRun = true
+
+	err := p.ApplyChanges(context.Background(), &plan.Changes{
+		Delete: []*endpoint.Endpoint{endpoint.NewEndpoint("www.example.org", endpoint.RecordTypeA, "192.0.2.1")},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, zone, knot.zones["example.org."])
+}
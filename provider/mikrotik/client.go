@@ -0,0 +1,170 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mikrotik
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// staticDNSPath is the path of the static DNS entries in the RouterOS REST API
+const staticDNSPath = "/ip/dns/static"
+
+// staticDNSEntry is a static DNS entry of RouterOS. The REST API encodes all the
+// values as strings, and omits the type of the A entries.
+type staticDNSEntry struct {
+	ID       string `json:".id,omitempty"`
+	Name     string `json:"name,omitempty"`
+	Regexp   string `json:"regexp,omitempty"`
+	Type     string `json:"type,omitempty"`
+	Address  string `json:"address,omitempty"`
+	CName    string `json:"cname,omitempty"`
+	Text     string `json:"text,omitempty"`
+	TTL      string `json:"ttl,omitempty"`
+	Comment  string `json:"comment,omitempty"`
+	Disabled string `json:"disabled,omitempty"`
+}
+
+// routerOSError is the body of the responses of the RouterOS REST API to failed requests
+type routerOSError struct {
+	Error   int    `json:"error"`
+	Message string `json:"message"`
+	Detail  string `json:"detail"`
+}
+
+// routerOSClient calls the REST API of a RouterOS device, available since RouterOS 7.1.
+type routerOSClient struct {
+	baseURL  *url.URL
+	username string
+	password string
+	client   *http.Client
+}
+
+func (c *routerOSClient) listStaticDNS(ctx context.Context) ([]staticDNSEntry, error) {
+	var entries []staticDNSEntry
+	if err := c.call(ctx, http.MethodGet, staticDNSPath, nil, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func (c *routerOSClient) createStaticDNS(ctx context.Context, entry staticDNSEntry) error {
+	// RouterOS creates entries with PUT and updates them with PATCH
+	return c.call(ctx, http.MethodPut, staticDNSPath, entry, nil)
+}
+
+func (c *routerOSClient) updateStaticDNS(ctx context.Context, id string, entry staticDNSEntry) error {
+	return c.call(ctx, http.MethodPatch, staticDNSPath+"/"+id, entry, nil)
+}
+
+func (c *routerOSClient) deleteStaticDNS(ctx context.Context, id string) error {
+	return c.call(ctx, http.MethodDelete, staticDNSPath+"/"+id, nil, nil)
+}
+
+func (c *routerOSClient) call(ctx context.Context, method, path string, reqBody, resBody interface{}) error {
+	u := *c.baseURL
+	u.Path = strings.TrimSuffix(u.Path, "/") + path
+
+	var body io.Reader
+	if reqBody != nil {
+		b, err := json.Marshal(reqBody)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(c.username, c.password)
+	req.Header.Set("Accept", "application/json")
+	if reqBody != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call the RouterOS API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var apiErr routerOSError
+		if err := json.NewDecoder(resp.Body).Decode(&apiErr); err == nil && apiErr.Detail != "" {
+			return fmt.Errorf("RouterOS API %s %s failed: %s: %s", method, path, resp.Status, apiErr.Detail)
+		}
+		return fmt.Errorf("RouterOS API %s %s failed: %s", method, path, resp.Status)
+	}
+	if resBody == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(resBody)
+}
+
+// parseTTL parses a RouterOS time interval, e.g. "1d2h3m4s", "5m", "00:05:00" or
+// "1d00:00:10", into a number of seconds.
+func parseTTL(s string) (int64, error) {
+	interval := s
+	var days int64
+	for _, unit := range []struct {
+		suffix string
+		days   int64
+	}{{"w", 7}, {"d", 1}} {
+		if n, rest, ok := strings.Cut(interval, unit.suffix); ok {
+			v, err := strconv.ParseInt(n, 10, 64)
+			if err != nil {
+				return 0, fmt.Errorf("invalid time interval %q", s)
+			}
+			days += v * unit.days
+			interval = rest
+		}
+	}
+	seconds := days * 24 * 60 * 60
+
+	if strings.Contains(interval, ":") {
+		parts := strings.Split(interval, ":")
+		if len(parts) != 3 {
+			return 0, fmt.Errorf("invalid time interval %q", s)
+		}
+		var total int64
+		for _, part := range parts {
+			n, err := strconv.ParseInt(part, 10, 64)
+			if err != nil {
+				return 0, fmt.Errorf("invalid time interval %q", s)
+			}
+			total = total*60 + n
+		}
+		return seconds + total, nil
+	}
+	if interval != "" {
+		d, err := time.ParseDuration(interval)
+		if err != nil {
+			return 0, fmt.Errorf("invalid time interval %q", s)
+		}
+		seconds += int64(d / time.Second)
+	}
+	return seconds, nil
+}
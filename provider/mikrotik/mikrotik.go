@@ -0,0 +1,307 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mikrotik
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+	"sigs.k8s.io/external-dns/provider"
+)
+
+const (
+	// entryComment marks the static DNS entries created by ExternalDNS for the administrators of the device
+	entryComment = "managed by external-dns"
+
+	defaultTimeout = 30 * time.Second
+)
+
+// supportedRecordTypes are the record types of the static DNS entries managed by the provider
+var supportedRecordTypes = map[string]bool{
+	endpoint.RecordTypeA:     true,
+	"AAAA":                   true,
+	endpoint.RecordTypeCNAME: true,
+	endpoint.RecordTypeTXT:   true,
+}
+
+// MikroTikConfig is the configuration of the MikroTik provider.
+type MikroTikConfig struct {
+	// BaseURL is the URL of the REST API of the device, e.g. https://192.168.88.1/rest
+	BaseURL       string
+	Username      string
+	Password      string
+	SkipTLSVerify bool
+	DomainFilter  endpoint.DomainFilter
+	DryRun        bool
+}
+
+// MikroTikProvider is an implementation of Provider managing the static DNS entries
+// of a MikroTik RouterOS device through its REST API.
+type MikroTikProvider struct {
+	provider.BaseProvider
+
+	client       *routerOSClient
+	domainFilter endpoint.DomainFilter
+	dryRun       bool
+}
+
+// NewMikroTikProvider creates a new MikroTik provider.
+func NewMikroTikProvider(config MikroTikConfig) (*MikroTikProvider, error) {
+	if config.BaseURL == "" {
+		return nil, errors.New("no MikroTik REST API url specified")
+	}
+	u, err := url.Parse(config.BaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid MikroTik REST API url %q: %w", config.BaseURL, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid MikroTik REST API url %q: scheme must be http or https", config.BaseURL)
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if config.SkipTLSVerify {
+		// RouterOS devices commonly serve the REST API with a self-signed certificate
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true} // #nosec G402
+	}
+
+	return &MikroTikProvider{
+		client: &routerOSClient{
+			baseURL:  u,
+			username: config.Username,
+			password: config.Password,
+			client:   &http.Client{Timeout: defaultTimeout, Transport: transport},
+		},
+		domainFilter: config.DomainFilter,
+		dryRun:       config.DryRun,
+	}, nil
+}
+
+// Records returns the static DNS entries of the device as endpoints, one per name and record type.
+func (p *MikroTikProvider) Records(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	entries, err := p.entries(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoints := []*endpoint.Endpoint{}
+	byKey := map[string]*endpoint.Endpoint{}
+	for _, entry := range entries {
+		key := entryKey(entry.Name, entryType(entry))
+		if ep, ok := byKey[key]; ok {
+			ep.Targets = append(ep.Targets, entryTarget(entry))
+			continue
+		}
+
+		var ttl int64
+		if entry.TTL != "" {
+			if ttl, err = parseTTL(entry.TTL); err != nil {
+				log.Warnf("Ignoring the TTL of the static DNS entry %s: %v", entry.Name, err)
+			}
+		}
+		ep := endpoint.NewEndpointWithTTL(entry.Name, entryType(entry), endpoint.TTL(ttl), entryTarget(entry))
+		byKey[key] = ep
+		endpoints = append(endpoints, ep)
+	}
+
+	return endpoints, nil
+}
+
+// ApplyChanges applies the given changes to the static DNS entries of the device. The updates
+// are applied against the entries found on the device, each target being a separate entry.
+func (p *MikroTikProvider) ApplyChanges(ctx context.Context, changes *plan.Changes) error {
+	entries, err := p.entries(ctx)
+	if err != nil {
+		return err
+	}
+	byKey := map[string][]staticDNSEntry{}
+	for _, entry := range entries {
+		key := entryKey(entry.Name, entryType(entry))
+		byKey[key] = append(byKey[key], entry)
+	}
+
+	for _, ep := range changes.Delete {
+		for _, entry := range byKey[entryKey(ep.DNSName, ep.RecordType)] {
+			if !hasTarget(ep.Targets, entryTarget(entry)) {
+				continue
+			}
+			if err := p.delete(ctx, ep, entry); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, ep := range changes.UpdateNew {
+		ttl := formatTTL(ep)
+		existing := map[string]bool{}
+		for _, entry := range byKey[entryKey(ep.DNSName, ep.RecordType)] {
+			target := entryTarget(entry)
+			switch {
+			case !hasTarget(ep.Targets, target) || existing[target]:
+				if err := p.delete(ctx, ep, entry); err != nil {
+					return err
+				}
+			case ttl != "" && !sameTTL(entry.TTL, ep.RecordTTL):
+				existing[target] = true
+				log.Infof("Updating the TTL of the static DNS entry %s %s %s to %s", ep.DNSName, ep.RecordType, target, ttl)
+				if !p.dryRun {
+					if err := p.client.updateStaticDNS(ctx, entry.ID, staticDNSEntry{TTL: ttl}); err != nil {
+						return err
+					}
+				}
+			default:
+				existing[target] = true
+			}
+		}
+		for _, target := range ep.Targets {
+			if existing[target] {
+				continue
+			}
+			if err := p.create(ctx, ep, target); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, ep := range changes.Create {
+		existing := map[string]bool{}
+		for _, entry := range byKey[entryKey(ep.DNSName, ep.RecordType)] {
+			existing[entryTarget(entry)] = true
+		}
+		for _, target := range ep.Targets {
+			if existing[target] {
+				log.Debugf("Static DNS entry %s %s %s already exists", ep.DNSName, ep.RecordType, target)
+				continue
+			}
+			if err := p.create(ctx, ep, target); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// entries returns the static DNS entries managed by the provider, leaving out the disabled
+// entries, the entries matching a regular expression and the unsupported record types.
+func (p *MikroTikProvider) entries(ctx context.Context) ([]staticDNSEntry, error) {
+	all, err := p.client.listStaticDNS(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []staticDNSEntry
+	for _, entry := range all {
+		if entry.Name == "" || entry.Disabled == "true" || !supportedRecordTypes[entryType(entry)] {
+			continue
+		}
+		if !p.domainFilter.Match(entry.Name) {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+func (p *MikroTikProvider) create(ctx context.Context, ep *endpoint.Endpoint, target string) error {
+	entry := staticDNSEntry{
+		Name:    strings.TrimSuffix(ep.DNSName, "."),
+		Type:    ep.RecordType,
+		TTL:     formatTTL(ep),
+		Comment: entryComment,
+	}
+	switch ep.RecordType {
+	case endpoint.RecordTypeCNAME:
+		entry.CName = strings.TrimSuffix(target, ".")
+	case endpoint.RecordTypeTXT:
+		entry.Text = target
+	default:
+		entry.Address = target
+	}
+
+	log.Infof("Creating the static DNS entry %s %s %s", entry.Name, ep.RecordType, target)
+	if p.dryRun {
+		return nil
+	}
+	return p.client.createStaticDNS(ctx, entry)
+}
+
+func (p *MikroTikProvider) delete(ctx context.Context, ep *endpoint.Endpoint, entry staticDNSEntry) error {
+	log.Infof("Deleting the static DNS entry %s %s %s", entry.Name, ep.RecordType, entryTarget(entry))
+	if p.dryRun {
+		return nil
+	}
+	return p.client.deleteStaticDNS(ctx, entry.ID)
+}
+
+func entryKey(name, recordType string) string {
+	return strings.ToLower(strings.TrimSuffix(name, ".")) + "::" + recordType
+}
+
+// entryType returns the record type of an entry, RouterOS omitting the type of the A entries.
+func entryType(entry staticDNSEntry) string {
+	if entry.Type == "" {
+		if entry.CName != "" {
+			return endpoint.RecordTypeCNAME
+		}
+		return endpoint.RecordTypeA
+	}
+	return entry.Type
+}
+
+func entryTarget(entry staticDNSEntry) string {
+	switch entryType(entry) {
+	case endpoint.RecordTypeCNAME:
+		return entry.CName
+	case endpoint.RecordTypeTXT:
+		return entry.Text
+	default:
+		return entry.Address
+	}
+}
+
+// formatTTL returns the TTL of an endpoint as a RouterOS time interval, empty to use the
+// default TTL of the device when the endpoint has none.
+func formatTTL(ep *endpoint.Endpoint) string {
+	if !ep.RecordTTL.IsConfigured() {
+		return ""
+	}
+	return fmt.Sprintf("%ds", ep.RecordTTL)
+}
+
+func sameTTL(ttl string, recordTTL endpoint.TTL) bool {
+	seconds, err := parseTTL(ttl)
+	return err == nil && seconds == int64(recordTTL)
+}
+
+func hasTarget(targets endpoint.Targets, target string) bool {
+	for _, t := range targets {
+		if t == target {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,214 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mikrotik
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+)
+
+// fakeRouterOS serves the static DNS entries of the RouterOS REST API.
+type fakeRouterOS struct {
+	sync.Mutex
+	entries []staticDNSEntry
+	nextID  int
+}
+
+func (f *fakeRouterOS) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	f.Lock()
+	defer f.Unlock()
+
+	if user, password, ok := req.BasicAuth(); !ok || user != "admin" || password != "secret" {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(routerOSError{Error: 401, Message: "Unauthorized"})
+		return
+	}
+
+	id := strings.TrimPrefix(req.URL.Path, "/rest"+staticDNSPath+"/")
+	switch {
+	case req.Method == http.MethodGet && req.URL.Path == "/rest"+staticDNSPath:
+		json.NewEncoder(w).Encode(f.entries)
+	case req.Method == http.MethodPut && req.URL.Path == "/rest"+staticDNSPath:
+		var entry staticDNSEntry
+		json.NewDecoder(req.Body).Decode(&entry)
+		if entry.Type == endpoint.RecordTypeA {
+			entry.Type = ""
+		}
+		f.nextID++
+		entry.ID = fmt.Sprintf("*%X", f.nextID)
+		f.entries = append(f.entries, entry)
+		json.NewEncoder(w).Encode(entry)
+	case req.Method == http.MethodPatch:
+		var patch staticDNSEntry
+		json.NewDecoder(req.Body).Decode(&patch)
+		for i := range f.entries {
+			if f.entries[i].ID == id {
+				f.entries[i].TTL = patch.TTL
+				json.NewEncoder(w).Encode(f.entries[i])
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(routerOSError{Error: 404, Message: "Not Found", Detail: "no such item"})
+	case req.Method == http.MethodDelete:
+		for i := range f.entries {
+			if f.entries[i].ID == id {
+				f.entries = append(f.entries[:i], f.entries[i+1:]...)
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(routerOSError{Error: 404, Message: "Not Found", Detail: "no such item"})
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+	}
+}
+
+func newTestProvider(t *testing.T, entries []staticDNSEntry, password string) (*MikroTikProvider, *fakeRouterOS) {
+	routerOS := &fakeRouterOS{entries: entries, nextID: len(entries)}
+	server := httptest.NewServer(routerOS)
+	t.Cleanup(server.Close)
+
+	p, err := NewMikroTikProvider(MikroTikConfig{
+		BaseURL:      server.URL + "/rest",
+		Username:     "admin",
+		Password:     password,
+		DomainFilter: endpoint.NewDomainFilter([]string{"example.org"}),
+	})
+	require.NoError(t, err)
+	return p, routerOS
+}
+
+func TestMikroTikRecords(t *testing.T) {
+	p, _ := newTestProvider(t, []staticDNSEntry{
+		{ID: "*1", Name: "www.example.org", Address: "192.0.2.1", TTL: "1d"},
+		{ID: "*2", Name: "www.example.org", Address: "192.0.2.2", TTL: "1d"},
+		{ID: "*3", Name: "www.example.org", Type: "AAAA", Address: "2001:db8::1", TTL: "5m"},
+		{ID: "*4", Name: "app.example.org", Type: "CNAME", CName: "www.example.org", TTL: "01:00:00"},
+		{ID: "*5", Name: "www.example.org", Type: "TXT", Text: "\"heritage=external-dns,external-dns/owner=default\""},
+		{ID: "*6", Name: "mail.example.org", Type: "MX", Address: "10 mx.example.org"},
+		{ID: "*7", Regexp: ".*\\.example\\.org", Address: "192.0.2.3"},
+		{ID: "*8", Name: "disabled.example.org", Address: "192.0.2.4", Disabled: "true"},
+		{ID: "*9", Name: "www.example.com", Address: "192.0.2.5"},
+	}, "secret")
+
+	records, err := p.Records(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []*endpoint.Endpoint{
+		endpoint.NewEndpointWithTTL("www.example.org", endpoint.RecordTypeA, 86400, "192.0.2.1", "192.0.2.2"),
+		endpoint.NewEndpointWithTTL("www.example.org", "AAAA", 300, "2001:db8::1"),
+		endpoint.NewEndpointWithTTL("app.example.org", endpoint.RecordTypeCNAME, 3600, "www.example.org"),
+		endpoint.NewEndpoint("www.example.org", endpoint.RecordTypeTXT, "\"heritage=external-dns,external-dns/owner=default\""),
+	}, records)
+}
+
+func TestMikroTikApplyChanges(t *testing.T) {
+	p, routerOS := newTestProvider(t, []staticDNSEntry{
+		{ID: "*1", Name: "www.example.org", Address: "192.0.2.1", TTL: "1d"},
+		{ID: "*2", Name: "www.example.org", Address: "192.0.2.2", TTL: "1d"},
+		{ID: "*3", Name: "old.example.org", Type: "CNAME", CName: "www.example.org"},
+		{ID: "*4", Name: "static.example.org", Address: "192.0.2.9", Comment: "set by hand"},
+	}, "secret")
+
+	err := p.ApplyChanges(context.Background(), &plan.Changes{
+		Create: []*endpoint.Endpoint{
+			endpoint.NewEndpointWithTTL("new.example.org", "AAAA", 300, "2001:db8::1"),
+			endpoint.NewEndpoint("new.example.org", endpoint.RecordTypeTXT, "\"heritage=external-dns\""),
+		},
+		UpdateOld: []*endpoint.Endpoint{
+			endpoint.NewEndpointWithTTL("www.example.org", endpoint.RecordTypeA, 86400, "192.0.2.1", "192.0.2.2"),
+		},
+		UpdateNew: []*endpoint.Endpoint{
+			endpoint.NewEndpointWithTTL("www.example.org", endpoint.RecordTypeA, 600, "192.0.2.2", "192.0.2.3"),
+		},
+		Delete: []*endpoint.Endpoint{
+			endpoint.NewEndpoint("old.example.org", endpoint.RecordTypeCNAME, "www.example.org"),
+		},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []staticDNSEntry{
+		{ID: "*2", Name: "www.example.org", Address: "192.0.2.2", TTL: "600s"},
+		{ID: "*4", Name: "static.example.org", Address: "192.0.2.9", Comment: "set by hand"},
+		{ID: "*5", Name: "www.example.org", Address: "192.0.2.3", TTL: "600s", Comment: entryComment},
+		{ID: "*6", Name: "new.example.org", Type: "AAAA", Address: "2001:db8::1", TTL: "300s", Comment: entryComment},
+		{ID: "*7", Name: "new.example.org", Type: "TXT", Text: "\"heritage=external-dns\"", Comment: entryComment},
+	}, routerOS.entries)
+}
+
+func TestMikroTikDryRun(t *testing.T) {
+	entries := []staticDNSEntry{{ID: "*1", Name: "www.example.org", Address: "192.0.2.1"}}
+	p, routerOS := newTestProvider(t, entries, "secret")
+	p.dryRun = true
+
+	err := p.ApplyChanges(context.Background(), &plan.Changes{
+		Create: []*endpoint.Endpoint{endpoint.NewEndpoint("new.example.org", endpoint.RecordTypeA, "192.0.2.2")},
+		Delete: []*endpoint.Endpoint{endpoint.NewEndpoint("www.example.org", endpoint.RecordTypeA, "192.0.2.1")},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, entries, routerOS.entries)
+}
+
+func TestMikroTikAPIError(t *testing.T) {
+	p, _ := newTestProvider(t, nil, "wrong")
+
+	_, err := p.Records(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "401")
+}
+
+func TestNewMikroTikProvider(t *testing.T) {
+	for _, baseURL := range []string{"", "192.168.88.1", "ftp://192.168.88.1/rest"} {
+		_, err := NewMikroTikProvider(MikroTikConfig{BaseURL: baseURL})
+		assert.Error(t, err, baseURL)
+	}
+}
+
+func TestParseTTL(t *testing.T) {
+	for s, expected := range map[string]int64{
+		"30s":        30,
+		"5m":         300,
+		"1h30m":      5400,
+		"1d":         86400,
+		"1w2d3h":     9*86400 + 3*3600,
+		"00:05:00":   300,
+		"1d00:00:10": 86410,
+		"1x":         0,
+		"1:00":       0,
+	} {
+		ttl, err := parseTTL(s)
+		if expected == 0 {
+			assert.Error(t, err, s)
+			continue
+		}
+		require.NoError(t, err, s)
+		assert.Equal(t, expected, ttl, s)
+	}
+}
@@ -0,0 +1,312 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ns1
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/ns1/ns1-go.v2/rest/model/data"
+	"gopkg.in/ns1/ns1-go.v2/rest/model/dns"
+	"gopkg.in/ns1/ns1-go.v2/rest/model/filter"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+const (
+	// ns1FiltersProperty is the filter chain of a record, a comma separated list of
+	// filters with their optional configuration, e.g. "up,geotarget_country,select_first_n:N=1"
+	ns1FiltersProperty = "ns1/filters"
+	// ns1MetaPropertyPrefix starts the properties setting a metadata field of the answers,
+	// a comma separated list of target=value entries, or a single value for all the targets
+	ns1MetaPropertyPrefix = "ns1/"
+)
+
+// ns1MetaFields are the metadata fields of the answers managed by the provider
+var ns1MetaFields = map[string]struct {
+	parse func(string) (interface{}, error)
+	set   func(*data.Meta, interface{})
+	get   func(*data.Meta) interface{}
+}{
+	"up": {
+		parse: func(v string) (interface{}, error) { return strconv.ParseBool(v) },
+		set:   func(m *data.Meta, v interface{}) { m.Up = v },
+		get:   func(m *data.Meta) interface{} { return m.Up },
+	},
+	"weight": {
+		parse: func(v string) (interface{}, error) { return strconv.ParseFloat(v, 64) },
+		set:   func(m *data.Meta, v interface{}) { m.Weight = v },
+		get:   func(m *data.Meta) interface{} { return m.Weight },
+	},
+	"priority": {
+		parse: func(v string) (interface{}, error) { return strconv.Atoi(v) },
+		set:   func(m *data.Meta, v interface{}) { m.Priority = v },
+		get:   func(m *data.Meta) interface{} { return m.Priority },
+	},
+	"country": {
+		parse: parseNS1List,
+		set:   func(m *data.Meta, v interface{}) { m.Country = v },
+		get:   func(m *data.Meta) interface{} { return m.Country },
+	},
+	"georegion": {
+		parse: parseNS1List,
+		set:   func(m *data.Meta, v interface{}) { m.Georegion = v },
+		get:   func(m *data.Meta) interface{} { return m.Georegion },
+	},
+	"us_state": {
+		parse: parseNS1List,
+		set:   func(m *data.Meta, v interface{}) { m.USState = v },
+		get:   func(m *data.Meta) interface{} { return m.USState },
+	},
+	"ca_province": {
+		parse: parseNS1List,
+		set:   func(m *data.Meta, v interface{}) { m.CAProvince = v },
+		get:   func(m *data.Meta) interface{} { return m.CAProvince },
+	},
+}
+
+// parseNS1List parses a semicolon separated list, e.g. "US;CA".
+func parseNS1List(v string) (interface{}, error) {
+	var list []string
+	for _, item := range strings.Split(v, ";") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	if len(list) == 0 {
+		return nil, fmt.Errorf("empty list")
+	}
+	return list, nil
+}
+
+// formatNS1Value returns the canonical text of a metadata value, as parsed or as read from the API.
+func formatNS1Value(v interface{}) (string, bool) {
+	switch value := v.(type) {
+	case bool:
+		return strconv.FormatBool(value), true
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64), true
+	case int:
+		return strconv.Itoa(value), true
+	case string:
+		return value, true
+	case []string:
+		list := append([]string{}, value...)
+		sort.Strings(list)
+		return strings.Join(list, ";"), true
+	case []interface{}:
+		list := make([]string, 0, len(value))
+		for _, item := range value {
+			s, ok := item.(string)
+			if !ok {
+				return "", false
+			}
+			list = append(list, s)
+		}
+		return formatNS1Value(list)
+	}
+	// e.g. the metadata driven by a data feed, which is not managed by the provider
+	return "", false
+}
+
+// parseNS1Filters parses a filter chain into the filters of a record.
+func parseNS1Filters(chain string) ([]*filter.Filter, error) {
+	filters := []*filter.Filter{}
+	for _, entry := range strings.Split(chain, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.Split(entry, ":")
+		f := &filter.Filter{Type: parts[0], Config: filter.Config{}}
+		for _, option := range parts[1:] {
+			key, value, ok := strings.Cut(option, "=")
+			if !ok || key == "" {
+				return nil, fmt.Errorf("invalid configuration %q of filter %s, expected key=value", option, f.Type)
+			}
+			if n, err := strconv.Atoi(value); err == nil {
+				f.Config[key] = n
+			} else if b, err := strconv.ParseBool(value); err == nil {
+				f.Config[key] = b
+			} else {
+				f.Config[key] = value
+			}
+		}
+		filters = append(filters, f)
+	}
+	return filters, nil
+}
+
+// formatNS1Filters returns the canonical filter chain of the filters of a record.
+func formatNS1Filters(filters []*filter.Filter) string {
+	entries := make([]string, 0, len(filters))
+	for _, f := range filters {
+		if f.Disabled {
+			continue
+		}
+		keys := make([]string, 0, len(f.Config))
+		for key := range f.Config {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		entry := f.Type
+		for _, key := range keys {
+			value := f.Config[key]
+			if n, ok := value.(float64); ok {
+				value = strconv.FormatFloat(n, 'f', -1, 64)
+			}
+			entry += fmt.Sprintf(":%s=%v", key, value)
+		}
+		entries = append(entries, entry)
+	}
+	return strings.Join(entries, ",")
+}
+
+// parseNS1Meta parses the entries of a metadata property by target, a single value applying
+// to all the given targets.
+func parseNS1Meta(field, value string, targets endpoint.Targets) map[string]interface{} {
+	values := map[string]interface{}{}
+	if value = strings.TrimSpace(value); value == "" {
+		return values
+	}
+	parse := ns1MetaFields[field].parse
+
+	if !strings.Contains(value, "=") {
+		v, err := parse(value)
+		if err != nil {
+			log.Warnf("Ignoring invalid NS1 %s %q: %v", field, value, err)
+			return values
+		}
+		for _, target := range targets {
+			values[target] = v
+		}
+		return values
+	}
+	for _, entry := range strings.Split(value, ",") {
+		target, text, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok {
+			log.Warnf("Ignoring invalid NS1 %s %q, expected target=value", field, entry)
+			continue
+		}
+		v, err := parse(text)
+		if err != nil {
+			log.Warnf("Ignoring invalid NS1 %s %q: %v", field, entry, err)
+			continue
+		}
+		values[strings.TrimSuffix(target, ".")] = v
+	}
+	return values
+}
+
+// formatNS1Meta returns the canonical text of the values of a metadata field by target.
+func formatNS1Meta(values map[string]interface{}) string {
+	entries := make([]string, 0, len(values))
+	for target, v := range values {
+		if text, ok := formatNS1Value(v); ok {
+			entries = append(entries, target+"="+text)
+		}
+	}
+	sort.Strings(entries)
+	return strings.Join(entries, ",")
+}
+
+// ns1Properties returns the provider specific properties of a record, all of them being set even
+// when empty, so that the plan notices when they are added to the desired records.
+func ns1Properties(record *dns.Record) endpoint.ProviderSpecific {
+	properties := endpoint.ProviderSpecific{}
+	filters := ""
+	if record != nil {
+		filters = formatNS1Filters(record.Filters)
+	}
+	properties = append(properties, endpoint.ProviderSpecificProperty{Name: ns1FiltersProperty, Value: filters})
+
+	for _, field := range sortedNS1MetaFields() {
+		values := map[string]interface{}{}
+		if record != nil {
+			for _, answer := range record.Answers {
+				if answer.Meta == nil {
+					continue
+				}
+				if v := ns1MetaFields[field].get(answer.Meta); v != nil {
+					values[strings.Join(answer.Rdata, " ")] = v
+				}
+			}
+		}
+		properties = append(properties, endpoint.ProviderSpecificProperty{Name: ns1MetaPropertyPrefix + field, Value: formatNS1Meta(values)})
+	}
+	return properties
+}
+
+// applyNS1Properties sets the filters and the metadata of the answers of a record from the
+// provider specific properties of its endpoint.
+func applyNS1Properties(record *dns.Record, ep *endpoint.Endpoint) {
+	if property, ok := ep.GetProviderSpecificProperty(ns1FiltersProperty); ok {
+		filters, err := parseNS1Filters(property.Value)
+		if err != nil {
+			log.Warnf("Ignoring the NS1 filters of %s: %v", ep.DNSName, err)
+		} else {
+			record.Filters = filters
+		}
+	}
+
+	for field, f := range ns1MetaFields {
+		property, ok := ep.GetProviderSpecificProperty(ns1MetaPropertyPrefix + field)
+		if !ok {
+			continue
+		}
+		for target, v := range parseNS1Meta(field, property.Value, ep.Targets) {
+			for _, answer := range record.Answers {
+				if strings.Join(answer.Rdata, " ") != target {
+					continue
+				}
+				if answer.Meta == nil {
+					answer.Meta = &data.Meta{}
+				}
+				f.set(answer.Meta, v)
+			}
+		}
+	}
+}
+
+// canonicalNS1Property returns the canonical value of an NS1 property of the endpoint.
+func canonicalNS1Property(name, value string, targets endpoint.Targets) (string, bool) {
+	if name == ns1FiltersProperty {
+		filters, err := parseNS1Filters(value)
+		if err != nil {
+			return value, true
+		}
+		return formatNS1Filters(filters), true
+	}
+	field := strings.TrimPrefix(name, ns1MetaPropertyPrefix)
+	if _, ok := ns1MetaFields[field]; !ok || !strings.HasPrefix(name, ns1MetaPropertyPrefix) {
+		return value, false
+	}
+	return formatNS1Meta(parseNS1Meta(field, value, targets)), true
+}
+
+func sortedNS1MetaFields() []string {
+	fields := make([]string, 0, len(ns1MetaFields))
+	for field := range ns1MetaFields {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields
+}
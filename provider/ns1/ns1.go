@@ -50,6 +50,7 @@ type NS1DomainClient interface {
 	DeleteRecord(zone string, domain string, t string) (*http.Response, error)
 	UpdateRecord(r *dns.Record) (*http.Response, error)
 	GetZone(zone string) (*dns.Zone, *http.Response, error)
+	GetRecord(zone string, domain string, t string) (*dns.Record, *http.Response, error)
 	ListZones() ([]*dns.Zone, *http.Response, error)
 }
 
@@ -78,6 +79,11 @@ func (n NS1DomainService) GetZone(zone string) (*dns.Zone, *http.Response, error
 	return n.service.Zones.Get(zone)
 }
 
+// GetRecord wraps the Get method of the API's Record service
+func (n NS1DomainService) GetRecord(zone string, domain string, t string) (*dns.Record, *http.Response, error) {
+	return n.service.Records.Get(zone, domain, t)
+}
+
 // ListZones wraps the List method of the API's Zones service
 func (n NS1DomainService) ListZones() ([]*dns.Zone, *http.Response, error) {
 	return n.service.Zones.List()
@@ -163,13 +169,22 @@ func (p *NS1Provider) Records(ctx context.Context) ([]*endpoint.Endpoint, error)
 
 		for _, record := range zoneData.Records {
 			if provider.SupportedRecordType(record.Type) {
-				endpoints = append(endpoints, endpoint.NewEndpointWithTTL(
+				// only the records above the basic tier have filters or metadata
+				var details *dns.Record
+				if tier, err := record.Tier.Int64(); err == nil && tier > 1 {
+					details, _, err = p.client.GetRecord(zone.Zone, record.Domain, record.Type)
+					if err != nil {
+						return nil, err
+					}
+				}
+				ep := endpoint.NewEndpointWithTTL(
 					record.Domain,
 					record.Type,
 					endpoint.TTL(record.TTL),
 					record.ShortAns...,
-				),
 				)
+				ep.ProviderSpecific = ns1Properties(details)
+				endpoints = append(endpoints, ep)
 			}
 		}
 	}
@@ -192,10 +207,33 @@ func (p *NS1Provider) ns1BuildRecord(zoneName string, change *ns1Change) *dns.Re
 		ttl = int(change.Endpoint.RecordTTL)
 	}
 	record.TTL = ttl
+	applyNS1Properties(record, change.Endpoint)
 
 	return record
 }
 
+// AdjustEndpoints returns the endpoints with their NS1 properties in canonical form, the
+// metadata set for all the targets being set for each of them.
+func (p *NS1Provider) AdjustEndpoints(endpoints []*endpoint.Endpoint) []*endpoint.Endpoint {
+	for _, ep := range endpoints {
+		for i, property := range ep.ProviderSpecific {
+			if value, ok := canonicalNS1Property(property.Name, property.Value, ep.Targets); ok {
+				ep.ProviderSpecific[i].Value = value
+			}
+		}
+	}
+	return endpoints
+}
+
+// PropertyValuesEqual compares the NS1 properties in canonical form.
+func (p *NS1Provider) PropertyValuesEqual(name string, previous string, current string) bool {
+	if previousValue, ok := canonicalNS1Property(name, previous, nil); ok {
+		currentValue, _ := canonicalNS1Property(name, current, nil)
+		return previousValue == currentValue
+	}
+	return p.BaseProvider.PropertyValuesEqual(name, previous, current)
+}
+
 // ns1SubmitChanges takes an array of changes and sends them to NS1
 func (p *NS1Provider) ns1SubmitChanges(changes []*ns1Change) error {
 	// return early if there is nothing to change
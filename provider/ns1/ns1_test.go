@@ -27,7 +27,9 @@ import (
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	api "gopkg.in/ns1/ns1-go.v2/rest"
+	"gopkg.in/ns1/ns1-go.v2/rest/model/data"
 	"gopkg.in/ns1/ns1-go.v2/rest/model/dns"
+	"gopkg.in/ns1/ns1-go.v2/rest/model/filter"
 
 	"sigs.k8s.io/external-dns/endpoint"
 	"sigs.k8s.io/external-dns/plan"
@@ -71,6 +73,10 @@ func (m *MockNS1DomainClient) GetZone(zone string) (*dns.Zone, *http.Response, e
 	return nil, nil, nil
 }
 
+func (m *MockNS1DomainClient) GetRecord(zone string, domain string, t string) (*dns.Record, *http.Response, error) {
+	return nil, nil, nil
+}
+
 func (m *MockNS1DomainClient) ListZones() ([]*dns.Zone, *http.Response, error) {
 	zones := []*dns.Zone{
 		{Zone: "foo.com", ID: "12345678910111213141516a"},
@@ -97,6 +103,10 @@ func (m *MockNS1GetZoneFail) GetZone(zone string) (*dns.Zone, *http.Response, er
 	return nil, nil, api.ErrZoneMissing
 }
 
+func (m *MockNS1GetZoneFail) GetRecord(zone string, domain string, t string) (*dns.Record, *http.Response, error) {
+	return nil, nil, nil
+}
+
 func (m *MockNS1GetZoneFail) ListZones() ([]*dns.Zone, *http.Response, error) {
 	zones := []*dns.Zone{
 		{Zone: "foo.com", ID: "12345678910111213141516a"},
@@ -123,6 +133,10 @@ func (m *MockNS1ListZonesFail) GetZone(zone string) (*dns.Zone, *http.Response,
 	return &dns.Zone{}, nil, nil
 }
 
+func (m *MockNS1ListZonesFail) GetRecord(zone string, domain string, t string) (*dns.Record, *http.Response, error) {
+	return nil, nil, nil
+}
+
 func (m *MockNS1ListZonesFail) ListZones() ([]*dns.Zone, *http.Response, error) {
 	return nil, nil, fmt.Errorf("no zones available")
 }
@@ -149,6 +163,83 @@ func TestNS1Records(t *testing.T) {
 	require.Error(t, err)
 }
 
+// MockNS1AdvancedClient serves a record above the basic tier, with filters and metadata.
+type MockNS1AdvancedClient struct {
+	MockNS1DomainClient
+	updated []*dns.Record
+}
+
+func (m *MockNS1AdvancedClient) GetZone(zone string) (*dns.Zone, *http.Response, error) {
+	return &dns.Zone{
+		Zone: "foo.com",
+		Records: []*dns.ZoneRecord{
+			{Domain: "geo.foo.com", ShortAns: []string{"1.1.1.1", "2.2.2.2"}, TTL: 60, Type: "A", Tier: "2"},
+		},
+	}, nil, nil
+}
+
+func (m *MockNS1AdvancedClient) GetRecord(zone string, domain string, t string) (*dns.Record, *http.Response, error) {
+	record := dns.NewRecord(zone, domain, t)
+	record.AddAnswer(&dns.Answer{Rdata: []string{"1.1.1.1"}, Meta: &data.Meta{Up: true, Country: []interface{}{"US", "CA"}, Weight: float64(10)}})
+	record.AddAnswer(&dns.Answer{Rdata: []string{"2.2.2.2"}, Meta: &data.Meta{Up: false, Country: []interface{}{"DE"}}})
+	record.AddFilter(filter.NewUp())
+	record.AddFilter(filter.NewGeotargetCountry())
+	record.AddFilter(&filter.Filter{Type: "select_first_n", Config: filter.Config{"N": float64(1)}})
+	return record, nil, nil
+}
+
+func (m *MockNS1AdvancedClient) UpdateRecord(r *dns.Record) (*http.Response, error) {
+	m.updated = append(m.updated, r)
+	return nil, nil
+}
+
+func TestNS1FiltersAndMeta(t *testing.T) {
+	client := &MockNS1AdvancedClient{}
+	p := &NS1Provider{
+		client:       client,
+		domainFilter: endpoint.NewDomainFilter([]string{"foo.com"}),
+		zoneIDFilter: provider.NewZoneIDFilter([]string{""}),
+	}
+
+	records, err := p.Records(context.Background())
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, endpoint.ProviderSpecific{
+		{Name: "ns1/filters", Value: "up,geotarget_country,select_first_n:N=1"},
+		{Name: "ns1/ca_province", Value: ""},
+		{Name: "ns1/country", Value: "1.1.1.1=CA;US,2.2.2.2=DE"},
+		{Name: "ns1/georegion", Value: ""},
+		{Name: "ns1/priority", Value: ""},
+		{Name: "ns1/up", Value: "1.1.1.1=true,2.2.2.2=false"},
+		{Name: "ns1/us_state", Value: ""},
+		{Name: "ns1/weight", Value: "1.1.1.1=10"},
+	}, records[0].ProviderSpecific)
+
+	// the desired records are compared in canonical form, the metadata for all the targets being set for each
+	desired := p.AdjustEndpoints([]*endpoint.Endpoint{
+		endpoint.NewEndpointWithTTL("geo.foo.com", "A", 60, "1.1.1.1", "2.2.2.2").
+			WithProviderSpecific("ns1/filters", "up, geotarget_country, select_first_n:N=1").
+			WithProviderSpecific("ns1/up", "true").
+			WithProviderSpecific("ns1/country", "2.2.2.2=DE,1.1.1.1=US;CA"),
+	})[0]
+	assert.Equal(t, "up,geotarget_country,select_first_n:N=1", desired.ProviderSpecific[0].Value)
+	assert.Equal(t, "1.1.1.1=true,2.2.2.2=true", desired.ProviderSpecific[1].Value)
+	assert.True(t, p.PropertyValuesEqual("ns1/country", records[0].ProviderSpecific[2].Value, desired.ProviderSpecific[2].Value))
+	assert.False(t, p.PropertyValuesEqual("ns1/up", records[0].ProviderSpecific[5].Value, desired.ProviderSpecific[1].Value))
+	assert.True(t, p.PropertyValuesEqual("ns1/priority", "", ""))
+
+	require.NoError(t, p.ApplyChanges(context.Background(), &plan.Changes{
+		UpdateOld: records,
+		UpdateNew: []*endpoint.Endpoint{desired},
+	}))
+	require.Len(t, client.updated, 1)
+	record := client.updated[0]
+	assert.Equal(t, []string{"up", "geotarget_country", "select_first_n"}, []string{record.Filters[0].Type, record.Filters[1].Type, record.Filters[2].Type})
+	assert.Equal(t, 1, record.Filters[2].Config["N"])
+	assert.Equal(t, &data.Meta{Up: true, Country: []string{"CA", "US"}}, record.Answers[0].Meta)
+	assert.Equal(t, &data.Meta{Up: true, Country: []string{"DE"}}, record.Answers[1].Meta)
+}
+
 func TestNewNS1Provider(t *testing.T) {
 	_ = os.Setenv("NS1_APIKEY", "xxxxxxxxxxxxxxxxx")
 	testNS1Config := NS1Config{
@@ -18,6 +18,8 @@ package provider
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"net"
 	"strings"
 
@@ -34,6 +36,80 @@ type Provider interface {
 	GetDomainFilter() endpoint.DomainFilterInterface
 }
 
+// ChangesError is returned by ApplyChanges when only part of the changes could be applied.
+// Failed holds the changes that were not applied, all the others were, so that callers can
+// rely on the applied changes while the failed ones are planned again by the next synchronization.
+type ChangesError struct {
+	Failed *plan.Changes
+	Err    error
+}
+
+func (e *ChangesError) Error() string {
+	return fmt.Sprintf("failed to apply %d of the changes: %v", len(e.Failed.Create)+len(e.Failed.UpdateNew)+len(e.Failed.Delete), e.Err)
+}
+
+func (e *ChangesError) Unwrap() error {
+	return e.Err
+}
+
+// Applied returns the given changes without the failed ones.
+func (e *ChangesError) Applied(changes *plan.Changes) *plan.Changes {
+	key := func(ep *endpoint.Endpoint) string {
+		return ep.DNSName + "/" + ep.RecordType + "/" + ep.SetIdentifier
+	}
+	keys := func(endpoints []*endpoint.Endpoint) map[string]bool {
+		m := make(map[string]bool, len(endpoints))
+		for _, ep := range endpoints {
+			m[key(ep)] = true
+		}
+		return m
+	}
+	without := func(endpoints []*endpoint.Endpoint, failed map[string]bool) []*endpoint.Endpoint {
+		var applied []*endpoint.Endpoint
+		for _, ep := range endpoints {
+			if !failed[key(ep)] {
+				applied = append(applied, ep)
+			}
+		}
+		return applied
+	}
+
+	applied := &plan.Changes{
+		Create: without(changes.Create, keys(e.Failed.Create)),
+		Delete: without(changes.Delete, keys(e.Failed.Delete)),
+	}
+	failedUpdates := keys(e.Failed.UpdateNew)
+	for i, ep := range changes.UpdateNew {
+		if !failedUpdates[key(ep)] {
+			applied.UpdateOld = append(applied.UpdateOld, changes.UpdateOld[i])
+			applied.UpdateNew = append(applied.UpdateNew, ep)
+		}
+	}
+	return applied
+}
+
+// ChangesValidator is implemented by the providers able to validate changes without applying
+// them, e.g. the syntax of the records, the supported record types or the quotas of the zones.
+// The controller validates the changes with it in dry-run mode. ValidateChanges returns a
+// *ChangesError holding the invalid changes when it can tell them apart from the valid ones.
+type ChangesValidator interface {
+	ValidateChanges(ctx context.Context, changes *plan.Changes) error
+}
+
+// ErrChangesValidationNotSupported is returned when validating changes with a provider that
+// doesn't implement ChangesValidator.
+var ErrChangesValidationNotSupported = errors.New("the provider doesn't support validating changes")
+
+// ValidateChanges validates the changes with the provider if it is a ChangesValidator,
+// and returns ErrChangesValidationNotSupported otherwise.
+func ValidateChanges(ctx context.Context, p Provider, changes *plan.Changes) error {
+	validator, ok := p.(ChangesValidator)
+	if !ok {
+		return ErrChangesValidationNotSupported
+	}
+	return validator.ValidateChanges(ctx, changes)
+}
+
 type BaseProvider struct {
 }
 
@@ -23,6 +23,9 @@ import (
 
 	log "github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
 )
 
 func TestMain(m *testing.M) {
@@ -63,3 +66,35 @@ func TestBaseProviderPropertyEquality(t *testing.T) {
 	assert.True(t, p.PropertyValuesEqual("some.property", "Foo", "Foo"), "Properties the same")
 	assert.False(t, p.PropertyValuesEqual("some.property", "Foo", "Bar"), "Attributes differ")
 }
+
+func TestChangesErrorApplied(t *testing.T) {
+	a := endpoint.NewEndpoint("a.example.org", endpoint.RecordTypeA, "1.2.3.4")
+	b := endpoint.NewEndpoint("b.example.org", endpoint.RecordTypeA, "1.2.3.4")
+	bOld := endpoint.NewEndpoint("b.example.org", endpoint.RecordTypeA, "1.2.3.5")
+	c := endpoint.NewEndpoint("c.example.org", endpoint.RecordTypeA, "1.2.3.4")
+	cOld := endpoint.NewEndpoint("c.example.org", endpoint.RecordTypeA, "1.2.3.5")
+	d := endpoint.NewEndpoint("d.example.org", endpoint.RecordTypeCNAME, "a.example.org")
+	changes := &plan.Changes{
+		Create:    []*endpoint.Endpoint{a, d},
+		UpdateOld: []*endpoint.Endpoint{bOld, cOld},
+		UpdateNew: []*endpoint.Endpoint{b, c},
+		Delete:    []*endpoint.Endpoint{d},
+	}
+
+	e := &ChangesError{
+		Failed: &plan.Changes{
+			Create:    []*endpoint.Endpoint{a},
+			UpdateOld: []*endpoint.Endpoint{cOld},
+			UpdateNew: []*endpoint.Endpoint{c},
+		},
+		Err: assert.AnError,
+	}
+	assert.Equal(t, &plan.Changes{
+		Create:    []*endpoint.Endpoint{d},
+		UpdateOld: []*endpoint.Endpoint{bOld},
+		UpdateNew: []*endpoint.Endpoint{b},
+		Delete:    []*endpoint.Endpoint{d},
+	}, e.Applied(changes))
+	assert.ErrorIs(t, e, assert.AnError)
+	assert.Contains(t, e.Error(), "failed to apply 2 of the changes")
+}
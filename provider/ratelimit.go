@@ -0,0 +1,223 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net"
+	"net/http"
+	"reflect"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/time/rate"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+)
+
+// maxRetryBackoff caps the exponential backoff between two retries
+const maxRetryBackoff = time.Minute
+
+var (
+	providerCallsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "external_dns",
+			Subsystem: "provider",
+			Name:      "calls_total",
+			Help:      "Number of calls to the DNS provider, by method and result.",
+		},
+		[]string{"method", "result"},
+	)
+	providerRetriesTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "external_dns",
+			Subsystem: "provider",
+			Name:      "retries_total",
+			Help:      "Number of retried calls to the DNS provider, by method.",
+		},
+		[]string{"method"},
+	)
+	providerThrottledSeconds = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "external_dns",
+			Subsystem: "provider",
+			Name:      "throttled_seconds_total",
+			Help:      "Time spent waiting for the rate limit of the DNS provider.",
+		},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(providerCallsTotal)
+	prometheus.MustRegister(providerRetriesTotal)
+	prometheus.MustRegister(providerThrottledSeconds)
+}
+
+// RateLimitedProvider wraps a Provider, limiting the rate of its calls and retrying the calls
+// failing with a transient error, i.e. a timeout, an HTTP 429 or an HTTP 5xx status.
+type RateLimitedProvider struct {
+	Provider
+	limiter *rate.Limiter
+	retries int
+	backoff time.Duration
+}
+
+// NewRateLimitedProvider returns the provider with at most qps calls per second, allowing bursts
+// of burst calls, and retrying transient errors up to retries times with a jittered exponential
+// backoff starting at backoff. A qps of zero disables the rate limit.
+func NewRateLimitedProvider(p Provider, qps float64, burst int, retries int, backoff time.Duration) *RateLimitedProvider {
+	limit := rate.Inf
+	if qps > 0 {
+		limit = rate.Limit(qps)
+	}
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &RateLimitedProvider{
+		Provider: p,
+		limiter:  rate.NewLimiter(limit, burst),
+		retries:  retries,
+		backoff:  backoff,
+	}
+}
+
+// Records returns the records of the wrapped provider.
+func (p *RateLimitedProvider) Records(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	var records []*endpoint.Endpoint
+	err := p.call(ctx, "records", func() error {
+		var err error
+		records, err = p.Provider.Records(ctx)
+		return err
+	})
+	return records, err
+}
+
+// ApplyChanges applies the changes with the wrapped provider. When the provider reports
+// a partial failure, only the failed changes are retried.
+func (p *RateLimitedProvider) ApplyChanges(ctx context.Context, changes *plan.Changes) error {
+	return p.call(ctx, "apply_changes", func() error {
+		err := p.Provider.ApplyChanges(ctx, changes)
+		var changesErr *ChangesError
+		if errors.As(err, &changesErr) {
+			changes = changesErr.Failed
+		}
+		return err
+	})
+}
+
+// ValidateChanges validates the changes with the wrapped provider.
+func (p *RateLimitedProvider) ValidateChanges(ctx context.Context, changes *plan.Changes) error {
+	validator, ok := p.Provider.(ChangesValidator)
+	if !ok {
+		return ErrChangesValidationNotSupported
+	}
+	return p.call(ctx, "validate_changes", func() error {
+		return validator.ValidateChanges(ctx, changes)
+	})
+}
+
+func (p *RateLimitedProvider) call(ctx context.Context, method string, f func() error) error {
+	backoff := p.backoff
+	for attempt := 0; ; attempt++ {
+		start := time.Now()
+		if err := p.limiter.Wait(ctx); err != nil {
+			return err
+		}
+		providerThrottledSeconds.Add(time.Since(start).Seconds())
+
+		err := f()
+		if err == nil {
+			providerCallsTotal.WithLabelValues(method, "success").Inc()
+			return nil
+		}
+		providerCallsTotal.WithLabelValues(method, "error").Inc()
+		if attempt >= p.retries || !IsTransientError(err) {
+			return err
+		}
+
+		// full jitter, so that replicas and zones throttled together don't retry together
+		delay := time.Duration(rand.Int63n(int64(backoff) + 1))
+		log.Warnf("Provider call %s failed with a transient error, retrying in %s: %v", method, delay, err)
+		providerRetriesTotal.WithLabelValues(method).Inc()
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+		if backoff *= 2; backoff > maxRetryBackoff {
+			backoff = maxRetryBackoff
+		}
+	}
+}
+
+// IsTransientError tells whether an error is worth retrying: a timeout, or an error carrying
+// an HTTP 429 or 5xx status. The status is found in the error chain the way most provider SDKs
+// expose it, with a StatusCode or HTTPStatusCode method, or a StatusCode or Code field.
+func IsTransientError(err error) bool {
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	for ; err != nil; err = errors.Unwrap(err) {
+		if status, ok := statusCode(err); ok {
+			return status == http.StatusTooManyRequests || status >= http.StatusInternalServerError
+		}
+	}
+	return false
+}
+
+func statusCode(err error) (int, bool) {
+	switch e := err.(type) {
+	case interface{ StatusCode() int }:
+		return e.StatusCode(), true
+	case interface{ HTTPStatusCode() int }:
+		return e.HTTPStatusCode(), true
+	}
+
+	v := reflect.ValueOf(err)
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return 0, false
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return 0, false
+	}
+	for _, name := range []string{"StatusCode", "Code"} {
+		f := v.FieldByName(name)
+		if !f.IsValid() {
+			continue
+		}
+		if f.Kind() == reflect.Interface && !f.IsNil() {
+			f = f.Elem()
+		}
+		switch f.Kind() {
+		case reflect.Int, reflect.Int32, reflect.Int64:
+			if status := int(f.Int()); status >= 100 && status < 600 {
+				return status, true
+			}
+		}
+	}
+	return 0, false
+}
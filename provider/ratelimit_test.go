@@ -0,0 +1,142 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+)
+
+type statusError struct {
+	status int
+}
+
+func (e statusError) Error() string   { return fmt.Sprintf("status %d", e.status) }
+func (e statusError) StatusCode() int { return e.status }
+
+type codeError struct {
+	Code int
+}
+
+func (e *codeError) Error() string { return fmt.Sprintf("code %d", e.Code) }
+
+// flakyProvider fails its calls with the given errors before succeeding.
+type flakyProvider struct {
+	BaseProvider
+	errs    []error
+	calls   int
+	applied []*plan.Changes
+}
+
+func (p *flakyProvider) next() error {
+	p.calls++
+	if len(p.errs) == 0 {
+		return nil
+	}
+	err := p.errs[0]
+	p.errs = p.errs[1:]
+	return err
+}
+
+func (p *flakyProvider) Records(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	return []*endpoint.Endpoint{endpoint.NewEndpoint("a.example.org", endpoint.RecordTypeA, "1.2.3.4")}, nil
+}
+
+func (p *flakyProvider) ApplyChanges(ctx context.Context, changes *plan.Changes) error {
+	p.applied = append(p.applied, changes)
+	return p.next()
+}
+
+func TestIsTransientError(t *testing.T) {
+	for _, tc := range []struct {
+		err       error
+		transient bool
+	}{
+		{statusError{429}, true},
+		{statusError{503}, true},
+		{statusError{404}, false},
+		{&codeError{Code: 500}, true},
+		{&codeError{Code: 400}, false},
+		{fmt.Errorf("listing zones: %w", statusError{502}), true},
+		{context.DeadlineExceeded, true},
+		{context.Canceled, false},
+		{errors.New("boom"), false},
+	} {
+		assert.Equal(t, tc.transient, IsTransientError(tc.err), tc.err.Error())
+	}
+}
+
+func TestRateLimitedProviderRetries(t *testing.T) {
+	p := &flakyProvider{errs: []error{statusError{429}, &codeError{Code: 503}}}
+	rl := NewRateLimitedProvider(p, 0, 1, 3, time.Millisecond)
+
+	records, err := rl.Records(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, records, 1)
+	assert.Equal(t, 3, p.calls)
+
+	p = &flakyProvider{errs: []error{statusError{429}, statusError{429}}}
+	_, err = NewRateLimitedProvider(p, 0, 1, 1, time.Millisecond).Records(context.Background())
+	assert.Error(t, err)
+	assert.Equal(t, 2, p.calls, "expected a single retry")
+
+	p = &flakyProvider{errs: []error{statusError{403}}}
+	_, err = NewRateLimitedProvider(p, 0, 1, 3, time.Millisecond).Records(context.Background())
+	assert.Error(t, err)
+	assert.Equal(t, 1, p.calls, "expected no retry of a permanent error")
+}
+
+func TestRateLimitedProviderRetriesFailedChanges(t *testing.T) {
+	a := endpoint.NewEndpoint("a.example.org", endpoint.RecordTypeA, "1.2.3.4")
+	b := endpoint.NewEndpoint("b.example.org", endpoint.RecordTypeA, "1.2.3.5")
+	failed := &plan.Changes{Create: []*endpoint.Endpoint{b}}
+	p := &flakyProvider{errs: []error{&ChangesError{Failed: failed, Err: statusError{500}}}}
+
+	err := NewRateLimitedProvider(p, 0, 1, 3, time.Millisecond).ApplyChanges(context.Background(), &plan.Changes{Create: []*endpoint.Endpoint{a, b}})
+	require.NoError(t, err)
+	require.Len(t, p.applied, 2)
+	assert.Equal(t, failed, p.applied[1], "expected only the failed changes to be retried")
+}
+
+func TestRateLimitedProviderLimitsRate(t *testing.T) {
+	p := &flakyProvider{}
+	rl := NewRateLimitedProvider(p, 20, 1, 0, 0)
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		_, err := rl.Records(context.Background())
+		require.NoError(t, err)
+	}
+	assert.GreaterOrEqual(t, time.Since(start), 90*time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := rl.Records(ctx)
+	assert.Error(t, err)
+}
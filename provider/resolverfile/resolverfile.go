@@ -0,0 +1,292 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resolverfile
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+	"sigs.k8s.io/external-dns/provider"
+)
+
+const (
+	// FormatDnsmasq renders host-record, cname and txt-record options of dnsmasq
+	FormatDnsmasq = "dnsmasq"
+	// FormatUnbound renders local-data entries of the server clause of unbound
+	FormatUnbound = "unbound"
+	// FormatHosts renders a hosts file, which only holds A and AAAA records
+	FormatHosts = "hosts"
+
+	header = "# Generated by ExternalDNS, do not edit."
+	// recordPrefix starts the comments holding the records, which all the formats support,
+	// so that the records are read back from the file regardless of the format
+	recordPrefix = "# record: "
+)
+
+// ResolverFileConfig is the configuration of the resolver file provider.
+type ResolverFileConfig struct {
+	// Path is the file the records are rendered into
+	Path   string
+	Format string
+	// ReloadPIDFile holds the pid of the resolver, sent a SIGHUP once the file is written
+	ReloadPIDFile string
+	// ReloadCommand is run with sh -c once the file is written
+	ReloadCommand string
+	DryRun        bool
+}
+
+// ResolverFileProvider is an implementation of Provider rendering the records into the
+// configuration file of a local resolver, such as dnsmasq or unbound, or into a hosts file.
+type ResolverFileProvider struct {
+	provider.BaseProvider
+
+	config ResolverFileConfig
+}
+
+// NewResolverFileProvider creates a new resolver file provider.
+func NewResolverFileProvider(config ResolverFileConfig) (*ResolverFileProvider, error) {
+	if config.Path == "" {
+		return nil, errors.New("no resolver file path specified")
+	}
+	switch config.Format {
+	case FormatDnsmasq, FormatUnbound, FormatHosts:
+	default:
+		return nil, fmt.Errorf("unsupported resolver file format %q", config.Format)
+	}
+
+	return &ResolverFileProvider{config: config}, nil
+}
+
+// Records returns the records stored in the file, none if the file does not exist yet.
+func (p *ResolverFileProvider) Records(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	f, err := os.Open(p.config.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return []*endpoint.Endpoint{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	endpoints := []*endpoint.Endpoint{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, recordPrefix) {
+			continue
+		}
+		ep := &endpoint.Endpoint{}
+		if err := json.Unmarshal([]byte(strings.TrimPrefix(line, recordPrefix)), ep); err != nil {
+			return nil, fmt.Errorf("invalid record in %s: %w", p.config.Path, err)
+		}
+		endpoints = append(endpoints, ep)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return endpoints, nil
+}
+
+// ApplyChanges renders the records with the changes applied into the file and reloads
+// the resolver, unless the content of the file is unchanged.
+func (p *ResolverFileProvider) ApplyChanges(ctx context.Context, changes *plan.Changes) error {
+	current, err := p.Records(ctx)
+	if err != nil {
+		return err
+	}
+
+	records := map[string]*endpoint.Endpoint{}
+	for _, ep := range current {
+		records[recordKey(ep)] = ep
+	}
+	for _, eps := range [][]*endpoint.Endpoint{changes.Delete, changes.UpdateOld} {
+		for _, ep := range eps {
+			delete(records, recordKey(ep))
+		}
+	}
+	for _, eps := range [][]*endpoint.Endpoint{changes.Create, changes.UpdateNew} {
+		for _, ep := range eps {
+			records[recordKey(ep)] = ep
+		}
+	}
+
+	endpoints := make([]*endpoint.Endpoint, 0, len(records))
+	for _, ep := range records {
+		endpoints = append(endpoints, ep)
+	}
+	sort.Slice(endpoints, func(i, j int) bool { return recordKey(endpoints[i]) < recordKey(endpoints[j]) })
+
+	content, err := render(p.config.Format, endpoints)
+	if err != nil {
+		return err
+	}
+	if previous, err := os.ReadFile(p.config.Path); err == nil && bytes.Equal(previous, content) {
+		log.Debugf("Resolver file %s is up to date", p.config.Path)
+		return nil
+	}
+
+	if p.config.DryRun {
+		log.Infof("Would write %d records to %s", len(endpoints), p.config.Path)
+		return nil
+	}
+	if err := writeFile(p.config.Path, content); err != nil {
+		return err
+	}
+	log.Infof("Wrote %d records to %s", len(endpoints), p.config.Path)
+
+	return p.reload(ctx)
+}
+
+// reload has the resolver read the file again.
+func (p *ResolverFileProvider) reload(ctx context.Context) error {
+	if p.config.ReloadPIDFile != "" {
+		b, err := os.ReadFile(p.config.ReloadPIDFile)
+		if err != nil {
+			return fmt.Errorf("failed to read the pid of the resolver: %w", err)
+		}
+		pid, err := strconv.Atoi(strings.TrimSpace(string(b)))
+		if err != nil {
+			return fmt.Errorf("invalid pid in %s: %w", p.config.ReloadPIDFile, err)
+		}
+		process, err := os.FindProcess(pid)
+		if err != nil {
+			return err
+		}
+		if err := process.Signal(syscall.SIGHUP); err != nil {
+			return fmt.Errorf("failed to reload the resolver with pid %d: %w", pid, err)
+		}
+	}
+
+	if p.config.ReloadCommand != "" {
+		output, err := exec.CommandContext(ctx, "sh", "-c", p.config.ReloadCommand).CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("failed to reload the resolver: %w: %s", err, strings.TrimSpace(string(output)))
+		}
+	}
+	return nil
+}
+
+// render returns the content of the file for the records in the given format. Every record
+// is preceded by a comment holding it, the records a format cannot hold only being stored there.
+func render(format string, endpoints []*endpoint.Endpoint) ([]byte, error) {
+	var b bytes.Buffer
+	fmt.Fprintln(&b, header)
+	if format == FormatUnbound {
+		fmt.Fprintln(&b, "server:")
+	}
+
+	for _, ep := range endpoints {
+		record, err := json.Marshal(ep)
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(&b, "%s%s\n", recordPrefix, record)
+
+		for _, target := range ep.Targets {
+			if line := renderTarget(format, ep, target); line != "" {
+				fmt.Fprintln(&b, line)
+			}
+		}
+	}
+	return b.Bytes(), nil
+}
+
+func renderTarget(format string, ep *endpoint.Endpoint, target string) string {
+	name := strings.TrimSuffix(ep.DNSName, ".")
+
+	switch format {
+	case FormatHosts:
+		if ep.RecordType == endpoint.RecordTypeA || ep.RecordType == "AAAA" {
+			return target + " " + name
+		}
+	case FormatDnsmasq:
+		ttl := ""
+		if ep.RecordTTL.IsConfigured() {
+			ttl = fmt.Sprintf(",%d", ep.RecordTTL)
+		}
+		switch ep.RecordType {
+		case endpoint.RecordTypeA, "AAAA":
+			return fmt.Sprintf("host-record=%s,%s%s", name, target, ttl)
+		case endpoint.RecordTypeCNAME:
+			return fmt.Sprintf("cname=%s,%s%s", name, strings.TrimSuffix(target, "."), ttl)
+		case endpoint.RecordTypeTXT:
+			return fmt.Sprintf("txt-record=%s,%s", name, quote(target))
+		}
+	case FormatUnbound:
+		ttl := ""
+		if ep.RecordTTL.IsConfigured() {
+			ttl = fmt.Sprintf(" %d", ep.RecordTTL)
+		}
+		switch ep.RecordType {
+		case endpoint.RecordTypeCNAME, endpoint.RecordTypeNS:
+			target = strings.TrimSuffix(target, ".") + "."
+		case endpoint.RecordTypeTXT:
+			// the TXT data is quoted in the data quoted for unbound
+			return fmt.Sprintf("    local-data: '%s.%s IN TXT %s'", name, ttl, quote(target))
+		}
+		return fmt.Sprintf("    local-data: \"%s.%s IN %s %s\"", name, ttl, ep.RecordType, target)
+	}
+	return ""
+}
+
+// quote returns the TXT data in double quotes, the TXT registry storing it quoted already.
+func quote(text string) string {
+	if strings.HasPrefix(text, `"`) && strings.HasSuffix(text, `"`) && len(text) > 1 {
+		return text
+	}
+	return strconv.Quote(text)
+}
+
+func recordKey(ep *endpoint.Endpoint) string {
+	return strings.ToLower(strings.TrimSuffix(ep.DNSName, ".")) + "::" + ep.RecordType + "::" + ep.SetIdentifier
+}
+
+// writeFile replaces the file atomically, so that the resolver never reads a partial file.
+func writeFile(path string, content []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,155 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resolverfile
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+)
+
+var testChanges = &plan.Changes{
+	Create: []*endpoint.Endpoint{
+		endpoint.NewEndpointWithTTL("www.example.org", endpoint.RecordTypeA, 300, "192.0.2.1", "192.0.2.2"),
+		endpoint.NewEndpoint("www.example.org", "AAAA", "2001:db8::1"),
+		endpoint.NewEndpoint("app.example.org", endpoint.RecordTypeCNAME, "www.example.org"),
+		endpoint.NewEndpoint("www.example.org", endpoint.RecordTypeTXT, "\"heritage=external-dns,external-dns/owner=default\""),
+	},
+}
+
+func TestResolverFileFormats(t *testing.T) {
+	for format, expected := range map[string][]string{
+		FormatDnsmasq: {
+			"cname=app.example.org,www.example.org",
+			"host-record=www.example.org,192.0.2.1,300",
+			"host-record=www.example.org,192.0.2.2,300",
+			"host-record=www.example.org,2001:db8::1",
+			"txt-record=www.example.org,\"heritage=external-dns,external-dns/owner=default\"",
+		},
+		FormatUnbound: {
+			"server:",
+			"    local-data: \"app.example.org. IN CNAME www.example.org.\"",
+			"    local-data: \"www.example.org. 300 IN A 192.0.2.1\"",
+			"    local-data: \"www.example.org. 300 IN A 192.0.2.2\"",
+			"    local-data: \"www.example.org. IN AAAA 2001:db8::1\"",
+			"    local-data: 'www.example.org. IN TXT \"heritage=external-dns,external-dns/owner=default\"'",
+		},
+		FormatHosts: {
+			"192.0.2.1 www.example.org",
+			"192.0.2.2 www.example.org",
+			"2001:db8::1 www.example.org",
+		},
+	} {
+		t.Run(format, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "records.conf")
+			p, err := NewResolverFileProvider(ResolverFileConfig{Path: path, Format: format})
+			require.NoError(t, err)
+
+			require.NoError(t, p.ApplyChanges(context.Background(), testChanges))
+			assert.Equal(t, expected, renderedLines(t, path))
+
+			// the records the format cannot hold are read back as well
+			records, err := p.Records(context.Background())
+			require.NoError(t, err)
+			assert.Len(t, records, len(testChanges.Create))
+		})
+	}
+}
+
+func TestResolverFileApplyChanges(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "records.conf")
+	reloads := filepath.Join(dir, "reloads")
+	p, err := NewResolverFileProvider(ResolverFileConfig{Path: path, Format: FormatHosts, ReloadCommand: "echo reload >> " + reloads})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	records, err := p.Records(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, records)
+
+	require.NoError(t, p.ApplyChanges(ctx, testChanges))
+	records, err = p.Records(ctx)
+	require.NoError(t, err)
+
+	err = p.ApplyChanges(ctx, &plan.Changes{
+		UpdateOld: []*endpoint.Endpoint{recordOfType(records, endpoint.RecordTypeA)},
+		UpdateNew: []*endpoint.Endpoint{endpoint.NewEndpoint("www.example.org", endpoint.RecordTypeA, "192.0.2.3")},
+		Delete:    []*endpoint.Endpoint{recordOfType(records, "AAAA")},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"192.0.2.3 www.example.org"}, renderedLines(t, path))
+
+	// the resolver is not reloaded when the file is unchanged
+	require.NoError(t, p.ApplyChanges(ctx, &plan.Changes{}))
+	b, err := os.ReadFile(reloads)
+	require.NoError(t, err)
+	assert.Equal(t, "reload\nreload\n", string(b))
+
+	p.config.ReloadCommand = "exit 1"
+	err = p.ApplyChanges(ctx, &plan.Changes{Delete: []*endpoint.Endpoint{recordOfType(records, endpoint.RecordTypeCNAME)}})
+	assert.Error(t, err)
+}
+
+func TestResolverFileDryRun(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "records.conf")
+	p, err := NewResolverFileProvider(ResolverFileConfig{Path: path, Format: FormatDnsmasq, DryRun: true})
+	require.NoError(t, err)
+
+	require.NoError(t, p.ApplyChanges(context.Background(), testChanges))
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestNewResolverFileProvider(t *testing.T) {
+	_, err := NewResolverFileProvider(ResolverFileConfig{Format: FormatDnsmasq})
+	assert.Error(t, err)
+	_, err = NewResolverFileProvider(ResolverFileConfig{Path: "records.conf", Format: "bind"})
+	assert.Error(t, err)
+}
+
+// renderedLines returns the lines of the file rendered for the resolver.
+func renderedLines(t *testing.T, path string) []string {
+	t.Helper()
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	var lines []string
+	for _, line := range strings.Split(strings.TrimSpace(string(b)), "\n") {
+		if !strings.HasPrefix(line, "#") {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+func recordOfType(records []*endpoint.Endpoint, recordType string) *endpoint.Endpoint {
+	for _, record := range records {
+		if record.RecordType == recordType {
+			return record
+		}
+	}
+	return nil
+}
@@ -20,8 +20,10 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"os"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/bodgit/tsig"
@@ -47,11 +49,7 @@ const (
 // rfc2136 provider type
 type rfc2136Provider struct {
 	provider.BaseProvider
-	nameserver      string
-	zoneName        string
-	tsigKeyName     string
-	tsigSecret      string
-	tsigSecretAlg   string
+	zones           []rfc2136Zone
 	insecure        bool
 	axfr            bool
 	minTTL          time.Duration
@@ -61,7 +59,7 @@ type rfc2136Provider struct {
 	gssTsig      bool
 	krb5Username string
 	krb5Password string
-	krb5Realm    string
+	krb5Keytab   string
 
 	// only consider hosted zones managing domains ending in this suffix
 	domainFilter endpoint.DomainFilter
@@ -69,6 +67,16 @@ type rfc2136Provider struct {
 	actions      rfc2136Actions
 }
 
+// rfc2136Zone is a zone managed by the provider along with the server and the key used to update it
+type rfc2136Zone struct {
+	name          string
+	nameserver    string
+	tsigKeyName   string
+	tsigSecret    string
+	tsigSecretAlg string
+	krb5Realm     string
+}
+
 var (
 	// Map of supported TSIG algorithms
 	tsigAlgs = map[string]string{
@@ -86,25 +94,42 @@ type rfc2136Actions interface {
 	IncomeTransfer(m *dns.Msg, a string) (env chan *dns.Envelope, err error)
 }
 
-// NewRfc2136Provider is a factory function for OpenStack rfc2136 providers
-func NewRfc2136Provider(host string, port int, zoneName string, insecure bool, keyName string, secret string, secretAlg string, axfr bool, domainFilter endpoint.DomainFilter, dryRun bool, minTTL time.Duration, gssTsig bool, krb5Username string, krb5Password string, krb5Realm string, batchChangeSize int, actions rfc2136Actions) (provider.Provider, error) {
-	secretAlgChecked, ok := tsigAlgs[secretAlg]
-	if !ok && !insecure && !gssTsig {
-		return nil, errors.Errorf("%s is not supported TSIG algorithm", secretAlg)
+// NewRfc2136Provider is a factory function for OpenStack rfc2136 providers.
+// Every zone of zoneNames is updated through the given host and TSIG key, zoneConfigs
+// add zones, or override these settings for a zone, with
+// "<zone>[,host=<host>][,port=<port>][,tsig-keyname=<name>][,tsig-secret=<secret>|,tsig-secret-file=<path>][,tsig-secret-alg=<alg>][,kerberos-realm=<realm>]".
+func NewRfc2136Provider(host string, port int, zoneNames []string, zoneConfigs []string, insecure bool, keyName string, secret string, secretAlg string, axfr bool, domainFilter endpoint.DomainFilter, dryRun bool, minTTL time.Duration, gssTsig bool, krb5Username string, krb5Password string, krb5Keytab string, krb5Realm string, batchChangeSize int, actions rfc2136Actions) (provider.Provider, error) {
+	defaults := rfc2136Zone{
+		nameserver:    net.JoinHostPort(host, strconv.Itoa(port)),
+		tsigKeyName:   keyName,
+		tsigSecret:    secret,
+		tsigSecretAlg: secretAlg,
+		krb5Realm:     krb5Realm,
 	}
 
-	if krb5Realm == "" {
-		krb5Realm = strings.ToUpper(zoneName)
+	var zones []rfc2136Zone
+	for _, zoneName := range zoneNames {
+		zone := defaults
+		zone.name = zoneName
+		zones = append(zones, zone)
+	}
+	for _, zoneConfig := range zoneConfigs {
+		zone, err := parseZoneConfig(zoneConfig, defaults)
+		if err != nil {
+			return nil, err
+		}
+		zones = mergeZone(zones, zone)
+	}
+	if len(zones) == 0 {
+		zones = []rfc2136Zone{defaults}
 	}
 
 	r := &rfc2136Provider{
-		nameserver:      net.JoinHostPort(host, strconv.Itoa(port)),
-		zoneName:        dns.Fqdn(zoneName),
 		insecure:        insecure,
 		gssTsig:         gssTsig,
 		krb5Username:    krb5Username,
 		krb5Password:    krb5Password,
-		krb5Realm:       strings.ToUpper(krb5Realm),
+		krb5Keytab:      krb5Keytab,
 		domainFilter:    domainFilter,
 		dryRun:          dryRun,
 		axfr:            axfr,
@@ -117,24 +142,138 @@ func NewRfc2136Provider(host string, port int, zoneName string, insecure bool, k
 		r.actions = r
 	}
 
-	if !insecure {
-		r.tsigKeyName = dns.Fqdn(keyName)
-		r.tsigSecret = secret
-		r.tsigSecretAlg = secretAlgChecked
+	for _, zone := range zones {
+		secretAlgChecked, ok := tsigAlgs[zone.tsigSecretAlg]
+		if !ok && !insecure && !gssTsig {
+			return nil, errors.Errorf("%s is not supported TSIG algorithm", zone.tsigSecretAlg)
+		}
+
+		if zone.krb5Realm == "" {
+			zone.krb5Realm = zone.name
+		}
+		zone.krb5Realm = strings.ToUpper(zone.krb5Realm)
+		zone.name = dns.Fqdn(zone.name)
+		if !insecure {
+			zone.tsigKeyName = dns.Fqdn(zone.tsigKeyName)
+			zone.tsigSecretAlg = secretAlgChecked
+		} else {
+			zone.tsigKeyName, zone.tsigSecret, zone.tsigSecretAlg = "", "", ""
+		}
+		r.zones = append(r.zones, zone)
+
+		log.Infof("Configured RFC2136 with zone '%s' and nameserver '%s'", zone.name, zone.nameserver)
 	}
 
-	log.Infof("Configured RFC2136 with zone '%s' and nameserver '%s'", r.zoneName, r.nameserver)
 	return r, nil
 }
 
-// KeyName will return TKEY name and TSIG handle to use for followon actions with a secure connection
-func (r rfc2136Provider) KeyData() (keyName string, handle *gss.Client, err error) {
+// parseZoneConfig parses the configuration of a zone, using the provider wide settings for
+// the options it doesn't set.
+func parseZoneConfig(zoneConfig string, defaults rfc2136Zone) (rfc2136Zone, error) {
+	fields := strings.Split(zoneConfig, ",")
+	zone := defaults
+	zone.name = strings.TrimSpace(fields[0])
+	if zone.name == "" || strings.Contains(zone.name, "=") {
+		return zone, errors.Errorf("invalid rfc2136 zone configuration %q: it must start with the zone name", zoneConfig)
+	}
+
+	host, port, err := net.SplitHostPort(defaults.nameserver)
+	if err != nil {
+		return zone, err
+	}
+	for _, field := range fields[1:] {
+		key, value, found := strings.Cut(strings.TrimSpace(field), "=")
+		if !found {
+			return zone, errors.Errorf("invalid rfc2136 zone configuration %q: expected key=value, got %q", zoneConfig, field)
+		}
+		switch key {
+		case "host":
+			host = value
+		case "port":
+			if _, err := strconv.ParseUint(value, 10, 16); err != nil {
+				return zone, errors.Errorf("invalid rfc2136 zone configuration %q: invalid port %q", zoneConfig, value)
+			}
+			port = value
+		case "tsig-keyname":
+			zone.tsigKeyName = value
+		case "tsig-secret":
+			zone.tsigSecret = value
+		case "tsig-secret-file":
+			// keeps the secret out of the flags, e.g. read from a mounted Kubernetes secret
+			secret, err := os.ReadFile(value)
+			if err != nil {
+				return zone, errors.Wrapf(err, "invalid rfc2136 zone configuration %q: failed to read the TSIG secret", zone.name)
+			}
+			zone.tsigSecret = strings.TrimSpace(string(secret))
+		case "tsig-secret-alg":
+			zone.tsigSecretAlg = value
+		case "kerberos-realm":
+			zone.krb5Realm = value
+		default:
+			return zone, errors.Errorf("invalid rfc2136 zone configuration %q: unknown option %q", zoneConfig, key)
+		}
+	}
+	zone.nameserver = net.JoinHostPort(host, port)
+
+	return zone, nil
+}
+
+// mergeZone replaces the zone of the same name in zones, or appends the zone.
+func mergeZone(zones []rfc2136Zone, zone rfc2136Zone) []rfc2136Zone {
+	for i := range zones {
+		if dns.Fqdn(zones[i].name) == dns.Fqdn(zone.name) {
+			zones[i] = zone
+			return zones
+		}
+	}
+	return append(zones, zone)
+}
+
+// zoneFor returns the most specific zone the given name belongs to.
+func (r rfc2136Provider) zoneFor(name string) (rfc2136Zone, bool) {
+	name = dns.Fqdn(name)
+
+	var (
+		match rfc2136Zone
+		found bool
+	)
+	for _, zone := range r.zones {
+		if zone.name != "." && name != zone.name && !strings.HasSuffix(name, "."+zone.name) {
+			continue
+		}
+		if !found || len(zone.name) > len(match.name) {
+			match, found = zone, true
+		}
+	}
+	return match, found
+}
+
+// zoneOf returns the zone a message built by the provider is about.
+func (r rfc2136Provider) zoneOf(msg *dns.Msg) (rfc2136Zone, error) {
+	if len(msg.Question) == 0 {
+		return rfc2136Zone{}, errors.New("message has no question section")
+	}
+	for _, zone := range r.zones {
+		if zone.name == msg.Question[0].Name {
+			return zone, nil
+		}
+	}
+	return rfc2136Zone{}, errors.Errorf("zone %s is not managed", msg.Question[0].Name)
+}
+
+// KeyName will return TKEY name and TSIG handle to use for followon actions with a secure connection.
+// The Kerberos user logs in with its keytab when one is configured, with its password otherwise.
+func (r rfc2136Provider) KeyData(zone rfc2136Zone) (keyName string, handle *gss.Client, err error) {
 	handle, err = gss.NewClient(new(dns.Client))
 	if err != nil {
 		return keyName, handle, err
 	}
 
-	keyName, _, err = handle.NegotiateContextWithCredentials(r.nameserver, r.krb5Realm, r.krb5Username, r.krb5Password)
+	if r.krb5Keytab != "" {
+		keyName, _, err = handle.NegotiateContextWithKeytab(zone.nameserver, zone.krb5Realm, r.krb5Username, r.krb5Keytab)
+	} else {
+		keyName, _, err = handle.NegotiateContextWithCredentials(zone.nameserver, zone.krb5Realm, r.krb5Username, r.krb5Password)
+	}
 
 	return keyName, handle, err
 }
@@ -176,6 +315,33 @@ OuterLoop:
 		case dns.TypeNS:
 			rrValues = []string{rr.(*dns.NS).Ns}
 			rrType = "NS"
+		case dns.TypeHTTPS, dns.TypeSVCB:
+			rrType = dns.TypeToString[rr.Header().Rrtype]
+			rrValues = []string{strings.TrimSpace(strings.TrimPrefix(rr.String(), rr.Header().String()))}
+			if target, err := endpoint.ParseSVCBTarget(rrValues[0]); err == nil {
+				rrValues[0] = target
+			}
+		case dns.TypeMX:
+			mx := rr.(*dns.MX)
+			rrType = endpoint.RecordTypeMX
+			rrValues = []string{fmt.Sprintf("%d %s", mx.Preference, mx.Mx)}
+			if target, err := endpoint.ParseMXTarget(rrValues[0]); err == nil {
+				rrValues[0] = target
+			}
+		case dns.TypeNAPTR:
+			naptr := rr.(*dns.NAPTR)
+			rrType = endpoint.RecordTypeNAPTR
+			rrValues = []string{fmt.Sprintf("%d %d %q %q %q %s", naptr.Order, naptr.Preference, naptr.Flags, naptr.Service, naptr.Regexp, naptr.Replacement)}
+			if target, err := endpoint.ParseNAPTRTarget(rrValues[0]); err == nil {
+				rrValues[0] = target
+			}
+		case dns.TypeTLSA:
+			tlsa := rr.(*dns.TLSA)
+			rrType = endpoint.RecordTypeTLSA
+			rrValues = []string{fmt.Sprintf("%d %d %d %s", tlsa.Usage, tlsa.Selector, tlsa.MatchingType, tlsa.Certificate)}
+			if target, err := endpoint.ParseTLSATarget(rrValues[0]); err == nil {
+				rrValues[0] = target
+			}
 		default:
 			continue // Unhandled record type
 		}
@@ -193,6 +359,11 @@ OuterLoop:
 			rrTTL,
 			rrValues...,
 		)
+		switch rrType {
+		case endpoint.RecordTypeHTTPS, endpoint.RecordTypeSVCB, endpoint.RecordTypeMX, endpoint.RecordTypeNAPTR:
+			// Keep the root target names of the record data, e.g. "0 ." for alias mode and null MX records.
+			ep.Targets = rrValues
+		}
 
 		eps = append(eps, ep)
 	}
@@ -203,38 +374,67 @@ OuterLoop:
 func (r rfc2136Provider) IncomeTransfer(m *dns.Msg, a string) (env chan *dns.Envelope, err error) {
 	t := new(dns.Transfer)
 	if !r.insecure && !r.gssTsig {
-		t.TsigSecret = map[string]string{r.tsigKeyName: r.tsigSecret}
+		zone, err := r.zoneOf(m)
+		if err != nil {
+			return nil, err
+		}
+		t.TsigSecret = map[string]string{zone.tsigKeyName: zone.tsigSecret}
 	}
 
-	return t.In(m, r.nameserver)
+	return t.In(m, a)
 }
 
+// List returns the records of all zones, transferred in parallel.
 func (r rfc2136Provider) List() ([]dns.RR, error) {
 	if !r.axfr {
 		log.Debug("axfr is disabled")
 		return make([]dns.RR, 0), nil
 	}
 
-	log.Debugf("Fetching records for '%s'", r.zoneName)
+	results := make([][]dns.RR, len(r.zones))
+	errs := make([]error, len(r.zones))
+	var wg sync.WaitGroup
+	for i, zone := range r.zones {
+		wg.Add(1)
+		go func(i int, zone rfc2136Zone) {
+			defer wg.Done()
+			results[i], errs[i] = r.listZone(zone)
+		}(i, zone)
+	}
+	wg.Wait()
+
+	records := make([]dns.RR, 0)
+	for i := range r.zones {
+		if errs[i] != nil {
+			return nil, errs[i]
+		}
+		records = append(records, results[i]...)
+	}
+
+	return records, nil
+}
+
+func (r rfc2136Provider) listZone(zone rfc2136Zone) ([]dns.RR, error) {
+	log.Debugf("Fetching records for '%s'", zone.name)
 
 	m := new(dns.Msg)
-	m.SetAxfr(r.zoneName)
+	m.SetAxfr(zone.name)
 	if !r.insecure && !r.gssTsig {
-		m.SetTsig(r.tsigKeyName, r.tsigSecretAlg, clockSkew, time.Now().Unix())
+		m.SetTsig(zone.tsigKeyName, zone.tsigSecretAlg, clockSkew, time.Now().Unix())
 	}
 
-	env, err := r.actions.IncomeTransfer(m, r.nameserver)
+	env, err := r.actions.IncomeTransfer(m, zone.nameserver)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch records via AXFR: %v", err)
+		return nil, fmt.Errorf("failed to fetch records of zone %s via AXFR: %v", zone.name, err)
 	}
 
 	records := make([]dns.RR, 0)
 	for e := range env {
 		if e.Error != nil {
 			if e.Error == dns.ErrSoa {
-				log.Error("AXFR error: unexpected response received from the server")
+				log.Errorf("AXFR error: unexpected response received from the server for zone %s", zone.name)
 			} else {
-				log.Errorf("AXFR error: %v", e.Error)
+				log.Errorf("AXFR error for zone %s: %v", zone.name, e.Error)
 			}
 			continue
 		}
@@ -244,24 +444,121 @@ func (r rfc2136Provider) List() ([]dns.RR, error) {
 	return records, nil
 }
 
-// ApplyChanges applies a given set of changes in a given zone.
+// ApplyChanges applies a given set of changes in the zones they belong to.
 func (r rfc2136Provider) ApplyChanges(ctx context.Context, changes *plan.Changes) error {
 	log.Debugf("ApplyChanges (Create: %d, UpdateOld: %d, UpdateNew: %d, Delete: %d)", len(changes.Create), len(changes.UpdateOld), len(changes.UpdateNew), len(changes.Delete))
 
+	zoneChanges := map[string]*plan.Changes{}
+	changesFor := func(ep *endpoint.Endpoint) *plan.Changes {
+		if !r.domainFilter.Match(ep.DNSName) {
+			log.Debugf("Skipping record %s because it was filtered out by the specified --domain-filter", ep.DNSName)
+			return nil
+		}
+		zone, ok := r.zoneFor(ep.DNSName)
+		if !ok {
+			log.Debugf("Skipping record %s because no configured zone matches it", ep.DNSName)
+			return nil
+		}
+		if zoneChanges[zone.name] == nil {
+			zoneChanges[zone.name] = &plan.Changes{}
+		}
+		return zoneChanges[zone.name]
+	}
+	for _, ep := range changes.Create {
+		if c := changesFor(ep); c != nil {
+			c.Create = append(c.Create, ep)
+		}
+	}
+	for i, ep := range changes.UpdateNew {
+		if c := changesFor(ep); c != nil {
+			c.UpdateOld = append(c.UpdateOld, changes.UpdateOld[i])
+			c.UpdateNew = append(c.UpdateNew, ep)
+		}
+	}
+	for _, ep := range changes.Delete {
+		if c := changesFor(ep); c != nil {
+			c.Delete = append(c.Delete, ep)
+		}
+	}
+
+	var errors []error
+	failed := &plan.Changes{}
+	for _, zone := range r.zones {
+		if c, ok := zoneChanges[zone.name]; ok {
+			errors = append(errors, r.applyZoneChanges(zone, c, failed)...)
+		}
+	}
+
+	if len(errors) > 0 {
+		return &provider.ChangesError{
+			Failed: failed,
+			Err:    fmt.Errorf("RFC2136 had errors in one or more of its batches: %v", errors),
+		}
+	}
+
+	return nil
+}
+
+// ValidateChanges checks that every change matches a configured zone and that its records can
+// be built, without sending any update.
+func (r rfc2136Provider) ValidateChanges(ctx context.Context, changes *plan.Changes) error {
+	var errors []error
+	invalid := &plan.Changes{}
+	validate := func(ep *endpoint.Endpoint) bool {
+		if !r.domainFilter.Match(ep.DNSName) {
+			return true
+		}
+		if _, ok := r.zoneFor(ep.DNSName); !ok {
+			errors = append(errors, fmt.Errorf("no configured zone matches %s", ep.DNSName))
+			return false
+		}
+		for _, target := range ep.Targets {
+			if _, err := dns.NewRR(fmt.Sprintf("%s %d %s %s", ep.DNSName, ep.RecordTTL, ep.RecordType, target)); err != nil {
+				errors = append(errors, fmt.Errorf("invalid %s record %s: %v", ep.RecordType, ep.DNSName, err))
+				return false
+			}
+		}
+		return true
+	}
+
+	for _, ep := range changes.Create {
+		if !validate(ep) {
+			invalid.Create = append(invalid.Create, ep)
+		}
+	}
+	for i, ep := range changes.UpdateNew {
+		if !validate(ep) || !validate(changes.UpdateOld[i]) {
+			invalid.UpdateOld = append(invalid.UpdateOld, changes.UpdateOld[i])
+			invalid.UpdateNew = append(invalid.UpdateNew, ep)
+		}
+	}
+	for _, ep := range changes.Delete {
+		if !validate(ep) {
+			invalid.Delete = append(invalid.Delete, ep)
+		}
+	}
+
+	if len(errors) > 0 {
+		return &provider.ChangesError{
+			Failed: invalid,
+			Err:    fmt.Errorf("RFC2136 would reject some of the changes: %v", errors),
+		}
+	}
+	return nil
+}
+
+// applyZoneChanges applies the changes of a zone in batches and returns the errors of the failed
+// batches, whose changes are added to failed.
+func (r rfc2136Provider) applyZoneChanges(zone rfc2136Zone, changes *plan.Changes, failed *plan.Changes) []error {
 	var errors []error
 
 	for c, chunk := range chunkBy(changes.Create, r.batchChangeSize) {
-		log.Debugf("Processing batch %d of create changes", c)
+		log.Debugf("Processing batch %d of create changes for zone %s", c, zone.name)
 
 		m := new(dns.Msg)
-		m.SetUpdate(r.zoneName)
+		m.SetUpdate(zone.name)
 
 		for _, ep := range chunk {
-			if !r.domainFilter.Match(ep.DNSName) {
-				log.Debugf("Skipping record %s because it was filtered out by the specified --domain-filter", ep.DNSName)
-				continue
-			}
-
 			r.AddRecord(m, ep)
 		}
 
@@ -271,24 +568,21 @@ func (r rfc2136Provider) ApplyChanges(ctx context.Context, changes *plan.Changes
 			if err != nil {
 				log.Errorf("RFC2136 update failed: %v", err)
 				errors = append(errors, err)
+				failed.Create = append(failed.Create, chunk...)
 				continue
 			}
 		}
 	}
 
 	for c, chunk := range chunkBy(changes.UpdateNew, r.batchChangeSize) {
-		log.Debugf("Processing batch %d of update changes", c)
+		log.Debugf("Processing batch %d of update changes for zone %s", c, zone.name)
 
 		m := new(dns.Msg)
-		m.SetUpdate(r.zoneName)
+		m.SetUpdate(zone.name)
 
+		oldChunk := changes.UpdateOld[c*r.batchChangeSize : c*r.batchChangeSize+len(chunk)]
 		for i, ep := range chunk {
-			if !r.domainFilter.Match(ep.DNSName) {
-				log.Debugf("Skipping record %s because it was filtered out by the specified --domain-filter", ep.DNSName)
-				continue
-			}
-
-			r.UpdateRecord(m, changes.UpdateOld[i], ep)
+			r.UpdateRecord(m, oldChunk[i], ep)
 		}
 
 		// only send if there are records available
@@ -297,23 +591,20 @@ func (r rfc2136Provider) ApplyChanges(ctx context.Context, changes *plan.Changes
 			if err != nil {
 				log.Errorf("RFC2136 update failed: %v", err)
 				errors = append(errors, err)
+				failed.UpdateOld = append(failed.UpdateOld, oldChunk...)
+				failed.UpdateNew = append(failed.UpdateNew, chunk...)
 				continue
 			}
 		}
 	}
 
 	for c, chunk := range chunkBy(changes.Delete, r.batchChangeSize) {
-		log.Debugf("Processing batch %d of delete changes", c)
+		log.Debugf("Processing batch %d of delete changes for zone %s", c, zone.name)
 
 		m := new(dns.Msg)
-		m.SetUpdate(r.zoneName)
+		m.SetUpdate(zone.name)
 
 		for _, ep := range chunk {
-			if !r.domainFilter.Match(ep.DNSName) {
-				log.Debugf("Skipping record %s because it was filtered out by the specified --domain-filter", ep.DNSName)
-				continue
-			}
-
 			r.RemoveRecord(m, ep)
 		}
 
@@ -323,16 +614,13 @@ func (r rfc2136Provider) ApplyChanges(ctx context.Context, changes *plan.Changes
 			if err != nil {
 				log.Errorf("RFC2136 update failed: %v", err)
 				errors = append(errors, err)
+				failed.Delete = append(failed.Delete, chunk...)
 				continue
 			}
 		}
 	}
 
-	if len(errors) > 0 {
-		return fmt.Errorf("RFC2136 had errors in one or more of its batches: %v", errors)
-	}
-
-	return nil
+	return errors
 }
 
 func (r rfc2136Provider) UpdateRecord(m *dns.Msg, oldEp *endpoint.Endpoint, newEp *endpoint.Endpoint) error {
@@ -391,12 +679,17 @@ func (r rfc2136Provider) SendMessage(msg *dns.Msg) error {
 	}
 	log.Debugf("SendMessage")
 
+	zone, err := r.zoneOf(msg)
+	if err != nil {
+		return err
+	}
+
 	c := new(dns.Client)
 	c.SingleInflight = true
 
 	if !r.insecure {
 		if r.gssTsig {
-			keyName, handle, err := r.KeyData()
+			keyName, handle, err := r.KeyData(zone)
 			if err != nil {
 				return err
 			}
@@ -407,8 +700,8 @@ func (r rfc2136Provider) SendMessage(msg *dns.Msg) error {
 
 			msg.SetTsig(keyName, tsig.GSS, clockSkew, time.Now().Unix())
 		} else {
-			c.TsigProvider = tsig.HMAC{r.tsigKeyName: r.tsigSecret}
-			msg.SetTsig(r.tsigKeyName, r.tsigSecretAlg, clockSkew, time.Now().Unix())
+			c.TsigProvider = tsig.HMAC{zone.tsigKeyName: zone.tsigSecret}
+			msg.SetTsig(zone.tsigKeyName, zone.tsigSecretAlg, clockSkew, time.Now().Unix())
 		}
 	}
 
@@ -416,7 +709,7 @@ func (r rfc2136Provider) SendMessage(msg *dns.Msg) error {
 		c.Net = "tcp"
 	}
 
-	resp, _, err := c.Exchange(msg, r.nameserver)
+	resp, _, err := c.Exchange(msg, zone.nameserver)
 	if err != nil {
 		if resp != nil && resp.Rcode != dns.RcodeSuccess {
 			log.Infof("error in dns.Client.Exchange: %s", err)
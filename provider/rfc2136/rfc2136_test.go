@@ -18,8 +18,12 @@ package rfc2136
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -95,7 +99,7 @@ func (r *rfc2136Stub) IncomeTransfer(m *dns.Msg, a string) (env chan *dns.Envelo
 }
 
 func createRfc2136StubProvider(stub *rfc2136Stub) (provider.Provider, error) {
-	return NewRfc2136Provider("", 0, "", false, "key", "secret", "hmac-sha512", true, endpoint.DomainFilter{}, false, 300*time.Second, false, "", "", "", 50, stub)
+	return NewRfc2136Provider("", 0, nil, nil, false, "key", "secret", "hmac-sha512", true, endpoint.DomainFilter{}, false, 300*time.Second, false, "", "", "", "", 50, stub)
 }
 
 func extractAuthoritySectionFromMessage(msg fmt.Stringer) []string {
@@ -360,3 +364,273 @@ func contains(arr []*endpoint.Endpoint, name string) bool {
 	}
 	return false
 }
+
+func TestRfc2136GetRecordsSVCB(t *testing.T) {
+	stub := newStub()
+	err := stub.setOutput([]string{
+		`foo.com 3600 IN HTTPS 1 . port=8443 alpn="h2,h3"`,
+		"foo.com 3600 IN HTTPS 2 svc.foo.com.",
+		"_8443._foo.foo.com 3600 IN SVCB 0 .",
+	})
+	assert.NoError(t, err)
+
+	provider, err := createRfc2136StubProvider(stub)
+	assert.NoError(t, err)
+
+	recs, err := provider.Records(context.Background())
+	assert.NoError(t, err)
+
+	assert.Equal(t, []*endpoint.Endpoint{
+		{DNSName: "foo.com", RecordType: endpoint.RecordTypeHTTPS, RecordTTL: 3600, Targets: endpoint.Targets{"1 . alpn=h2,h3 port=8443", "2 svc.foo.com"}, Labels: endpoint.Labels{}},
+		{DNSName: "_8443._foo.foo.com", RecordType: endpoint.RecordTypeSVCB, RecordTTL: 3600, Targets: endpoint.Targets{"0 ."}, Labels: endpoint.Labels{}},
+	}, recs)
+}
+
+func TestRfc2136ApplyChangesSVCB(t *testing.T) {
+	stub := newStub()
+	provider, err := createRfc2136StubProvider(stub)
+	assert.NoError(t, err)
+
+	err = provider.ApplyChanges(context.Background(), &plan.Changes{
+		Create: []*endpoint.Endpoint{
+			{DNSName: "foo.com", RecordType: endpoint.RecordTypeHTTPS, Targets: endpoint.Targets{"1 . alpn=h2,h3 ipv4hint=192.0.2.1"}, RecordTTL: 300},
+		},
+	})
+	assert.NoError(t, err)
+
+	assert.Equal(t, 1, len(stub.createMsgs))
+	assert.Contains(t, stub.createMsgs[0].String(), `foo.com.	300	IN	HTTPS	1 . alpn="h2,h3" ipv4hint="192.0.2.1"`)
+}
+
+func TestRfc2136GetRecordsTLSA(t *testing.T) {
+	stub := newStub()
+	err := stub.setOutput([]string{
+		"_443._tcp.foo.com 3600 IN TLSA 3 1 1 0D6FCE3368BF9E6E78BC3F0153B31E676E3D91E1B1BC5A3D75D5E3BC7B0C1F22",
+	})
+	assert.NoError(t, err)
+
+	provider, err := createRfc2136StubProvider(stub)
+	assert.NoError(t, err)
+
+	recs, err := provider.Records(context.Background())
+	assert.NoError(t, err)
+
+	assert.Equal(t, []*endpoint.Endpoint{
+		{DNSName: "_443._tcp.foo.com", RecordType: endpoint.RecordTypeTLSA, RecordTTL: 3600, Targets: endpoint.Targets{"3 1 1 0d6fce3368bf9e6e78bc3f0153b31e676e3d91e1b1bc5a3d75d5e3bc7b0c1f22"}, Labels: endpoint.Labels{}},
+	}, recs)
+}
+
+func TestRfc2136GetRecordsMX(t *testing.T) {
+	stub := newStub()
+	err := stub.setOutput([]string{
+		"foo.com 3600 IN MX 10 mx1.foo.com.",
+		"foo.com 3600 IN MX 20 MX2.foo.com.",
+		"bar.com 3600 IN MX 0 .",
+	})
+	assert.NoError(t, err)
+
+	provider, err := createRfc2136StubProvider(stub)
+	assert.NoError(t, err)
+
+	recs, err := provider.Records(context.Background())
+	assert.NoError(t, err)
+
+	assert.Equal(t, []*endpoint.Endpoint{
+		{DNSName: "foo.com", RecordType: endpoint.RecordTypeMX, RecordTTL: 3600, Targets: endpoint.Targets{"10 mx1.foo.com", "20 mx2.foo.com"}, Labels: endpoint.Labels{}},
+		{DNSName: "bar.com", RecordType: endpoint.RecordTypeMX, RecordTTL: 3600, Targets: endpoint.Targets{"0 ."}, Labels: endpoint.Labels{}},
+	}, recs)
+}
+
+func TestRfc2136GetRecordsNAPTR(t *testing.T) {
+	stub := newStub()
+	err := stub.setOutput([]string{
+		`4.3.2.1.5.5.5.0.0.8.1.e164.foo.com 3600 IN NAPTR 100 10 "u" "E2U+sip" "!^.*$!sip:info@foo.com!" .`,
+		`foo.com 3600 IN NAPTR 10 0 "s" "SIP+D2U" "" _sip._udp.foo.com.`,
+	})
+	assert.NoError(t, err)
+
+	provider, err := createRfc2136StubProvider(stub)
+	assert.NoError(t, err)
+
+	recs, err := provider.Records(context.Background())
+	assert.NoError(t, err)
+
+	assert.Equal(t, []*endpoint.Endpoint{
+		{DNSName: "4.3.2.1.5.5.5.0.0.8.1.e164.foo.com", RecordType: endpoint.RecordTypeNAPTR, RecordTTL: 3600, Targets: endpoint.Targets{`100 10 "U" "E2U+sip" "!^.*$!sip:info@foo.com!" .`}, Labels: endpoint.Labels{}},
+		{DNSName: "foo.com", RecordType: endpoint.RecordTypeNAPTR, RecordTTL: 3600, Targets: endpoint.Targets{`10 0 "S" "SIP+D2U" "" _sip._udp.foo.com`}, Labels: endpoint.Labels{}},
+	}, recs)
+}
+
+func TestRfc2136ApplyChangesNAPTR(t *testing.T) {
+	stub := newStub()
+	provider, err := createRfc2136StubProvider(stub)
+	assert.NoError(t, err)
+
+	err = provider.ApplyChanges(context.Background(), &plan.Changes{
+		Create: []*endpoint.Endpoint{
+			{DNSName: "foo.com", RecordType: endpoint.RecordTypeNAPTR, Targets: endpoint.Targets{`10 0 "S" "SIP+D2U" "" _sip._udp.foo.com`}, RecordTTL: 300},
+		},
+	})
+	assert.NoError(t, err)
+
+	assert.Equal(t, 1, len(stub.createMsgs))
+	assert.Contains(t, stub.createMsgs[0].String(), `foo.com.	300	IN	NAPTR	10 0 "S" "SIP+D2U" "" _sip._udp.foo.com`)
+}
+
+// rfc2136ZoneStub serves the records of several zones and records the server and TSIG key of every transfer.
+type rfc2136ZoneStub struct {
+	zoneOutput map[string][]string
+	failZone   string
+
+	sync.Mutex
+	transfers map[string]string
+	messages  []*dns.Msg
+}
+
+func (r *rfc2136ZoneStub) SendMessage(msg *dns.Msg) error {
+	r.Lock()
+	defer r.Unlock()
+	if msg.Question[0].Name == r.failZone {
+		return fmt.Errorf("update of zone %s refused", r.failZone)
+	}
+	r.messages = append(r.messages, msg)
+	return nil
+}
+
+func (r *rfc2136ZoneStub) IncomeTransfer(m *dns.Msg, a string) (env chan *dns.Envelope, err error) {
+	zone := m.Question[0].Name
+	r.Lock()
+	r.transfers[zone] = a + " " + m.IsTsig().Hdr.Name
+	r.Unlock()
+
+	outChan := make(chan *dns.Envelope, len(r.zoneOutput[zone]))
+	for _, e := range r.zoneOutput[zone] {
+		rr, err := dns.NewRR(e)
+		if err != nil {
+			return nil, err
+		}
+		outChan <- &dns.Envelope{RR: []dns.RR{rr}}
+	}
+	close(outChan)
+
+	return outChan, nil
+}
+
+func TestRfc2136MultipleZones(t *testing.T) {
+	stub := &rfc2136ZoneStub{
+		zoneOutput: map[string][]string{
+			"foo.com.":     {"v1.foo.com 3600 IN A 1.1.1.1"},
+			"bar.foo.com.": {"v1.bar.foo.com 3600 IN A 2.2.2.2"},
+			"example.org.": {"v1.example.org 3600 IN TXT test"},
+		},
+		transfers: map[string]string{},
+	}
+	provider, err := NewRfc2136Provider("10.0.0.1", 53, []string{"foo.com", "example.org"}, []string{"bar.foo.com,host=10.0.0.2,port=5353,tsig-keyname=bar-key,tsig-secret=c2VjcmV0"}, false, "key", "secret", "hmac-sha512", true, endpoint.DomainFilter{}, false, 300*time.Second, false, "", "", "", "", 50, stub)
+	assert.NoError(t, err)
+
+	recs, err := provider.Records(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(recs))
+	assert.True(t, contains(recs, "v1.foo.com"))
+	assert.True(t, contains(recs, "v1.bar.foo.com"))
+	assert.True(t, contains(recs, "v1.example.org"))
+	assert.Equal(t, map[string]string{
+		"foo.com.":     "10.0.0.1:53 key.",
+		"bar.foo.com.": "10.0.0.2:5353 bar-key.",
+		"example.org.": "10.0.0.1:53 key.",
+	}, stub.transfers)
+
+	err = provider.ApplyChanges(context.Background(), &plan.Changes{
+		Create: []*endpoint.Endpoint{
+			{DNSName: "v2.foo.com", RecordType: "A", Targets: []string{"1.2.3.4"}},
+			{DNSName: "v2.bar.foo.com", RecordType: "A", Targets: []string{"1.2.3.5"}},
+			{DNSName: "v2.example.net", RecordType: "A", Targets: []string{"1.2.3.6"}},
+		},
+		UpdateOld: []*endpoint.Endpoint{
+			{DNSName: "v1.example.org", RecordType: "TXT", Targets: []string{"test"}},
+		},
+		UpdateNew: []*endpoint.Endpoint{
+			{DNSName: "v1.example.org", RecordType: "TXT", Targets: []string{"test2"}},
+		},
+	})
+	assert.NoError(t, err)
+
+	zones := map[string]string{}
+	for _, msg := range stub.messages {
+		zones[msg.Question[0].Name] = msg.String()
+	}
+	assert.Equal(t, 3, len(zones), "expected one message per zone")
+	assert.Contains(t, zones["foo.com."], "v2.foo.com")
+	assert.NotContains(t, zones["foo.com."], "v2.bar.foo.com")
+	assert.Contains(t, zones["bar.foo.com."], "v2.bar.foo.com")
+	assert.Contains(t, zones["example.org."], "test2")
+}
+
+func TestRfc2136InvalidZoneConfig(t *testing.T) {
+	for _, zoneConfig := range []string{
+		"",
+		"host=10.0.0.2",
+		"foo.com,host",
+		"foo.com,port=dns",
+		"foo.com,unknown=value",
+		"foo.com,tsig-secret-alg=hmac-unknown",
+		"foo.com,tsig-secret-file=/nonexistent/tsig-secret",
+	} {
+		_, err := NewRfc2136Provider("10.0.0.1", 53, nil, []string{zoneConfig}, false, "key", "secret", "hmac-sha512", true, endpoint.DomainFilter{}, false, 300*time.Second, false, "", "", "", "", 50, newStub())
+		assert.Error(t, err, zoneConfig)
+	}
+}
+
+func TestRfc2136ZoneConfigSecretFile(t *testing.T) {
+	secretFile := filepath.Join(t.TempDir(), "bar-key")
+	assert.NoError(t, os.WriteFile(secretFile, []byte("c2VjcmV0\n"), 0o600))
+
+	zone, err := parseZoneConfig("bar.foo.com,tsig-keyname=bar-key,tsig-secret-file="+secretFile, rfc2136Zone{nameserver: "10.0.0.1:53", tsigSecret: "secret"})
+	assert.NoError(t, err)
+	assert.Equal(t, "bar-key", zone.tsigKeyName)
+	assert.Equal(t, "c2VjcmV0", zone.tsigSecret)
+}
+
+func TestRfc2136ApplyChangesPartialFailure(t *testing.T) {
+	stub := &rfc2136ZoneStub{failZone: "bar.com.", transfers: map[string]string{}}
+	p, err := NewRfc2136Provider("10.0.0.1", 53, []string{"foo.com", "bar.com"}, nil, false, "key", "secret", "hmac-sha512", true, endpoint.DomainFilter{}, false, 300*time.Second, false, "", "", "", "", 50, stub)
+	assert.NoError(t, err)
+
+	err = p.ApplyChanges(context.Background(), &plan.Changes{
+		Create: []*endpoint.Endpoint{
+			{DNSName: "v1.foo.com", RecordType: "A", Targets: []string{"1.2.3.4"}},
+			{DNSName: "v1.bar.com", RecordType: "A", Targets: []string{"1.2.3.5"}},
+		},
+		UpdateOld: []*endpoint.Endpoint{{DNSName: "v2.bar.com", RecordType: "A", Targets: []string{"1.2.3.6"}}},
+		UpdateNew: []*endpoint.Endpoint{{DNSName: "v2.bar.com", RecordType: "A", Targets: []string{"1.2.3.7"}}},
+	})
+	var changesErr *provider.ChangesError
+	assert.True(t, errors.As(err, &changesErr))
+	assert.Equal(t, 1, len(stub.messages))
+	assert.Equal(t, 1, len(changesErr.Failed.Create))
+	assert.Equal(t, "v1.bar.com", changesErr.Failed.Create[0].DNSName)
+	assert.Equal(t, "v2.bar.com", changesErr.Failed.UpdateOld[0].DNSName)
+	assert.Equal(t, "v2.bar.com", changesErr.Failed.UpdateNew[0].DNSName)
+}
+
+func TestRfc2136ValidateChanges(t *testing.T) {
+	stub := newStub()
+	p, err := NewRfc2136Provider("10.0.0.1", 53, []string{"foo.com"}, nil, false, "key", "secret", "hmac-sha512", true, endpoint.DomainFilter{}, false, 300*time.Second, false, "", "", "", "", 50, stub)
+	assert.NoError(t, err)
+
+	err = p.(provider.ChangesValidator).ValidateChanges(context.Background(), &plan.Changes{
+		Create: []*endpoint.Endpoint{
+			{DNSName: "v1.foo.com", RecordType: "A", Targets: []string{"1.2.3.4"}},
+			{DNSName: "v2.foo.com", RecordType: "A", Targets: []string{"not-an-ip"}},
+			{DNSName: "v1.bar.com", RecordType: "A", Targets: []string{"1.2.3.5"}},
+		},
+		Delete: []*endpoint.Endpoint{{DNSName: "v3.foo.com", RecordType: "MX", Targets: []string{"10 mail.foo.com"}}},
+	})
+	var changesErr *provider.ChangesError
+	assert.True(t, errors.As(err, &changesErr))
+	assert.Equal(t, 0, len(stub.createMsgs)+len(stub.updateMsgs), "expected no update to be sent")
+	assert.Equal(t, 2, len(changesErr.Failed.Create))
+	assert.Equal(t, "v2.foo.com", changesErr.Failed.Create[0].DNSName)
+	assert.Equal(t, "v1.bar.com", changesErr.Failed.Create[1].DNSName)
+	assert.Empty(t, changesErr.Failed.Delete)
+}
@@ -55,6 +55,11 @@ func (im *NoopRegistry) ApplyChanges(ctx context.Context, changes *plan.Changes)
 	return im.provider.ApplyChanges(ctx, changes)
 }
 
+// ValidateChanges validates the changes with the dns provider without applying them
+func (im *NoopRegistry) ValidateChanges(ctx context.Context, changes *plan.Changes) error {
+	return provider.ValidateChanges(ctx, im.provider, changes)
+}
+
 // PropertyValuesEqual compares two property values for equality
 func (im *NoopRegistry) PropertyValuesEqual(attribute string, previous string, current string) bool {
 	return im.provider.PropertyValuesEqual(attribute, previous, current)
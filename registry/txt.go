@@ -38,6 +38,10 @@ type TXTRegistry struct {
 	ownerID  string //refers to the owner id of the current instance
 	mapper   nameMapper
 
+	// ownerGroup are the owner ids of the instances the records are shared with, e.g. the
+	// instances of an active/passive pair, whose records are managed as if owned by this instance
+	ownerGroup map[string]struct{}
+
 	// cache the records in memory and update on an interval instead.
 	recordsCache            []*endpoint.Endpoint
 	recordsCacheRefreshTime time.Time
@@ -52,10 +56,23 @@ type TXTRegistry struct {
 
 	// missingTXTRecords stores TXT records which are missing after the migration to the new format
 	missingTXTRecords []*endpoint.Endpoint
+
+	// encryption, if set, encrypts the labels stored in the TXT records
+	encryption *txtEncryption
+	// txtTargets stores the targets of the encrypted TXT records, which cannot be generated again
+	// from the labels to update or delete the records
+	txtTargets map[string]string
+
+	// formatV4 stores the labels of all the records of a name in a single TXT record in the v4 format
+	formatV4 bool
+	// txtV4Records and legacyTXTRecords store the TXT records in the v4 and previous formats by key,
+	// found by Records when formatV4 is set
+	txtV4Records     map[string]*txtV4Record
+	legacyTXTRecords map[string]*txtLegacyRecord
 }
 
 // NewTXTRegistry returns new TXTRegistry object
-func NewTXTRegistry(provider provider.Provider, txtPrefix, txtSuffix, ownerID string, cacheInterval time.Duration, txtWildcardReplacement string, managedRecordTypes []string) (*TXTRegistry, error) {
+func NewTXTRegistry(provider provider.Provider, txtPrefix, txtSuffix, ownerID string, ownerGroup []string, cacheInterval time.Duration, txtWildcardReplacement string, managedRecordTypes []string, encryptionKeys [][]byte, formatV4 bool) (*TXTRegistry, error) {
 	if ownerID == "" {
 		return nil, errors.New("owner id cannot be empty")
 	}
@@ -64,15 +81,31 @@ func NewTXTRegistry(provider provider.Provider, txtPrefix, txtSuffix, ownerID st
 		return nil, errors.New("txt-prefix and txt-suffix are mutual exclusive")
 	}
 
+	encryption, err := newTXTEncryption(encryptionKeys)
+	if err != nil {
+		return nil, err
+	}
+
 	mapper := newaffixNameMapper(txtPrefix, txtSuffix, txtWildcardReplacement)
 
+	group := make(map[string]struct{}, len(ownerGroup))
+	for _, id := range ownerGroup {
+		group[id] = struct{}{}
+	}
+
 	return &TXTRegistry{
 		provider:            provider,
 		ownerID:             ownerID,
+		ownerGroup:          group,
 		mapper:              mapper,
 		cacheInterval:       cacheInterval,
 		wildcardReplacement: txtWildcardReplacement,
 		managedRecordTypes:  managedRecordTypes,
+		encryption:          encryption,
+		txtTargets:          map[string]string{},
+		formatV4:            formatV4,
+		txtV4Records:        map[string]*txtV4Record{},
+		legacyTXTRecords:    map[string]*txtLegacyRecord{},
 	}, nil
 }
 
@@ -105,14 +138,30 @@ func (im *TXTRegistry) Records(ctx context.Context) ([]*endpoint.Endpoint, error
 
 	labelMap := map[string]endpoint.Labels{}
 	txtRecordsMap := map[string]struct{}{}
+	txtTargets := map[string]string{}
+	v4LabelMap := map[string]endpoint.Labels{}
+	txtV4Records := map[string]*txtV4Record{}
+	legacyTXTRecords := map[string]*txtLegacyRecord{}
 
 	for _, record := range records {
 		if record.RecordType != endpoint.RecordTypeTXT {
 			endpoints = append(endpoints, record)
 			continue
 		}
+		if im.formatV4 {
+			if payload, outdated, ok := im.parseV4(record.Targets[0]); ok {
+				for recordType, labels := range payload.Labels {
+					if outdated && im.owns(labels) {
+						labels[endpoint.OutdatedOwnershipLabelKey] = "true"
+					}
+					v4LabelMap[fmt.Sprintf("%s::%s::%s", strings.ToLower(payload.Name), record.SetIdentifier, recordType)] = labels
+				}
+				txtV4Records[im.v4Key(payload.Name, record.SetIdentifier)] = &txtV4Record{txt: record, payload: payload}
+				continue
+			}
+		}
 		// We simply assume that TXT records for the registry will always have only one target.
-		labels, err := endpoint.NewLabelsFromString(record.Targets[0])
+		labels, outdated, err := im.parseLabels(record.Targets[0])
 		if err == endpoint.ErrInvalidHeritage {
 			//if no heritage is found or it is invalid
 			//case when value of txt record cannot be identified
@@ -123,6 +172,16 @@ func (im *TXTRegistry) Records(ctx context.Context) ([]*endpoint.Endpoint, error
 		if err != nil {
 			return nil, err
 		}
+		// the records are migrated to the v4 format as the other outdated records
+		if (outdated || im.formatV4) && im.owns(labels) {
+			labels[endpoint.OutdatedOwnershipLabelKey] = "true"
+		}
+		if im.encryption != nil {
+			txtTargets[txtRecordKey(record)] = record.Targets[0]
+		}
+		if im.formatV4 {
+			legacyTXTRecords[txtRecordKey(record)] = &txtLegacyRecord{txt: record}
+		}
 		key := fmt.Sprintf("%s::%s", im.mapper.toEndpointName(record.DNSName), record.SetIdentifier)
 		labelMap[key] = labels
 		txtRecordsMap[record.DNSName] = struct{}{}
@@ -139,15 +198,24 @@ func (im *TXTRegistry) Records(ctx context.Context) ([]*endpoint.Endpoint, error
 		}
 		dnsName := strings.Join(dnsNameSplit, ".")
 		key := fmt.Sprintf("%s::%s", dnsName, ep.SetIdentifier)
-		if labels, ok := labelMap[key]; ok {
+		if labels, ok := v4LabelMap[fmt.Sprintf("%s::%s::%s", strings.ToLower(ep.DNSName), ep.SetIdentifier, ep.RecordType)]; ok {
 			for k, v := range labels {
 				ep.Labels[k] = v
 			}
+		} else if labels, ok := labelMap[key]; ok {
+			for k, v := range labels {
+				ep.Labels[k] = v
+			}
+			for _, name := range []string{im.mapper.toTXTName(ep.DNSName), im.mapper.toNewTXTName(ep.DNSName, ep.RecordType)} {
+				if legacy, ok := legacyTXTRecords[fmt.Sprintf("%s::%s", strings.ToLower(name), ep.SetIdentifier)]; ok {
+					legacy.users = append(legacy.users, ep.RecordType)
+				}
+			}
 		}
 
 		// Handle the migration of TXT records created before the new format (introduced in v0.12.0).
 		// The migration is done for the TXT records owned by this instance only.
-		if len(txtRecordsMap) > 0 && ep.Labels[endpoint.OwnerLabelKey] == im.ownerID {
+		if !im.formatV4 && len(txtRecordsMap) > 0 && im.owns(ep.Labels) {
 			if plan.IsManagedRecord(ep.RecordType, im.managedRecordTypes) {
 				// Get desired TXT records and detect the missing ones
 				desiredTXTs := im.generateTXTRecord(ep)
@@ -173,10 +241,91 @@ func (im *TXTRegistry) Records(ctx context.Context) ([]*endpoint.Endpoint, error
 	}
 
 	im.missingTXTRecords = missingEndpoints
+	im.txtTargets = txtTargets
+	im.txtV4Records = txtV4Records
+	im.legacyTXTRecords = legacyTXTRecords
 
 	return endpoints, nil
 }
 
+// parseLabels returns the labels stored in the target of a TXT record, and whether they are
+// outdated, i.e. to be stored again encrypted with the primary key.
+func (im *TXTRegistry) parseLabels(target string) (endpoint.Labels, bool, error) {
+	labels, err := endpoint.NewLabelsFromString(target)
+	if im.encryption == nil || err == nil {
+		return labels, im.encryption != nil, err
+	}
+
+	text, key, decryptErr := im.encryption.decrypt(target)
+	if decryptErr != nil {
+		return nil, false, err
+	}
+	labels, err = endpoint.NewLabelsFromString(text)
+	return labels, key > 0, err
+}
+
+// serializeLabels returns the target of the TXT records storing the labels.
+func (im *TXTRegistry) serializeLabels(labels endpoint.Labels) (string, error) {
+	if _, ok := labels[endpoint.OutdatedOwnershipLabelKey]; ok {
+		current := labels
+		labels = endpoint.Labels{}
+		for k, v := range current {
+			if k != endpoint.OutdatedOwnershipLabelKey {
+				labels[k] = v
+			}
+		}
+	}
+
+	if im.encryption == nil {
+		return labels.Serialize(true), nil
+	}
+	return im.encryption.encrypt(labels.Serialize(false))
+}
+
+// recordOwner returns the owner id a created or updated record is registered with: the owner
+// pinned by its owner-override label, which is consumed, or the owner id of this instance.
+func (im *TXTRegistry) recordOwner(r *endpoint.Endpoint) string {
+	if owner, ok := r.Labels[endpoint.OwnerOverrideLabelKey]; ok {
+		delete(r.Labels, endpoint.OwnerOverrideLabelKey)
+		log.Debugf("Registering %s with the pinned owner id %q", r.DNSName, owner)
+		return owner
+	}
+	return im.ownerID
+}
+
+// owns returns whether the labels of a record are those of a record owned by this instance
+// or by its owner group.
+func (im *TXTRegistry) owns(labels endpoint.Labels) bool {
+	owner, ok := labels[endpoint.OwnerLabelKey]
+	if !ok {
+		return false
+	}
+	if _, shared := im.ownerGroup[owner]; shared {
+		return true
+	}
+	return owner == im.ownerID
+}
+
+func (im *TXTRegistry) filterOwnedRecords(eps []*endpoint.Endpoint) []*endpoint.Endpoint {
+	if len(im.ownerGroup) == 0 {
+		return filterOwnedRecords(im.ownerID, eps)
+	}
+
+	filtered := []*endpoint.Endpoint{}
+	for _, ep := range eps {
+		if !im.owns(ep.Labels) {
+			log.Debugf(`Skipping endpoint %v because owner id does not match, found: "%s", required: "%s" or one of its owner group`, ep, ep.Labels[endpoint.OwnerLabelKey], im.ownerID)
+			continue
+		}
+		filtered = append(filtered, ep)
+	}
+	return filtered
+}
+
+func txtRecordKey(txt *endpoint.Endpoint) string {
+	return fmt.Sprintf("%s::%s", strings.ToLower(txt.DNSName), txt.SetIdentifier)
+}
+
 // MissingRecords returns the TXT record to be created.
 // The missing records are collected during the run of Records method.
 func (im *TXTRegistry) MissingRecords() []*endpoint.Endpoint {
@@ -191,32 +340,74 @@ func (im *TXTRegistry) generateTXTRecord(r *endpoint.Endpoint) []*endpoint.Endpo
 	if r.RecordType == endpoint.RecordTypeTXT {
 		return nil
 	}
+	target, err := im.serializeLabels(r.Labels)
+	if err != nil {
+		log.Errorf("Skipping the TXT records of %s: %v", r.DNSName, err)
+		return nil
+	}
 	// old TXT record format
-	txt := endpoint.NewEndpoint(im.mapper.toTXTName(r.DNSName), endpoint.RecordTypeTXT, r.Labels.Serialize(true)).WithSetIdentifier(r.SetIdentifier)
+	txt := endpoint.NewEndpoint(im.mapper.toTXTName(r.DNSName), endpoint.RecordTypeTXT, target).WithSetIdentifier(r.SetIdentifier)
 	txt.ProviderSpecific = r.ProviderSpecific
 	// new TXT record format (containing record type)
-	txtNew := endpoint.NewEndpoint(im.mapper.toNewTXTName(r.DNSName, r.RecordType), endpoint.RecordTypeTXT, r.Labels.Serialize(true)).WithSetIdentifier(r.SetIdentifier)
+	txtNew := endpoint.NewEndpoint(im.mapper.toNewTXTName(r.DNSName, r.RecordType), endpoint.RecordTypeTXT, target).WithSetIdentifier(r.SetIdentifier)
 	txtNew.ProviderSpecific = r.ProviderSpecific
 
 	return []*endpoint.Endpoint{txt, txtNew}
 }
 
+// currentTXTRecords returns the TXT records of an existing record. The encrypted ones are
+// returned as found by Records, since they cannot be generated again from the labels.
+func (im *TXTRegistry) currentTXTRecords(r *endpoint.Endpoint) []*endpoint.Endpoint {
+	txts := im.generateTXTRecord(r)
+	if im.encryption == nil {
+		return txts
+	}
+	for _, txt := range txts {
+		if target, ok := im.txtTargets[txtRecordKey(txt)]; ok {
+			txt.Targets = endpoint.Targets{target}
+		}
+	}
+	return txts
+}
+
+// storeTXTRecords keeps track of the targets of the encrypted TXT records created or
+// deleted by the changes, for the next changes applied before Records is called again.
+func (im *TXTRegistry) storeTXTRecords(created, deleted []*endpoint.Endpoint) {
+	if im.encryption == nil {
+		return
+	}
+	for _, txt := range deleted {
+		if txt.RecordType == endpoint.RecordTypeTXT {
+			delete(im.txtTargets, txtRecordKey(txt))
+		}
+	}
+	for _, txt := range created {
+		if txt.RecordType == endpoint.RecordTypeTXT && len(txt.Targets) == 1 {
+			im.txtTargets[txtRecordKey(txt)] = txt.Targets[0]
+		}
+	}
+}
+
 // ApplyChanges updates dns provider with the changes
 // for each created/deleted record it will also take into account TXT records for creation/deletion
 func (im *TXTRegistry) ApplyChanges(ctx context.Context, changes *plan.Changes) error {
 	filteredChanges := &plan.Changes{
 		Create:    changes.Create,
-		UpdateNew: filterOwnedRecords(im.ownerID, changes.UpdateNew),
-		UpdateOld: filterOwnedRecords(im.ownerID, changes.UpdateOld),
-		Delete:    filterOwnedRecords(im.ownerID, changes.Delete),
+		UpdateNew: im.filterOwnedRecords(changes.UpdateNew),
+		UpdateOld: im.filterOwnedRecords(changes.UpdateOld),
+		Delete:    im.filterOwnedRecords(changes.Delete),
 	}
+	// the records of the changes, without the TXT records added below
+	records := *filteredChanges
 	for _, r := range filteredChanges.Create {
 		if r.Labels == nil {
 			r.Labels = make(map[string]string)
 		}
-		r.Labels[endpoint.OwnerLabelKey] = im.ownerID
+		r.Labels[endpoint.OwnerLabelKey] = im.recordOwner(r)
 
-		filteredChanges.Create = append(filteredChanges.Create, im.generateTXTRecord(r)...)
+		if !im.formatV4 {
+			filteredChanges.Create = append(filteredChanges.Create, im.generateTXTRecord(r)...)
+		}
 
 		if im.cacheInterval > 0 {
 			im.addToCache(r)
@@ -227,7 +418,9 @@ func (im *TXTRegistry) ApplyChanges(ctx context.Context, changes *plan.Changes)
 		// when we delete TXT records for which value has changed (due to new label) this would still work because
 		// !!! TXT record value is uniquely generated from the Labels of the endpoint. Hence old TXT record can be uniquely reconstructed
 		// !!! After migration to the new TXT registry format we can drop records in old format here!!!
-		filteredChanges.Delete = append(filteredChanges.Delete, im.generateTXTRecord(r)...)
+		if !im.formatV4 {
+			filteredChanges.Delete = append(filteredChanges.Delete, im.currentTXTRecords(r)...)
+		}
 
 		if im.cacheInterval > 0 {
 			im.removeFromCache(r)
@@ -238,7 +431,9 @@ func (im *TXTRegistry) ApplyChanges(ctx context.Context, changes *plan.Changes)
 	for _, r := range filteredChanges.UpdateOld {
 		// when we updateOld TXT records for which value has changed (due to new label) this would still work because
 		// !!! TXT record value is uniquely generated from the Labels of the endpoint. Hence old TXT record can be uniquely reconstructed
-		filteredChanges.UpdateOld = append(filteredChanges.UpdateOld, im.generateTXTRecord(r)...)
+		if !im.formatV4 {
+			filteredChanges.UpdateOld = append(filteredChanges.UpdateOld, im.currentTXTRecords(r)...)
+		}
 		// remove old version of record from cache
 		if im.cacheInterval > 0 {
 			im.removeFromCache(r)
@@ -247,18 +442,183 @@ func (im *TXTRegistry) ApplyChanges(ctx context.Context, changes *plan.Changes)
 
 	// make sure TXT records are consistently updated as well
 	for _, r := range filteredChanges.UpdateNew {
-		filteredChanges.UpdateNew = append(filteredChanges.UpdateNew, im.generateTXTRecord(r)...)
+		// records taken over from the owner group are owned by this instance once updated,
+		// unless pinned to another owner
+		r.Labels[endpoint.OwnerLabelKey] = im.recordOwner(r)
+		if !im.formatV4 {
+			filteredChanges.UpdateNew = append(filteredChanges.UpdateNew, im.generateTXTRecord(r)...)
+		}
 		// add new version of record to cache
 		if im.cacheInterval > 0 {
 			im.addToCache(r)
 		}
 	}
 
+	var txtV4Records map[string]*txtV4Record
+	if im.formatV4 {
+		txtV4Records = im.addV4Changes(filteredChanges)
+	}
+
 	// when caching is enabled, disable the provider from using the cache
 	if im.cacheInterval > 0 {
 		ctx = context.WithValue(ctx, provider.RecordsContextKey, nil)
 	}
-	return im.provider.ApplyChanges(ctx, filteredChanges)
+	err := im.provider.ApplyChanges(ctx, filteredChanges)
+	if err != nil {
+		var changesErr *provider.ChangesError
+		if !errors.As(err, &changesErr) || im.formatV4 {
+			// the state of the records is unknown, list them again with the next synchronization
+			im.recordsCache = nil
+			return err
+		}
+		// keep track of the applied changes, the failed ones are planned again with the next synchronization
+		im.revertCache(&records, changesErr.Applied(&records))
+		filteredChanges = changesErr.Applied(filteredChanges)
+	}
+	if im.formatV4 {
+		im.storeV4Records(txtV4Records)
+		for _, r := range filteredChanges.Delete {
+			delete(im.legacyTXTRecords, txtRecordKey(r))
+		}
+	}
+	im.storeTXTRecords(
+		append(append([]*endpoint.Endpoint{}, filteredChanges.Create...), filteredChanges.UpdateNew...),
+		append(append([]*endpoint.Endpoint{}, filteredChanges.Delete...), filteredChanges.UpdateOld...),
+	)
+	return err
+}
+
+// ValidateChanges validates the changes with the provider, along with the TXT records they imply,
+// without applying them nor updating the cache.
+func (im *TXTRegistry) ValidateChanges(ctx context.Context, changes *plan.Changes) error {
+	owned := &plan.Changes{
+		UpdateOld: im.filterOwnedRecords(changes.UpdateOld),
+		Delete:    im.filterOwnedRecords(changes.Delete),
+	}
+	validated := *owned
+	for _, r := range changes.Create {
+		validated.Create = append(validated.Create, im.ownedCopy(r)...)
+	}
+	for _, r := range im.filterOwnedRecords(changes.UpdateNew) {
+		validated.UpdateNew = append(validated.UpdateNew, im.ownedCopy(r)...)
+	}
+	if !im.formatV4 {
+		for _, r := range owned.UpdateOld {
+			validated.UpdateOld = append(validated.UpdateOld, im.currentTXTRecords(r)...)
+		}
+		for _, r := range owned.Delete {
+			validated.Delete = append(validated.Delete, im.currentTXTRecords(r)...)
+		}
+	}
+	return provider.ValidateChanges(ctx, im.provider, &validated)
+}
+
+// ownedCopy returns a copy of the record labelled with its owner, followed by its TXT records.
+func (im *TXTRegistry) ownedCopy(r *endpoint.Endpoint) []*endpoint.Endpoint {
+	r = r.DeepCopy()
+	if r.Labels == nil {
+		r.Labels = make(map[string]string)
+	}
+	r.Labels[endpoint.OwnerLabelKey] = im.recordOwner(r)
+	if im.formatV4 {
+		return []*endpoint.Endpoint{r}
+	}
+	return append([]*endpoint.Endpoint{r}, im.generateTXTRecord(r)...)
+}
+
+// revertCache reverts the cache updates of the records whose changes were not applied.
+func (im *TXTRegistry) revertCache(records, applied *plan.Changes) {
+	if im.cacheInterval <= 0 {
+		return
+	}
+
+	isApplied := map[*endpoint.Endpoint]bool{}
+	for _, endpoints := range [][]*endpoint.Endpoint{applied.Create, applied.UpdateNew, applied.UpdateOld, applied.Delete} {
+		for _, r := range endpoints {
+			isApplied[r] = true
+		}
+	}
+	for _, endpoints := range [][]*endpoint.Endpoint{records.Create, records.UpdateNew} {
+		for _, r := range endpoints {
+			if !isApplied[r] {
+				im.removeFromCache(r)
+			}
+		}
+	}
+	for _, endpoints := range [][]*endpoint.Endpoint{records.Delete, records.UpdateOld} {
+		for _, r := range endpoints {
+			if !isApplied[r] {
+				im.addToCache(r)
+			}
+		}
+	}
+}
+
+// TransferOwnership rewrites the TXT records owned by fromOwnerID so that they are owned by
+// the owner of this registry, and returns the applied changes. The records themselves are
+// left untouched, so that they can be migrated between instances without downtime. With
+// dryRun, the changes are only calculated.
+func (im *TXTRegistry) TransferOwnership(ctx context.Context, fromOwnerID string, dryRun bool) (*plan.Changes, error) {
+	if fromOwnerID == "" || fromOwnerID == im.ownerID {
+		return nil, fmt.Errorf("invalid owner id to transfer the records from %q", fromOwnerID)
+	}
+
+	records, err := im.provider.Records(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := &plan.Changes{}
+	for _, record := range records {
+		if record.RecordType != endpoint.RecordTypeTXT || len(record.Targets) != 1 {
+			continue
+		}
+		if im.formatV4 {
+			if payload, _, ok := im.parseV4(record.Targets[0]); ok {
+				transferred := false
+				for _, labels := range payload.Labels {
+					if labels[endpoint.OwnerLabelKey] == fromOwnerID {
+						labels[endpoint.OwnerLabelKey] = im.ownerID
+						transferred = true
+					}
+				}
+				if !transferred {
+					continue
+				}
+				target, err := im.serializeV4(payload)
+				if err != nil {
+					return nil, err
+				}
+				transferredRecord := record.DeepCopy()
+				transferredRecord.Targets = endpoint.Targets{target}
+				changes.UpdateOld = append(changes.UpdateOld, record)
+				changes.UpdateNew = append(changes.UpdateNew, transferredRecord)
+				continue
+			}
+		}
+
+		labels, _, err := im.parseLabels(record.Targets[0])
+		if err != nil || labels[endpoint.OwnerLabelKey] != fromOwnerID {
+			continue
+		}
+		labels[endpoint.OwnerLabelKey] = im.ownerID
+		target, err := im.serializeLabels(labels)
+		if err != nil {
+			return nil, err
+		}
+
+		transferred := record.DeepCopy()
+		transferred.Targets = endpoint.Targets{target}
+		changes.UpdateOld = append(changes.UpdateOld, record)
+		changes.UpdateNew = append(changes.UpdateNew, transferred)
+	}
+
+	if dryRun || !changes.HasChanges() {
+		return changes, nil
+	}
+	// the cached records carry the labels of the previous owner
+	im.recordsCache = nil
+	return changes, im.provider.ApplyChanges(ctx, changes)
 }
 
 // PropertyValuesEqual compares two attribute values for equality
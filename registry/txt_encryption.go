@@ -0,0 +1,101 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+// txtEncryption encrypts the labels stored in TXT records with AES-GCM. The labels are encrypted
+// with the primary key, the first one, and decrypted with any of the keys, so that the records
+// encrypted with a previous key can be re-encrypted with a new primary key.
+type txtEncryption struct {
+	aeads []cipher.AEAD
+}
+
+// newTXTEncryption returns the encryption of the TXT records with the given AES-256 keys, or nil
+// if no key is given.
+func newTXTEncryption(keys [][]byte) (*txtEncryption, error) {
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	e := &txtEncryption{}
+	for i, key := range keys {
+		if len(key) != 32 {
+			return nil, fmt.Errorf("invalid TXT encryption key #%d: expected a 32 bytes AES-256 key, got %d bytes", i, len(key))
+		}
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid TXT encryption key #%d: %w", i, err)
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, fmt.Errorf("invalid TXT encryption key #%d: %w", i, err)
+		}
+		e.aeads = append(e.aeads, aead)
+	}
+	return e, nil
+}
+
+// encrypt returns the text encrypted with the primary key, as the quoted base64 encoding of the
+// nonce followed by the ciphertext.
+func (e *txtEncryption) encrypt(text string) (string, error) {
+	aead := e.aeads[0]
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate the nonce of a TXT record: %w", err)
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(text), nil)
+	return fmt.Sprintf("\"%s\"", base64.StdEncoding.EncodeToString(sealed)), nil
+}
+
+// decrypt returns the text decrypted by any of the keys and the index of the key that decrypted it.
+func (e *txtEncryption) decrypt(text string) (string, int, error) {
+	sealed, err := base64.StdEncoding.DecodeString(strings.Trim(text, "\""))
+	if err != nil {
+		return "", 0, fmt.Errorf("TXT record is not encrypted: %w", err)
+	}
+	for i, aead := range e.aeads {
+		if len(sealed) < aead.NonceSize() {
+			break
+		}
+		nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+		if plaintext, err := aead.Open(nil, nonce, ciphertext, nil); err == nil {
+			return string(plaintext), i, nil
+		}
+	}
+	return "", 0, fmt.Errorf("TXT record cannot be decrypted with any of the keys")
+}
+
+// ParseTXTEncryptionKey parses an AES-256 key given either as 32 raw bytes or base64 encoded.
+func ParseTXTEncryptionKey(key string) ([]byte, error) {
+	if len(key) == 32 {
+		return []byte(key), nil
+	}
+	for _, encoding := range []*base64.Encoding{base64.StdEncoding, base64.URLEncoding} {
+		if decoded, err := encoding.DecodeString(key); err == nil && len(decoded) == 32 {
+			return decoded, nil
+		}
+	}
+	return nil, fmt.Errorf("invalid TXT encryption key: expected 32 bytes, raw or base64 encoded")
+}
@@ -0,0 +1,150 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"context"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+	"sigs.k8s.io/external-dns/provider/inmemory"
+)
+
+var (
+	testPrimaryKey  = []byte("0123456789abcdef0123456789abcdef")
+	testPreviousKey = []byte("fedcba9876543210fedcba9876543210")
+)
+
+func TestTXTEncryption(t *testing.T) {
+	_, err := newTXTEncryption([][]byte{[]byte("too short")})
+	assert.Error(t, err)
+
+	e, err := newTXTEncryption(nil)
+	require.NoError(t, err)
+	assert.Nil(t, e)
+
+	previous, err := newTXTEncryption([][]byte{testPreviousKey})
+	require.NoError(t, err)
+	encrypted, err := previous.encrypt("heritage=external-dns,external-dns/owner=owner")
+	require.NoError(t, err)
+	assert.NotContains(t, encrypted, "heritage")
+
+	rotated, err := newTXTEncryption([][]byte{testPrimaryKey, testPreviousKey})
+	require.NoError(t, err)
+	text, key, err := rotated.decrypt(encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, "heritage=external-dns,external-dns/owner=owner", text)
+	assert.Equal(t, 1, key)
+
+	primary, err := newTXTEncryption([][]byte{testPrimaryKey})
+	require.NoError(t, err)
+	_, _, err = primary.decrypt(encrypted)
+	assert.Error(t, err)
+	_, _, err = primary.decrypt("\"heritage=external-dns,external-dns/owner=owner\"")
+	assert.Error(t, err)
+}
+
+func TestParseTXTEncryptionKey(t *testing.T) {
+	for _, key := range []string{
+		string(testPrimaryKey),
+		base64.StdEncoding.EncodeToString(testPrimaryKey),
+		base64.URLEncoding.EncodeToString(testPrimaryKey),
+	} {
+		parsed, err := ParseTXTEncryptionKey(key)
+		require.NoError(t, err, key)
+		assert.Equal(t, testPrimaryKey, parsed)
+	}
+
+	_, err := ParseTXTEncryptionKey("too short")
+	assert.Error(t, err)
+}
+
+func TestTXTRegistryEncryptionKeyRotation(t *testing.T) {
+	ctx := context.Background()
+	p := inmemory.NewInMemoryProvider()
+	p.CreateZone(testZone)
+	p.ApplyChanges(ctx, &plan.Changes{
+		Create: []*endpoint.Endpoint{
+			newEndpointWithOwner("bar.test-zone.example.org", "bar.loadbalancer.com", endpoint.RecordTypeCNAME, ""),
+			newEndpointWithOwner("bar.test-zone.example.org", "\"heritage=external-dns,external-dns/owner=other-owner\"", endpoint.RecordTypeTXT, ""),
+		},
+	})
+
+	r, err := NewTXTRegistry(p, "", "", "owner", nil, 0, "", []string{}, [][]byte{testPreviousKey}, false)
+	require.NoError(t, err)
+	require.NoError(t, r.ApplyChanges(ctx, &plan.Changes{
+		Create: []*endpoint.Endpoint{
+			newEndpointWithOwner("foo.test-zone.example.org", "foo.loadbalancer.com", endpoint.RecordTypeCNAME, ""),
+		},
+	}))
+	assertTXTRecordsEncrypted(t, p, "foo.test-zone.example.org", testPreviousKey)
+
+	// The records encrypted with the previous key are flagged to be encrypted with the new primary key.
+	r, err = NewTXTRegistry(p, "", "", "owner", nil, 0, "", []string{}, [][]byte{testPrimaryKey, testPreviousKey}, false)
+	require.NoError(t, err)
+	records, err := r.Records(ctx)
+	require.NoError(t, err)
+	labels := labelsByName(records)
+	assert.Equal(t, endpoint.Labels{endpoint.OwnerLabelKey: "owner", endpoint.OutdatedOwnershipLabelKey: "true"}, labels["foo.test-zone.example.org"])
+	assert.Equal(t, endpoint.Labels{endpoint.OwnerLabelKey: "other-owner"}, labels["bar.test-zone.example.org"])
+
+	changes := (&plan.Plan{
+		Policies:       []plan.Policy{&plan.SyncPolicy{}},
+		Current:        records,
+		Desired:        []*endpoint.Endpoint{endpoint.NewEndpoint("foo.test-zone.example.org", endpoint.RecordTypeCNAME, "foo.loadbalancer.com")},
+		ManagedRecords: []string{endpoint.RecordTypeCNAME},
+	}).Calculate().Changes
+	require.Len(t, changes.UpdateNew, 1)
+	require.NoError(t, r.ApplyChanges(ctx, changes))
+
+	// Once re-encrypted, the records are readable without the previous key.
+	assertTXTRecordsEncrypted(t, p, "foo.test-zone.example.org", testPrimaryKey)
+}
+
+func labelsByName(records []*endpoint.Endpoint) map[string]endpoint.Labels {
+	labels := map[string]endpoint.Labels{}
+	for _, record := range records {
+		labels[record.DNSName] = record.Labels
+	}
+	return labels
+}
+
+// assertTXTRecordsEncrypted asserts that both TXT records of the name are encrypted with the key.
+func assertTXTRecordsEncrypted(t *testing.T, p *inmemory.InMemoryProvider, name string, key []byte) {
+	t.Helper()
+	e, err := newTXTEncryption([][]byte{key})
+	require.NoError(t, err)
+	records, err := p.Records(context.Background())
+	require.NoError(t, err)
+
+	found := 0
+	for _, record := range records {
+		if record.RecordType == endpoint.RecordTypeTXT && strings.HasSuffix(record.DNSName, name) {
+			found++
+			text, _, err := e.decrypt(record.Targets[0])
+			require.NoError(t, err, record.DNSName)
+			assert.Contains(t, text, "external-dns/owner=owner", record.DNSName)
+		}
+	}
+	assert.Equal(t, 2, found)
+}
@@ -18,6 +18,7 @@ package registry
 
 import (
 	"context"
+	"errors"
 	"reflect"
 	"strings"
 	"testing"
@@ -46,20 +47,20 @@ func TestTXTRegistry(t *testing.T) {
 
 func testTXTRegistryNew(t *testing.T) {
 	p := inmemory.NewInMemoryProvider()
-	_, err := NewTXTRegistry(p, "txt", "", "", time.Hour, "", []string{})
+	_, err := NewTXTRegistry(p, "txt", "", "", nil, time.Hour, "", []string{}, nil, false)
 	require.Error(t, err)
 
-	_, err = NewTXTRegistry(p, "", "txt", "", time.Hour, "", []string{})
+	_, err = NewTXTRegistry(p, "", "txt", "", nil, time.Hour, "", []string{}, nil, false)
 	require.Error(t, err)
 
-	r, err := NewTXTRegistry(p, "txt", "", "owner", time.Hour, "", []string{})
+	r, err := NewTXTRegistry(p, "txt", "", "owner", nil, time.Hour, "", []string{}, nil, false)
 	require.NoError(t, err)
 	assert.Equal(t, p, r.provider)
 
-	r, err = NewTXTRegistry(p, "", "txt", "owner", time.Hour, "", []string{})
+	r, err = NewTXTRegistry(p, "", "txt", "owner", nil, time.Hour, "", []string{}, nil, false)
 	require.NoError(t, err)
 
-	_, err = NewTXTRegistry(p, "txt", "txt", "owner", time.Hour, "", []string{})
+	_, err = NewTXTRegistry(p, "txt", "txt", "owner", nil, time.Hour, "", []string{}, nil, false)
 	require.Error(t, err)
 
 	_, ok := r.mapper.(affixNameMapper)
@@ -67,7 +68,7 @@ func testTXTRegistryNew(t *testing.T) {
 	assert.Equal(t, "owner", r.ownerID)
 	assert.Equal(t, p, r.provider)
 
-	r, err = NewTXTRegistry(p, "", "", "owner", time.Hour, "", []string{})
+	r, err = NewTXTRegistry(p, "", "", "owner", nil, time.Hour, "", []string{}, nil, false)
 	require.NoError(t, err)
 
 	_, ok = r.mapper.(affixNameMapper)
@@ -183,13 +184,13 @@ func testTXTRegistryRecordsPrefixed(t *testing.T) {
 		},
 	}
 
-	r, _ := NewTXTRegistry(p, "txt.", "", "owner", time.Hour, "wc", []string{})
+	r, _ := NewTXTRegistry(p, "txt.", "", "owner", nil, time.Hour, "wc", []string{}, nil, false)
 	records, _ := r.Records(ctx)
 
 	assert.True(t, testutils.SameEndpoints(records, expectedRecords))
 
 	// Ensure prefix is case-insensitive
-	r, _ = NewTXTRegistry(p, "TxT.", "", "owner", time.Hour, "", []string{})
+	r, _ = NewTXTRegistry(p, "TxT.", "", "owner", nil, time.Hour, "", []string{}, nil, false)
 	records, _ = r.Records(ctx)
 
 	assert.True(t, testutils.SameEndpointLabels(records, expectedRecords))
@@ -288,13 +289,13 @@ func testTXTRegistryRecordsSuffixed(t *testing.T) {
 		},
 	}
 
-	r, _ := NewTXTRegistry(p, "", "-txt", "owner", time.Hour, "", []string{})
+	r, _ := NewTXTRegistry(p, "", "-txt", "owner", nil, time.Hour, "", []string{}, nil, false)
 	records, _ := r.Records(ctx)
 
 	assert.True(t, testutils.SameEndpoints(records, expectedRecords))
 
 	// Ensure prefix is case-insensitive
-	r, _ = NewTXTRegistry(p, "", "-TxT", "owner", time.Hour, "", []string{})
+	r, _ = NewTXTRegistry(p, "", "-TxT", "owner", nil, time.Hour, "", []string{}, nil, false)
 	records, _ = r.Records(ctx)
 
 	assert.True(t, testutils.SameEndpointLabels(records, expectedRecords))
@@ -369,7 +370,7 @@ func testTXTRegistryRecordsNoPrefix(t *testing.T) {
 		},
 	}
 
-	r, _ := NewTXTRegistry(p, "", "", "owner", time.Hour, "", []string{})
+	r, _ := NewTXTRegistry(p, "", "", "owner", nil, time.Hour, "", []string{}, nil, false)
 	records, _ := r.Records(ctx)
 
 	assert.True(t, testutils.SameEndpoints(records, expectedRecords))
@@ -412,7 +413,7 @@ func testTXTRegistryApplyChangesWithPrefix(t *testing.T) {
 			newEndpointWithOwner("txt.cname-multiple.test-zone.example.org", "\"heritage=external-dns,external-dns/owner=owner\"", endpoint.RecordTypeTXT, "").WithSetIdentifier("test-set-2"),
 		},
 	})
-	r, _ := NewTXTRegistry(p, "txt.", "", "owner", time.Hour, "", []string{})
+	r, _ := NewTXTRegistry(p, "txt.", "", "owner", nil, time.Hour, "", []string{}, nil, false)
 
 	changes := &plan.Changes{
 		Create: []*endpoint.Endpoint{
@@ -501,7 +502,7 @@ func testTXTRegistryApplyChangesWithTemplatedPrefix(t *testing.T) {
 	p.ApplyChanges(ctx, &plan.Changes{
 		Create: []*endpoint.Endpoint{},
 	})
-	r, _ := NewTXTRegistry(p, "prefix%{record_type}.", "", "owner", time.Hour, "", []string{})
+	r, _ := NewTXTRegistry(p, "prefix%{record_type}.", "", "owner", nil, time.Hour, "", []string{}, nil, false)
 	changes := &plan.Changes{
 		Create: []*endpoint.Endpoint{
 			newEndpointWithOwnerResource("new-record-1.test-zone.example.org", "new-loadbalancer-1.lb.com", endpoint.RecordTypeCNAME, "", "ingress/default/my-ingress"),
@@ -544,7 +545,7 @@ func testTXTRegistryApplyChangesWithTemplatedSuffix(t *testing.T) {
 	p.OnApplyChanges = func(ctx context.Context, got *plan.Changes) {
 		assert.Equal(t, ctxEndpoints, ctx.Value(provider.RecordsContextKey))
 	}
-	r, _ := NewTXTRegistry(p, "", "-%{record_type}suffix", "owner", time.Hour, "", []string{})
+	r, _ := NewTXTRegistry(p, "", "-%{record_type}suffix", "owner", nil, time.Hour, "", []string{}, nil, false)
 	changes := &plan.Changes{
 		Create: []*endpoint.Endpoint{
 			newEndpointWithOwnerResource("new-record-1.test-zone.example.org", "new-loadbalancer-1.lb.com", endpoint.RecordTypeCNAME, "", "ingress/default/my-ingress"),
@@ -609,7 +610,7 @@ func testTXTRegistryApplyChangesWithSuffix(t *testing.T) {
 			newEndpointWithOwner("cname-multiple-txt.test-zone.example.org", "\"heritage=external-dns,external-dns/owner=owner\"", endpoint.RecordTypeTXT, "").WithSetIdentifier("test-set-2"),
 		},
 	})
-	r, _ := NewTXTRegistry(p, "", "-txt", "owner", time.Hour, "wildcard", []string{})
+	r, _ := NewTXTRegistry(p, "", "-txt", "owner", nil, time.Hour, "wildcard", []string{}, nil, false)
 
 	changes := &plan.Changes{
 		Create: []*endpoint.Endpoint{
@@ -713,7 +714,7 @@ func testTXTRegistryApplyChangesNoPrefix(t *testing.T) {
 			newEndpointWithOwner("cname-foobar.test-zone.example.org", "\"heritage=external-dns,external-dns/owner=owner\"", endpoint.RecordTypeTXT, ""),
 		},
 	})
-	r, _ := NewTXTRegistry(p, "", "", "owner", time.Hour, "", []string{})
+	r, _ := NewTXTRegistry(p, "", "", "owner", nil, time.Hour, "", []string{}, nil, false)
 
 	changes := &plan.Changes{
 		Create: []*endpoint.Endpoint{
@@ -863,7 +864,7 @@ func testTXTRegistryMissingRecordsNoPrefix(t *testing.T) {
 		},
 	}
 
-	r, _ := NewTXTRegistry(p, "", "", "owner", time.Hour, "wc", []string{endpoint.RecordTypeCNAME, endpoint.RecordTypeA, endpoint.RecordTypeNS})
+	r, _ := NewTXTRegistry(p, "", "", "owner", nil, time.Hour, "wc", []string{endpoint.RecordTypeCNAME, endpoint.RecordTypeA, endpoint.RecordTypeNS}, nil, false)
 	records, _ := r.Records(ctx)
 	missingRecords := r.MissingRecords()
 
@@ -961,7 +962,7 @@ func testTXTRegistryMissingRecordsWithPrefix(t *testing.T) {
 		},
 	}
 
-	r, _ := NewTXTRegistry(p, "txt.", "", "owner", time.Hour, "wc", []string{endpoint.RecordTypeCNAME, endpoint.RecordTypeA, endpoint.RecordTypeNS})
+	r, _ := NewTXTRegistry(p, "txt.", "", "owner", nil, time.Hour, "wc", []string{endpoint.RecordTypeCNAME, endpoint.RecordTypeA, endpoint.RecordTypeNS}, nil, false)
 	records, _ := r.Records(ctx)
 	missingRecords := r.MissingRecords()
 
@@ -1030,6 +1031,81 @@ func TestCacheMethods(t *testing.T) {
 	}
 }
 
+// partialFailureProvider fails the changes of the records whose name contains failName and
+// applies the other changes to the wrapped provider.
+type partialFailureProvider struct {
+	provider.Provider
+	failName string
+}
+
+func (p *partialFailureProvider) ApplyChanges(ctx context.Context, changes *plan.Changes) error {
+	applied, failed := &plan.Changes{}, &plan.Changes{}
+	split := func(endpoints []*endpoint.Endpoint, applied, failed *[]*endpoint.Endpoint) {
+		for _, ep := range endpoints {
+			if strings.Contains(ep.DNSName, p.failName) {
+				*failed = append(*failed, ep)
+			} else {
+				*applied = append(*applied, ep)
+			}
+		}
+	}
+	split(changes.Create, &applied.Create, &failed.Create)
+	split(changes.Delete, &applied.Delete, &failed.Delete)
+	if err := p.Provider.ApplyChanges(ctx, applied); err != nil {
+		return err
+	}
+	return &provider.ChangesError{Failed: failed, Err: errors.New("boom")}
+}
+
+func TestTXTRegistryPartialFailure(t *testing.T) {
+	ctx := context.Background()
+	p := inmemory.NewInMemoryProvider()
+	p.CreateZone(testZone)
+	r, _ := NewTXTRegistry(&partialFailureProvider{Provider: p, failName: "failing"}, "", "", "owner", nil, time.Hour, "", []string{}, nil, false)
+
+	records, err := r.Records(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, records)
+
+	err = r.ApplyChanges(ctx, &plan.Changes{
+		Create: []*endpoint.Endpoint{
+			newEndpointWithOwner("working.test-zone.example.org", "1.2.3.4", endpoint.RecordTypeA, ""),
+			newEndpointWithOwner("failing.test-zone.example.org", "1.2.3.5", endpoint.RecordTypeA, ""),
+		},
+	})
+	var changesErr *provider.ChangesError
+	require.True(t, errors.As(err, &changesErr))
+	for _, ep := range changesErr.Failed.Create {
+		assert.Contains(t, ep.DNSName, "failing")
+	}
+
+	// the cached records only contain the applied changes, so that the failed ones are planned again
+	records, err = r.Records(ctx)
+	require.NoError(t, err)
+	assert.True(t, testutils.SameEndpoints(records, []*endpoint.Endpoint{
+		newEndpointWithOwner("working.test-zone.example.org", "1.2.3.4", endpoint.RecordTypeA, "owner"),
+	}))
+
+	// without partial failure reporting, the records are listed again from the provider
+	r.provider = &failingProvider{p}
+	err = r.ApplyChanges(ctx, &plan.Changes{
+		Delete: []*endpoint.Endpoint{
+			newEndpointWithOwner("working.test-zone.example.org", "1.2.3.4", endpoint.RecordTypeA, "owner"),
+		},
+	})
+	require.Error(t, err)
+	assert.Nil(t, r.recordsCache)
+}
+
+// failingProvider fails all changes.
+type failingProvider struct {
+	provider.Provider
+}
+
+func (p *failingProvider) ApplyChanges(ctx context.Context, changes *plan.Changes) error {
+	return errors.New("boom")
+}
+
 func TestDropPrefix(t *testing.T) {
 	mapper := newaffixNameMapper("foo-%{record_type}-", "", "")
 	cnameRecord := "foo-cname-test.example.com"
@@ -1080,7 +1156,7 @@ func TestNewTXTScheme(t *testing.T) {
 			newEndpointWithOwner("cname-foobar.test-zone.example.org", "\"heritage=external-dns,external-dns/owner=owner\"", endpoint.RecordTypeTXT, ""),
 		},
 	})
-	r, _ := NewTXTRegistry(p, "", "", "owner", time.Hour, "", []string{})
+	r, _ := NewTXTRegistry(p, "", "", "owner", nil, time.Hour, "", []string{}, nil, false)
 
 	changes := &plan.Changes{
 		Create: []*endpoint.Endpoint{
@@ -1152,7 +1228,7 @@ func TestGenerateTXT(t *testing.T) {
 	}
 	p := inmemory.NewInMemoryProvider()
 	p.CreateZone(testZone)
-	r, _ := NewTXTRegistry(p, "", "", "owner", time.Hour, "", []string{})
+	r, _ := NewTXTRegistry(p, "", "", "owner", nil, time.Hour, "", []string{}, nil, false)
 	gotTXT := r.generateTXTRecord(record)
 	assert.Equal(t, expectedTXT, gotTXT)
 }
@@ -1182,3 +1258,163 @@ func newEndpointWithOwnerResource(dnsName, target, recordType, ownerID, resource
 	e.Labels[endpoint.ResourceLabelKey] = resource
 	return e
 }
+
+func TestTXTRegistryTransferOwnership(t *testing.T) {
+	p := inmemory.NewInMemoryProvider()
+	p.CreateZone(testZone)
+	p.ApplyChanges(context.Background(), &plan.Changes{
+		Create: []*endpoint.Endpoint{
+			newEndpointWithOwner("foo.test-zone.example.org", "foo.loadbalancer.com", endpoint.RecordTypeCNAME, ""),
+			newEndpointWithOwner("foo.test-zone.example.org", "\"heritage=external-dns,external-dns/owner=old-owner,external-dns/resource=ingress/default/foo\"", endpoint.RecordTypeTXT, ""),
+			newEndpointWithOwner("cname-foo.test-zone.example.org", "\"heritage=external-dns,external-dns/owner=old-owner,external-dns/resource=ingress/default/foo\"", endpoint.RecordTypeTXT, ""),
+			newEndpointWithOwner("bar.test-zone.example.org", "bar.loadbalancer.com", endpoint.RecordTypeCNAME, ""),
+			newEndpointWithOwner("bar.test-zone.example.org", "\"heritage=external-dns,external-dns/owner=other-owner\"", endpoint.RecordTypeTXT, ""),
+			newEndpointWithOwner("qux.test-zone.example.org", "random", endpoint.RecordTypeTXT, ""),
+		},
+	})
+	r, _ := NewTXTRegistry(p, "", "", "new-owner", nil, 0, "", []string{}, nil, false)
+
+	_, err := r.TransferOwnership(context.Background(), "new-owner", false)
+	assert.Error(t, err)
+
+	changes, err := r.TransferOwnership(context.Background(), "old-owner", true)
+	require.NoError(t, err)
+	assert.Len(t, changes.UpdateOld, 2)
+	assert.Len(t, changes.UpdateNew, 2)
+	for _, ep := range changes.UpdateNew {
+		assert.Equal(t, endpoint.Targets{"\"heritage=external-dns,external-dns/owner=new-owner,external-dns/resource=ingress/default/foo\""}, ep.Targets)
+	}
+
+	records, err := r.Records(context.Background())
+	require.NoError(t, err)
+	owners := map[string]string{}
+	for _, record := range records {
+		owners[record.DNSName] = record.Labels[endpoint.OwnerLabelKey]
+	}
+	assert.Equal(t, "old-owner", owners["foo.test-zone.example.org"], "dry run must not apply the changes")
+
+	_, err = r.TransferOwnership(context.Background(), "old-owner", false)
+	require.NoError(t, err)
+
+	records, err = r.Records(context.Background())
+	require.NoError(t, err)
+	for _, record := range records {
+		owners[record.DNSName] = record.Labels[endpoint.OwnerLabelKey]
+	}
+	assert.Equal(t, map[string]string{
+		"foo.test-zone.example.org": "new-owner",
+		"bar.test-zone.example.org": "other-owner",
+		"qux.test-zone.example.org": "",
+	}, owners)
+}
+
+func TestTXTRegistryOwnerGroup(t *testing.T) {
+	p := inmemory.NewInMemoryProvider()
+	p.CreateZone(testZone)
+	p.ApplyChanges(context.Background(), &plan.Changes{
+		Create: []*endpoint.Endpoint{
+			newEndpointWithOwner("foo.test-zone.example.org", "foo.loadbalancer.com", endpoint.RecordTypeCNAME, ""),
+			newEndpointWithOwner("foo.test-zone.example.org", "\"heritage=external-dns,external-dns/owner=passive\"", endpoint.RecordTypeTXT, ""),
+			newEndpointWithOwner("cname-foo.test-zone.example.org", "\"heritage=external-dns,external-dns/owner=passive\"", endpoint.RecordTypeTXT, ""),
+			newEndpointWithOwner("bar.test-zone.example.org", "bar.loadbalancer.com", endpoint.RecordTypeCNAME, ""),
+			newEndpointWithOwner("bar.test-zone.example.org", "\"heritage=external-dns,external-dns/owner=other\"", endpoint.RecordTypeTXT, ""),
+			newEndpointWithOwner("cname-bar.test-zone.example.org", "\"heritage=external-dns,external-dns/owner=other\"", endpoint.RecordTypeTXT, ""),
+		},
+	})
+	r, _ := NewTXTRegistry(p, "", "", "active", []string{"passive"}, 0, "", []string{}, nil, false)
+
+	changes := &plan.Changes{
+		UpdateOld: []*endpoint.Endpoint{
+			newEndpointWithOwner("foo.test-zone.example.org", "foo.loadbalancer.com", endpoint.RecordTypeCNAME, "passive"),
+		},
+		UpdateNew: []*endpoint.Endpoint{
+			newEndpointWithOwner("foo.test-zone.example.org", "new-foo.loadbalancer.com", endpoint.RecordTypeCNAME, "passive"),
+		},
+		Delete: []*endpoint.Endpoint{
+			newEndpointWithOwner("bar.test-zone.example.org", "bar.loadbalancer.com", endpoint.RecordTypeCNAME, "other"),
+		},
+	}
+	expected := &plan.Changes{
+		UpdateOld: []*endpoint.Endpoint{
+			newEndpointWithOwner("foo.test-zone.example.org", "foo.loadbalancer.com", endpoint.RecordTypeCNAME, "passive"),
+			newEndpointWithOwner("foo.test-zone.example.org", "\"heritage=external-dns,external-dns/owner=passive\"", endpoint.RecordTypeTXT, ""),
+			newEndpointWithOwner("cname-foo.test-zone.example.org", "\"heritage=external-dns,external-dns/owner=passive\"", endpoint.RecordTypeTXT, ""),
+		},
+		UpdateNew: []*endpoint.Endpoint{
+			newEndpointWithOwner("foo.test-zone.example.org", "new-foo.loadbalancer.com", endpoint.RecordTypeCNAME, "active"),
+			newEndpointWithOwner("foo.test-zone.example.org", "\"heritage=external-dns,external-dns/owner=active\"", endpoint.RecordTypeTXT, ""),
+			newEndpointWithOwner("cname-foo.test-zone.example.org", "\"heritage=external-dns,external-dns/owner=active\"", endpoint.RecordTypeTXT, ""),
+		},
+	}
+	p.OnApplyChanges = func(ctx context.Context, got *plan.Changes) {
+		mExpected := map[string][]*endpoint.Endpoint{
+			"Create":    expected.Create,
+			"UpdateNew": expected.UpdateNew,
+			"UpdateOld": expected.UpdateOld,
+			"Delete":    expected.Delete,
+		}
+		mGot := map[string][]*endpoint.Endpoint{
+			"Create":    got.Create,
+			"UpdateNew": got.UpdateNew,
+			"UpdateOld": got.UpdateOld,
+			"Delete":    got.Delete,
+		}
+		assert.True(t, testutils.SamePlanChanges(mGot, mExpected))
+	}
+	require.NoError(t, r.ApplyChanges(context.Background(), changes))
+}
+
+func TestTXTRegistryOwnerOverride(t *testing.T) {
+	p := inmemory.NewInMemoryProvider()
+	p.CreateZone(testZone)
+	r, _ := NewTXTRegistry(p, "", "", "owner", nil, 0, "", []string{}, nil, false)
+
+	pinned := newEndpointWithOwner("foo.test-zone.example.org", "foo.loadbalancer.com", endpoint.RecordTypeCNAME, "")
+	pinned.Labels[endpoint.OwnerOverrideLabelKey] = "pinned-owner"
+	changes := &plan.Changes{
+		Create: []*endpoint.Endpoint{
+			pinned,
+			newEndpointWithOwner("bar.test-zone.example.org", "bar.loadbalancer.com", endpoint.RecordTypeCNAME, ""),
+		},
+	}
+	expected := &plan.Changes{
+		Create: []*endpoint.Endpoint{
+			newEndpointWithOwner("foo.test-zone.example.org", "foo.loadbalancer.com", endpoint.RecordTypeCNAME, "pinned-owner"),
+			newEndpointWithOwner("foo.test-zone.example.org", "\"heritage=external-dns,external-dns/owner=pinned-owner\"", endpoint.RecordTypeTXT, ""),
+			newEndpointWithOwner("cname-foo.test-zone.example.org", "\"heritage=external-dns,external-dns/owner=pinned-owner\"", endpoint.RecordTypeTXT, ""),
+			newEndpointWithOwner("bar.test-zone.example.org", "bar.loadbalancer.com", endpoint.RecordTypeCNAME, "owner"),
+			newEndpointWithOwner("bar.test-zone.example.org", "\"heritage=external-dns,external-dns/owner=owner\"", endpoint.RecordTypeTXT, ""),
+			newEndpointWithOwner("cname-bar.test-zone.example.org", "\"heritage=external-dns,external-dns/owner=owner\"", endpoint.RecordTypeTXT, ""),
+		},
+	}
+	p.OnApplyChanges = func(ctx context.Context, got *plan.Changes) {
+		assert.True(t, testutils.SameEndpoints(got.Create, expected.Create))
+		for _, ep := range got.Create {
+			assert.NotContains(t, ep.Labels, endpoint.OwnerOverrideLabelKey)
+		}
+	}
+	require.NoError(t, r.ApplyChanges(context.Background(), changes))
+}
+
+func TestTXTRegistryValidateChanges(t *testing.T) {
+	ctx := context.Background()
+	p := inmemory.NewInMemoryProvider()
+	p.CreateZone(testZone)
+	r, _ := NewTXTRegistry(p, "", "", "owner", nil, time.Hour, "", []string{}, nil, false)
+
+	create := newEndpointWithOwner("new.test-zone.example.org", "1.2.3.4", endpoint.RecordTypeA, "")
+	changes := &plan.Changes{Create: []*endpoint.Endpoint{create}}
+	require.NoError(t, r.ValidateChanges(ctx, changes))
+	assert.Empty(t, create.Labels[endpoint.OwnerLabelKey], "expected the changes not to be modified")
+	records, err := r.Records(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, records, "expected the validated changes not to be applied")
+
+	require.NoError(t, p.ApplyChanges(ctx, &plan.Changes{Create: []*endpoint.Endpoint{
+		newEndpointWithOwner("a-new.test-zone.example.org", "\"heritage=external-dns,external-dns/owner=owner\"", endpoint.RecordTypeTXT, ""),
+	}}))
+	assert.ErrorIs(t, r.ValidateChanges(ctx, changes), inmemory.ErrRecordAlreadyExists, "expected the TXT records to be validated")
+
+	r, _ = NewTXTRegistry(&failingProvider{p}, "", "", "owner", nil, time.Hour, "", []string{}, nil, false)
+	assert.ErrorIs(t, r.ValidateChanges(ctx, changes), provider.ErrChangesValidationNotSupported)
+}
@@ -0,0 +1,287 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+)
+
+const (
+	// txtV4Prefix starts the value of the TXT records in the v4 format
+	txtV4Prefix = "v4:"
+	// txtV4RecordType replaces the record type in the names of the TXT records in the v4 format
+	txtV4RecordType = "v4"
+)
+
+// txtV4Payload is the content of a TXT record in the v4 format, which stores the labels of all
+// the records of a name and set identifier, by record type, as compressed and base64 encoded JSON.
+type txtV4Payload struct {
+	Name   string                     `json:"n"`
+	Labels map[string]endpoint.Labels `json:"l"`
+}
+
+// txtV4Record is a TXT record in the v4 format.
+type txtV4Record struct {
+	txt     *endpoint.Endpoint
+	payload txtV4Payload
+}
+
+// txtLegacyRecord is a TXT record in the previous formats, with the types of the records relying on it.
+type txtLegacyRecord struct {
+	txt   *endpoint.Endpoint
+	users []string
+}
+
+func encodeTXTV4(payload txtV4Payload) (string, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+
+	var compressed bytes.Buffer
+	w, err := flate.NewWriter(&compressed, flate.BestCompression)
+	if err != nil {
+		return "", err
+	}
+	if _, err := w.Write(data); err != nil {
+		return "", err
+	}
+	if err := w.Close(); err != nil {
+		return "", err
+	}
+	return txtV4Prefix + base64.RawStdEncoding.EncodeToString(compressed.Bytes()), nil
+}
+
+func decodeTXTV4(text string) (txtV4Payload, error) {
+	var payload txtV4Payload
+	compressed, err := base64.RawStdEncoding.DecodeString(strings.TrimPrefix(text, txtV4Prefix))
+	if err != nil {
+		return payload, fmt.Errorf("invalid v4 TXT record: %w", err)
+	}
+	data, err := io.ReadAll(flate.NewReader(bytes.NewReader(compressed)))
+	if err != nil {
+		return payload, fmt.Errorf("invalid v4 TXT record: %w", err)
+	}
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return payload, fmt.Errorf("invalid v4 TXT record: %w", err)
+	}
+	if payload.Name == "" {
+		return payload, fmt.Errorf("invalid v4 TXT record: missing name")
+	}
+	return payload, nil
+}
+
+// parseV4 returns the payload of a TXT record in the v4 format and whether it is outdated, i.e.
+// to be stored again encrypted with the primary key. It returns false if the record is not in
+// the v4 format.
+func (im *TXTRegistry) parseV4(target string) (txtV4Payload, bool, bool) {
+	text, outdated := strings.Trim(target, "\""), im.encryption != nil
+	if im.encryption != nil {
+		if decrypted, key, err := im.encryption.decrypt(target); err == nil {
+			text, outdated = decrypted, key > 0
+		}
+	}
+	if !strings.HasPrefix(text, txtV4Prefix) {
+		return txtV4Payload{}, false, false
+	}
+
+	payload, err := decodeTXTV4(text)
+	if err != nil {
+		log.Warnf("Ignoring TXT record: %v", err)
+		return txtV4Payload{}, false, false
+	}
+	return payload, outdated, true
+}
+
+// serializeV4 returns the target of the TXT record in the v4 format storing the payload.
+func (im *TXTRegistry) serializeV4(payload txtV4Payload) (string, error) {
+	text, err := encodeTXTV4(payload)
+	if err != nil {
+		return "", err
+	}
+	if im.encryption == nil {
+		return fmt.Sprintf("\"%s\"", text), nil
+	}
+	return im.encryption.encrypt(text)
+}
+
+// v4Key returns the key of the TXT record in the v4 format of the records of a name and set identifier.
+func (im *TXTRegistry) v4Key(dnsName, setIdentifier string) string {
+	return fmt.Sprintf("%s::%s", strings.ToLower(im.mapper.toNewTXTName(dnsName, txtV4RecordType)), setIdentifier)
+}
+
+// v4Labels returns the labels of a record stored in a TXT record in the v4 format.
+func v4Labels(r *endpoint.Endpoint) endpoint.Labels {
+	labels := endpoint.Labels{}
+	for k, v := range r.Labels {
+		if k != endpoint.OutdatedOwnershipLabelKey && k != endpoint.OwnerOverrideLabelKey {
+			labels[k] = v
+		}
+	}
+	return labels
+}
+
+// addV4Changes adds to the changes the TXT records in the v4 format storing the labels of the
+// created, updated and deleted records, and the deletion of the TXT records in the previous formats
+// no longer used. It returns the resulting TXT records in the v4 format by key, nil once deleted,
+// to be stored once the changes are applied.
+func (im *TXTRegistry) addV4Changes(changes *plan.Changes) map[string]*txtV4Record {
+	pending := map[string]*txtV4Record{}
+	// the records the TXT records are created for, whose provider specific properties they inherit
+	owners := map[string]*endpoint.Endpoint{}
+	pendingPayload := func(r *endpoint.Endpoint) *txtV4Payload {
+		key := im.v4Key(r.DNSName, r.SetIdentifier)
+		if record, ok := pending[key]; ok {
+			return &record.payload
+		}
+		record := &txtV4Record{payload: txtV4Payload{Name: r.DNSName, Labels: map[string]endpoint.Labels{}}}
+		if current, ok := im.txtV4Records[key]; ok {
+			record.payload.Name = current.payload.Name
+			for recordType, labels := range current.payload.Labels {
+				record.payload.Labels[recordType] = labels
+			}
+		}
+		pending[key] = record
+		owners[key] = r
+		return &record.payload
+	}
+
+	var removed, added []*endpoint.Endpoint
+	removed = append(append(removed, changes.Delete...), changes.UpdateOld...)
+	added = append(append(added, changes.Create...), changes.UpdateNew...)
+	for _, r := range removed {
+		if r.RecordType != endpoint.RecordTypeTXT {
+			delete(pendingPayload(r).Labels, r.RecordType)
+		}
+	}
+	for _, r := range added {
+		if r.RecordType != endpoint.RecordTypeTXT {
+			pendingPayload(r).Labels[r.RecordType] = v4Labels(r)
+			owners[im.v4Key(r.DNSName, r.SetIdentifier)] = r
+		}
+	}
+
+	keys := make([]string, 0, len(pending))
+	for key := range pending {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		record, current := pending[key], im.txtV4Records[key]
+		if len(record.payload.Labels) == 0 {
+			if current != nil {
+				changes.Delete = append(changes.Delete, current.txt)
+			}
+			pending[key] = nil
+			continue
+		}
+
+		target, err := im.serializeV4(record.payload)
+		if err != nil {
+			log.Errorf("Skipping the TXT record of %s: %v", record.payload.Name, err)
+			delete(pending, key)
+			continue
+		}
+		r := owners[key]
+		record.txt = endpoint.NewEndpoint(im.mapper.toNewTXTName(r.DNSName, txtV4RecordType), endpoint.RecordTypeTXT, target).WithSetIdentifier(r.SetIdentifier)
+		record.txt.ProviderSpecific = r.ProviderSpecific
+		if current != nil {
+			changes.UpdateOld = append(changes.UpdateOld, current.txt)
+			changes.UpdateNew = append(changes.UpdateNew, record.txt)
+		} else {
+			changes.Create = append(changes.Create, record.txt)
+		}
+	}
+
+	changes.Delete = append(changes.Delete, im.unusedLegacyTXTRecords(removed, pending)...)
+	return pending
+}
+
+// unusedLegacyTXTRecords returns the TXT records in the previous formats of the removed records
+// which no record relies on anymore, once the labels of the records are stored in the v4 format.
+func (im *TXTRegistry) unusedLegacyTXTRecords(removed []*endpoint.Endpoint, pending map[string]*txtV4Record) []*endpoint.Endpoint {
+	var unused []*endpoint.Endpoint
+	seen := map[string]struct{}{}
+	for _, r := range removed {
+		if r.RecordType == endpoint.RecordTypeTXT {
+			continue
+		}
+		key := im.v4Key(r.DNSName, r.SetIdentifier)
+		record, ok := pending[key]
+		if !ok {
+			record = im.txtV4Records[key]
+		}
+
+		for _, name := range []string{im.mapper.toTXTName(r.DNSName), im.mapper.toNewTXTName(r.DNSName, r.RecordType)} {
+			legacyKey := fmt.Sprintf("%s::%s", strings.ToLower(name), r.SetIdentifier)
+			legacy, ok := im.legacyTXTRecords[legacyKey]
+			if _, done := seen[legacyKey]; !ok || done {
+				continue
+			}
+			seen[legacyKey] = struct{}{}
+
+			// a TXT record in the previous formats is kept while a record not stored in the
+			// v4 format, and not deleted, relies on it
+			inUse := false
+			for _, recordType := range legacy.users {
+				migrated := false
+				if record != nil {
+					_, migrated = record.payload.Labels[recordType]
+				}
+				if !migrated && !removedRecord(removed, r.DNSName, r.SetIdentifier, recordType) {
+					inUse = true
+				}
+			}
+			if !inUse {
+				unused = append(unused, legacy.txt)
+			}
+		}
+	}
+	return unused
+}
+
+func removedRecord(removed []*endpoint.Endpoint, dnsName, setIdentifier, recordType string) bool {
+	for _, r := range removed {
+		if strings.EqualFold(r.DNSName, dnsName) && r.SetIdentifier == setIdentifier && r.RecordType == recordType {
+			return true
+		}
+	}
+	return false
+}
+
+// storeV4Records stores the TXT records in the v4 format once the changes are applied.
+func (im *TXTRegistry) storeV4Records(records map[string]*txtV4Record) {
+	for key, record := range records {
+		if record == nil {
+			delete(im.txtV4Records, key)
+		} else {
+			im.txtV4Records[key] = record
+		}
+	}
+}
@@ -0,0 +1,172 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/plan"
+	"sigs.k8s.io/external-dns/provider/inmemory"
+)
+
+func TestTXTV4Encoding(t *testing.T) {
+	payload := txtV4Payload{
+		Name: "foo.test-zone.example.org",
+		Labels: map[string]endpoint.Labels{
+			endpoint.RecordTypeA: {endpoint.OwnerLabelKey: "owner", endpoint.ResourceLabelKey: "ingress/default/foo"},
+			"AAAA":               {endpoint.OwnerLabelKey: "owner", endpoint.ResourceLabelKey: "ingress/default/foo"},
+		},
+	}
+	text, err := encodeTXTV4(payload)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(text, txtV4Prefix))
+	plain := len(payload.Labels[endpoint.RecordTypeA].Serialize(false)) * 2
+	assert.Less(t, len(text), plain, "the v4 format is smaller than the TXT records in the previous format")
+
+	decoded, err := decodeTXTV4(text)
+	require.NoError(t, err)
+	assert.Equal(t, payload, decoded)
+
+	for _, invalid := range []string{"v4:", "v4:not base64!", "v4:aGVsbG8"} {
+		_, err := decodeTXTV4(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestTXTRegistryV4(t *testing.T) {
+	ctx := context.Background()
+	p := inmemory.NewInMemoryProvider()
+	p.CreateZone(testZone)
+	r, err := NewTXTRegistry(p, "", "", "owner", nil, 0, "", []string{}, nil, true)
+	require.NoError(t, err)
+
+	a := newEndpointWithOwnerResource("foo.test-zone.example.org", "1.2.3.4", endpoint.RecordTypeA, "", "ingress/default/foo")
+	aaaa := newEndpointWithOwnerResource("foo.test-zone.example.org", "2001:db8::1", "AAAA", "", "ingress/default/foo")
+	require.NoError(t, r.ApplyChanges(ctx, &plan.Changes{Create: []*endpoint.Endpoint{a, aaaa}}))
+	assert.Equal(t, []string{"v4-foo.test-zone.example.org"}, txtRecordNames(t, p))
+
+	records, err := r.Records(ctx)
+	require.NoError(t, err)
+	for _, record := range records {
+		assert.Equal(t, endpoint.Labels{endpoint.OwnerLabelKey: "owner", endpoint.ResourceLabelKey: "ingress/default/foo"}, record.Labels, record.RecordType)
+	}
+
+	// The TXT record is updated while other records rely on it, and deleted with the last one.
+	require.NoError(t, r.ApplyChanges(ctx, &plan.Changes{Delete: []*endpoint.Endpoint{recordOfType(records, endpoint.RecordTypeA)}}))
+	assert.Equal(t, []string{"v4-foo.test-zone.example.org"}, txtRecordNames(t, p))
+	records, err = r.Records(ctx)
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, "owner", records[0].Labels[endpoint.OwnerLabelKey])
+
+	require.NoError(t, r.ApplyChanges(ctx, &plan.Changes{Delete: records}))
+	assert.Empty(t, txtRecordNames(t, p))
+}
+
+func TestTXTRegistryV4Migration(t *testing.T) {
+	ctx := context.Background()
+	p := inmemory.NewInMemoryProvider()
+	p.CreateZone(testZone)
+	p.ApplyChanges(ctx, &plan.Changes{
+		Create: []*endpoint.Endpoint{
+			newEndpointWithOwner("foo.test-zone.example.org", "1.2.3.4", endpoint.RecordTypeA, ""),
+			newEndpointWithOwner("foo.test-zone.example.org", "\"heritage=external-dns,external-dns/owner=owner\"", endpoint.RecordTypeTXT, ""),
+			newEndpointWithOwner("a-foo.test-zone.example.org", "\"heritage=external-dns,external-dns/owner=owner\"", endpoint.RecordTypeTXT, ""),
+			newEndpointWithOwner("foo.test-zone.example.org", "foo.loadbalancer.com", endpoint.RecordTypeNS, ""),
+			newEndpointWithOwner("ns-foo.test-zone.example.org", "\"heritage=external-dns,external-dns/owner=owner\"", endpoint.RecordTypeTXT, ""),
+			newEndpointWithOwner("bar.test-zone.example.org", "bar.loadbalancer.com", endpoint.RecordTypeCNAME, ""),
+			newEndpointWithOwner("bar.test-zone.example.org", "\"heritage=external-dns,external-dns/owner=other\"", endpoint.RecordTypeTXT, ""),
+		},
+	})
+	r, err := NewTXTRegistry(p, "", "", "owner", nil, 0, "", []string{}, nil, true)
+	require.NoError(t, err)
+
+	records, err := r.Records(ctx)
+	require.NoError(t, err)
+	for _, record := range records {
+		_, outdated := record.Labels[endpoint.OutdatedOwnershipLabelKey]
+		assert.Equal(t, record.Labels[endpoint.OwnerLabelKey] == "owner", outdated, record.DNSName)
+	}
+	assert.Empty(t, r.MissingRecords())
+
+	// The TXT records in the previous formats are deleted once no record relies on them.
+	a := recordOfType(records, endpoint.RecordTypeA)
+	updated := a.DeepCopy()
+	delete(updated.Labels, endpoint.OutdatedOwnershipLabelKey)
+	require.NoError(t, r.ApplyChanges(ctx, &plan.Changes{UpdateOld: []*endpoint.Endpoint{a}, UpdateNew: []*endpoint.Endpoint{updated}}))
+	assert.Equal(t, []string{"bar.test-zone.example.org", "foo.test-zone.example.org", "ns-foo.test-zone.example.org", "v4-foo.test-zone.example.org"}, txtRecordNames(t, p))
+
+	ns := recordOfType(records, endpoint.RecordTypeNS)
+	updated = ns.DeepCopy()
+	delete(updated.Labels, endpoint.OutdatedOwnershipLabelKey)
+	require.NoError(t, r.ApplyChanges(ctx, &plan.Changes{UpdateOld: []*endpoint.Endpoint{ns}, UpdateNew: []*endpoint.Endpoint{updated}}))
+	assert.Equal(t, []string{"bar.test-zone.example.org", "v4-foo.test-zone.example.org"}, txtRecordNames(t, p))
+
+	// The inmemory provider shares the labels of its records, so check the stored payload.
+	payload := v4Payload(t, p, "v4-foo.test-zone.example.org")
+	assert.Equal(t, map[string]endpoint.Labels{
+		endpoint.RecordTypeA:  {endpoint.OwnerLabelKey: "owner"},
+		endpoint.RecordTypeNS: {endpoint.OwnerLabelKey: "owner"},
+	}, payload.Labels)
+}
+
+func v4Payload(t *testing.T, p *inmemory.InMemoryProvider, name string) txtV4Payload {
+	t.Helper()
+	records, err := p.Records(context.Background())
+	require.NoError(t, err)
+
+	for _, record := range records {
+		if record.RecordType == endpoint.RecordTypeTXT && record.DNSName == name {
+			payload, err := decodeTXTV4(strings.Trim(record.Targets[0], `"`))
+			require.NoError(t, err)
+			return payload
+		}
+	}
+	t.Fatalf("no TXT record %s", name)
+	return txtV4Payload{}
+}
+
+func recordOfType(records []*endpoint.Endpoint, recordType string) *endpoint.Endpoint {
+	for _, record := range records {
+		if record.RecordType == recordType {
+			return record
+		}
+	}
+	return nil
+}
+
+func txtRecordNames(t *testing.T, p *inmemory.InMemoryProvider) []string {
+	t.Helper()
+	records, err := p.Records(context.Background())
+	require.NoError(t, err)
+
+	names := []string{}
+	for _, record := range records {
+		if record.RecordType == endpoint.RecordTypeTXT {
+			names = append(names, record.DNSName)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"strings"
+)
+
+// annotationPrefix is the prefix shared by all the external-dns annotations
+const annotationPrefix = "external-dns.alpha.kubernetes.io/"
+
+// annotationPrefixAliases are alternate prefixes accepted in place of annotationPrefix
+var annotationPrefixAliases []string
+
+// SetAnnotationPrefixAliases configures alternate annotation prefixes, e.g. dns.example.io,
+// which are accepted in place of external-dns.alpha.kubernetes.io by all the sources.
+// Annotations with the standard prefix take precedence over their aliases.
+func SetAnnotationPrefixAliases(prefixes []string) {
+	aliases := make([]string, 0, len(prefixes))
+	for _, prefix := range prefixes {
+		prefix = strings.TrimSuffix(strings.TrimSpace(prefix), "/")
+		if prefix == "" {
+			continue
+		}
+		aliases = append(aliases, prefix+"/")
+	}
+	annotationPrefixAliases = aliases
+}
+
+// lookupAnnotation returns the value of an external-dns annotation, falling back to
+// the same annotation under the aliased prefixes.
+func lookupAnnotation(annotations map[string]string, key string) (string, bool) {
+	if v, ok := annotations[key]; ok {
+		return v, true
+	}
+	if !strings.HasPrefix(key, annotationPrefix) {
+		return "", false
+	}
+	name := strings.TrimPrefix(key, annotationPrefix)
+	for _, alias := range annotationPrefixAliases {
+		if v, ok := annotations[alias+name]; ok {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// aliasedAnnotations returns the annotations with the keys under an aliased prefix
+// rewritten to the standard prefix.
+func aliasedAnnotations(annotations map[string]string) map[string]string {
+	if len(annotationPrefixAliases) == 0 {
+		return annotations
+	}
+
+	aliased := make(map[string]string, len(annotations))
+	for k, v := range annotations {
+		aliased[k] = v
+	}
+	// Iterate the aliases in reverse so that the first configured alias wins.
+	for i := len(annotationPrefixAliases) - 1; i >= 0; i-- {
+		alias := annotationPrefixAliases[i]
+		for k, v := range annotations {
+			if !strings.HasPrefix(k, alias) {
+				continue
+			}
+			key := annotationPrefix + strings.TrimPrefix(k, alias)
+			if _, ok := annotations[key]; !ok {
+				aliased[key] = v
+			}
+		}
+	}
+	return aliased
+}
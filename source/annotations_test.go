@@ -0,0 +1,77 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// TestAnnotationPrefixAliases must not run in parallel, it changes the package wide aliases.
+func TestAnnotationPrefixAliases(t *testing.T) {
+	SetAnnotationPrefixAliases([]string{"dns.example.io/", " ", "legacy.example.io"})
+	t.Cleanup(func() { SetAnnotationPrefixAliases(nil) })
+
+	annotations := map[string]string{
+		"dns.example.io/hostname":                     "foo.example.org",
+		"dns.example.io/ttl":                          "60",
+		"legacy.example.io/ttl":                       "120",
+		"dns.example.io/target":                       "lb.example.org",
+		"external-dns.alpha.kubernetes.io/target":     "standard.example.org",
+		"legacy.example.io/aws-weight":                "10",
+		"dns.example.io/set-identifier":               "blue",
+		"dns.example.io/cloudflare-proxied":           "true",
+		"dns.example.io/cloudflare-comment":           "managed",
+		"other.example.io/internal-hostname":          "bar.example.org",
+		"external-dns.alpha.kubernetes.io/controller": "dns-controller",
+	}
+
+	assert.Equal(t, []string{"foo.example.org"}, getHostnamesFromAnnotations(annotations))
+	assert.Nil(t, getInternalHostnamesFromAnnotations(annotations))
+
+	ttl, err := getTTLFromAnnotations(annotations)
+	require.NoError(t, err)
+	assert.Equal(t, endpoint.TTL(60), ttl, "the first alias wins")
+
+	assert.Equal(t, endpoint.Targets{"standard.example.org"}, getTargetsFromTargetAnnotation(annotations), "the standard prefix wins")
+
+	providerSpecific, setIdentifier := getProviderSpecificAnnotations(annotations)
+	assert.Equal(t, "blue", setIdentifier)
+	assert.ElementsMatch(t, endpoint.ProviderSpecific{
+		{Name: CloudflareProxiedKey, Value: "true"},
+		{Name: CloudflareCommentKey, Value: "managed"},
+		{Name: "aws/weight", Value: "10"},
+	}, providerSpecific)
+
+	SetAnnotationPrefixAliases(nil)
+	assert.Nil(t, getHostnamesFromAnnotations(annotations))
+}
+
+func TestNS1Annotations(t *testing.T) {
+	providerSpecific, _ := getProviderSpecificAnnotations(map[string]string{
+		"external-dns.alpha.kubernetes.io/ns1-filters": "up,select_first_n:N=1",
+		"external-dns.alpha.kubernetes.io/ns1-up":      "192.0.2.1=true,192.0.2.2=false",
+	})
+	assert.ElementsMatch(t, endpoint.ProviderSpecific{
+		{Name: "ns1/filters", Value: "up,select_first_n:N=1"},
+		{Name: "ns1/up", Value: "192.0.2.1=true,192.0.2.2=false"},
+	}, providerSpecific)
+}
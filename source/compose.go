@@ -0,0 +1,176 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v2"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// composeFile is the part of a Compose file read by the compose source.
+type composeFile struct {
+	Name     string                    `yaml:"name"`
+	Services map[string]composeService `yaml:"services"`
+}
+
+type composeService struct {
+	Labels composeLabels `yaml:"labels"`
+}
+
+// composeLabels are the labels of a Compose service, given either as a map or as a list of key=value strings.
+type composeLabels map[string]string
+
+func (l *composeLabels) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var m map[string]string
+	if err := unmarshal(&m); err == nil {
+		*l = m
+		return nil
+	}
+
+	var list []string
+	if err := unmarshal(&list); err != nil {
+		return fmt.Errorf("labels must be a map or a list of key=value strings: %w", err)
+	}
+	*l = make(composeLabels, len(list))
+	for _, item := range list {
+		key, value, _ := strings.Cut(item, "=")
+		(*l)[key] = value
+	}
+	return nil
+}
+
+// composeSource is an implementation of Source that provides endpoints for the services
+// of Compose files, without a running Docker daemon.
+//
+// Services are configured with the same keys as the annotations of Kubernetes resources,
+// set as service labels, e.g. external-dns.alpha.kubernetes.io/hostname. As no container
+// addresses are known offline, services without a target label point to the default targets.
+type composeSource struct {
+	files          []string
+	defaultTargets endpoint.Targets
+	labelSelector  labels.Selector
+}
+
+// NewComposeSource creates a new composeSource reading the given Compose files.
+func NewComposeSource(files, defaultTargets []string, annotationFilter string) (Source, error) {
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no Compose file specified")
+	}
+
+	labelSelector, err := getLabelSelector(annotationFilter)
+	if err != nil {
+		return nil, err
+	}
+
+	return &composeSource{
+		files:          files,
+		defaultTargets: defaultTargets,
+		labelSelector:  labelSelector,
+	}, nil
+}
+
+// Endpoints returns endpoint objects for each service of the Compose files having a hostname label.
+func (cs *composeSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	endpoints := []*endpoint.Endpoint{}
+
+	for _, file := range cs.files {
+		compose, err := readComposeFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		project := compose.Name
+		if project == "" {
+			project = filepath.Base(filepath.Dir(file))
+		}
+
+		names := make([]string, 0, len(compose.Services))
+		for name := range compose.Services {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			svcLabels := compose.Services[name].Labels
+			if !matchLabelSelector(cs.labelSelector, svcLabels) {
+				continue
+			}
+
+			controller, ok := lookupAnnotation(svcLabels, controllerAnnotationKey)
+			if ok && controller != controllerAnnotationValue {
+				log.Debugf("Skipping Compose service %s/%s because controller value does not match, found: %s, required: %s",
+					project, name, controller, controllerAnnotationValue)
+				continue
+			}
+
+			hostnames := getHostnamesFromAnnotations(svcLabels)
+			if len(hostnames) == 0 {
+				continue
+			}
+
+			targets := getTargetsFromTargetAnnotation(svcLabels)
+			if len(targets) == 0 {
+				targets = cs.defaultTargets
+			}
+			if len(targets) == 0 {
+				log.Warnf("Skipping Compose service %s/%s: no target", project, name)
+				continue
+			}
+
+			ttl, err := getTTLFromAnnotations(svcLabels)
+			if err != nil {
+				log.Warn(err)
+			}
+			providerSpecific, setIdentifier := getProviderSpecificAnnotations(svcLabels)
+
+			for _, hostname := range hostnames {
+				for _, ep := range endpointsForHostname(hostname, targets, ttl, providerSpecific, setIdentifier) {
+					ep.Labels[endpoint.ResourceLabelKey] = fmt.Sprintf("compose/%s/%s", project, name)
+					endpoints = append(endpoints, ep)
+				}
+			}
+		}
+	}
+
+	return endpoints, nil
+}
+
+func (cs *composeSource) AddEventHandler(ctx context.Context, handler func()) {
+}
+
+func readComposeFile(file string) (*composeFile, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Compose file: %w", err)
+	}
+
+	compose := &composeFile{}
+	if err := yaml.Unmarshal(data, compose); err != nil {
+		return nil, fmt.Errorf("failed to parse Compose file %s: %w", file, err)
+	}
+	return compose, nil
+}
@@ -0,0 +1,153 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+const testComposeFile = `
+services:
+  web:
+    image: nginx
+    labels:
+      external-dns.alpha.kubernetes.io/hostname: www.example.org, web.example.org
+      external-dns.alpha.kubernetes.io/ttl: "60"
+  api:
+    image: api
+    labels:
+      - external-dns.alpha.kubernetes.io/hostname=api.example.org
+      - external-dns.alpha.kubernetes.io/target=lb.example.net
+      - external-dns.alpha.kubernetes.io/cloudflare-proxied=true
+      - external-dns.alpha.kubernetes.io/cloudflare-comment=shop api
+      - tier=backend
+  db:
+    image: postgres
+  other:
+    image: other
+    labels:
+      external-dns.alpha.kubernetes.io/hostname: other.example.org
+      external-dns.alpha.kubernetes.io/controller: other-controller
+`
+
+func TestComposeSource(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Interface", testComposeSourceImplementsSource)
+	t.Run("Endpoints", testComposeSourceEndpoints)
+}
+
+// testComposeSourceImplementsSource tests that composeSource is a valid Source.
+func testComposeSourceImplementsSource(t *testing.T) {
+	assert.Implements(t, (*Source)(nil), new(composeSource))
+}
+
+func testComposeSourceEndpoints(t *testing.T) {
+	apiProviderSpecific := endpoint.ProviderSpecific{
+		{Name: CloudflareProxiedKey, Value: "true"},
+		{Name: CloudflareCommentKey, Value: "shop api"},
+	}
+
+	dir := filepath.Join(t.TempDir(), "shop")
+	require.NoError(t, os.Mkdir(dir, 0o755))
+	composeFile := filepath.Join(dir, "docker-compose.yml")
+	require.NoError(t, os.WriteFile(composeFile, []byte(testComposeFile), 0o644))
+	namedFile := filepath.Join(dir, "compose.override.yml")
+	require.NoError(t, os.WriteFile(namedFile, []byte("name: blog\nservices:\n  ghost:\n    labels:\n      external-dns.alpha.kubernetes.io/hostname: blog.example.org\n"), 0o644))
+	invalidFile := filepath.Join(dir, "invalid.yml")
+	require.NoError(t, os.WriteFile(invalidFile, []byte("services:\n  web:\n    labels: 42\n"), 0o644))
+
+	for _, ti := range []struct {
+		title            string
+		files            []string
+		defaultTargets   []string
+		annotationFilter string
+		expected         []*endpoint.Endpoint
+		expectError      bool
+	}{
+		{
+			title:       "missing file",
+			files:       []string{filepath.Join(dir, "missing.yml")},
+			expectError: true,
+		},
+		{
+			title:       "invalid labels",
+			files:       []string{invalidFile},
+			expectError: true,
+		},
+		{
+			title: "services without target are skipped without default targets",
+			files: []string{composeFile},
+			expected: []*endpoint.Endpoint{
+				{DNSName: "api.example.org", Targets: endpoint.Targets{"lb.example.net"}, RecordType: endpoint.RecordTypeCNAME, ProviderSpecific: apiProviderSpecific, Labels: endpoint.Labels{endpoint.ResourceLabelKey: "compose/shop/api"}},
+			},
+		},
+		{
+			title:          "default targets",
+			files:          []string{composeFile, namedFile},
+			defaultTargets: []string{"192.0.2.10"},
+			expected: []*endpoint.Endpoint{
+				{DNSName: "api.example.org", Targets: endpoint.Targets{"lb.example.net"}, RecordType: endpoint.RecordTypeCNAME, ProviderSpecific: apiProviderSpecific},
+				{DNSName: "www.example.org", Targets: endpoint.Targets{"192.0.2.10"}, RecordType: endpoint.RecordTypeA, RecordTTL: 60, Labels: endpoint.Labels{endpoint.ResourceLabelKey: "compose/shop/web"}},
+				{DNSName: "web.example.org", Targets: endpoint.Targets{"192.0.2.10"}, RecordType: endpoint.RecordTypeA, RecordTTL: 60},
+				{DNSName: "blog.example.org", Targets: endpoint.Targets{"192.0.2.10"}, RecordType: endpoint.RecordTypeA, Labels: endpoint.Labels{endpoint.ResourceLabelKey: "compose/blog/ghost"}},
+			},
+		},
+		{
+			title:            "annotation filter",
+			files:            []string{composeFile},
+			defaultTargets:   []string{"192.0.2.10"},
+			annotationFilter: "tier=backend",
+			expected: []*endpoint.Endpoint{
+				{DNSName: "api.example.org", Targets: endpoint.Targets{"lb.example.net"}, RecordType: endpoint.RecordTypeCNAME, ProviderSpecific: apiProviderSpecific},
+			},
+		},
+	} {
+		ti := ti
+		t.Run(ti.title, func(t *testing.T) {
+			t.Parallel()
+
+			src, err := NewComposeSource(ti.files, ti.defaultTargets, ti.annotationFilter)
+			require.NoError(t, err)
+
+			endpoints, err := src.Endpoints(context.Background())
+			if ti.expectError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			validateEndpoints(t, endpoints, ti.expected)
+		})
+	}
+}
+
+func TestNewComposeSource(t *testing.T) {
+	_, err := NewComposeSource(nil, nil, "")
+	assert.Error(t, err)
+
+	_, err = NewComposeSource([]string{"docker-compose.yml"}, nil, "tier in (")
+	assert.Error(t, err)
+}
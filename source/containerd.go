@@ -0,0 +1,178 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// nerdctlContainer is the part of a container inspected by nerdctl in the Docker compatible mode read by the source.
+type nerdctlContainer struct {
+	ID     string `json:"Id"`
+	Name   string `json:"Name"`
+	Config struct {
+		Labels map[string]string `json:"Labels"`
+	} `json:"Config"`
+	NetworkSettings struct {
+		IPAddress string `json:"IPAddress"`
+		Networks  map[string]struct {
+			IPAddress string `json:"IPAddress"`
+		} `json:"Networks"`
+	} `json:"NetworkSettings"`
+}
+
+// containerdSource is an implementation of Source that provides endpoints for the running
+// containers of the given containerd namespaces.
+//
+// The containers are read with nerdctl rather than with the containerd API: containerd
+// doesn't know the addresses of the containers, which nerdctl attaches with CNI and keeps
+// in its own state. Containers are configured through their labels like the containers of
+// the podman source, e.g. the services of a nerdctl compose project.
+type containerdSource struct {
+	nerdctl        string
+	address        string
+	namespaces     []string
+	defaultTargets endpoint.Targets
+	labelSelector  labels.Selector
+}
+
+// NewContainerdSource creates a new containerdSource running the given nerdctl binary against
+// the given containerd socket.
+func NewContainerdSource(nerdctl, address string, namespaces, defaultTargets []string, annotationFilter string) (Source, error) {
+	if nerdctl == "" {
+		return nil, fmt.Errorf("no nerdctl binary specified")
+	}
+	if len(namespaces) == 0 {
+		return nil, fmt.Errorf("no containerd namespace specified")
+	}
+
+	labelSelector, err := getLabelSelector(annotationFilter)
+	if err != nil {
+		return nil, err
+	}
+
+	return &containerdSource{
+		nerdctl:        nerdctl,
+		address:        address,
+		namespaces:     namespaces,
+		defaultTargets: defaultTargets,
+		labelSelector:  labelSelector,
+	}, nil
+}
+
+// Endpoints returns endpoint objects for each running container having a hostname label.
+func (cs *containerdSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	endpoints := []*endpoint.Endpoint{}
+
+	for _, namespace := range cs.namespaces {
+		containers, err := cs.containers(ctx, namespace)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, c := range containers {
+			c := c
+			name := strings.TrimPrefix(c.Name, "/")
+			endpoints = append(endpoints, containerEndpoints("containerd container", fmt.Sprintf("containerd/%s/%s", namespace, name), c.Config.Labels, cs.labelSelector, cs.defaultTargets, func() (endpoint.Targets, error) {
+				addresses := []string{c.NetworkSettings.IPAddress}
+				for _, network := range c.NetworkSettings.Networks {
+					addresses = append(addresses, network.IPAddress)
+				}
+				return ipv4Targets(addresses), nil
+			})...)
+		}
+	}
+
+	return endpoints, nil
+}
+
+func (cs *containerdSource) AddEventHandler(ctx context.Context, handler func()) {
+}
+
+// containers returns the running containers of the namespace, sorted by name.
+func (cs *containerdSource) containers(ctx context.Context, namespace string) ([]nerdctlContainer, error) {
+	out, err := cs.run(ctx, namespace, "ps", "--quiet", "--no-trunc")
+	if err != nil {
+		return nil, err
+	}
+	ids := strings.Fields(string(out))
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	containers, err := cs.inspect(ctx, namespace, ids...)
+	if err != nil {
+		// a container exiting since it was listed fails the inspection of all of them
+		log.Debugf("Inspecting the containers of containerd namespace %s one by one: %v", namespace, err)
+		containers = nil
+		for _, id := range ids {
+			c, err := cs.inspect(ctx, namespace, id)
+			if err != nil {
+				if strings.Contains(err.Error(), "no such") {
+					log.Debugf("Skipping containerd container %s that is gone: %v", id, err)
+					continue
+				}
+				return nil, err
+			}
+			containers = append(containers, c...)
+		}
+	}
+
+	sort.Slice(containers, func(i, j int) bool { return containers[i].Name < containers[j].Name })
+	return containers, nil
+}
+
+// inspect returns the given containers of the namespace.
+func (cs *containerdSource) inspect(ctx context.Context, namespace string, ids ...string) ([]nerdctlContainer, error) {
+	out, err := cs.run(ctx, namespace, append([]string{"inspect", "--mode=dockercompat"}, ids...)...)
+	if err != nil {
+		return nil, err
+	}
+	var containers []nerdctlContainer
+	if err := json.Unmarshal(out, &containers); err != nil {
+		return nil, fmt.Errorf("failed to decode the containers of containerd namespace %s: %w", namespace, err)
+	}
+	return containers, nil
+}
+
+func (cs *containerdSource) run(ctx context.Context, namespace string, args ...string) ([]byte, error) {
+	globalArgs := []string{"--namespace", namespace}
+	if cs.address != "" {
+		globalArgs = append(globalArgs, "--address", cs.address)
+	}
+
+	out, err := exec.CommandContext(ctx, cs.nerdctl, append(globalArgs, args...)...).Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return nil, fmt.Errorf("failed to run nerdctl %s in containerd namespace %s: %w: %s", args[0], namespace, err, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return nil, fmt.Errorf("failed to run nerdctl %s in containerd namespace %s: %w", args[0], namespace, err)
+	}
+	return out, nil
+}
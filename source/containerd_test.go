@@ -0,0 +1,150 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// fakeNerdctl is a nerdctl answering ps and inspect in the default namespace, failing to inspect
+// a container gone since listed in the vanishing namespace, and failing in the broken namespace.
+const fakeNerdctl = `#!/bin/sh
+[ "$1" = "--namespace" ] || exit 2
+namespace=$2
+shift 2
+[ "$1" = "--address" ] && shift 2
+case "$namespace/$1" in
+default/ps)
+	echo c1
+	echo c2
+	echo c3
+	;;
+default/inspect)
+	[ "$2" = "--mode=dockercompat" ] || exit 2
+	cat <<'EOF'
+[
+	{"Id": "c2", "Name": "api", "Config": {"Labels": {"external-dns.alpha.kubernetes.io/hostname": "api.example.org", "external-dns.alpha.kubernetes.io/target": "lb.example.org"}}},
+	{"Id": "c1", "Name": "web", "Config": {"Labels": {"external-dns.alpha.kubernetes.io/hostname": "web.example.org", "com.docker.compose.project": "shop"}}, "NetworkSettings": {"Networks": {"unknown-eth0": {"IPAddress": "10.4.0.2"}, "unknown-eth1": {"IPAddress": "fd00::2"}}}},
+	{"Id": "c3", "Name": "nolabel", "Config": {"Labels": {}}, "NetworkSettings": {"Networks": {"unknown-eth0": {"IPAddress": "10.4.0.3"}}}}
+]
+EOF
+	;;
+empty/ps)
+	;;
+vanishing/ps)
+	echo c1
+	echo gone
+	;;
+vanishing/inspect)
+	shift 2
+	for id in "$@"; do
+		if [ "$id" != c1 ]; then
+			echo "no such object: $id" >&2
+			exit 1
+		fi
+	done
+	echo '[{"Id": "c1", "Name": "web", "Config": {"Labels": {"external-dns.alpha.kubernetes.io/hostname": "web.example.org"}}, "NetworkSettings": {"Networks": {"unknown-eth0": {"IPAddress": "10.4.0.2"}}}}]'
+	;;
+*)
+	echo "namespace $namespace not found" >&2
+	exit 1
+	;;
+esac
+`
+
+func newFakeNerdctl(t *testing.T) string {
+	path := filepath.Join(t.TempDir(), "nerdctl")
+	require.NoError(t, os.WriteFile(path, []byte(fakeNerdctl), 0o755))
+	return path
+}
+
+func TestContainerdSource(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Interface", testContainerdSourceImplementsSource)
+	t.Run("NewContainerdSource", testContainerdSourceNewContainerdSource)
+	t.Run("Endpoints", testContainerdSourceEndpoints)
+}
+
+// testContainerdSourceImplementsSource tests that containerdSource is a valid Source.
+func testContainerdSourceImplementsSource(t *testing.T) {
+	assert.Implements(t, (*Source)(nil), new(containerdSource))
+}
+
+func testContainerdSourceNewContainerdSource(t *testing.T) {
+	_, err := NewContainerdSource("", "", []string{"default"}, nil, "")
+	assert.Error(t, err)
+
+	_, err = NewContainerdSource("nerdctl", "", nil, nil, "")
+	assert.Error(t, err)
+}
+
+func testContainerdSourceEndpoints(t *testing.T) {
+	nerdctl := newFakeNerdctl(t)
+
+	for _, ti := range []struct {
+		title       string
+		namespaces  []string
+		expected    []*endpoint.Endpoint
+		expectError bool
+	}{
+		{
+			title:      "containers with a hostname label",
+			namespaces: []string{"default", "empty"},
+			expected: []*endpoint.Endpoint{
+				{DNSName: "api.example.org", Targets: endpoint.Targets{"lb.example.org"}, RecordType: endpoint.RecordTypeCNAME, Labels: endpoint.Labels{endpoint.ResourceLabelKey: "containerd/default/api"}},
+				{DNSName: "web.example.org", Targets: endpoint.Targets{"10.4.0.2"}, RecordType: endpoint.RecordTypeA, Labels: endpoint.Labels{endpoint.ResourceLabelKey: "containerd/default/web"}},
+			},
+		},
+		{
+			title:      "containers gone since listed are skipped",
+			namespaces: []string{"vanishing"},
+			expected: []*endpoint.Endpoint{
+				{DNSName: "web.example.org", Targets: endpoint.Targets{"10.4.0.2"}, RecordType: endpoint.RecordTypeA, Labels: endpoint.Labels{endpoint.ResourceLabelKey: "containerd/vanishing/web"}},
+			},
+		},
+		{
+			title:       "failing namespace",
+			namespaces:  []string{"default", "broken"},
+			expectError: true,
+		},
+	} {
+		ti := ti
+		t.Run(ti.title, func(t *testing.T) {
+			src, err := NewContainerdSource(nerdctl, "/run/containerd/containerd.sock", ti.namespaces, nil, "")
+			require.NoError(t, err)
+
+			endpoints, err := src.Endpoints(context.Background())
+			if ti.expectError {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), "namespace broken not found")
+				return
+			}
+			require.NoError(t, err)
+
+			validateEndpoints(t, endpoints, ti.expected)
+		})
+	}
+}
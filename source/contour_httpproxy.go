@@ -135,7 +135,7 @@ func (sc *httpProxySource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint,
 
 	for _, hp := range httpProxies {
 		// Check controller annotation to see if we are responsible.
-		controller, ok := hp.Annotations[controllerAnnotationKey]
+		controller, ok := lookupAnnotation(hp.Annotations, controllerAnnotationKey)
 		if ok && controller != controllerAnnotationValue {
 			log.Debugf("Skipping HTTPProxy %s/%s because controller value does not match, found: %s, required: %s",
 				hp.Namespace, hp.Name, controller, controllerAnnotationValue)
@@ -138,32 +138,7 @@ func (cs *crdSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error
 	}
 
 	for _, dnsEndpoint := range result.Items {
-		// Make sure that all endpoints have targets for A or CNAME type
-		crdEndpoints := []*endpoint.Endpoint{}
-		for _, ep := range dnsEndpoint.Spec.Endpoints {
-			if (ep.RecordType == "CNAME" || ep.RecordType == "A" || ep.RecordType == "AAAA") && len(ep.Targets) < 1 {
-				log.Warnf("Endpoint %s with DNSName %s has an empty list of targets", dnsEndpoint.ObjectMeta.Name, ep.DNSName)
-				continue
-			}
-
-			illegalTarget := false
-			for _, target := range ep.Targets {
-				if strings.HasSuffix(target, ".") {
-					illegalTarget = true
-					break
-				}
-			}
-			if illegalTarget {
-				log.Warnf("Endpoint %s with DNSName %s has an illegal target. The subdomain must consist of lower case alphanumeric characters, '-' or '.', and must start and end with an alphanumeric character (e.g. 'example.com')", dnsEndpoint.ObjectMeta.Name, ep.DNSName)
-				continue
-			}
-
-			if ep.Labels == nil {
-				ep.Labels = endpoint.NewLabels()
-			}
-
-			crdEndpoints = append(crdEndpoints, ep)
-		}
+		crdEndpoints := validDNSEndpointEndpoints(&dnsEndpoint)
 
 		cs.setResourceLabel(&dnsEndpoint, crdEndpoints)
 		endpoints = append(endpoints, crdEndpoints...)
@@ -183,6 +158,37 @@ func (cs *crdSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error
 	return endpoints, nil
 }
 
+// validDNSEndpointEndpoints returns the endpoints of the DNSEndpoint which have the targets required by their record type.
+func validDNSEndpointEndpoints(dnsEndpoint *endpoint.DNSEndpoint) []*endpoint.Endpoint {
+	// Make sure that all endpoints have targets for A or CNAME type
+	crdEndpoints := []*endpoint.Endpoint{}
+	for _, ep := range dnsEndpoint.Spec.Endpoints {
+		if (ep.RecordType == "CNAME" || ep.RecordType == "A" || ep.RecordType == "AAAA") && len(ep.Targets) < 1 {
+			log.Warnf("Endpoint %s with DNSName %s has an empty list of targets", dnsEndpoint.ObjectMeta.Name, ep.DNSName)
+			continue
+		}
+
+		illegalTarget := false
+		for _, target := range ep.Targets {
+			if strings.HasSuffix(target, ".") {
+				illegalTarget = true
+				break
+			}
+		}
+		if illegalTarget {
+			log.Warnf("Endpoint %s with DNSName %s has an illegal target. The subdomain must consist of lower case alphanumeric characters, '-' or '.', and must start and end with an alphanumeric character (e.g. 'example.com')", dnsEndpoint.ObjectMeta.Name, ep.DNSName)
+			continue
+		}
+
+		if ep.Labels == nil {
+			ep.Labels = endpoint.NewLabels()
+		}
+
+		crdEndpoints = append(crdEndpoints, ep)
+	}
+	return crdEndpoints
+}
+
 func (cs *crdSource) setResourceLabel(crd *endpoint.DNSEndpoint, endpoints []*endpoint.Endpoint) {
 	for _, ep := range endpoints {
 		ep.Labels[endpoint.ResourceLabelKey] = fmt.Sprintf("crd/%s/%s", crd.ObjectMeta.Namespace, crd.ObjectMeta.Name)
@@ -0,0 +1,172 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/labels"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// dnsEndpointFileSource is an implementation of Source that provides endpoints from
+// DNSEndpoint manifests stored on the local filesystem, for hosts without a Kubernetes
+// API server. The same manifests can be applied to a cluster and read by the crd source.
+//
+// Each path is either a file or a directory whose *.yaml, *.yml and *.json files are read,
+// e.g. a mounted ConfigMap. A file may hold multiple YAML documents; documents of another
+// apiVersion or kind are ignored.
+type dnsEndpointFileSource struct {
+	paths            []string
+	apiVersion       string
+	kind             string
+	namespace        string
+	annotationFilter labels.Selector
+	labelSelector    labels.Selector
+}
+
+// NewDNSEndpointFileSource creates a new dnsEndpointFileSource with the given config.
+func NewDNSEndpointFileSource(paths []string, apiVersion, kind, namespace, annotationFilter string, labelSelector labels.Selector) (Source, error) {
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no DNSEndpoint path specified")
+	}
+
+	annotationSelector, err := getLabelSelector(annotationFilter)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dnsEndpointFileSource{
+		paths:            paths,
+		apiVersion:       apiVersion,
+		kind:             kind,
+		namespace:        namespace,
+		annotationFilter: annotationSelector,
+		labelSelector:    labelSelector,
+	}, nil
+}
+
+// Endpoints returns endpoint objects.
+func (fs *dnsEndpointFileSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	files, err := fs.files()
+	if err != nil {
+		return nil, err
+	}
+
+	endpoints := []*endpoint.Endpoint{}
+	for _, file := range files {
+		dnsEndpoints, err := fs.readFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, dnsEndpoint := range dnsEndpoints {
+			if fs.namespace != "" && dnsEndpoint.Namespace != fs.namespace {
+				continue
+			}
+			if !fs.labelSelector.Matches(labels.Set(dnsEndpoint.Labels)) || !matchLabelSelector(fs.annotationFilter, dnsEndpoint.Annotations) {
+				continue
+			}
+
+			crdEndpoints := validDNSEndpointEndpoints(dnsEndpoint)
+			for _, ep := range crdEndpoints {
+				ep.Labels[endpoint.ResourceLabelKey] = fmt.Sprintf("crd/%s/%s", dnsEndpoint.Namespace, dnsEndpoint.Name)
+			}
+			endpoints = append(endpoints, crdEndpoints...)
+		}
+	}
+
+	return endpoints, nil
+}
+
+func (fs *dnsEndpointFileSource) AddEventHandler(ctx context.Context, handler func()) {
+}
+
+// files returns the manifest files of the configured paths.
+func (fs *dnsEndpointFileSource) files() ([]string, error) {
+	var files []string
+	for _, path := range fs.paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read DNSEndpoint path: %w", err)
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read DNSEndpoint directory: %w", err)
+		}
+		var dirFiles []string
+		for _, entry := range entries {
+			// skip hidden entries such as the ..data directory of a mounted ConfigMap
+			if strings.HasPrefix(entry.Name(), ".") {
+				continue
+			}
+			switch filepath.Ext(entry.Name()) {
+			case ".yaml", ".yml", ".json":
+				dirFiles = append(dirFiles, filepath.Join(path, entry.Name()))
+			}
+		}
+		sort.Strings(dirFiles)
+		files = append(files, dirFiles...)
+	}
+	return files, nil
+}
+
+// readFile returns the DNSEndpoint documents of a manifest file.
+func (fs *dnsEndpointFileSource) readFile(file string) ([]*endpoint.DNSEndpoint, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read DNSEndpoint file: %w", err)
+	}
+	defer f.Close()
+
+	var dnsEndpoints []*endpoint.DNSEndpoint
+	decoder := utilyaml.NewYAMLOrJSONDecoder(f, 4096)
+	for {
+		dnsEndpoint := &endpoint.DNSEndpoint{}
+		if err := decoder.Decode(dnsEndpoint); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("failed to parse DNSEndpoint file %s: %w", file, err)
+		}
+		if dnsEndpoint.Kind == "" && dnsEndpoint.APIVersion == "" {
+			// empty document
+			continue
+		}
+		if dnsEndpoint.APIVersion != fs.apiVersion || dnsEndpoint.Kind != fs.kind {
+			log.Debugf("Skipping %s %s in %s: not a %s %s", dnsEndpoint.APIVersion, dnsEndpoint.Kind, file, fs.apiVersion, fs.kind)
+			continue
+		}
+		dnsEndpoints = append(dnsEndpoints, dnsEndpoint)
+	}
+	return dnsEndpoints, nil
+}
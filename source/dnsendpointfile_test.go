@@ -0,0 +1,187 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+const testDNSEndpointManifests = `
+apiVersion: externaldns.k8s.io/v1alpha1
+kind: DNSEndpoint
+metadata:
+  name: web
+  namespace: default
+  labels:
+    team: web
+spec:
+  endpoints:
+  - dnsName: www.example.org
+    recordTTL: 300
+    recordType: A
+    targets:
+    - 192.0.2.10
+  - dnsName: empty.example.org
+    recordType: CNAME
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: unrelated
+---
+apiVersion: externaldns.k8s.io/v1alpha1
+kind: DNSEndpoint
+metadata:
+  name: mail
+  namespace: infra
+  annotations:
+    external-dns.alpha.kubernetes.io/environment: prod
+spec:
+  endpoints:
+  - dnsName: mail.example.org
+    recordType: CNAME
+    targets:
+    - mx.example.net.
+  - dnsName: smtp.example.org
+    recordType: CNAME
+    targets:
+    - mx.example.net
+`
+
+const testDNSEndpointJSON = `{
+  "apiVersion": "externaldns.k8s.io/v1alpha1",
+  "kind": "DNSEndpoint",
+  "metadata": {"name": "txt", "namespace": "default"},
+  "spec": {"endpoints": [{"dnsName": "example.org", "recordType": "TXT", "targets": ["v=spf1 -all"]}]}
+}`
+
+func TestDNSEndpointFileSource(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Interface", testDNSEndpointFileSourceImplementsSource)
+	t.Run("Endpoints", testDNSEndpointFileSourceEndpoints)
+}
+
+// testDNSEndpointFileSourceImplementsSource tests that dnsEndpointFileSource is a valid Source.
+func testDNSEndpointFileSourceImplementsSource(t *testing.T) {
+	assert.Implements(t, (*Source)(nil), new(dnsEndpointFileSource))
+}
+
+func testDNSEndpointFileSourceEndpoints(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "endpoints.yaml"), []byte(testDNSEndpointManifests), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "txt.json"), []byte(testDNSEndpointJSON), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("# not a manifest"), 0o644))
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "..data"), 0o755))
+	invalidFile := filepath.Join(t.TempDir(), "invalid.yaml")
+	require.NoError(t, os.WriteFile(invalidFile, []byte("apiVersion: externaldns.k8s.io/v1alpha1\nkind: DNSEndpoint\nspec: [\n"), 0o644))
+
+	for _, ti := range []struct {
+		title            string
+		paths            []string
+		namespace        string
+		annotationFilter string
+		labelFilter      string
+		expected         []*endpoint.Endpoint
+		expectError      bool
+	}{
+		{
+			title:       "missing path",
+			paths:       []string{filepath.Join(dir, "missing.yaml")},
+			expectError: true,
+		},
+		{
+			title:       "invalid manifest",
+			paths:       []string{invalidFile},
+			expectError: true,
+		},
+		{
+			title: "directory",
+			paths: []string{dir},
+			expected: []*endpoint.Endpoint{
+				{DNSName: "www.example.org", Targets: endpoint.Targets{"192.0.2.10"}, RecordType: endpoint.RecordTypeA, RecordTTL: 300, Labels: endpoint.Labels{endpoint.ResourceLabelKey: "crd/default/web"}},
+				{DNSName: "smtp.example.org", Targets: endpoint.Targets{"mx.example.net"}, RecordType: endpoint.RecordTypeCNAME, Labels: endpoint.Labels{endpoint.ResourceLabelKey: "crd/infra/mail"}},
+				{DNSName: "example.org", Targets: endpoint.Targets{"v=spf1 -all"}, RecordType: endpoint.RecordTypeTXT, Labels: endpoint.Labels{endpoint.ResourceLabelKey: "crd/default/txt"}},
+			},
+		},
+		{
+			title: "file",
+			paths: []string{filepath.Join(dir, "txt.json")},
+			expected: []*endpoint.Endpoint{
+				{DNSName: "example.org", Targets: endpoint.Targets{"v=spf1 -all"}, RecordType: endpoint.RecordTypeTXT},
+			},
+		},
+		{
+			title:     "namespace",
+			paths:     []string{dir},
+			namespace: "infra",
+			expected: []*endpoint.Endpoint{
+				{DNSName: "smtp.example.org", Targets: endpoint.Targets{"mx.example.net"}, RecordType: endpoint.RecordTypeCNAME},
+			},
+		},
+		{
+			title:            "annotation filter",
+			paths:            []string{dir},
+			annotationFilter: "external-dns.alpha.kubernetes.io/environment=prod",
+			expected: []*endpoint.Endpoint{
+				{DNSName: "smtp.example.org", Targets: endpoint.Targets{"mx.example.net"}, RecordType: endpoint.RecordTypeCNAME},
+			},
+		},
+		{
+			title:       "label filter",
+			paths:       []string{dir},
+			labelFilter: "team=web",
+			expected: []*endpoint.Endpoint{
+				{DNSName: "www.example.org", Targets: endpoint.Targets{"192.0.2.10"}, RecordType: endpoint.RecordTypeA, RecordTTL: 300},
+			},
+		},
+	} {
+		ti := ti
+		t.Run(ti.title, func(t *testing.T) {
+			t.Parallel()
+
+			labelSelector, err := labels.Parse(ti.labelFilter)
+			require.NoError(t, err)
+
+			src, err := NewDNSEndpointFileSource(ti.paths, "externaldns.k8s.io/v1alpha1", "DNSEndpoint", ti.namespace, ti.annotationFilter, labelSelector)
+			require.NoError(t, err)
+
+			endpoints, err := src.Endpoints(context.Background())
+			if ti.expectError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			validateEndpoints(t, endpoints, ti.expected)
+		})
+	}
+}
+
+func TestNewDNSEndpointFileSource(t *testing.T) {
+	_, err := NewDNSEndpointFileSource(nil, "externaldns.k8s.io/v1alpha1", "DNSEndpoint", "", "", labels.Everything())
+	assert.Error(t, err)
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// domainFilterSource is a Source that removes endpoints not matching the domain filter from its wrapped source.
+type domainFilterSource struct {
+	source       Source
+	domainFilter endpoint.DomainFilter
+}
+
+// NewDomainFilterSource creates a new domainFilterSource wrapping the provided Source.
+func NewDomainFilterSource(source Source, domainFilter endpoint.DomainFilter) Source {
+	return &domainFilterSource{source: source, domainFilter: domainFilter}
+}
+
+// Endpoints collects endpoints from its wrapped source and returns
+// the ones whose DNS name matches the domain filter.
+func (ds *domainFilterSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	result := []*endpoint.Endpoint{}
+
+	endpoints, err := ds.source.Endpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, ep := range endpoints {
+		if !ds.domainFilter.Match(ep.DNSName) {
+			log.Debugf("Skipping endpoint %s not matching the source domain filter", ep.DNSName)
+			continue
+		}
+		result = append(result, ep)
+	}
+
+	return result, nil
+}
+
+func (ds *domainFilterSource) AddEventHandler(ctx context.Context, handler func()) {
+	ds.source.AddEventHandler(ctx, handler)
+}
+
+// sourceDomainFilter returns the domain filter configured for the named source by the
+// <source>=<domain> entries of the source domain filters and exclusions, and whether any
+// entry, be it a filter or an exclusion, is configured for that source.
+func sourceDomainFilter(name string, domainFilters, excludeDomains []string) (endpoint.DomainFilter, bool) {
+	domainsOf := func(entries []string) []string {
+		var domains []string
+		for _, entry := range entries {
+			if source, domain, found := strings.Cut(entry, "="); found && source == name {
+				domains = append(domains, domain)
+			}
+		}
+		return domains
+	}
+	filters, exclusions := domainsOf(domainFilters), domainsOf(excludeDomains)
+	return endpoint.NewDomainFilterWithExclusions(filters, exclusions), len(filters) > 0 || len(exclusions) > 0
+}
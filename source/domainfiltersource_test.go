@@ -0,0 +1,114 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/internal/testutils"
+)
+
+// Validates that domainFilterSource is a Source
+var _ Source = &domainFilterSource{}
+
+func TestDomainFilterSource(t *testing.T) {
+	t.Run("Endpoints", testDomainFilterSourceEndpoints)
+	t.Run("SourceDomainFilter", testSourceDomainFilter)
+}
+
+// testDomainFilterSourceEndpoints tests that endpoints not matching the domain filter are removed.
+func testDomainFilterSourceEndpoints(t *testing.T) {
+	endpoints := []*endpoint.Endpoint{
+		{DNSName: "foo.example.org", Targets: endpoint.Targets{"1.2.3.4"}},
+		{DNSName: "bar.internal.example.org", Targets: endpoint.Targets{"1.2.3.5"}},
+		{DNSName: "foo.example.com", Targets: endpoint.Targets{"1.2.3.6"}},
+	}
+
+	for _, tc := range []struct {
+		title        string
+		domainFilter endpoint.DomainFilter
+		expected     []*endpoint.Endpoint
+	}{
+		{
+			"include filter keeps matching endpoints",
+			endpoint.NewDomainFilter([]string{"example.org"}),
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", Targets: endpoint.Targets{"1.2.3.4"}},
+				{DNSName: "bar.internal.example.org", Targets: endpoint.Targets{"1.2.3.5"}},
+			},
+		},
+		{
+			"exclusions alone remove matching endpoints",
+			endpoint.NewDomainFilterWithExclusions(nil, []string{"internal.example.org"}),
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", Targets: endpoint.Targets{"1.2.3.4"}},
+				{DNSName: "foo.example.com", Targets: endpoint.Targets{"1.2.3.6"}},
+			},
+		},
+		{
+			"exclusions remove matching endpoints",
+			endpoint.NewDomainFilterWithExclusions([]string{"example.org"}, []string{"internal.example.org"}),
+			[]*endpoint.Endpoint{
+				{DNSName: "foo.example.org", Targets: endpoint.Targets{"1.2.3.4"}},
+			},
+		},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			mockSource := new(testutils.MockSource)
+			mockSource.On("Endpoints").Return(endpoints, nil)
+
+			// Create our object under test and get the endpoints.
+			source := NewDomainFilterSource(mockSource, tc.domainFilter)
+
+			endpoints, err := source.Endpoints(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Validate returned endpoints against desired endpoints.
+			validateEndpoints(t, endpoints, tc.expected)
+
+			// Validate that the mock source was called.
+			mockSource.AssertExpectations(t)
+		})
+	}
+}
+
+func testSourceDomainFilter(t *testing.T) {
+	domainFilters := []string{"ingress=example.org", "service=example.com", "ingress=example.net"}
+	excludeDomains := []string{"ingress=internal.example.org", "crd=internal.example.com"}
+
+	domainFilter, ok := sourceDomainFilter("ingress", domainFilters, excludeDomains)
+	assert.True(t, ok)
+	assert.Equal(t, endpoint.NewDomainFilterWithExclusions([]string{"example.org", "example.net"}, []string{"internal.example.org"}), domainFilter)
+
+	domainFilter, ok = sourceDomainFilter("service", domainFilters, excludeDomains)
+	assert.True(t, ok)
+	assert.Equal(t, endpoint.NewDomainFilterWithExclusions([]string{"example.com"}, nil), domainFilter)
+
+	domainFilter, ok = sourceDomainFilter("crd", domainFilters, excludeDomains)
+	assert.True(t, ok, "expected exclusions alone to configure the filter")
+	assert.False(t, domainFilter.Match("app.internal.example.com"))
+	assert.True(t, domainFilter.Match("app.example.com"))
+
+	_, ok = sourceDomainFilter("node", domainFilters, excludeDomains)
+	assert.False(t, ok)
+}
@@ -0,0 +1,226 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// hostSource is an implementation of Source for bare-metal hosts. It publishes the
+// local hostname pointing to the addresses of the local network interfaces, and
+// optionally additional records described by per-unit drop-in files.
+//
+// Every drop-in file in the unit directory with a .conf extension holds one
+// annotation per line in key=value form, using the same annotation keys as the
+// Kubernetes sources, e.g.:
+//
+//	external-dns.alpha.kubernetes.io/hostname=grafana.example.org
+//	external-dns.alpha.kubernetes.io/ttl=60
+//
+// Units without a target annotation point to the host interface addresses.
+type hostSource struct {
+	domain     string
+	interfaces []string
+	unitDir    string
+
+	hostname       func() (string, error)
+	interfaceAddrs func() (map[string][]net.IP, error)
+}
+
+// NewHostSource creates a new hostSource with the given config.
+func NewHostSource(domain string, interfaces []string, unitDir string) (Source, error) {
+	return &hostSource{
+		domain:         strings.Trim(domain, "."),
+		interfaces:     interfaces,
+		unitDir:        unitDir,
+		hostname:       os.Hostname,
+		interfaceAddrs: localInterfaceAddrs,
+	}, nil
+}
+
+// Endpoints returns endpoint objects.
+func (hs *hostSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	endpoints := []*endpoint.Endpoint{}
+
+	targets, err := hs.hostTargets()
+	if err != nil {
+		return nil, err
+	}
+
+	hostname, err := hs.hostname()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get hostname: %w", err)
+	}
+	if fqdn := hs.fqdn(hostname); fqdn != "" && len(targets) > 0 {
+		for _, ep := range endpointsForHostname(fqdn, targets, endpoint.TTL(0), nil, "") {
+			ep.Labels[endpoint.ResourceLabelKey] = fmt.Sprintf("host/%s", hostname)
+			endpoints = append(endpoints, ep)
+		}
+	} else {
+		log.Debugf("Skipping host record for %q: no domain or no usable interface address", hostname)
+	}
+
+	if hs.unitDir == "" {
+		return endpoints, nil
+	}
+
+	files, err := filepath.Glob(filepath.Join(hs.unitDir, "*.conf"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+
+	for _, file := range files {
+		unit := strings.TrimSuffix(filepath.Base(file), ".conf")
+		annotations, err := readUnitAnnotations(file)
+		if err != nil {
+			log.Warnf("Skipping unit %s: %v", unit, err)
+			continue
+		}
+
+		ttl, err := getTTLFromAnnotations(annotations)
+		if err != nil {
+			log.Warn(err)
+		}
+
+		unitTargets := getTargetsFromTargetAnnotation(annotations)
+		if len(unitTargets) == 0 {
+			unitTargets = targets
+		}
+		if len(unitTargets) == 0 {
+			log.Debugf("Skipping unit %s: no targets", unit)
+			continue
+		}
+
+		providerSpecific, setIdentifier := getProviderSpecificAnnotations(annotations)
+		for _, hostname := range getHostnamesFromAnnotations(annotations) {
+			for _, ep := range endpointsForHostname(hostname, unitTargets, ttl, providerSpecific, setIdentifier) {
+				ep.Labels[endpoint.ResourceLabelKey] = fmt.Sprintf("host-unit/%s", unit)
+				endpoints = append(endpoints, ep)
+			}
+		}
+	}
+
+	return endpoints, nil
+}
+
+func (hs *hostSource) AddEventHandler(ctx context.Context, handler func()) {
+}
+
+// fqdn returns the DNS name published for the given local hostname.
+func (hs *hostSource) fqdn(hostname string) string {
+	hostname = strings.TrimSuffix(hostname, ".")
+	if hs.domain == "" {
+		if strings.Contains(hostname, ".") {
+			return hostname
+		}
+		return ""
+	}
+	if i := strings.Index(hostname, "."); i >= 0 {
+		hostname = hostname[:i]
+	}
+	return hostname + "." + hs.domain
+}
+
+// hostTargets returns the IPv4 addresses of the selected local interfaces.
+func (hs *hostSource) hostTargets() (endpoint.Targets, error) {
+	addrs, err := hs.interfaceAddrs()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list interface addresses: %w", err)
+	}
+
+	names := hs.interfaces
+	if len(names) == 0 {
+		for name := range addrs {
+			names = append(names, name)
+		}
+	}
+
+	var targets endpoint.Targets
+	for _, name := range names {
+		for _, ip := range addrs[name] {
+			if ip.To4() == nil || ip.IsLinkLocalUnicast() {
+				continue
+			}
+			targets = append(targets, ip.String())
+		}
+	}
+	sort.Sort(targets)
+	return targets, nil
+}
+
+// localInterfaceAddrs returns the addresses of all local interfaces that are up,
+// excluding loopback interfaces, keyed by interface name.
+func localInterfaceAddrs() (map[string][]net.IP, error) {
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return nil, err
+	}
+
+	result := map[string][]net.IP{}
+	for _, iface := range ifaces {
+		if iface.Flags&net.FlagUp == 0 || iface.Flags&net.FlagLoopback != 0 {
+			continue
+		}
+		addrs, err := iface.Addrs()
+		if err != nil {
+			return nil, err
+		}
+		for _, addr := range addrs {
+			if ipNet, ok := addr.(*net.IPNet); ok {
+				result[iface.Name] = append(result[iface.Name], ipNet.IP)
+			}
+		}
+	}
+	return result, nil
+}
+
+// readUnitAnnotations parses a drop-in file made of key=value lines. Empty lines
+// and lines starting with '#' are ignored.
+func readUnitAnnotations(file string) (map[string]string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	annotations := map[string]string{}
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		key, value, found := strings.Cut(text, "=")
+		if !found {
+			return nil, fmt.Errorf("line %d: expected key=value, got %q", line, text)
+		}
+		annotations[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	return annotations, scanner.Err()
+}
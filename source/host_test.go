@@ -0,0 +1,129 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+func TestHostSource(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Interface", testHostSourceImplementsSource)
+	t.Run("Endpoints", testHostSourceEndpoints)
+}
+
+// testHostSourceImplementsSource tests that hostSource is a valid Source.
+func testHostSourceImplementsSource(t *testing.T) {
+	assert.Implements(t, (*Source)(nil), new(hostSource))
+}
+
+func testHostSourceEndpoints(t *testing.T) {
+	addrs := map[string][]net.IP{
+		"eth0": {net.ParseIP("10.0.0.10"), net.ParseIP("fe80::1")},
+		"eth1": {net.ParseIP("192.168.1.10"), net.ParseIP("169.254.0.1")},
+	}
+
+	for _, ti := range []struct {
+		title      string
+		hostname   string
+		domain     string
+		interfaces []string
+		units      map[string]string
+		expected   []*endpoint.Endpoint
+	}{
+		{
+			title:    "short hostname without domain is skipped",
+			hostname: "node1",
+			expected: []*endpoint.Endpoint{},
+		},
+		{
+			title:    "fully qualified hostname without domain",
+			hostname: "node1.example.org",
+			expected: []*endpoint.Endpoint{
+				{DNSName: "node1.example.org", Targets: endpoint.Targets{"10.0.0.10", "192.168.1.10"}, RecordType: endpoint.RecordTypeA},
+			},
+		},
+		{
+			title:    "domain replaces the hostname domain",
+			hostname: "node1.localdomain",
+			domain:   "example.org.",
+			expected: []*endpoint.Endpoint{
+				{DNSName: "node1.example.org", Targets: endpoint.Targets{"10.0.0.10", "192.168.1.10"}, RecordType: endpoint.RecordTypeA},
+			},
+		},
+		{
+			title:      "interfaces limit the published addresses",
+			hostname:   "node1",
+			domain:     "example.org",
+			interfaces: []string{"eth1"},
+			expected: []*endpoint.Endpoint{
+				{DNSName: "node1.example.org", Targets: endpoint.Targets{"192.168.1.10"}, RecordType: endpoint.RecordTypeA},
+			},
+		},
+		{
+			title:    "units add records",
+			hostname: "node1",
+			domain:   "example.org",
+			units: map[string]string{
+				"grafana.conf": "# grafana dashboard\nexternal-dns.alpha.kubernetes.io/hostname=grafana.example.org, metrics.example.org\nexternal-dns.alpha.kubernetes.io/ttl=60\n",
+				"proxy.conf":   "external-dns.alpha.kubernetes.io/hostname=proxy.example.org\nexternal-dns.alpha.kubernetes.io/target=lb.example.org\n",
+				"broken.conf":  "not an annotation\n",
+				"ignored.txt":  "external-dns.alpha.kubernetes.io/hostname=ignored.example.org\n",
+			},
+			expected: []*endpoint.Endpoint{
+				{DNSName: "node1.example.org", Targets: endpoint.Targets{"10.0.0.10", "192.168.1.10"}, RecordType: endpoint.RecordTypeA},
+				{DNSName: "grafana.example.org", Targets: endpoint.Targets{"10.0.0.10", "192.168.1.10"}, RecordType: endpoint.RecordTypeA, RecordTTL: 60},
+				{DNSName: "metrics.example.org", Targets: endpoint.Targets{"10.0.0.10", "192.168.1.10"}, RecordType: endpoint.RecordTypeA, RecordTTL: 60},
+				{DNSName: "proxy.example.org", Targets: endpoint.Targets{"lb.example.org"}, RecordType: endpoint.RecordTypeCNAME},
+			},
+		},
+	} {
+		ti := ti
+		t.Run(ti.title, func(t *testing.T) {
+			t.Parallel()
+
+			unitDir := ""
+			if len(ti.units) > 0 {
+				unitDir = t.TempDir()
+				for name, content := range ti.units {
+					require.NoError(t, os.WriteFile(filepath.Join(unitDir, name), []byte(content), 0o644))
+				}
+			}
+
+			src, err := NewHostSource(ti.domain, ti.interfaces, unitDir)
+			require.NoError(t, err)
+			hs := src.(*hostSource)
+			hs.hostname = func() (string, error) { return ti.hostname, nil }
+			hs.interfaceAddrs = func() (map[string][]net.IP, error) { return addrs, nil }
+
+			endpoints, err := hs.Endpoints(context.Background())
+			require.NoError(t, err)
+
+			validateEndpoints(t, endpoints, ti.expected)
+		})
+	}
+}
@@ -120,7 +120,7 @@ func (sc *ingressSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, e
 
 	for _, ing := range ingresses {
 		// Check controller annotation to see if we are responsible.
-		controller, ok := ing.Annotations[controllerAnnotationKey]
+		controller, ok := lookupAnnotation(ing.Annotations, controllerAnnotationKey)
 		if ok && controller != controllerAnnotationValue {
 			log.Debugf("Skipping ingress %s/%s because controller value does not match, found: %s, required: %s",
 				ing.Namespace, ing.Name, controller, controllerAnnotationValue)
@@ -274,7 +274,7 @@ func endpointsFromIngress(ing *networkv1.Ingress, ignoreHostnameAnnotation bool,
 	}
 
 	// Determine which hostnames to consider in our final list
-	hostnameSourceAnnotation, hostnameSourceAnnotationExists := ing.Annotations[ingressHostnameSourceKey]
+	hostnameSourceAnnotation, hostnameSourceAnnotationExists := lookupAnnotation(ing.Annotations, ingressHostnameSourceKey)
 	if !hostnameSourceAnnotationExists {
 		return append(definedHostsEndpoints, annotationEndpoints...)
 	}
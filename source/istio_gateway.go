@@ -135,7 +135,7 @@ func (sc *gatewaySource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, e
 
 	for _, gateway := range gateways {
 		// Check controller annotation to see if we are responsible.
-		controller, ok := gateway.Annotations[controllerAnnotationKey]
+		controller, ok := lookupAnnotation(gateway.Annotations, controllerAnnotationKey)
 		if ok && controller != controllerAnnotationValue {
 			log.Debugf("Skipping gateway %s/%s because controller value does not match, found: %s, required: %s",
 				gateway.Namespace, gateway.Name, controller, controllerAnnotationValue)
@@ -137,7 +137,7 @@ func (sc *virtualServiceSource) Endpoints(ctx context.Context) ([]*endpoint.Endp
 
 	for _, virtualService := range virtualServices {
 		// Check controller annotation to see if we are responsible.
-		controller, ok := virtualService.Annotations[controllerAnnotationKey]
+		controller, ok := lookupAnnotation(virtualService.Annotations, controllerAnnotationKey)
 		if ok && controller != controllerAnnotationValue {
 			log.Debugf("Skipping VirtualService %s/%s because controller value does not match, found: %s, required: %s",
 				virtualService.Namespace, virtualService.Name, controller, controllerAnnotationValue)
@@ -0,0 +1,239 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/digitalocean/go-libvirt"
+	"github.com/digitalocean/go-libvirt/socket/dialers"
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+const (
+	// LibvirtMetadataURI is the namespace of the domain metadata element read by the libvirt source, e.g.
+	// virsh metadata <domain> --uri https://sigs.k8s.io/external-dns --key external-dns --set '<dns><hostname>vm.example.org</hostname></dns>'
+	LibvirtMetadataURI = "https://sigs.k8s.io/external-dns"
+
+	libvirtAddressSourceLease = "lease"
+	libvirtAddressSourceAgent = "agent"
+)
+
+// libvirtClient is the subset of the libvirt RPC API used by the libvirt source.
+type libvirtClient interface {
+	ConnectListAllDomains(NeedResults int32, Flags libvirt.ConnectListAllDomainsFlags) ([]libvirt.Domain, uint32, error)
+	DomainInterfaceAddresses(Dom libvirt.Domain, Source uint32, Flags uint32) ([]libvirt.DomainInterface, error)
+	DomainGetMetadata(Dom libvirt.Domain, Type int32, Uri libvirt.OptString, Flags libvirt.DomainModificationImpact) (string, error)
+	ConnectToURI(uri libvirt.ConnectURI) error
+	Disconnected() <-chan struct{}
+}
+
+// libvirtMetadata is the domain metadata element read by the libvirt source.
+type libvirtMetadata struct {
+	Hostnames []string `xml:"hostname"`
+	TTL       string   `xml:"ttl"`
+}
+
+// libvirtSource is an implementation of Source that provides endpoints for the
+// running domains of a libvirt daemon.
+type libvirtSource struct {
+	client        libvirtClient
+	uri           libvirt.ConnectURI
+	addressSource uint32
+	fqdnTemplate  *template.Template
+}
+
+// NewLibvirtClient connects to the libvirt daemon listening on the given unix socket.
+func NewLibvirtClient(socket, uri string, timeout time.Duration) (*libvirt.Libvirt, error) {
+	l := libvirt.NewWithDialer(dialers.NewLocal(dialers.WithSocket(socket), dialers.WithLocalTimeout(timeout)))
+	if err := l.ConnectToURI(libvirt.ConnectURI(uri)); err != nil {
+		return nil, fmt.Errorf("failed to connect to libvirt at %s: %w", socket, err)
+	}
+	return l, nil
+}
+
+// NewLibvirtSource creates a new libvirtSource with the given config, reconnecting the client to
+// the given URI when the connection to the libvirt daemon is lost.
+func NewLibvirtSource(client libvirtClient, uri, addressSource, fqdnTemplate string) (Source, error) {
+	var src uint32
+	switch addressSource {
+	case libvirtAddressSourceLease, "":
+		src = uint32(libvirt.DomainInterfaceAddressesSrcLease)
+	case libvirtAddressSourceAgent:
+		src = uint32(libvirt.DomainInterfaceAddressesSrcAgent)
+	default:
+		return nil, fmt.Errorf("unsupported libvirt address source %q", addressSource)
+	}
+
+	tmpl, err := parseTemplate(fqdnTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	return &libvirtSource{
+		client:        client,
+		uri:           libvirt.ConnectURI(uri),
+		addressSource: src,
+		fqdnTemplate:  tmpl,
+	}, nil
+}
+
+// Endpoints returns endpoint objects for each running domain having at least one IPv4 address.
+func (ls *libvirtSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	if err := ls.reconnect(); err != nil {
+		return nil, err
+	}
+
+	domains, _, err := ls.client.ConnectListAllDomains(1, libvirt.ConnectListDomainsActive)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list libvirt domains: %w", err)
+	}
+
+	endpoints := []*endpoint.Endpoint{}
+	for _, domain := range domains {
+		metadata, err := ls.domainMetadata(domain)
+		if err != nil {
+			log.Warnf("Skipping libvirt domain %s: %v", domain.Name, err)
+			continue
+		}
+
+		hostnames := metadata.Hostnames
+		if len(hostnames) == 0 {
+			hostnames, err = ls.hostnamesFromTemplate(domain)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if len(hostnames) == 0 {
+			log.Debugf("Skipping libvirt domain %s: no hostname", domain.Name)
+			continue
+		}
+
+		var ttl endpoint.TTL
+		if metadata.TTL != "" {
+			ttl, err = getTTLFromAnnotations(map[string]string{ttlAnnotationKey: metadata.TTL})
+			if err != nil {
+				log.Warnf("libvirt domain %s: %v", domain.Name, err)
+			}
+		}
+
+		targets, err := ls.domainTargets(domain)
+		if err != nil {
+			log.Warnf("Skipping libvirt domain %s: %v", domain.Name, err)
+			continue
+		}
+		if len(targets) == 0 {
+			log.Debugf("Skipping libvirt domain %s: no IPv4 address", domain.Name)
+			continue
+		}
+
+		for _, hostname := range hostnames {
+			for _, ep := range endpointsForHostname(hostname, targets, ttl, nil, "") {
+				ep.Labels[endpoint.ResourceLabelKey] = fmt.Sprintf("libvirt-domain/%s", domain.Name)
+				endpoints = append(endpoints, ep)
+			}
+		}
+	}
+
+	return endpoints, nil
+}
+
+func (ls *libvirtSource) AddEventHandler(ctx context.Context, handler func()) {
+}
+
+// reconnect connects the client again when the connection was lost, e.g. on a restart of libvirtd,
+// which the client doesn't recover from by itself.
+func (ls *libvirtSource) reconnect() error {
+	select {
+	case <-ls.client.Disconnected():
+	default:
+		return nil
+	}
+
+	log.Info("Reconnecting to libvirt")
+	if err := ls.client.ConnectToURI(ls.uri); err != nil {
+		return fmt.Errorf("failed to reconnect to libvirt: %w", err)
+	}
+	return nil
+}
+
+// domainMetadata returns the external-dns metadata element of the domain, or an empty one if it is not defined.
+func (ls *libvirtSource) domainMetadata(domain libvirt.Domain) (libvirtMetadata, error) {
+	metadata := libvirtMetadata{}
+
+	raw, err := ls.client.DomainGetMetadata(domain, int32(libvirt.DomainMetadataElement), libvirt.OptString{LibvirtMetadataURI}, libvirt.DomainAffectCurrent)
+	if err != nil {
+		var libvirtErr libvirt.Error
+		if errors.As(err, &libvirtErr) && libvirtErr.Code == uint32(libvirt.ErrNoDomainMetadata) {
+			return metadata, nil
+		}
+		return metadata, fmt.Errorf("failed to get metadata: %w", err)
+	}
+
+	if err := xml.Unmarshal([]byte(raw), &metadata); err != nil {
+		return metadata, fmt.Errorf("failed to parse metadata: %w", err)
+	}
+	for i, hostname := range metadata.Hostnames {
+		metadata.Hostnames[i] = strings.TrimSpace(hostname)
+	}
+	metadata.TTL = strings.TrimSpace(metadata.TTL)
+	return metadata, nil
+}
+
+func (ls *libvirtSource) hostnamesFromTemplate(domain libvirt.Domain) ([]string, error) {
+	if ls.fqdnTemplate == nil {
+		return nil, nil
+	}
+
+	hostnames, err := execTemplate(ls.fqdnTemplate, domain)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile the hostnames of libvirt domain %s: %w", domain.Name, err)
+	}
+	return hostnames, nil
+}
+
+// domainTargets returns the IPv4 addresses of all interfaces of the domain.
+func (ls *libvirtSource) domainTargets(domain libvirt.Domain) (endpoint.Targets, error) {
+	ifaces, err := ls.client.DomainInterfaceAddresses(domain, ls.addressSource, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get interface addresses: %w", err)
+	}
+
+	var targets endpoint.Targets
+	for _, iface := range ifaces {
+		// the guest agent also reports the loopback interface
+		if iface.Name == "lo" {
+			continue
+		}
+		for _, addr := range iface.Addrs {
+			if addr.Type == int32(libvirt.IPAddrTypeIpv4) {
+				targets = append(targets, addr.Addr)
+			}
+		}
+	}
+	sort.Sort(targets)
+	return targets, nil
+}
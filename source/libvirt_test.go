@@ -0,0 +1,244 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/digitalocean/go-libvirt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+type fakeLibvirtDomain struct {
+	metadata string
+	ifaces   []libvirt.DomainInterface
+	err      error
+}
+
+type fakeLibvirtClient struct {
+	domains map[string]fakeLibvirtDomain
+	listErr error
+
+	// disconnected is closed when the connection is lost, nil keeps the client connected
+	disconnected chan struct{}
+	connectErr   error
+	connectedTo  []libvirt.ConnectURI
+}
+
+func (c *fakeLibvirtClient) ConnectListAllDomains(NeedResults int32, Flags libvirt.ConnectListAllDomainsFlags) ([]libvirt.Domain, uint32, error) {
+	if c.listErr != nil {
+		return nil, 0, c.listErr
+	}
+	var domains []libvirt.Domain
+	for name := range c.domains {
+		domains = append(domains, libvirt.Domain{Name: name})
+	}
+	return domains, uint32(len(domains)), nil
+}
+
+func (c *fakeLibvirtClient) DomainInterfaceAddresses(Dom libvirt.Domain, Source uint32, Flags uint32) ([]libvirt.DomainInterface, error) {
+	d := c.domains[Dom.Name]
+	return d.ifaces, d.err
+}
+
+func (c *fakeLibvirtClient) DomainGetMetadata(Dom libvirt.Domain, Type int32, Uri libvirt.OptString, Flags libvirt.DomainModificationImpact) (string, error) {
+	d := c.domains[Dom.Name]
+	if d.metadata == "" {
+		return "", libvirt.Error{Code: uint32(libvirt.ErrNoDomainMetadata), Message: "metadata not found"}
+	}
+	return d.metadata, nil
+}
+
+func (c *fakeLibvirtClient) ConnectToURI(uri libvirt.ConnectURI) error {
+	c.connectedTo = append(c.connectedTo, uri)
+	if c.connectErr != nil {
+		return c.connectErr
+	}
+	c.disconnected = make(chan struct{})
+	return nil
+}
+
+func (c *fakeLibvirtClient) Disconnected() <-chan struct{} {
+	return c.disconnected
+}
+
+func libvirtIface(name string, addrs ...string) libvirt.DomainInterface {
+	iface := libvirt.DomainInterface{Name: name}
+	for _, addr := range addrs {
+		addrType := int32(libvirt.IPAddrTypeIpv4)
+		if strings.Contains(addr, ":") {
+			addrType = int32(libvirt.IPAddrTypeIpv6)
+		}
+		iface.Addrs = append(iface.Addrs, libvirt.DomainIPAddr{Type: addrType, Addr: addr})
+	}
+	return iface
+}
+
+func TestLibvirtSource(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Interface", testLibvirtSourceImplementsSource)
+	t.Run("NewLibvirtSource", testLibvirtSourceNewLibvirtSource)
+	t.Run("Endpoints", testLibvirtSourceEndpoints)
+	t.Run("Reconnect", testLibvirtSourceReconnect)
+}
+
+// testLibvirtSourceImplementsSource tests that libvirtSource is a valid Source.
+func testLibvirtSourceImplementsSource(t *testing.T) {
+	assert.Implements(t, (*Source)(nil), new(libvirtSource))
+	assert.Implements(t, (*libvirtClient)(nil), new(libvirt.Libvirt))
+}
+
+func testLibvirtSourceNewLibvirtSource(t *testing.T) {
+	for _, ti := range []struct {
+		title         string
+		addressSource string
+		fqdnTemplate  string
+		expected      uint32
+		expectError   bool
+	}{
+		{title: "default address source", expected: uint32(libvirt.DomainInterfaceAddressesSrcLease)},
+		{title: "lease address source", addressSource: "lease", expected: uint32(libvirt.DomainInterfaceAddressesSrcLease)},
+		{title: "agent address source", addressSource: "agent", expected: uint32(libvirt.DomainInterfaceAddressesSrcAgent)},
+		{title: "unknown address source", addressSource: "arp", expectError: true},
+		{title: "invalid template", fqdnTemplate: "{{.Name", expectError: true},
+	} {
+		ti := ti
+		t.Run(ti.title, func(t *testing.T) {
+			src, err := NewLibvirtSource(&fakeLibvirtClient{}, "qemu:///system", ti.addressSource, ti.fqdnTemplate)
+			if ti.expectError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, ti.expected, src.(*libvirtSource).addressSource)
+		})
+	}
+}
+
+func testLibvirtSourceEndpoints(t *testing.T) {
+	for _, ti := range []struct {
+		title        string
+		fqdnTemplate string
+		client       *fakeLibvirtClient
+		expected     []*endpoint.Endpoint
+		expectError  bool
+	}{
+		{
+			title:        "list error",
+			fqdnTemplate: "{{.Name}}.vm.example.org",
+			client:       &fakeLibvirtClient{listErr: errors.New("connection reset")},
+			expectError:  true,
+		},
+		{
+			title:  "no hostname",
+			client: &fakeLibvirtClient{domains: map[string]fakeLibvirtDomain{"web": {ifaces: []libvirt.DomainInterface{libvirtIface("vnet0", "192.168.122.10")}}}},
+		},
+		{
+			title:        "hostname from template",
+			fqdnTemplate: "{{.Name}}.vm.example.org",
+			client: &fakeLibvirtClient{domains: map[string]fakeLibvirtDomain{
+				"web": {ifaces: []libvirt.DomainInterface{libvirtIface("vnet0", "192.168.122.10", "fd00::10")}},
+				"db":  {ifaces: []libvirt.DomainInterface{libvirtIface("lo", "127.0.0.1"), libvirtIface("vnet1", "192.168.122.11"), libvirtIface("vnet2", "10.0.0.11")}},
+			}},
+			expected: []*endpoint.Endpoint{
+				{DNSName: "web.vm.example.org", Targets: endpoint.Targets{"192.168.122.10"}, RecordType: endpoint.RecordTypeA, Labels: endpoint.Labels{endpoint.ResourceLabelKey: "libvirt-domain/web"}},
+				{DNSName: "db.vm.example.org", Targets: endpoint.Targets{"10.0.0.11", "192.168.122.11"}, RecordType: endpoint.RecordTypeA, Labels: endpoint.Labels{endpoint.ResourceLabelKey: "libvirt-domain/db"}},
+			},
+		},
+		{
+			title:        "hostnames from metadata take precedence over template",
+			fqdnTemplate: "{{.Name}}.vm.example.org",
+			client: &fakeLibvirtClient{domains: map[string]fakeLibvirtDomain{
+				"web": {
+					metadata: `<dns><hostname>www.example.org</hostname><hostname> web.example.org </hostname><ttl>300</ttl></dns>`,
+					ifaces:   []libvirt.DomainInterface{libvirtIface("vnet0", "192.168.122.10")},
+				},
+			}},
+			expected: []*endpoint.Endpoint{
+				{DNSName: "www.example.org", Targets: endpoint.Targets{"192.168.122.10"}, RecordType: endpoint.RecordTypeA, RecordTTL: 300},
+				{DNSName: "web.example.org", Targets: endpoint.Targets{"192.168.122.10"}, RecordType: endpoint.RecordTypeA, RecordTTL: 300},
+			},
+		},
+		{
+			title:        "domains without addresses or with errors are skipped",
+			fqdnTemplate: "{{.Name}}.vm.example.org",
+			client: &fakeLibvirtClient{domains: map[string]fakeLibvirtDomain{
+				"booting": {},
+				"broken":  {err: errors.New("guest agent is not responding")},
+				"garbled": {metadata: `<dns><hostname>`, ifaces: []libvirt.DomainInterface{libvirtIface("vnet0", "192.168.122.12")}},
+				"web":     {ifaces: []libvirt.DomainInterface{libvirtIface("vnet0", "192.168.122.10")}},
+			}},
+			expected: []*endpoint.Endpoint{
+				{DNSName: "web.vm.example.org", Targets: endpoint.Targets{"192.168.122.10"}, RecordType: endpoint.RecordTypeA},
+			},
+		},
+	} {
+		ti := ti
+		t.Run(ti.title, func(t *testing.T) {
+			t.Parallel()
+
+			src, err := NewLibvirtSource(ti.client, "qemu:///system", "", ti.fqdnTemplate)
+			require.NoError(t, err)
+
+			endpoints, err := src.Endpoints(context.Background())
+			if ti.expectError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			validateEndpoints(t, endpoints, ti.expected)
+		})
+	}
+}
+
+func testLibvirtSourceReconnect(t *testing.T) {
+	disconnected := make(chan struct{})
+	close(disconnected)
+	client := &fakeLibvirtClient{
+		domains:      map[string]fakeLibvirtDomain{"web": {ifaces: []libvirt.DomainInterface{libvirtIface("vnet0", "192.168.122.10")}}},
+		disconnected: disconnected,
+		connectErr:   errors.New("connection refused"),
+	}
+
+	src, err := NewLibvirtSource(client, "qemu:///system", "", "{{.Name}}.vm.example.org")
+	require.NoError(t, err)
+
+	// libvirtd is still restarting
+	_, err = src.Endpoints(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "connection refused")
+
+	client.connectErr = nil
+	endpoints, err := src.Endpoints(context.Background())
+	require.NoError(t, err)
+	validateEndpoints(t, endpoints, []*endpoint.Endpoint{
+		{DNSName: "web.vm.example.org", Targets: endpoint.Targets{"192.168.122.10"}, RecordType: endpoint.RecordTypeA},
+	})
+
+	// the client stays connected
+	_, err = src.Endpoints(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []libvirt.ConnectURI{"qemu:///system", "qemu:///system"}, client.connectedTo)
+}
@@ -0,0 +1,221 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// mqttQoS is the quality of service used for the subscription, at least once delivery.
+const mqttQoS = 1
+
+// mqttSettleTime is the time given to the broker to deliver the retained messages once
+// subscribed, before NewMQTTSource returns.
+const mqttSettleTime = 2 * time.Second
+
+// mqttSource is an implementation of Source that provides the endpoints pushed by
+// agents to an MQTT broker.
+//
+// Every agent publishes the JSON encoded list of its desired endpoints as a retained
+// message on its own topic matching the subscribed topic filter, e.g.
+// external-dns/endpoints/<agent>. Publishing an empty message withdraws the endpoints
+// of that topic. The last list received per topic is cached and returned by Endpoints.
+//
+// NewMQTTSource waits for the first subscription to settle: returning the retained endpoints
+// received so far would make the controller delete the records of the others.
+type mqttSource struct {
+	topic     string
+	ready     chan struct{}
+	readyOnce sync.Once
+
+	sync.RWMutex
+	endpoints map[string][]*endpoint.Endpoint
+	handlers  []func()
+}
+
+// NewMQTTSource creates a new mqttSource connected to the given broker, e.g.
+// tcp://mqtt.example.org:1883 or ssl://mqtt.example.org:8883, and subscribed to the
+// given topic filter, once the retained messages of the topic were delivered. The connection
+// is closed once ctx is done.
+func NewMQTTSource(ctx context.Context, broker, topic, clientID, username, password string, timeout time.Duration) (Source, error) {
+	if broker == "" {
+		return nil, fmt.Errorf("no MQTT broker specified")
+	}
+	if topic == "" {
+		return nil, fmt.Errorf("no MQTT topic specified")
+	}
+
+	ms := newMQTTSource(topic)
+
+	opts := mqtt.NewClientOptions().
+		AddBroker(broker).
+		SetClientID(clientID).
+		SetUsername(username).
+		SetPassword(password).
+		SetConnectTimeout(timeout).
+		SetAutoReconnect(true).
+		SetOnConnectHandler(ms.subscribe).
+		SetConnectionLostHandler(func(_ mqtt.Client, err error) {
+			log.Warnf("Lost connection to MQTT broker %s: %v", broker, err)
+		})
+
+	client := mqtt.NewClient(opts)
+	token := client.Connect()
+	if !token.WaitTimeout(timeout) {
+		return nil, fmt.Errorf("timed out connecting to MQTT broker %s", broker)
+	}
+	if err := token.Error(); err != nil {
+		return nil, fmt.Errorf("failed to connect to MQTT broker %s: %w", broker, err)
+	}
+
+	if err := ms.waitReady(ctx, timeout+mqttSettleTime); err != nil {
+		client.Disconnect(250)
+		return nil, err
+	}
+
+	go func() {
+		<-ctx.Done()
+		client.Disconnect(250)
+	}()
+
+	return ms, nil
+}
+
+func newMQTTSource(topic string) *mqttSource {
+	return &mqttSource{
+		topic:     topic,
+		ready:     make(chan struct{}),
+		endpoints: map[string][]*endpoint.Endpoint{},
+	}
+}
+
+// waitReady waits for the retained messages delivered after the first subscription.
+func (ms *mqttSource) waitReady(ctx context.Context, timeout time.Duration) error {
+	select {
+	case <-ms.ready:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(timeout):
+		return fmt.Errorf("timed out subscribing to MQTT topic %s", ms.topic)
+	}
+}
+
+// Endpoints returns endpoint objects.
+func (ms *mqttSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	ms.RLock()
+	defer ms.RUnlock()
+
+	topics := make([]string, 0, len(ms.endpoints))
+	for topic := range ms.endpoints {
+		topics = append(topics, topic)
+	}
+	sort.Strings(topics)
+
+	endpoints := []*endpoint.Endpoint{}
+	for _, topic := range topics {
+		for _, ep := range ms.endpoints[topic] {
+			endpoints = append(endpoints, ep.DeepCopy())
+		}
+	}
+	return endpoints, nil
+}
+
+// AddEventHandler adds an event handler that is called whenever a message is received.
+func (ms *mqttSource) AddEventHandler(ctx context.Context, handler func()) {
+	ms.Lock()
+	defer ms.Unlock()
+
+	ms.handlers = append(ms.handlers, handler)
+}
+
+// subscribe (re-)subscribes to the topic filter; the broker then redelivers all retained messages.
+func (ms *mqttSource) subscribe(client mqtt.Client) {
+	token := client.Subscribe(ms.topic, mqttQoS, func(_ mqtt.Client, msg mqtt.Message) {
+		ms.handleMessage(msg.Topic(), msg.Payload())
+	})
+	go func() {
+		<-token.Done()
+		if err := token.Error(); err != nil {
+			log.Errorf("Failed to subscribe to MQTT topic %s: %v", ms.topic, err)
+			return
+		}
+		time.AfterFunc(mqttSettleTime, ms.markReady)
+	}()
+}
+
+// markReady releases waitReady the first time it is called.
+func (ms *mqttSource) markReady() {
+	ms.readyOnce.Do(func() {
+		log.Debugf("Subscribed to MQTT topic %s", ms.topic)
+		close(ms.ready)
+	})
+}
+
+// handleMessage replaces the endpoints cached for the topic with the ones decoded from
+// payload and notifies the event handlers. A message none of whose endpoints is valid is
+// ignored, so that a broken agent doesn't withdraw its endpoints.
+func (ms *mqttSource) handleMessage(topic string, payload []byte) {
+	var endpoints []*endpoint.Endpoint
+	if len(payload) > 0 {
+		var received []*endpoint.Endpoint
+		if err := json.Unmarshal(payload, &received); err != nil {
+			log.Warnf("Ignoring message received on MQTT topic %s: %v", topic, err)
+			return
+		}
+		for _, ep := range received {
+			if err := validateRemoteEndpoint(ep); err != nil {
+				log.Warnf("Skipping endpoint received on MQTT topic %s: %v", topic, err)
+				continue
+			}
+			if ep.Labels == nil {
+				ep.Labels = endpoint.NewLabels()
+			}
+			ep.Labels[endpoint.ResourceLabelKey] = fmt.Sprintf("mqtt/%s", topic)
+			endpoints = append(endpoints, ep)
+		}
+		if len(received) > 0 && len(endpoints) == 0 {
+			log.Warnf("Ignoring message received on MQTT topic %s: none of its %d endpoints is valid", topic, len(received))
+			return
+		}
+	}
+
+	ms.Lock()
+	if len(endpoints) == 0 {
+		delete(ms.endpoints, topic)
+	} else {
+		ms.endpoints[topic] = endpoints
+	}
+	handlers := ms.handlers
+	ms.Unlock()
+
+	log.Debugf("Received %d endpoints on MQTT topic %s", len(endpoints), topic)
+
+	for _, handler := range handlers {
+		handler()
+	}
+}
@@ -0,0 +1,182 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+func TestMQTTSource(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Interface", testMQTTSourceImplementsSource)
+	t.Run("NewMQTTSource", testMQTTSourceNewMQTTSource)
+	t.Run("Endpoints", testMQTTSourceEndpoints)
+	t.Run("EventHandler", testMQTTSourceEventHandler)
+	t.Run("Ready", testMQTTSourceReady)
+}
+
+// testMQTTSourceImplementsSource tests that mqttSource is a valid Source.
+func testMQTTSourceImplementsSource(t *testing.T) {
+	assert.Implements(t, (*Source)(nil), new(mqttSource))
+}
+
+func testMQTTSourceNewMQTTSource(t *testing.T) {
+	for _, ti := range []struct {
+		title  string
+		broker string
+		topic  string
+	}{
+		{title: "no broker", topic: "external-dns/endpoints/#"},
+		{title: "no topic", broker: "tcp://127.0.0.1:1"},
+		{title: "unreachable broker", broker: "tcp://127.0.0.1:1", topic: "external-dns/endpoints/#"},
+	} {
+		ti := ti
+		t.Run(ti.title, func(t *testing.T) {
+			_, err := NewMQTTSource(context.Background(), ti.broker, ti.topic, "external-dns", "", "", time.Second)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func testMQTTSourceEndpoints(t *testing.T) {
+	type message struct {
+		topic   string
+		payload string
+	}
+
+	for _, ti := range []struct {
+		title    string
+		messages []message
+		expected []*endpoint.Endpoint
+	}{
+		{
+			title: "no messages",
+		},
+		{
+			title: "endpoints of all topics",
+			messages: []message{
+				{topic: "external-dns/endpoints/edge-2", payload: `[{"dnsName": "edge-2.example.org", "targets": ["10.0.0.2"], "recordType": "A"}]`},
+				{topic: "external-dns/endpoints/edge-1", payload: `[{"dnsName": "edge-1.example.org", "targets": ["10.0.0.1"], "recordType": "A", "recordTTL": 60}, {"dnsName": "www.example.org", "targets": ["edge-1.example.org"], "recordType": "CNAME"}]`},
+			},
+			expected: []*endpoint.Endpoint{
+				{DNSName: "edge-1.example.org", Targets: endpoint.Targets{"10.0.0.1"}, RecordType: endpoint.RecordTypeA, RecordTTL: 60, Labels: endpoint.Labels{endpoint.ResourceLabelKey: "mqtt/external-dns/endpoints/edge-1"}},
+				{DNSName: "www.example.org", Targets: endpoint.Targets{"edge-1.example.org"}, RecordType: endpoint.RecordTypeCNAME, Labels: endpoint.Labels{endpoint.ResourceLabelKey: "mqtt/external-dns/endpoints/edge-1"}},
+				{DNSName: "edge-2.example.org", Targets: endpoint.Targets{"10.0.0.2"}, RecordType: endpoint.RecordTypeA, Labels: endpoint.Labels{endpoint.ResourceLabelKey: "mqtt/external-dns/endpoints/edge-2"}},
+			},
+		},
+		{
+			title: "latest message of a topic wins",
+			messages: []message{
+				{topic: "external-dns/endpoints/edge-1", payload: `[{"dnsName": "edge-1.example.org", "targets": ["10.0.0.1"], "recordType": "A"}]`},
+				{topic: "external-dns/endpoints/edge-1", payload: `[{"dnsName": "edge-1.example.org", "targets": ["10.0.0.11"], "recordType": "A"}]`},
+			},
+			expected: []*endpoint.Endpoint{
+				{DNSName: "edge-1.example.org", Targets: endpoint.Targets{"10.0.0.11"}, RecordType: endpoint.RecordTypeA},
+			},
+		},
+		{
+			title: "empty message withdraws endpoints",
+			messages: []message{
+				{topic: "external-dns/endpoints/edge-1", payload: `[{"dnsName": "edge-1.example.org", "targets": ["10.0.0.1"], "recordType": "A"}]`},
+				{topic: "external-dns/endpoints/edge-2", payload: `[{"dnsName": "edge-2.example.org", "targets": ["10.0.0.2"], "recordType": "A"}]`},
+				{topic: "external-dns/endpoints/edge-1"},
+			},
+			expected: []*endpoint.Endpoint{
+				{DNSName: "edge-2.example.org", Targets: endpoint.Targets{"10.0.0.2"}, RecordType: endpoint.RecordTypeA},
+			},
+		},
+		{
+			title: "messages without valid endpoints are ignored",
+			messages: []message{
+				{topic: "external-dns/endpoints/edge-1", payload: `[{"dnsName": "edge-1.example.org", "targets": ["10.0.0.1"], "recordType": "A"}]`},
+				{topic: "external-dns/endpoints/edge-1", payload: `[{"dnsName": "edge-1.example.org", "targets": ["not-an-ip"], "recordType": "A"}]`},
+			},
+			expected: []*endpoint.Endpoint{
+				{DNSName: "edge-1.example.org", Targets: endpoint.Targets{"10.0.0.1"}, RecordType: endpoint.RecordTypeA},
+			},
+		},
+		{
+			title: "invalid messages and endpoints are ignored",
+			messages: []message{
+				{topic: "external-dns/endpoints/edge-1", payload: `[{"dnsName": "edge-1.example.org", "targets": ["10.0.0.1"], "recordType": "A"}]`},
+				{topic: "external-dns/endpoints/edge-1", payload: `{"dnsName":`},
+				{topic: "external-dns/endpoints/edge-2", payload: `[{"dnsName": "edge-2.example.org", "targets": ["fd00::2"], "recordType": "A"}, {"dnsName": "edge-2.example.org", "targets": ["fd00::2"], "recordType": "AAAA"}]`},
+			},
+			expected: []*endpoint.Endpoint{
+				{DNSName: "edge-1.example.org", Targets: endpoint.Targets{"10.0.0.1"}, RecordType: endpoint.RecordTypeA},
+				{DNSName: "edge-2.example.org", Targets: endpoint.Targets{"fd00::2"}, RecordType: "AAAA"},
+			},
+		},
+	} {
+		ti := ti
+		t.Run(ti.title, func(t *testing.T) {
+			t.Parallel()
+
+			src := newMQTTSource("external-dns/endpoints/#")
+			for _, msg := range ti.messages {
+				src.handleMessage(msg.topic, []byte(msg.payload))
+			}
+
+			endpoints, err := src.Endpoints(context.Background())
+			require.NoError(t, err)
+
+			validateEndpoints(t, endpoints, ti.expected)
+		})
+	}
+}
+
+func testMQTTSourceEventHandler(t *testing.T) {
+	src := newMQTTSource("external-dns/endpoints/#")
+
+	calls := 0
+	src.AddEventHandler(context.Background(), func() { calls++ })
+
+	src.handleMessage("external-dns/endpoints/edge-1", []byte(`[{"dnsName": "edge-1.example.org", "targets": ["10.0.0.1"], "recordType": "A"}]`))
+	src.handleMessage("external-dns/endpoints/edge-1", nil)
+	assert.Equal(t, 2, calls)
+
+	src.handleMessage("external-dns/endpoints/edge-1", []byte(`not json`))
+	assert.Equal(t, 2, calls)
+}
+
+func testMQTTSourceReady(t *testing.T) {
+	src := newMQTTSource("external-dns/endpoints/#")
+	assert.Error(t, src.waitReady(context.Background(), 10*time.Millisecond), "expected a timeout before the subscription settled")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, src.waitReady(ctx, time.Second), context.Canceled)
+
+	go func() {
+		src.handleMessage("external-dns/endpoints/edge-1", []byte(`[{"dnsName": "edge-1.example.org", "targets": ["10.0.0.1"], "recordType": "A"}]`))
+		src.markReady()
+		src.markReady()
+	}()
+	require.NoError(t, src.waitReady(context.Background(), time.Second))
+
+	endpoints, err := src.Endpoints(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, endpoints, 1)
+}
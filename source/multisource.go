@@ -18,19 +18,38 @@ package source
 
 import (
 	"context"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
 
 	"sigs.k8s.io/external-dns/endpoint"
 )
 
+// Strategies to resolve conflicting endpoints, i.e. endpoints with the same DNS name,
+// record type and set identifier returned by different nested Sources.
+const (
+	// ConflictStrategyNone passes all endpoints through.
+	ConflictStrategyNone = "none"
+	// ConflictStrategyFirstWins keeps the endpoint of the Source specified first.
+	ConflictStrategyFirstWins = "first-wins"
+	// ConflictStrategyMergeTargets adds the targets of all conflicting endpoints to the endpoint of the Source specified first.
+	ConflictStrategyMergeTargets = "merge-targets"
+	// ConflictStrategyError fails the synchronization.
+	ConflictStrategyError = "error"
+)
+
 // multiSource is a Source that merges the endpoints of its nested Sources.
 type multiSource struct {
-	children       []Source
-	defaultTargets []string
+	children         []Source
+	defaultTargets   []string
+	conflictStrategy string
 }
 
 // Endpoints collects endpoints of all nested Sources and returns them in a single slice.
 func (ms *multiSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error) {
 	result := []*endpoint.Endpoint{}
+	// the endpoints of the previous children by DNS name, record type and set identifier
+	seen := map[string]*endpoint.Endpoint{}
 
 	for _, s := range ms.children {
 		endpoints, err := s.Endpoints(ctx)
@@ -42,7 +61,42 @@ func (ms *multiSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, err
 				endpoints[i].Targets = ms.defaultTargets
 			}
 		}
-		result = append(result, endpoints...)
+		if ms.conflictStrategy == ConflictStrategyNone || ms.conflictStrategy == "" {
+			result = append(result, endpoints...)
+			continue
+		}
+
+		current := map[string]*endpoint.Endpoint{}
+		for _, ep := range endpoints {
+			key := fmt.Sprintf("%s/%s/%s", ep.DNSName, ep.RecordType, ep.SetIdentifier)
+			existing, ok := seen[key]
+			if !ok {
+				if _, ok := current[key]; !ok {
+					current[key] = ep
+				}
+				result = append(result, ep)
+				continue
+			}
+
+			switch ms.conflictStrategy {
+			case ConflictStrategyError:
+				return nil, fmt.Errorf("conflicting %s endpoints for %s returned by multiple sources", ep.RecordType, ep.DNSName)
+			case ConflictStrategyMergeTargets:
+				// the targets may be shared with the source, e.g. its default targets
+				existing.Targets = append(endpoint.Targets{}, existing.Targets...)
+				for _, target := range ep.Targets {
+					if !containsTarget(existing.Targets, target) {
+						existing.Targets = append(existing.Targets, target)
+					}
+				}
+				log.Debugf("Merged targets of conflicting %s endpoint %s", ep.RecordType, ep.DNSName)
+			default:
+				log.Debugf("Skipping conflicting %s endpoint %s %v returned by another source", ep.RecordType, ep.DNSName, ep.Targets)
+			}
+		}
+		for key, ep := range current {
+			seen[key] = ep
+		}
 	}
 
 	return result, nil
@@ -54,7 +108,16 @@ func (ms *multiSource) AddEventHandler(ctx context.Context, handler func()) {
 	}
 }
 
-// NewMultiSource creates a new multiSource.
-func NewMultiSource(children []Source, defaultTargets []string) Source {
-	return &multiSource{children: children, defaultTargets: defaultTargets}
+// NewMultiSource creates a new multiSource resolving conflicting endpoints of its children with the given strategy.
+func NewMultiSource(children []Source, defaultTargets []string, conflictStrategy string) Source {
+	return &multiSource{children: children, defaultTargets: defaultTargets, conflictStrategy: conflictStrategy}
+}
+
+func containsTarget(targets endpoint.Targets, target string) bool {
+	for _, t := range targets {
+		if t == target {
+			return true
+		}
+	}
+	return false
 }
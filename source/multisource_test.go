@@ -35,6 +35,8 @@ func TestMultiSource(t *testing.T) {
 	t.Run("Endpoints", testMultiSourceEndpoints)
 	t.Run("EndpointsWithError", testMultiSourceEndpointsWithError)
 	t.Run("EndpointsDefaultTargets", testMultiSourceEndpointsDefaultTargets)
+	t.Run("EndpointsConflictStrategy", testMultiSourceEndpointsConflictStrategy)
+	t.Run("EndpointsMergeTargetsSharedTargets", testMultiSourceEndpointsMergeTargetsSharedTargets)
 }
 
 // testMultiSourceImplementsSource tests that multiSource is a valid Source.
@@ -89,7 +91,7 @@ func testMultiSourceEndpoints(t *testing.T) {
 			}
 
 			// Create our object under test and get the endpoints.
-			source := NewMultiSource(sources, nil)
+			source := NewMultiSource(sources, nil, ConflictStrategyNone)
 
 			// Get endpoints from the source.
 			endpoints, err := source.Endpoints(context.Background())
@@ -116,7 +118,7 @@ func testMultiSourceEndpointsWithError(t *testing.T) {
 	src.On("Endpoints").Return(nil, errSomeError)
 
 	// Create our object under test and get the endpoints.
-	source := NewMultiSource([]Source{src}, nil)
+	source := NewMultiSource([]Source{src}, nil, ConflictStrategyNone)
 
 	// Get endpoints from our source.
 	_, err := source.Endpoints(context.Background())
@@ -147,7 +149,7 @@ func testMultiSourceEndpointsDefaultTargets(t *testing.T) {
 	src.On("Endpoints").Return(sourceEndpoints, nil)
 
 	// Create our object under test with non-empty defaultTargets and get the endpoints.
-	source := NewMultiSource([]Source{src}, defaultTargets)
+	source := NewMultiSource([]Source{src}, defaultTargets, ConflictStrategyNone)
 
 	// Get endpoints from our source.
 	endpoints, err := source.Endpoints(context.Background())
@@ -159,3 +161,107 @@ func testMultiSourceEndpointsDefaultTargets(t *testing.T) {
 	// Validate that the nested sources were called.
 	src.AssertExpectations(t)
 }
+
+// testMultiSourceEndpointsConflictStrategy tests that endpoints conflicting between children are resolved.
+func testMultiSourceEndpointsConflictStrategy(t *testing.T) {
+	newEndpoints := func() [][]*endpoint.Endpoint {
+		return [][]*endpoint.Endpoint{
+			{
+				{DNSName: "foo", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"8.8.8.8"}},
+				{DNSName: "bar", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"8.8.4.4"}},
+			},
+			{
+				{DNSName: "foo", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"1.1.1.1", "8.8.8.8"}},
+				{DNSName: "foo", RecordType: endpoint.RecordTypeTXT, Targets: endpoint.Targets{"text"}},
+				{DNSName: "bar", RecordType: endpoint.RecordTypeA, SetIdentifier: "secondary", Targets: endpoint.Targets{"1.0.0.1"}},
+			},
+		}
+	}
+
+	for _, tc := range []struct {
+		strategy    string
+		expected    []*endpoint.Endpoint
+		expectError bool
+	}{
+		{
+			strategy: ConflictStrategyNone,
+			expected: []*endpoint.Endpoint{
+				{DNSName: "foo", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"8.8.8.8"}},
+				{DNSName: "bar", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"8.8.4.4"}},
+				{DNSName: "foo", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"1.1.1.1", "8.8.8.8"}},
+				{DNSName: "foo", RecordType: endpoint.RecordTypeTXT, Targets: endpoint.Targets{"text"}},
+				{DNSName: "bar", RecordType: endpoint.RecordTypeA, SetIdentifier: "secondary", Targets: endpoint.Targets{"1.0.0.1"}},
+			},
+		},
+		{
+			strategy: ConflictStrategyFirstWins,
+			expected: []*endpoint.Endpoint{
+				{DNSName: "foo", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"8.8.8.8"}},
+				{DNSName: "bar", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"8.8.4.4"}},
+				{DNSName: "foo", RecordType: endpoint.RecordTypeTXT, Targets: endpoint.Targets{"text"}},
+				{DNSName: "bar", RecordType: endpoint.RecordTypeA, SetIdentifier: "secondary", Targets: endpoint.Targets{"1.0.0.1"}},
+			},
+		},
+		{
+			strategy: ConflictStrategyMergeTargets,
+			expected: []*endpoint.Endpoint{
+				{DNSName: "foo", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"8.8.8.8", "1.1.1.1"}},
+				{DNSName: "bar", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"8.8.4.4"}},
+				{DNSName: "foo", RecordType: endpoint.RecordTypeTXT, Targets: endpoint.Targets{"text"}},
+				{DNSName: "bar", RecordType: endpoint.RecordTypeA, SetIdentifier: "secondary", Targets: endpoint.Targets{"1.0.0.1"}},
+			},
+		},
+		{
+			strategy:    ConflictStrategyError,
+			expectError: true,
+		},
+	} {
+		tc := tc
+		t.Run(tc.strategy, func(t *testing.T) {
+			t.Parallel()
+
+			sources := []Source{}
+			for _, endpoints := range newEndpoints() {
+				src := new(testutils.MockSource)
+				src.On("Endpoints").Return(endpoints, nil)
+				sources = append(sources, src)
+			}
+
+			endpoints, err := NewMultiSource(sources, nil, tc.strategy).Endpoints(context.Background())
+			if tc.expectError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			validateEndpoints(t, endpoints, tc.expected)
+		})
+	}
+}
+
+// testMultiSourceEndpointsMergeTargetsSharedTargets tests that merging targets doesn't modify the targets shared by other endpoints.
+func testMultiSourceEndpointsMergeTargetsSharedTargets(t *testing.T) {
+	shared := make(endpoint.Targets, 1, 2)
+	shared[0] = "8.8.8.8"
+
+	first := new(testutils.MockSource)
+	first.On("Endpoints").Return([]*endpoint.Endpoint{
+		{DNSName: "foo", RecordType: endpoint.RecordTypeA, Targets: shared},
+		{DNSName: "bar", RecordType: endpoint.RecordTypeA, Targets: shared},
+	}, nil)
+	second := new(testutils.MockSource)
+	second.On("Endpoints").Return([]*endpoint.Endpoint{
+		{DNSName: "foo", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"1.1.1.1"}},
+		{DNSName: "bar", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"1.0.0.1"}},
+	}, nil)
+
+	endpoints, err := NewMultiSource([]Source{first, second}, nil, ConflictStrategyMergeTargets).Endpoints(context.Background())
+	require.NoError(t, err)
+
+	validateEndpoints(t, endpoints, []*endpoint.Endpoint{
+		{DNSName: "foo", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"8.8.8.8", "1.1.1.1"}},
+		{DNSName: "bar", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"8.8.8.8", "1.0.0.1"}},
+	})
+	assert.Equal(t, endpoint.Targets{"8.8.8.8"}, shared)
+	assert.Empty(t, shared[:2][1], "expected the shared targets not to be modified")
+}
@@ -0,0 +1,253 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+const netboxPageSize = 1000
+
+// netboxIPAddress is an IP address as returned by the NetBox /api/ipam/ip-addresses/ API.
+type netboxIPAddress struct {
+	ID             int    `json:"id"`
+	Address        string `json:"address"`
+	DNSName        string `json:"dns_name"`
+	AssignedObject struct {
+		Name   string `json:"name"`
+		Device *struct {
+			Name string `json:"name"`
+		} `json:"device"`
+		VirtualMachine *struct {
+			Name string `json:"name"`
+		} `json:"virtual_machine"`
+	} `json:"assigned_object"`
+}
+
+type netboxIPAddressList struct {
+	Next    string            `json:"next"`
+	Results []netboxIPAddress `json:"results"`
+}
+
+// netboxTemplateData is the data the FQDN template is executed with for IP addresses without a DNS name.
+type netboxTemplateData struct {
+	Address        string
+	Interface      string
+	Device         string
+	VirtualMachine string
+}
+
+// netboxSource is an implementation of Source that provides A, AAAA and optionally
+// PTR endpoints for the active IP addresses managed in NetBox.
+type netboxSource struct {
+	apiURL       string
+	token        string
+	client       *http.Client
+	query        url.Values
+	createPTR    bool
+	fqdnTemplate *template.Template
+}
+
+// NewNetBoxSource creates a new netboxSource with the given config. IP addresses can be
+// filtered by tag slug and by custom fields given in the form name=value.
+func NewNetBoxSource(apiURL, token string, tags, customFields []string, createPTR bool, fqdnTemplate string, timeout time.Duration) (Source, error) {
+	if apiURL == "" {
+		return nil, fmt.Errorf("no NetBox URL specified")
+	}
+
+	tmpl, err := parseTemplate(fqdnTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	query := url.Values{}
+	query.Set("status", "active")
+	query.Set("limit", fmt.Sprint(netboxPageSize))
+	for _, tag := range tags {
+		query.Add("tag", tag)
+	}
+	for _, field := range customFields {
+		name, value, found := strings.Cut(field, "=")
+		if !found || name == "" {
+			return nil, fmt.Errorf("invalid NetBox custom field filter %q: expected name=value", field)
+		}
+		query.Add("cf_"+name, value)
+	}
+
+	return &netboxSource{
+		apiURL:       strings.TrimSuffix(apiURL, "/"),
+		token:        token,
+		client:       &http.Client{Timeout: timeout},
+		query:        query,
+		createPTR:    createPTR,
+		fqdnTemplate: tmpl,
+	}, nil
+}
+
+// Endpoints returns endpoint objects for the IP addresses matching the configured filters.
+func (ns *netboxSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	addresses, err := ns.listIPAddresses(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	endpointsByKey := map[string]*endpoint.Endpoint{}
+	var keys []string
+	add := func(dnsName, recordType, target, resource string) {
+		key := recordType + "/" + dnsName
+		ep, ok := endpointsByKey[key]
+		if !ok {
+			ep = endpoint.NewEndpoint(dnsName, recordType)
+			ep.Labels[endpoint.ResourceLabelKey] = resource
+			endpointsByKey[key] = ep
+			keys = append(keys, key)
+		}
+		ep.Targets = append(ep.Targets, target)
+	}
+
+	for _, address := range addresses {
+		ip, _, err := net.ParseCIDR(address.Address)
+		if err != nil {
+			log.Warnf("Skipping NetBox IP address %d: %v", address.ID, err)
+			continue
+		}
+
+		hostnames, err := ns.hostnames(address)
+		if err != nil {
+			return nil, err
+		}
+		if len(hostnames) == 0 {
+			log.Debugf("Skipping NetBox IP address %s: no DNS name", address.Address)
+			continue
+		}
+
+		recordType := endpoint.RecordTypeA
+		if ip.To4() == nil {
+			recordType = "AAAA"
+		}
+
+		resource := fmt.Sprintf("netbox/ip-address/%d", address.ID)
+		for _, hostname := range hostnames {
+			add(hostname, recordType, ip.String(), resource)
+		}
+		if ns.createPTR {
+			add(reverseAddr(ip), endpoint.RecordTypePTR, hostnames[0], resource)
+		}
+	}
+
+	endpoints := make([]*endpoint.Endpoint, 0, len(keys))
+	for _, key := range keys {
+		ep := endpointsByKey[key]
+		sort.Sort(ep.Targets)
+		endpoints = append(endpoints, ep)
+	}
+	return endpoints, nil
+}
+
+func (ns *netboxSource) AddEventHandler(ctx context.Context, handler func()) {
+}
+
+func (ns *netboxSource) hostnames(address netboxIPAddress) ([]string, error) {
+	if name := strings.TrimSuffix(strings.TrimSpace(address.DNSName), "."); name != "" {
+		return []string{name}, nil
+	}
+	if ns.fqdnTemplate == nil {
+		return nil, nil
+	}
+
+	data := netboxTemplateData{
+		Address:   address.Address,
+		Interface: address.AssignedObject.Name,
+	}
+	if address.AssignedObject.Device != nil {
+		data.Device = address.AssignedObject.Device.Name
+	}
+	if address.AssignedObject.VirtualMachine != nil {
+		data.VirtualMachine = address.AssignedObject.VirtualMachine.Name
+	}
+
+	hostnames, err := execTemplate(ns.fqdnTemplate, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile the hostnames of NetBox IP address %s: %w", address.Address, err)
+	}
+	return hostnames, nil
+}
+
+// listIPAddresses returns all pages of IP addresses matching the configured filters.
+func (ns *netboxSource) listIPAddresses(ctx context.Context) ([]netboxIPAddress, error) {
+	var addresses []netboxIPAddress
+
+	next := ns.apiURL + "/api/ipam/ip-addresses/?" + ns.query.Encode()
+	for next != "" {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, next, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Accept", "application/json")
+		if ns.token != "" {
+			req.Header.Set("Authorization", "Token "+ns.token)
+		}
+
+		resp, err := ns.client.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list NetBox IP addresses: %w", err)
+		}
+
+		var page netboxIPAddressList
+		if resp.StatusCode != http.StatusOK {
+			err = fmt.Errorf("failed to list NetBox IP addresses: unexpected status %s", resp.Status)
+		} else if err = json.NewDecoder(resp.Body).Decode(&page); err != nil {
+			err = fmt.Errorf("failed to decode NetBox IP addresses: %w", err)
+		}
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		addresses = append(addresses, page.Results...)
+		next = page.Next
+	}
+
+	return addresses, nil
+}
+
+// reverseAddr returns the in-addr.arpa or ip6.arpa name of the given IP address.
+func reverseAddr(ip net.IP) string {
+	if ip4 := ip.To4(); ip4 != nil {
+		return fmt.Sprintf("%d.%d.%d.%d.in-addr.arpa", ip4[3], ip4[2], ip4[1], ip4[0])
+	}
+
+	const hexDigits = "0123456789abcdef"
+	labels := make([]string, 0, 2*net.IPv6len+1)
+	for i := net.IPv6len - 1; i >= 0; i-- {
+		labels = append(labels, string(hexDigits[ip[i]&0x0f]), string(hexDigits[ip[i]>>4]))
+	}
+	return strings.Join(append(labels, "ip6.arpa"), ".")
+}
@@ -0,0 +1,183 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+const netboxTestToken = "0123456789abcdef0123456789abcdef01234567"
+
+func newNetBoxTestServer(t *testing.T) *httptest.Server {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Token "+netboxTestToken {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path != "/api/ipam/ip-addresses/" || r.URL.Query().Get("status") != "active" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		query := r.URL.Query()
+		switch {
+		case query.Get("tag") == "dns" && query.Get("cf_zone") == "internal":
+			fmt.Fprint(w, `{"next": null, "results": [
+				{"id": 3, "address": "10.0.0.3/24", "dns_name": "db.example.org"}
+			]}`)
+		case query.Get("tag") == "dns":
+			fmt.Fprint(w, `{"next": null, "results": [
+				{"id": 1, "address": "10.0.0.1/24", "dns_name": "web.example.org"},
+				{"id": 2, "address": "10.0.0.2/24", "dns_name": "web.example.org."}
+			]}`)
+		case query.Get("offset") == "":
+			fmt.Fprintf(w, `{"next": "%s/api/ipam/ip-addresses/?status=active&offset=2", "results": [
+				{"id": 1, "address": "10.0.0.1/24", "dns_name": "web.example.org"},
+				{"id": 4, "address": "2001:db8::4/64", "dns_name": "web.example.org"}
+			]}`, server.URL)
+		default:
+			fmt.Fprint(w, `{"next": null, "results": [
+				{"id": 5, "address": "10.0.0.5/24", "assigned_object": {"name": "eth0", "device": {"name": "switch1"}}},
+				{"id": 6, "address": "10.0.0.6/24", "assigned_object": {"name": "eth0", "virtual_machine": {"name": "vm1"}}},
+				{"id": 7, "address": "invalid", "dns_name": "invalid.example.org"}
+			]}`)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestNetBoxSource(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Interface", testNetBoxSourceImplementsSource)
+	t.Run("Endpoints", testNetBoxSourceEndpoints)
+}
+
+// testNetBoxSourceImplementsSource tests that netboxSource is a valid Source.
+func testNetBoxSourceImplementsSource(t *testing.T) {
+	assert.Implements(t, (*Source)(nil), new(netboxSource))
+}
+
+func testNetBoxSourceEndpoints(t *testing.T) {
+	server := newNetBoxTestServer(t)
+
+	for _, ti := range []struct {
+		title        string
+		token        string
+		tags         []string
+		customFields []string
+		createPTR    bool
+		fqdnTemplate string
+		expected     []*endpoint.Endpoint
+		expectError  bool
+	}{
+		{
+			title:       "unauthorized",
+			token:       "invalid",
+			expectError: true,
+		},
+		{
+			title: "all pages",
+			token: netboxTestToken,
+			expected: []*endpoint.Endpoint{
+				{DNSName: "web.example.org", Targets: endpoint.Targets{"10.0.0.1"}, RecordType: endpoint.RecordTypeA, Labels: endpoint.Labels{endpoint.ResourceLabelKey: "netbox/ip-address/1"}},
+				{DNSName: "web.example.org", Targets: endpoint.Targets{"2001:db8::4"}, RecordType: "AAAA", Labels: endpoint.Labels{endpoint.ResourceLabelKey: "netbox/ip-address/4"}},
+			},
+		},
+		{
+			title:     "ptr records for ipv4 and ipv6 addresses",
+			token:     netboxTestToken,
+			createPTR: true,
+			expected: []*endpoint.Endpoint{
+				{DNSName: "web.example.org", Targets: endpoint.Targets{"10.0.0.1"}, RecordType: endpoint.RecordTypeA},
+				{DNSName: "1.0.0.10.in-addr.arpa", Targets: endpoint.Targets{"web.example.org"}, RecordType: endpoint.RecordTypePTR},
+				{DNSName: "web.example.org", Targets: endpoint.Targets{"2001:db8::4"}, RecordType: "AAAA"},
+				{DNSName: "4.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.8.b.d.0.1.0.0.2.ip6.arpa", Targets: endpoint.Targets{"web.example.org"}, RecordType: endpoint.RecordTypePTR},
+			},
+		},
+		{
+			title:        "hostnames from template for addresses without dns name",
+			token:        netboxTestToken,
+			fqdnTemplate: "{{.Device}}{{.VirtualMachine}}.example.org",
+			expected: []*endpoint.Endpoint{
+				{DNSName: "web.example.org", Targets: endpoint.Targets{"10.0.0.1"}, RecordType: endpoint.RecordTypeA},
+				{DNSName: "web.example.org", Targets: endpoint.Targets{"2001:db8::4"}, RecordType: "AAAA"},
+				{DNSName: "switch1.example.org", Targets: endpoint.Targets{"10.0.0.5"}, RecordType: endpoint.RecordTypeA},
+				{DNSName: "vm1.example.org", Targets: endpoint.Targets{"10.0.0.6"}, RecordType: endpoint.RecordTypeA},
+			},
+		},
+		{
+			title: "tag filter merges targets",
+			token: netboxTestToken,
+			tags:  []string{"dns"},
+			expected: []*endpoint.Endpoint{
+				{DNSName: "web.example.org", Targets: endpoint.Targets{"10.0.0.1", "10.0.0.2"}, RecordType: endpoint.RecordTypeA},
+			},
+		},
+		{
+			title:        "custom field filter with ptr records",
+			token:        netboxTestToken,
+			tags:         []string{"dns"},
+			customFields: []string{"zone=internal"},
+			createPTR:    true,
+			expected: []*endpoint.Endpoint{
+				{DNSName: "db.example.org", Targets: endpoint.Targets{"10.0.0.3"}, RecordType: endpoint.RecordTypeA},
+				{DNSName: "3.0.0.10.in-addr.arpa", Targets: endpoint.Targets{"db.example.org"}, RecordType: endpoint.RecordTypePTR},
+			},
+		},
+	} {
+		ti := ti
+		t.Run(ti.title, func(t *testing.T) {
+			t.Parallel()
+
+			src, err := NewNetBoxSource(server.URL+"/", ti.token, ti.tags, ti.customFields, ti.createPTR, ti.fqdnTemplate, time.Second)
+			require.NoError(t, err)
+
+			endpoints, err := src.Endpoints(context.Background())
+			if ti.expectError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			validateEndpoints(t, endpoints, ti.expected)
+		})
+	}
+}
+
+func TestNewNetBoxSource(t *testing.T) {
+	_, err := NewNetBoxSource("", netboxTestToken, nil, nil, false, "", time.Second)
+	assert.Error(t, err)
+
+	_, err = NewNetBoxSource("https://netbox.example.org", netboxTestToken, nil, []string{"zone"}, false, "", time.Second)
+	assert.Error(t, err)
+
+	_, err = NewNetBoxSource("https://netbox.example.org", netboxTestToken, nil, nil, false, "{{.Device", time.Second)
+	assert.Error(t, err)
+}
@@ -0,0 +1,278 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+const (
+	// PluginMediaType is the content type of the requests and responses of the source plugin protocol
+	PluginMediaType = "application/external.dns.source.plugin+json;version=1"
+	// PluginEndpointsPath returns the JSON encoded list of endpoints of a plugin
+	PluginEndpointsPath = "/endpoints"
+	// PluginEventsPath streams one JSON encoded event per line whenever the endpoints of a plugin change
+	PluginEventsPath = "/events"
+
+	pluginEventsRetryInterval = 5 * time.Second
+)
+
+// PluginEvent is the event streamed by a plugin when its endpoints change.
+type PluginEvent struct {
+	Type string `json:"type"`
+}
+
+// pluginSource is an implementation of Source that provides endpoints served by an
+// out-of-tree source plugin speaking the source plugin protocol over HTTP(S).
+type pluginSource struct {
+	url           *url.URL
+	client        *http.Client
+	eventsClient  *http.Client
+	retryInterval time.Duration
+}
+
+// NewPluginSource creates a new pluginSource talking to the plugin listening on the given URL.
+func NewPluginSource(pluginURL string, timeout time.Duration) (Source, error) {
+	u, err := url.Parse(pluginURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid plugin source url %q: %w", pluginURL, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid plugin source url %q: scheme must be http or https", pluginURL)
+	}
+
+	return &pluginSource{
+		url:           u,
+		client:        &http.Client{Timeout: timeout},
+		eventsClient:  &http.Client{},
+		retryInterval: pluginEventsRetryInterval,
+	}, nil
+}
+
+// Endpoints returns endpoint objects.
+func (ps *pluginSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	resp, err := ps.get(ctx, ps.client, PluginEndpointsPath)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch endpoints from plugin %s: unexpected status %s", ps.url, resp.Status)
+	}
+
+	var pluginEndpoints []*endpoint.Endpoint
+	if err := json.NewDecoder(resp.Body).Decode(&pluginEndpoints); err != nil {
+		return nil, fmt.Errorf("failed to decode endpoints from plugin %s: %w", ps.url, err)
+	}
+
+	endpoints := []*endpoint.Endpoint{}
+	for _, ep := range pluginEndpoints {
+		if err := validateRemoteEndpoint(ep); err != nil {
+			log.Warnf("Skipping endpoint received from plugin %s: %v", ps.url, err)
+			continue
+		}
+		if ep.Labels == nil {
+			ep.Labels = endpoint.NewLabels()
+		}
+		if ep.Labels[endpoint.ResourceLabelKey] == "" {
+			ep.Labels[endpoint.ResourceLabelKey] = fmt.Sprintf("plugin/%s", ep.DNSName)
+		}
+		endpoints = append(endpoints, ep)
+	}
+
+	log.Debugf("Received endpoints from plugin %s: %#v", ps.url, endpoints)
+
+	return endpoints, nil
+}
+
+// AddEventHandler subscribes to the event stream of the plugin and calls the handler
+// for every event received. The stream is re-established until the context is done.
+func (ps *pluginSource) AddEventHandler(ctx context.Context, handler func()) {
+	go func() {
+		for {
+			supported, err := ps.watch(ctx, handler)
+			if ctx.Err() != nil {
+				return
+			}
+			if !supported {
+				log.Infof("Plugin %s does not support events, relying on the synchronization interval", ps.url)
+				return
+			}
+			log.Warnf("Event stream of plugin %s interrupted, reconnecting in %s: %v", ps.url, ps.retryInterval, err)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(ps.retryInterval):
+			}
+		}
+	}()
+}
+
+// watch consumes the event stream of the plugin until it ends. It returns false
+// if the plugin does not implement the events path.
+func (ps *pluginSource) watch(ctx context.Context, handler func()) (bool, error) {
+	resp, err := ps.get(ctx, ps.eventsClient, PluginEventsPath)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound, http.StatusNotImplemented:
+		return false, nil
+	default:
+		return true, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		handler()
+	}
+	if err := scanner.Err(); err != nil {
+		return true, err
+	}
+	return true, fmt.Errorf("stream closed")
+}
+
+func (ps *pluginSource) get(ctx context.Context, client *http.Client, path string) (*http.Response, error) {
+	u := *ps.url
+	u.Path = strings.TrimSuffix(u.Path, "/") + path
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", PluginMediaType)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call plugin %s: %w", ps.url, err)
+	}
+	return resp, nil
+}
+
+// pluginHandler serves a Source over the source plugin protocol.
+type pluginHandler struct {
+	source Source
+
+	sync.Mutex
+	subscribers map[chan struct{}]struct{}
+}
+
+// NewPluginHandler returns an http.Handler serving the given Source over the source
+// plugin protocol, so that any Source implementation can be run as an out-of-tree plugin.
+func NewPluginHandler(ctx context.Context, source Source) http.Handler {
+	h := &pluginHandler{
+		source:      source,
+		subscribers: map[chan struct{}]struct{}{},
+	}
+	source.AddEventHandler(ctx, h.notify)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(PluginEndpointsPath, h.endpoints)
+	mux.HandleFunc(PluginEventsPath, h.events)
+	return mux
+}
+
+func (h *pluginHandler) endpoints(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	endpoints, err := h.source.Endpoints(req.Context())
+	if err != nil {
+		log.Errorf("Failed to get endpoints: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", PluginMediaType)
+	if err := json.NewEncoder(w).Encode(endpoints); err != nil {
+		log.Errorf("Failed to encode endpoints: %v", err)
+	}
+}
+
+func (h *pluginHandler) events(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		w.WriteHeader(http.StatusNotImplemented)
+		return
+	}
+
+	// A single pending notification is enough, the client refetches all endpoints anyway.
+	ch := make(chan struct{}, 1)
+	h.Lock()
+	h.subscribers[ch] = struct{}{}
+	h.Unlock()
+	defer func() {
+		h.Lock()
+		delete(h.subscribers, ch)
+		h.Unlock()
+	}()
+
+	w.Header().Set("Content-Type", PluginMediaType)
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	encoder := json.NewEncoder(w)
+	for {
+		select {
+		case <-req.Context().Done():
+			return
+		case <-ch:
+			if err := encoder.Encode(PluginEvent{Type: "changed"}); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+func (h *pluginHandler) notify() {
+	h.Lock()
+	defer h.Unlock()
+
+	for ch := range h.subscribers {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
@@ -0,0 +1,186 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// pluginTestSource is a Source whose endpoints and events are controlled by the test.
+type pluginTestSource struct {
+	endpoints []*endpoint.Endpoint
+	err       error
+
+	sync.Mutex
+	handlers []func()
+}
+
+func (s *pluginTestSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	return s.endpoints, s.err
+}
+
+func (s *pluginTestSource) AddEventHandler(ctx context.Context, handler func()) {
+	s.Lock()
+	defer s.Unlock()
+	s.handlers = append(s.handlers, handler)
+}
+
+func (s *pluginTestSource) trigger() {
+	s.Lock()
+	defer s.Unlock()
+	for _, handler := range s.handlers {
+		handler()
+	}
+}
+
+func TestPluginSource(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Interface", testPluginSourceImplementsSource)
+	t.Run("Endpoints", testPluginSourceEndpoints)
+	t.Run("Events", testPluginSourceEvents)
+	t.Run("EventsUnsupported", testPluginSourceEventsUnsupported)
+}
+
+// testPluginSourceImplementsSource tests that pluginSource is a valid Source.
+func testPluginSourceImplementsSource(t *testing.T) {
+	assert.Implements(t, (*Source)(nil), new(pluginSource))
+}
+
+func testPluginSourceEndpoints(t *testing.T) {
+	for _, ti := range []struct {
+		title       string
+		source      *pluginTestSource
+		expected    []*endpoint.Endpoint
+		expectError bool
+	}{
+		{
+			title: "valid endpoints",
+			source: &pluginTestSource{endpoints: []*endpoint.Endpoint{
+				{DNSName: "www.example.org", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"192.0.2.1"}, RecordTTL: 300},
+				{DNSName: "app.example.org", RecordType: endpoint.RecordTypeCNAME, Targets: endpoint.Targets{"lb.example.org"}, Labels: endpoint.Labels{endpoint.ResourceLabelKey: "service/default/app"}},
+				{DNSName: "invalid.example.org", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"lb.example.org"}},
+			}},
+			expected: []*endpoint.Endpoint{
+				{DNSName: "www.example.org", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"192.0.2.1"}, RecordTTL: 300, Labels: endpoint.Labels{endpoint.ResourceLabelKey: "plugin/www.example.org"}},
+				{DNSName: "app.example.org", RecordType: endpoint.RecordTypeCNAME, Targets: endpoint.Targets{"lb.example.org"}, Labels: endpoint.Labels{endpoint.ResourceLabelKey: "service/default/app"}},
+			},
+		},
+		{
+			title:    "no endpoints",
+			source:   &pluginTestSource{},
+			expected: []*endpoint.Endpoint{},
+		},
+		{
+			title:       "source error",
+			source:      &pluginTestSource{err: errors.New("boom")},
+			expectError: true,
+		},
+	} {
+		ti := ti
+		t.Run(ti.title, func(t *testing.T) {
+			t.Parallel()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			server := httptest.NewServer(NewPluginHandler(ctx, ti.source))
+			t.Cleanup(server.Close)
+			t.Cleanup(cancel)
+
+			src, err := NewPluginSource(server.URL+"/", time.Second)
+			require.NoError(t, err)
+
+			endpoints, err := src.Endpoints(ctx)
+			if ti.expectError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			validateEndpoints(t, endpoints, ti.expected)
+			for i := range endpoints {
+				assert.Equal(t, ti.expected[i].Labels, endpoints[i].Labels)
+			}
+		})
+	}
+}
+
+func testPluginSourceEvents(t *testing.T) {
+	pluginSrc := &pluginTestSource{}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	server := httptest.NewServer(NewPluginHandler(ctx, pluginSrc))
+	t.Cleanup(server.Close)
+	// Cleanups run in reverse order: the event stream must be cancelled before
+	// server.Close, which waits for all active requests to complete.
+	t.Cleanup(cancel)
+
+	src, err := NewPluginSource(server.URL, time.Second)
+	require.NoError(t, err)
+
+	events := make(chan struct{}, 1)
+	src.AddEventHandler(ctx, func() {
+		select {
+		case events <- struct{}{}:
+		default:
+		}
+	})
+
+	// The subscription is established asynchronously, so keep triggering until an event arrives.
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case <-events:
+			return
+		case <-ticker.C:
+			pluginSrc.trigger()
+		case <-timeout:
+			t.Fatal("no event received from the plugin")
+		}
+	}
+}
+
+func testPluginSourceEventsUnsupported(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	t.Cleanup(server.Close)
+
+	src, err := NewPluginSource(server.URL, time.Second)
+	require.NoError(t, err)
+
+	supported, err := src.(*pluginSource).watch(context.Background(), func() {})
+	assert.NoError(t, err)
+	assert.False(t, supported)
+}
+
+func TestNewPluginSource(t *testing.T) {
+	for _, pluginURL := range []string{"", "unix:///var/run/plugin.sock", "://invalid"} {
+		_, err := NewPluginSource(pluginURL, time.Second)
+		assert.Error(t, err, pluginURL)
+	}
+}
@@ -90,14 +90,14 @@ func (ps *podSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error
 			continue
 		}
 
-		if domain, ok := pod.Annotations[internalHostnameAnnotationKey]; ok {
+		if domain, ok := lookupAnnotation(pod.Annotations, internalHostnameAnnotationKey); ok {
 			if _, ok := domains[domain]; !ok {
 				domains[domain] = []string{}
 			}
 			domains[domain] = append(domains[domain], pod.Status.PodIP)
 		}
 
-		if domain, ok := pod.Annotations[hostnameAnnotationKey]; ok {
+		if domain, ok := lookupAnnotation(pod.Annotations, hostnameAnnotationKey); ok {
 			if _, ok := domains[domain]; !ok {
 				domains[domain] = []string{}
 			}
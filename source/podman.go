@@ -0,0 +1,292 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// podmanAPIPrefix is the prefix of the libpod REST API paths, served by Podman 4 and later.
+const podmanAPIPrefix = "/v4.0.0/libpod"
+
+// podmanContainer is a container as returned by the libpod containers list API.
+type podmanContainer struct {
+	ID      string            `json:"Id"`
+	Names   []string          `json:"Names"`
+	Labels  map[string]string `json:"Labels"`
+	Pod     string            `json:"Pod"`
+	IsInfra bool              `json:"IsInfra"`
+}
+
+// podmanPod is a pod as returned by the libpod pods list API.
+type podmanPod struct {
+	ID      string            `json:"Id"`
+	Name    string            `json:"Name"`
+	Status  string            `json:"Status"`
+	Labels  map[string]string `json:"Labels"`
+	InfraID string            `json:"InfraId"`
+}
+
+type podmanNetwork struct {
+	IPAddress string `json:"IPAddress"`
+}
+
+// podmanContainerInspect is the part of a container inspected with the libpod API read by the source.
+type podmanContainerInspect struct {
+	NetworkSettings struct {
+		podmanNetwork
+		Networks map[string]podmanNetwork `json:"Networks"`
+	} `json:"NetworkSettings"`
+}
+
+// podmanSource is an implementation of Source that provides endpoints for the running
+// containers and pods of a Podman host, read from the libpod REST API of its socket.
+//
+// Containers and pods are configured with the same keys as the annotations of Kubernetes
+// resources, set as labels, e.g. external-dns.alpha.kubernetes.io/hostname, like the
+// services of the compose source. Without a target label, the endpoints point to the IPv4
+// addresses of the container, or of the infra container of its pod, and then to the default
+// targets, e.g. for rootless containers whose network isn't reachable from the host.
+type podmanSource struct {
+	baseURL        string
+	client         *http.Client
+	defaultTargets endpoint.Targets
+	labelSelector  labels.Selector
+}
+
+// NewPodmanSource creates a new podmanSource calling the Podman API at the given address,
+// either unix:///run/podman/podman.sock or tcp://podman.example.org:8080.
+func NewPodmanSource(address string, defaultTargets []string, annotationFilter string, timeout time.Duration) (Source, error) {
+	if address == "" {
+		return nil, fmt.Errorf("no Podman socket specified")
+	}
+
+	u, err := url.Parse(address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Podman socket %q: %w", address, err)
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	baseURL := "http://" + u.Host
+	switch u.Scheme {
+	case "unix":
+		socket := u.Path
+		transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", socket)
+		}
+		baseURL = "http://podman"
+	case "tcp":
+	default:
+		return nil, fmt.Errorf("invalid Podman socket %q: unsupported scheme %q, expected unix or tcp", address, u.Scheme)
+	}
+
+	labelSelector, err := getLabelSelector(annotationFilter)
+	if err != nil {
+		return nil, err
+	}
+
+	return &podmanSource{
+		baseURL:        baseURL,
+		client:         &http.Client{Transport: transport, Timeout: timeout},
+		defaultTargets: defaultTargets,
+		labelSelector:  labelSelector,
+	}, nil
+}
+
+// Endpoints returns endpoint objects for each running container and pod having a hostname label.
+func (ps *podmanSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	var containers []podmanContainer
+	if err := ps.get(ctx, "/containers/json", &containers); err != nil {
+		return nil, err
+	}
+	var pods []podmanPod
+	if err := ps.get(ctx, "/pods/json", &pods); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(containers, func(i, j int) bool { return podmanContainerName(containers[i]) < podmanContainerName(containers[j]) })
+	sort.Slice(pods, func(i, j int) bool { return pods[i].Name < pods[j].Name })
+
+	infraOf := map[string]string{}
+	for _, pod := range pods {
+		infraOf[pod.ID] = pod.InfraID
+	}
+
+	endpoints := []*endpoint.Endpoint{}
+	for _, c := range containers {
+		if c.IsInfra {
+			continue
+		}
+		name := podmanContainerName(c)
+		endpoints = append(endpoints, ps.endpointsFor("container", name, c.Labels, func() (endpoint.Targets, error) {
+			targets, err := ps.containerTargets(ctx, c.ID)
+			if err == nil && len(targets) == 0 && infraOf[c.Pod] != "" {
+				// the containers of a pod share the network of its infra container
+				targets, err = ps.containerTargets(ctx, infraOf[c.Pod])
+			}
+			return targets, err
+		})...)
+	}
+
+	for _, pod := range pods {
+		if pod.Status != "Running" {
+			continue
+		}
+		endpoints = append(endpoints, ps.endpointsFor("pod", pod.Name, pod.Labels, func() (endpoint.Targets, error) {
+			if pod.InfraID == "" {
+				return nil, nil
+			}
+			return ps.containerTargets(ctx, pod.InfraID)
+		})...)
+	}
+
+	return endpoints, nil
+}
+
+func (ps *podmanSource) AddEventHandler(ctx context.Context, handler func()) {
+}
+
+// endpointsFor returns the endpoints of a container or pod with the given labels, addresses
+// returning its addresses when it has no target label.
+func (ps *podmanSource) endpointsFor(kind, name string, podmanLabels map[string]string, addresses func() (endpoint.Targets, error)) []*endpoint.Endpoint {
+	return containerEndpoints("Podman "+kind, fmt.Sprintf("podman/%s/%s", kind, name), podmanLabels, ps.labelSelector, ps.defaultTargets, addresses)
+}
+
+// containerEndpoints returns the endpoints of a container configured through its labels like
+// the services of the compose source, i.e. with the keys of the annotations of Kubernetes
+// resources. Without a target label, they point to the addresses of the container and then
+// to the default targets.
+func containerEndpoints(kind, resource string, containerLabels map[string]string, labelSelector labels.Selector, defaultTargets endpoint.Targets, addresses func() (endpoint.Targets, error)) []*endpoint.Endpoint {
+	if !matchLabelSelector(labelSelector, containerLabels) {
+		return nil
+	}
+
+	controller, ok := lookupAnnotation(containerLabels, controllerAnnotationKey)
+	if ok && controller != controllerAnnotationValue {
+		log.Debugf("Skipping %s %s because controller value does not match, found: %s, required: %s",
+			kind, resource, controller, controllerAnnotationValue)
+		return nil
+	}
+
+	hostnames := getHostnamesFromAnnotations(containerLabels)
+	if len(hostnames) == 0 {
+		return nil
+	}
+
+	targets := getTargetsFromTargetAnnotation(containerLabels)
+	if len(targets) == 0 {
+		var err error
+		if targets, err = addresses(); err != nil {
+			// e.g. the container was removed since it was listed
+			log.Warnf("Skipping %s %s: %v", kind, resource, err)
+			return nil
+		}
+	}
+	if len(targets) == 0 {
+		targets = defaultTargets
+	}
+	if len(targets) == 0 {
+		log.Warnf("Skipping %s %s: no target", kind, resource)
+		return nil
+	}
+
+	ttl, err := getTTLFromAnnotations(containerLabels)
+	if err != nil {
+		log.Warn(err)
+	}
+	providerSpecific, setIdentifier := getProviderSpecificAnnotations(containerLabels)
+
+	var endpoints []*endpoint.Endpoint
+	for _, hostname := range hostnames {
+		for _, ep := range endpointsForHostname(hostname, targets, ttl, providerSpecific, setIdentifier) {
+			ep.Labels[endpoint.ResourceLabelKey] = resource
+			endpoints = append(endpoints, ep)
+		}
+	}
+	return endpoints
+}
+
+// containerTargets returns the IPv4 addresses of the container on all its networks.
+func (ps *podmanSource) containerTargets(ctx context.Context, id string) (endpoint.Targets, error) {
+	var inspect podmanContainerInspect
+	if err := ps.get(ctx, fmt.Sprintf("/containers/%s/json", url.PathEscape(id)), &inspect); err != nil {
+		return nil, err
+	}
+
+	addresses := []string{inspect.NetworkSettings.IPAddress}
+	for _, network := range inspect.NetworkSettings.Networks {
+		addresses = append(addresses, network.IPAddress)
+	}
+	return ipv4Targets(addresses), nil
+}
+
+// ipv4Targets returns the sorted and deduplicated IPv4 addresses among the given addresses.
+func ipv4Targets(addresses []string) endpoint.Targets {
+	var targets endpoint.Targets
+	for _, address := range addresses {
+		ip := net.ParseIP(address)
+		if ip == nil || ip.To4() == nil || containsTarget(targets, ip.String()) {
+			continue
+		}
+		targets = append(targets, ip.String())
+	}
+	sort.Sort(targets)
+	return targets
+}
+
+// get calls the given path of the libpod API and decodes the response into v.
+func (ps *podmanSource) get(ctx context.Context, path string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ps.baseURL+podmanAPIPrefix+path, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := ps.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call Podman API %s: %w", path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to call Podman API %s: unexpected status %s", path, resp.Status)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode Podman API %s response: %w", path, err)
+	}
+	return nil
+}
+
+func podmanContainerName(c podmanContainer) string {
+	if len(c.Names) == 0 {
+		return c.ID
+	}
+	return strings.TrimPrefix(c.Names[0], "/")
+}
@@ -0,0 +1,168 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+func newPodmanTestHandler() http.Handler {
+	responses := map[string]string{
+		"/v4.0.0/libpod/containers/json": `[
+			{"Id": "c1", "Names": ["web"], "Labels": {"external-dns.alpha.kubernetes.io/hostname": "web.example.org", "external-dns.alpha.kubernetes.io/ttl": "60"}},
+			{"Id": "c2", "Names": ["api"], "Labels": {"external-dns.alpha.kubernetes.io/hostname": "api.example.org", "external-dns.alpha.kubernetes.io/target": "lb.example.org"}},
+			{"Id": "c3", "Names": ["rootless"], "Labels": {"external-dns.alpha.kubernetes.io/hostname": "rootless.example.org"}},
+			{"Id": "c4", "Names": ["app"], "Pod": "p1", "Labels": {"external-dns.alpha.kubernetes.io/hostname": "app.example.org"}},
+			{"Id": "c5", "Names": ["other"], "Labels": {"external-dns.alpha.kubernetes.io/hostname": "other.example.org", "external-dns.alpha.kubernetes.io/controller": "other"}},
+			{"Id": "c6", "Names": ["nolabel"]},
+			{"Id": "i1", "Names": ["p1-infra"], "Pod": "p1", "IsInfra": true}
+		]`,
+		"/v4.0.0/libpod/pods/json": `[
+			{"Id": "p1", "Name": "shop", "Status": "Running", "InfraId": "i1", "Labels": {"external-dns.alpha.kubernetes.io/hostname": "shop.example.org"}},
+			{"Id": "p2", "Name": "stopped", "Status": "Exited", "InfraId": "i2", "Labels": {"external-dns.alpha.kubernetes.io/hostname": "stopped.example.org"}}
+		]`,
+		"/v4.0.0/libpod/containers/c1/json": `{"NetworkSettings": {"IPAddress": "", "Networks": {"podman": {"IPAddress": "10.88.0.2"}, "backend": {"IPAddress": "10.89.0.2"}}}}`,
+		"/v4.0.0/libpod/containers/c3/json": `{"NetworkSettings": {"IPAddress": "", "Networks": {}}}`,
+		"/v4.0.0/libpod/containers/c4/json": `{"NetworkSettings": {"IPAddress": ""}}`,
+		"/v4.0.0/libpod/containers/i1/json": `{"NetworkSettings": {"IPAddress": "10.88.0.10", "Networks": {"podman": {"IPAddress": "10.88.0.10"}}}}`,
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		response, ok := responses[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(response))
+	})
+}
+
+func TestPodmanSource(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Interface", testPodmanSourceImplementsSource)
+	t.Run("NewPodmanSource", testPodmanSourceNewPodmanSource)
+	t.Run("Endpoints", testPodmanSourceEndpoints)
+	t.Run("UnixSocket", testPodmanSourceUnixSocket)
+}
+
+// testPodmanSourceImplementsSource tests that podmanSource is a valid Source.
+func testPodmanSourceImplementsSource(t *testing.T) {
+	assert.Implements(t, (*Source)(nil), new(podmanSource))
+}
+
+func testPodmanSourceNewPodmanSource(t *testing.T) {
+	for _, ti := range []struct {
+		title   string
+		address string
+		filter  string
+	}{
+		{title: "no socket"},
+		{title: "unsupported scheme", address: "ssh://podman.example.org"},
+		{title: "invalid annotation filter", address: "unix:///run/podman/podman.sock", filter: "=invalid"},
+	} {
+		ti := ti
+		t.Run(ti.title, func(t *testing.T) {
+			_, err := NewPodmanSource(ti.address, nil, ti.filter, time.Second)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func testPodmanSourceEndpoints(t *testing.T) {
+	server := httptest.NewServer(newPodmanTestHandler())
+	defer server.Close()
+
+	for _, ti := range []struct {
+		title            string
+		defaultTargets   []string
+		annotationFilter string
+		expected         []*endpoint.Endpoint
+	}{
+		{
+			title: "containers and pods with a hostname label",
+			expected: []*endpoint.Endpoint{
+				{DNSName: "api.example.org", Targets: endpoint.Targets{"lb.example.org"}, RecordType: endpoint.RecordTypeCNAME, Labels: endpoint.Labels{endpoint.ResourceLabelKey: "podman/container/api"}},
+				{DNSName: "app.example.org", Targets: endpoint.Targets{"10.88.0.10"}, RecordType: endpoint.RecordTypeA, Labels: endpoint.Labels{endpoint.ResourceLabelKey: "podman/container/app"}},
+				{DNSName: "web.example.org", Targets: endpoint.Targets{"10.88.0.2", "10.89.0.2"}, RecordType: endpoint.RecordTypeA, RecordTTL: 60, Labels: endpoint.Labels{endpoint.ResourceLabelKey: "podman/container/web"}},
+				{DNSName: "shop.example.org", Targets: endpoint.Targets{"10.88.0.10"}, RecordType: endpoint.RecordTypeA, Labels: endpoint.Labels{endpoint.ResourceLabelKey: "podman/pod/shop"}},
+			},
+		},
+		{
+			title:          "default targets of containers without address",
+			defaultTargets: []string{"192.0.2.1"},
+			expected: []*endpoint.Endpoint{
+				{DNSName: "api.example.org", Targets: endpoint.Targets{"lb.example.org"}, RecordType: endpoint.RecordTypeCNAME},
+				{DNSName: "app.example.org", Targets: endpoint.Targets{"10.88.0.10"}, RecordType: endpoint.RecordTypeA},
+				{DNSName: "rootless.example.org", Targets: endpoint.Targets{"192.0.2.1"}, RecordType: endpoint.RecordTypeA, Labels: endpoint.Labels{endpoint.ResourceLabelKey: "podman/container/rootless"}},
+				{DNSName: "web.example.org", Targets: endpoint.Targets{"10.88.0.2", "10.89.0.2"}, RecordType: endpoint.RecordTypeA, RecordTTL: 60},
+				{DNSName: "shop.example.org", Targets: endpoint.Targets{"10.88.0.10"}, RecordType: endpoint.RecordTypeA},
+			},
+		},
+		{
+			title:            "annotation filter",
+			annotationFilter: "external-dns.alpha.kubernetes.io/ttl=60",
+			expected: []*endpoint.Endpoint{
+				{DNSName: "web.example.org", Targets: endpoint.Targets{"10.88.0.2", "10.89.0.2"}, RecordType: endpoint.RecordTypeA, RecordTTL: 60},
+			},
+		},
+	} {
+		ti := ti
+		t.Run(ti.title, func(t *testing.T) {
+			src, err := NewPodmanSource("tcp://"+server.Listener.Addr().String(), ti.defaultTargets, ti.annotationFilter, time.Second)
+			require.NoError(t, err)
+
+			endpoints, err := src.Endpoints(context.Background())
+			require.NoError(t, err)
+
+			validateEndpoints(t, endpoints, ti.expected)
+		})
+	}
+}
+
+func testPodmanSourceUnixSocket(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "podman.sock")
+	listener, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+
+	server := httptest.NewUnstartedServer(newPodmanTestHandler())
+	server.Listener = listener
+	server.Start()
+	defer server.Close()
+
+	src, err := NewPodmanSource("unix://"+socket, nil, "", time.Second)
+	require.NoError(t, err)
+
+	endpoints, err := src.Endpoints(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, endpoints, 4)
+
+	server.Close()
+	_, err = src.Endpoints(context.Background())
+	assert.Error(t, err)
+}
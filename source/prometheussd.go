@@ -0,0 +1,193 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"text/template"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// prometheusSDTargetGroup is a target group of the Prometheus HTTP service discovery format,
+// see https://prometheus.io/docs/prometheus/latest/http_sd/.
+type prometheusSDTargetGroup struct {
+	Targets []string          `json:"targets"`
+	Labels  map[string]string `json:"labels"`
+}
+
+// prometheusSDTarget is the data the FQDN template is executed with for each target.
+type prometheusSDTarget struct {
+	Target string
+	Host   string
+	Port   string
+	Labels map[string]string
+}
+
+// prometheusSDSource is an implementation of Source that provides endpoints for the
+// targets returned by a Prometheus HTTP service discovery endpoint.
+//
+// The hostnames of a target group are read from the configured label, holding a comma
+// separated list of hostnames, or compiled from each target via the FQDN Go template string.
+// Each hostname points to the hosts of the targets of its group, with their ports removed;
+// IPv6 targets are skipped.
+type prometheusSDSource struct {
+	url           string
+	authHeader    string
+	client        *http.Client
+	hostnameLabel string
+	ttlLabel      string
+	fqdnTemplate  *template.Template
+}
+
+// NewPrometheusSDSource creates a new prometheusSDSource polling the given URL.
+func NewPrometheusSDSource(sdURL, authHeader, hostnameLabel, ttlLabel, fqdnTemplate string, timeout time.Duration) (Source, error) {
+	if sdURL == "" {
+		return nil, fmt.Errorf("no Prometheus HTTP SD URL specified")
+	}
+
+	tmpl, err := parseTemplate(fqdnTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	return &prometheusSDSource{
+		url:           sdURL,
+		authHeader:    authHeader,
+		client:        &http.Client{Timeout: timeout},
+		hostnameLabel: hostnameLabel,
+		ttlLabel:      ttlLabel,
+		fqdnTemplate:  tmpl,
+	}, nil
+}
+
+// Endpoints returns endpoint objects for each labeled target group.
+func (ps *prometheusSDSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	groups, err := ps.targetGroups(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// the targets of a hostname are merged across the target groups, e.g. when the groups of
+	// the scrape jobs of a host list the same address
+	targetsByHostname := map[string]endpoint.Targets{}
+	ttlByHostname := map[string]endpoint.TTL{}
+	seen := map[string]bool{}
+	var hostnames []string
+	for i, group := range groups {
+		var ttl endpoint.TTL
+		if value, ok := group.Labels[ps.ttlLabel]; ok && ps.ttlLabel != "" {
+			ttl, err = getTTLFromAnnotations(map[string]string{ttlAnnotationKey: value})
+			if err != nil {
+				log.Warnf("Prometheus SD target group %d: %v", i, err)
+			}
+		}
+
+		groupHasHostname := false
+		for _, target := range group.Targets {
+			host, port, err := net.SplitHostPort(target)
+			if err != nil {
+				host = target
+			}
+			if ip := net.ParseIP(host); host == "" || (ip != nil && ip.To4() == nil) {
+				continue
+			}
+
+			names, err := ps.hostnames(group, prometheusSDTarget{Target: target, Host: host, Port: port, Labels: group.Labels})
+			if err != nil {
+				return nil, err
+			}
+			for _, name := range names {
+				groupHasHostname = true
+				if _, ok := targetsByHostname[name]; !ok {
+					hostnames = append(hostnames, name)
+					ttlByHostname[name] = ttl
+				}
+				if !seen[name+"/"+host] {
+					seen[name+"/"+host] = true
+					targetsByHostname[name] = append(targetsByHostname[name], host)
+				}
+			}
+		}
+		if !groupHasHostname {
+			log.Debugf("Skipping Prometheus SD target group %d: no hostname", i)
+		}
+	}
+
+	endpoints := []*endpoint.Endpoint{}
+	for _, hostname := range hostnames {
+		for _, ep := range endpointsForHostname(hostname, targetsByHostname[hostname], ttlByHostname[hostname], nil, "") {
+			ep.Labels[endpoint.ResourceLabelKey] = fmt.Sprintf("prometheus-sd/%s", hostname)
+			endpoints = append(endpoints, ep)
+		}
+	}
+
+	return endpoints, nil
+}
+
+func (ps *prometheusSDSource) AddEventHandler(ctx context.Context, handler func()) {
+}
+
+// hostnames returns the hostnames of the group label or, if it is not set, the ones compiled from the target.
+func (ps *prometheusSDSource) hostnames(group prometheusSDTargetGroup, target prometheusSDTarget) ([]string, error) {
+	if label, ok := group.Labels[ps.hostnameLabel]; ok && ps.hostnameLabel != "" {
+		return splitHostnames(label), nil
+	}
+	if ps.fqdnTemplate == nil {
+		return nil, nil
+	}
+
+	hostnames, err := execTemplate(ps.fqdnTemplate, target)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile the hostnames of Prometheus SD target %s: %w", target.Target, err)
+	}
+	return hostnames, nil
+}
+
+func (ps *prometheusSDSource) targetGroups(ctx context.Context) ([]prometheusSDTargetGroup, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ps.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if ps.authHeader != "" {
+		req.Header.Set("Authorization", ps.authHeader)
+	}
+
+	resp, err := ps.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch Prometheus SD targets from %s: %w", ps.url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch Prometheus SD targets from %s: unexpected status %s", ps.url, resp.Status)
+	}
+
+	var groups []prometheusSDTargetGroup
+	if err := json.NewDecoder(resp.Body).Decode(&groups); err != nil {
+		return nil, fmt.Errorf("failed to decode Prometheus SD targets from %s: %w", ps.url, err)
+	}
+	return groups, nil
+}
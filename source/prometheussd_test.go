@@ -0,0 +1,156 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+func TestPrometheusSDSource(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Interface", testPrometheusSDSourceImplementsSource)
+	t.Run("Endpoints", testPrometheusSDSourceEndpoints)
+}
+
+// testPrometheusSDSourceImplementsSource tests that prometheusSDSource is a valid Source.
+func testPrometheusSDSourceImplementsSource(t *testing.T) {
+	assert.Implements(t, (*Source)(nil), new(prometheusSDSource))
+}
+
+func testPrometheusSDSourceEndpoints(t *testing.T) {
+	for _, ti := range []struct {
+		title        string
+		status       int
+		response     string
+		authHeader   string
+		fqdnTemplate string
+		expected     []*endpoint.Endpoint
+		expectError  bool
+	}{
+		{
+			title:       "unexpected status",
+			status:      http.StatusInternalServerError,
+			expectError: true,
+		},
+		{
+			title:       "invalid json",
+			response:    `{"targets":`,
+			expectError: true,
+		},
+		{
+			title:    "no target groups",
+			response: `[]`,
+			expected: []*endpoint.Endpoint{},
+		},
+		{
+			title: "hostnames from label",
+			response: `[
+				{"targets": ["10.0.0.1:9100", "10.0.0.2:9100", "10.0.0.1:9256"], "labels": {"job": "node", "dns_name": "nodes.example.org", "dns_ttl": "60"}},
+				{"targets": ["lb.example.net:443"], "labels": {"dns_name": "www.example.org, web.example.org"}},
+				{"targets": ["10.0.0.3:9100", "[fd00::3]:9100"], "labels": {"dns_name": "db.example.org"}},
+				{"targets": ["10.0.0.4:9100"], "labels": {"job": "unlabeled"}}
+			]`,
+			expected: []*endpoint.Endpoint{
+				{DNSName: "nodes.example.org", Targets: endpoint.Targets{"10.0.0.1", "10.0.0.2"}, RecordType: endpoint.RecordTypeA, RecordTTL: 60, Labels: endpoint.Labels{endpoint.ResourceLabelKey: "prometheus-sd/nodes.example.org"}},
+				{DNSName: "www.example.org", Targets: endpoint.Targets{"lb.example.net"}, RecordType: endpoint.RecordTypeCNAME, Labels: endpoint.Labels{endpoint.ResourceLabelKey: "prometheus-sd/www.example.org"}},
+				{DNSName: "web.example.org", Targets: endpoint.Targets{"lb.example.net"}, RecordType: endpoint.RecordTypeCNAME, Labels: endpoint.Labels{endpoint.ResourceLabelKey: "prometheus-sd/web.example.org"}},
+				{DNSName: "db.example.org", Targets: endpoint.Targets{"10.0.0.3"}, RecordType: endpoint.RecordTypeA},
+			},
+		},
+		{
+			title:        "hostnames from template for groups without label",
+			fqdnTemplate: `{{index .Labels "job"}}-{{.Port}}.example.org`,
+			response: `[
+				{"targets": ["10.0.0.1:9100"], "labels": {"job": "node", "dns_name": "nodes.example.org"}},
+				{"targets": ["10.0.0.4:9100", "10.0.0.5:9256"], "labels": {"job": "unlabeled"}}
+			]`,
+			expected: []*endpoint.Endpoint{
+				{DNSName: "nodes.example.org", Targets: endpoint.Targets{"10.0.0.1"}, RecordType: endpoint.RecordTypeA},
+				{DNSName: "unlabeled-9100.example.org", Targets: endpoint.Targets{"10.0.0.4"}, RecordType: endpoint.RecordTypeA},
+				{DNSName: "unlabeled-9256.example.org", Targets: endpoint.Targets{"10.0.0.5"}, RecordType: endpoint.RecordTypeA},
+			},
+		},
+		{
+			title: "targets of a hostname are merged across groups",
+			response: `[
+				{"targets": ["10.0.0.1:9100", "10.0.0.2:9100"], "labels": {"job": "node", "dns_name": "nodes.example.org", "dns_ttl": "60"}},
+				{"targets": ["10.0.0.1:9256", "10.0.0.3:9256"], "labels": {"job": "process", "dns_name": "nodes.example.org", "dns_ttl": "300"}},
+				{"targets": ["lb.example.net:443"], "labels": {"dns_name": "nodes.example.org"}}
+			]`,
+			expected: []*endpoint.Endpoint{
+				{DNSName: "nodes.example.org", Targets: endpoint.Targets{"10.0.0.1", "10.0.0.2", "10.0.0.3"}, RecordType: endpoint.RecordTypeA, RecordTTL: 60},
+				{DNSName: "nodes.example.org", Targets: endpoint.Targets{"lb.example.net"}, RecordType: endpoint.RecordTypeCNAME, RecordTTL: 60},
+			},
+		},
+		{
+			title:      "authorization header",
+			authHeader: "Bearer secret",
+			response:   `[{"targets": ["10.0.0.1:9100"], "labels": {"dns_name": "nodes.example.org"}}]`,
+			expected: []*endpoint.Endpoint{
+				{DNSName: "nodes.example.org", Targets: endpoint.Targets{"10.0.0.1"}, RecordType: endpoint.RecordTypeA},
+			},
+		},
+	} {
+		ti := ti
+		t.Run(ti.title, func(t *testing.T) {
+			t.Parallel()
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("Authorization") != ti.authHeader {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				if ti.status != 0 {
+					w.WriteHeader(ti.status)
+					return
+				}
+				w.Write([]byte(ti.response))
+			}))
+			defer server.Close()
+
+			src, err := NewPrometheusSDSource(server.URL, ti.authHeader, "dns_name", "dns_ttl", ti.fqdnTemplate, time.Second)
+			require.NoError(t, err)
+
+			endpoints, err := src.Endpoints(context.Background())
+			if ti.expectError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			validateEndpoints(t, endpoints, ti.expected)
+		})
+	}
+}
+
+func TestNewPrometheusSDSource(t *testing.T) {
+	_, err := NewPrometheusSDSource("", "", "dns_name", "dns_ttl", "", time.Second)
+	assert.Error(t, err)
+
+	_, err = NewPrometheusSDSource("http://sd.example.org", "", "dns_name", "dns_ttl", "{{.Host", time.Second)
+	assert.Error(t, err)
+}
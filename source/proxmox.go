@@ -0,0 +1,262 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+const (
+	proxmoxTypeQemu = "qemu"
+	proxmoxTypeLXC  = "lxc"
+)
+
+// proxmoxResource is a guest as returned by the Proxmox /cluster/resources API.
+type proxmoxResource struct {
+	ID     string `json:"id"`
+	VMID   int    `json:"vmid"`
+	Name   string `json:"name"`
+	Node   string `json:"node"`
+	Pool   string `json:"pool"`
+	Status string `json:"status"`
+	Type   string `json:"type"`
+	Tags   string `json:"tags"`
+}
+
+type proxmoxQemuInterfaces struct {
+	Result []struct {
+		Name        string `json:"name"`
+		IPAddresses []struct {
+			Type    string `json:"ip-address-type"`
+			Address string `json:"ip-address"`
+		} `json:"ip-addresses"`
+	} `json:"result"`
+}
+
+type proxmoxLXCInterface struct {
+	Name string `json:"name"`
+	Inet string `json:"inet"`
+}
+
+// proxmoxSource is an implementation of Source that provides endpoints for the
+// running QEMU virtual machines and LXC containers of a Proxmox VE cluster.
+//
+// Hostnames are taken from guest tags starting with the configured tag prefix
+// (e.g. the tag "dns.www.example.org" publishes www.example.org), or compiled
+// from the guest via the FQDN Go template string.
+type proxmoxSource struct {
+	apiURL       string
+	token        string
+	client       *http.Client
+	nodes        map[string]bool
+	pools        map[string]bool
+	tagPrefix    string
+	fqdnTemplate *template.Template
+}
+
+// NewProxmoxSource creates a new proxmoxSource with the given config. The token
+// has the form USER@REALM!TOKENID=SECRET.
+func NewProxmoxSource(apiURL, token string, insecure bool, nodes, pools []string, tagPrefix, fqdnTemplate string, timeout time.Duration) (Source, error) {
+	if apiURL == "" {
+		return nil, fmt.Errorf("no Proxmox API URL specified")
+	}
+
+	tmpl, err := parseTemplate(fqdnTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: insecure}
+
+	return &proxmoxSource{
+		apiURL:       strings.TrimSuffix(apiURL, "/"),
+		token:        token,
+		client:       &http.Client{Transport: transport, Timeout: timeout},
+		nodes:        toSet(nodes),
+		pools:        toSet(pools),
+		tagPrefix:    tagPrefix,
+		fqdnTemplate: tmpl,
+	}, nil
+}
+
+// Endpoints returns endpoint objects for each running guest having at least one IPv4 address.
+func (ps *proxmoxSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	var resources []proxmoxResource
+	if err := ps.get(ctx, "/cluster/resources?type=vm", &resources); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(resources, func(i, j int) bool { return resources[i].VMID < resources[j].VMID })
+
+	endpoints := []*endpoint.Endpoint{}
+	for _, guest := range resources {
+		if guest.Status != "running" {
+			continue
+		}
+		if len(ps.nodes) > 0 && !ps.nodes[guest.Node] {
+			continue
+		}
+		if len(ps.pools) > 0 && !ps.pools[guest.Pool] {
+			continue
+		}
+
+		hostnames, err := ps.hostnames(guest)
+		if err != nil {
+			return nil, err
+		}
+		if len(hostnames) == 0 {
+			log.Debugf("Skipping Proxmox guest %s: no hostname", guest.ID)
+			continue
+		}
+
+		targets, err := ps.guestTargets(ctx, guest)
+		if err != nil {
+			log.Warnf("Skipping Proxmox guest %s: %v", guest.ID, err)
+			continue
+		}
+		if len(targets) == 0 {
+			log.Debugf("Skipping Proxmox guest %s: no IPv4 address", guest.ID)
+			continue
+		}
+
+		for _, hostname := range hostnames {
+			for _, ep := range endpointsForHostname(hostname, targets, endpoint.TTL(0), nil, "") {
+				ep.Labels[endpoint.ResourceLabelKey] = fmt.Sprintf("proxmox/%s/%s", guest.Node, guest.ID)
+				endpoints = append(endpoints, ep)
+			}
+		}
+	}
+
+	return endpoints, nil
+}
+
+func (ps *proxmoxSource) AddEventHandler(ctx context.Context, handler func()) {
+}
+
+func (ps *proxmoxSource) hostnames(guest proxmoxResource) ([]string, error) {
+	var hostnames []string
+	if ps.tagPrefix != "" {
+		for _, tag := range strings.FieldsFunc(guest.Tags, func(r rune) bool { return r == ';' || r == ',' || r == ' ' }) {
+			if hostname := strings.TrimPrefix(tag, ps.tagPrefix); hostname != tag && hostname != "" {
+				hostnames = append(hostnames, hostname)
+			}
+		}
+	}
+	if len(hostnames) > 0 || ps.fqdnTemplate == nil {
+		return hostnames, nil
+	}
+
+	hostnames, err := execTemplate(ps.fqdnTemplate, guest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile the hostnames of Proxmox guest %s: %w", guest.ID, err)
+	}
+	return hostnames, nil
+}
+
+// guestTargets returns the IPv4 addresses reported for the guest, excluding loopback addresses.
+func (ps *proxmoxSource) guestTargets(ctx context.Context, guest proxmoxResource) (endpoint.Targets, error) {
+	var addresses []string
+
+	switch guest.Type {
+	case proxmoxTypeQemu:
+		var ifaces proxmoxQemuInterfaces
+		if err := ps.get(ctx, fmt.Sprintf("/nodes/%s/qemu/%d/agent/network-get-interfaces", guest.Node, guest.VMID), &ifaces); err != nil {
+			return nil, err
+		}
+		for _, iface := range ifaces.Result {
+			for _, addr := range iface.IPAddresses {
+				if addr.Type == "ipv4" {
+					addresses = append(addresses, addr.Address)
+				}
+			}
+		}
+	case proxmoxTypeLXC:
+		var ifaces []proxmoxLXCInterface
+		if err := ps.get(ctx, fmt.Sprintf("/nodes/%s/lxc/%d/interfaces", guest.Node, guest.VMID), &ifaces); err != nil {
+			return nil, err
+		}
+		for _, iface := range ifaces {
+			if iface.Inet != "" {
+				addresses = append(addresses, strings.SplitN(iface.Inet, "/", 2)[0])
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unsupported guest type %q", guest.Type)
+	}
+
+	var targets endpoint.Targets
+	for _, addr := range addresses {
+		ip := net.ParseIP(addr)
+		if ip == nil || ip.To4() == nil || ip.IsLoopback() {
+			continue
+		}
+		targets = append(targets, ip.String())
+	}
+	sort.Sort(targets)
+	return targets, nil
+}
+
+// get calls the given path of the Proxmox API and decodes the data field of the response into v.
+func (ps *proxmoxSource) get(ctx context.Context, path string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ps.apiURL+"/api2/json"+path, nil)
+	if err != nil {
+		return err
+	}
+	if ps.token != "" {
+		req.Header.Set("Authorization", "PVEAPIToken="+ps.token)
+	}
+
+	resp, err := ps.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call Proxmox API %s: %w", path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to call Proxmox API %s: unexpected status %s", path, resp.Status)
+	}
+
+	body := struct {
+		Data interface{} `json:"data"`
+	}{Data: v}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return fmt.Errorf("failed to decode Proxmox API %s response: %w", path, err)
+	}
+	return nil
+}
+
+func toSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		set[v] = true
+	}
+	return set
+}
@@ -0,0 +1,168 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+const proxmoxTestToken = "external-dns@pve!dns=00000000-0000-0000-0000-000000000000"
+
+func newProxmoxTestServer(t *testing.T) *httptest.Server {
+	responses := map[string]string{
+		"/api2/json/cluster/resources": `{"data": [
+			{"id": "qemu/100", "vmid": 100, "name": "web", "node": "pve1", "pool": "prod", "status": "running", "type": "qemu", "tags": "dns.www.example.org;dns.web.example.org;backup"},
+			{"id": "qemu/101", "vmid": 101, "name": "db", "node": "pve2", "status": "running", "type": "qemu"},
+			{"id": "lxc/200", "vmid": 200, "name": "proxy", "node": "pve1", "pool": "prod", "status": "running", "type": "lxc"},
+			{"id": "lxc/201", "vmid": 201, "name": "stopped", "node": "pve1", "status": "stopped", "type": "lxc"},
+			{"id": "qemu/102", "vmid": 102, "name": "noagent", "node": "pve1", "status": "running", "type": "qemu"}
+		]}`,
+		"/api2/json/nodes/pve1/qemu/100/agent/network-get-interfaces": `{"data": {"result": [
+			{"name": "lo", "ip-addresses": [{"ip-address-type": "ipv4", "ip-address": "127.0.0.1"}]},
+			{"name": "eth0", "ip-addresses": [{"ip-address-type": "ipv4", "ip-address": "10.0.0.100"}, {"ip-address-type": "ipv6", "ip-address": "fd00::100"}]}
+		]}}`,
+		"/api2/json/nodes/pve2/qemu/101/agent/network-get-interfaces": `{"data": {"result": [
+			{"name": "eth0", "ip-addresses": [{"ip-address-type": "ipv4", "ip-address": "10.0.0.101"}]}
+		]}}`,
+		"/api2/json/nodes/pve1/lxc/200/interfaces": `{"data": [
+			{"name": "lo", "inet": "127.0.0.1/8"},
+			{"name": "eth0", "inet": "10.0.0.200/24"}
+		]}`,
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "PVEAPIToken="+proxmoxTestToken {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		response, ok := responses[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte(response))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestProxmoxSource(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Interface", testProxmoxSourceImplementsSource)
+	t.Run("Endpoints", testProxmoxSourceEndpoints)
+}
+
+// testProxmoxSourceImplementsSource tests that proxmoxSource is a valid Source.
+func testProxmoxSourceImplementsSource(t *testing.T) {
+	assert.Implements(t, (*Source)(nil), new(proxmoxSource))
+}
+
+func testProxmoxSourceEndpoints(t *testing.T) {
+	server := newProxmoxTestServer(t)
+
+	for _, ti := range []struct {
+		title        string
+		token        string
+		nodes        []string
+		pools        []string
+		tagPrefix    string
+		fqdnTemplate string
+		expected     []*endpoint.Endpoint
+		expectError  bool
+	}{
+		{
+			title:       "unauthorized",
+			token:       "invalid",
+			expectError: true,
+		},
+		{
+			title:     "hostnames from tags",
+			token:     proxmoxTestToken,
+			tagPrefix: "dns.",
+			expected: []*endpoint.Endpoint{
+				{DNSName: "www.example.org", Targets: endpoint.Targets{"10.0.0.100"}, RecordType: endpoint.RecordTypeA, Labels: endpoint.Labels{endpoint.ResourceLabelKey: "proxmox/pve1/qemu/100"}},
+				{DNSName: "web.example.org", Targets: endpoint.Targets{"10.0.0.100"}, RecordType: endpoint.RecordTypeA, Labels: endpoint.Labels{endpoint.ResourceLabelKey: "proxmox/pve1/qemu/100"}},
+			},
+		},
+		{
+			title:        "hostnames from template for untagged guests",
+			token:        proxmoxTestToken,
+			tagPrefix:    "dns.",
+			fqdnTemplate: "{{.Name}}.{{.Node}}.example.org",
+			expected: []*endpoint.Endpoint{
+				{DNSName: "www.example.org", Targets: endpoint.Targets{"10.0.0.100"}, RecordType: endpoint.RecordTypeA},
+				{DNSName: "web.example.org", Targets: endpoint.Targets{"10.0.0.100"}, RecordType: endpoint.RecordTypeA},
+				{DNSName: "db.pve2.example.org", Targets: endpoint.Targets{"10.0.0.101"}, RecordType: endpoint.RecordTypeA},
+				{DNSName: "proxy.pve1.example.org", Targets: endpoint.Targets{"10.0.0.200"}, RecordType: endpoint.RecordTypeA},
+			},
+		},
+		{
+			title:        "node filter",
+			token:        proxmoxTestToken,
+			nodes:        []string{"pve2"},
+			fqdnTemplate: "{{.Name}}.example.org",
+			expected: []*endpoint.Endpoint{
+				{DNSName: "db.example.org", Targets: endpoint.Targets{"10.0.0.101"}, RecordType: endpoint.RecordTypeA},
+			},
+		},
+		{
+			title:        "pool filter",
+			token:        proxmoxTestToken,
+			pools:        []string{"prod"},
+			fqdnTemplate: "{{.Name}}.example.org",
+			expected: []*endpoint.Endpoint{
+				{DNSName: "web.example.org", Targets: endpoint.Targets{"10.0.0.100"}, RecordType: endpoint.RecordTypeA},
+				{DNSName: "proxy.example.org", Targets: endpoint.Targets{"10.0.0.200"}, RecordType: endpoint.RecordTypeA},
+			},
+		},
+	} {
+		ti := ti
+		t.Run(ti.title, func(t *testing.T) {
+			t.Parallel()
+
+			src, err := NewProxmoxSource(server.URL+"/", ti.token, false, ti.nodes, ti.pools, ti.tagPrefix, ti.fqdnTemplate, time.Second)
+			require.NoError(t, err)
+
+			endpoints, err := src.Endpoints(context.Background())
+			if ti.expectError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			validateEndpoints(t, endpoints, ti.expected)
+		})
+	}
+}
+
+func TestNewProxmoxSource(t *testing.T) {
+	_, err := NewProxmoxSource("", proxmoxTestToken, false, nil, nil, "dns.", "", time.Second)
+	assert.Error(t, err)
+
+	_, err = NewProxmoxSource("https://pve.example.org:8006", proxmoxTestToken, false, nil, nil, "dns.", "{{.Name", time.Second)
+	assert.Error(t, err)
+}
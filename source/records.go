@@ -0,0 +1,240 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+const (
+	// The annotation used for defining SRV records, as a comma separated list of
+	// "[_service._proto] priority weight port target" entries
+	srvAnnotationKey = "external-dns.alpha.kubernetes.io/srv"
+	// The annotations used for defining HTTPS and SVCB records, as a semicolon separated list of
+	// "[_port._scheme] priority target [key=value...]" entries
+	httpsAnnotationKey = "external-dns.alpha.kubernetes.io/https"
+	svcbAnnotationKey  = "external-dns.alpha.kubernetes.io/svcb"
+	// The annotation used for defining TLSA records, as a comma separated list of
+	// "[_port._proto] usage selector matching-type certdata" entries
+	tlsaAnnotationKey = "external-dns.alpha.kubernetes.io/tlsa"
+	// The annotation used for defining MX records, as a comma separated list of
+	// "priority exchange" entries
+	mxAnnotationKey = "external-dns.alpha.kubernetes.io/mx"
+	// The annotation used for defining NAPTR records, as a semicolon separated list of
+	// `order preference "flags" "service" "regexp" replacement` entries
+	naptrAnnotationKey = "external-dns.alpha.kubernetes.io/naptr"
+)
+
+// recordAnnotation describes an annotation defining records of a given type. The value of
+// the annotation is a list of entries, each parsed into a target and an optional name
+// relative to the hostname of the resource.
+type recordAnnotation struct {
+	recordType string
+	// separator splits the entries, a comma unless the record data may contain commas
+	separator string
+	parse     func(entry string) (name, target string, err error)
+}
+
+// recordAnnotations are the annotations defining additional records for each hostname of a resource
+var recordAnnotations = map[string]recordAnnotation{
+	srvAnnotationKey:   {recordType: endpoint.RecordTypeSRV, parse: parseSRVEntry},
+	httpsAnnotationKey: {recordType: endpoint.RecordTypeHTTPS, separator: ";", parse: parseSVCBEntry},
+	svcbAnnotationKey:  {recordType: endpoint.RecordTypeSVCB, separator: ";", parse: parseSVCBEntry},
+	tlsaAnnotationKey:  {recordType: endpoint.RecordTypeTLSA, parse: parseTLSAEntry},
+	mxAnnotationKey:    {recordType: endpoint.RecordTypeMX, parse: parseMXEntry},
+	naptrAnnotationKey: {recordType: endpoint.RecordTypeNAPTR, separator: ";", parse: parseNAPTREntry},
+}
+
+// getRecordAnnotations returns the record annotations of a resource as provider specific
+// properties, so that they reach endpointsForHostname along with the other annotations.
+func getRecordAnnotations(annotations map[string]string) endpoint.ProviderSpecific {
+	var properties endpoint.ProviderSpecific
+	for key := range recordAnnotations {
+		if v, exists := annotations[key]; exists && strings.TrimSpace(v) != "" {
+			properties = append(properties, endpoint.ProviderSpecificProperty{Name: key, Value: v})
+		}
+	}
+	sort.Slice(properties, func(i, j int) bool { return properties[i].Name < properties[j].Name })
+	return properties
+}
+
+// endpointsForRecordAnnotations returns the endpoints defined by the record annotations found
+// in providerSpecific for the hostname, and the provider specific properties left once the
+// record annotations are removed.
+func endpointsForRecordAnnotations(hostname string, ttl endpoint.TTL, providerSpecific endpoint.ProviderSpecific, setIdentifier string) ([]*endpoint.Endpoint, endpoint.ProviderSpecific) {
+	var (
+		endpoints []*endpoint.Endpoint
+		remaining = endpoint.ProviderSpecific{}
+		found     bool
+	)
+	hostname = strings.TrimSuffix(hostname, ".")
+
+	for _, property := range providerSpecific {
+		if _, ok := recordAnnotations[property.Name]; ok {
+			found = true
+		}
+	}
+	if !found {
+		return nil, providerSpecific
+	}
+
+	for _, property := range providerSpecific {
+		annotation, ok := recordAnnotations[property.Name]
+		if !ok {
+			remaining = append(remaining, property)
+			continue
+		}
+
+		var names []string
+		targets := map[string]endpoint.Targets{}
+		separator := annotation.separator
+		if separator == "" {
+			separator = ","
+		}
+		for _, entry := range strings.Split(property.Value, separator) {
+			entry = strings.TrimSpace(entry)
+			if entry == "" {
+				continue
+			}
+			name, target, err := annotation.parse(entry)
+			if err != nil {
+				log.Warnf("Skipping invalid %s entry %q of %s: %v", annotation.recordType, entry, hostname, err)
+				continue
+			}
+			if name == "" {
+				name = hostname
+			} else {
+				name = name + "." + hostname
+			}
+			if _, ok := targets[name]; !ok {
+				names = append(names, name)
+			}
+			targets[name] = append(targets[name], target)
+		}
+
+		for _, name := range names {
+			endpoints = append(endpoints, &endpoint.Endpoint{
+				DNSName:       name,
+				Targets:       targets[name],
+				RecordTTL:     ttl,
+				RecordType:    annotation.recordType,
+				Labels:        endpoint.NewLabels(),
+				SetIdentifier: setIdentifier,
+			})
+		}
+	}
+
+	for _, ep := range endpoints {
+		ep.ProviderSpecific = remaining
+	}
+	return endpoints, remaining
+}
+
+// parseSRVEntry parses "[_service._proto] priority weight port target".
+func parseSRVEntry(entry string) (string, string, error) {
+	fields := strings.Fields(entry)
+	name := ""
+	if len(fields) == 5 {
+		name, fields = fields[0], fields[1:]
+		labels := strings.Split(name, ".")
+		if len(labels) != 2 || !strings.HasPrefix(labels[0], "_") || !strings.HasPrefix(labels[1], "_") {
+			return "", "", fmt.Errorf("invalid service and protocol %q, expected _service._proto", name)
+		}
+	}
+	if len(fields) != 4 {
+		return "", "", fmt.Errorf("expected [_service._proto] priority weight port target")
+	}
+	for i, field := range []string{"priority", "weight", "port"} {
+		if _, err := strconv.ParseUint(fields[i], 10, 16); err != nil {
+			return "", "", fmt.Errorf("invalid %s %q", field, fields[i])
+		}
+	}
+	target := fields[3]
+	if target != "." {
+		target = strings.TrimSuffix(target, ".")
+	}
+
+	return name, strings.Join(fields[:3], " ") + " " + target, nil
+}
+
+// parseSVCBEntry parses "[_port._scheme] priority target [key=value...]".
+func parseSVCBEntry(entry string) (string, string, error) {
+	name := ""
+	if strings.HasPrefix(entry, "_") {
+		fields := strings.SplitN(entry, " ", 2)
+		if len(fields) != 2 {
+			return "", "", fmt.Errorf("expected [_port._scheme] priority target [key=value...]")
+		}
+		name, entry = fields[0], fields[1]
+	}
+
+	target, err := endpoint.ParseSVCBTarget(entry)
+	if err != nil {
+		return "", "", err
+	}
+	return name, target, nil
+}
+
+// parseTLSAEntry parses "[_port._proto] usage selector matching-type certdata".
+func parseTLSAEntry(entry string) (string, string, error) {
+	name := ""
+	if strings.HasPrefix(entry, "_") {
+		fields := strings.SplitN(entry, " ", 2)
+		if len(fields) != 2 {
+			return "", "", fmt.Errorf("expected [_port._proto] usage selector matching-type certdata")
+		}
+		name, entry = fields[0], fields[1]
+		labels := strings.Split(name, ".")
+		if len(labels) != 2 || !strings.HasPrefix(labels[1], "_") {
+			return "", "", fmt.Errorf("invalid port and protocol %q, expected _port._proto", name)
+		}
+		if _, err := strconv.ParseUint(strings.TrimPrefix(labels[0], "_"), 10, 16); err != nil {
+			return "", "", fmt.Errorf("invalid port %q", labels[0])
+		}
+	}
+
+	target, err := endpoint.ParseTLSATarget(entry)
+	if err != nil {
+		return "", "", err
+	}
+	return name, target, nil
+}
+
+// parseMXEntry parses "priority exchange".
+func parseMXEntry(entry string) (string, string, error) {
+	target, err := endpoint.ParseMXTarget(entry)
+	if err != nil {
+		return "", "", err
+	}
+	return "", target, nil
+}
+
+// parseNAPTREntry parses `order preference "flags" "service" "regexp" replacement`.
+func parseNAPTREntry(entry string) (string, string, error) {
+	target, err := endpoint.ParseNAPTRTarget(entry)
+	if err != nil {
+		return "", "", err
+	}
+	return "", target, nil
+}
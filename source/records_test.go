@@ -0,0 +1,126 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+func TestRecordAnnotations(t *testing.T) {
+	for _, tc := range []struct {
+		title       string
+		annotations map[string]string
+		targets     endpoint.Targets
+		expected    []*endpoint.Endpoint
+	}{
+		{
+			title:       "no record annotation",
+			annotations: map[string]string{CloudflareProxiedKey: "true"},
+			targets:     endpoint.Targets{"192.0.2.1"},
+			expected: []*endpoint.Endpoint{
+				{DNSName: "sip.example.org", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"192.0.2.1"}, ProviderSpecific: endpoint.ProviderSpecific{{Name: CloudflareProxiedKey, Value: "true"}}},
+			},
+		},
+		{
+			title: "srv",
+			annotations: map[string]string{
+				CloudflareProxiedKey: "true",
+				srvAnnotationKey:     "_sip._udp 10 5 5060 sip.example.org., _sip._tcp 10 5 5060 sip.example.org, _sip._udp 20 5 5060 backup.example.org, 0 0 0 .",
+			},
+			targets: endpoint.Targets{"192.0.2.1"},
+			expected: []*endpoint.Endpoint{
+				{DNSName: "sip.example.org", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"192.0.2.1"}, ProviderSpecific: endpoint.ProviderSpecific{{Name: CloudflareProxiedKey, Value: "true"}}},
+				{DNSName: "_sip._udp.sip.example.org", RecordType: endpoint.RecordTypeSRV, Targets: endpoint.Targets{"10 5 5060 sip.example.org", "20 5 5060 backup.example.org"}, ProviderSpecific: endpoint.ProviderSpecific{{Name: CloudflareProxiedKey, Value: "true"}}},
+				{DNSName: "_sip._tcp.sip.example.org", RecordType: endpoint.RecordTypeSRV, Targets: endpoint.Targets{"10 5 5060 sip.example.org"}, ProviderSpecific: endpoint.ProviderSpecific{{Name: CloudflareProxiedKey, Value: "true"}}},
+				{DNSName: "sip.example.org", RecordType: endpoint.RecordTypeSRV, Targets: endpoint.Targets{"0 0 0 ."}, ProviderSpecific: endpoint.ProviderSpecific{{Name: CloudflareProxiedKey, Value: "true"}}},
+			},
+		},
+		{
+			title:       "srv without address targets",
+			annotations: map[string]string{srvAnnotationKey: "_xmpp._tcp 5 0 5222 xmpp.example.org"},
+			expected: []*endpoint.Endpoint{
+				{DNSName: "_xmpp._tcp.sip.example.org", RecordType: endpoint.RecordTypeSRV, Targets: endpoint.Targets{"5 0 5222 xmpp.example.org"}, ProviderSpecific: endpoint.ProviderSpecific{}},
+			},
+		},
+		{
+			title: "https and svcb",
+			annotations: map[string]string{
+				httpsAnnotationKey: `1 . alpn="h2,h3" port=443; 2 backup.example.org.; 0 . alpn=h2; _8443._https 1 . port=8443`,
+				svcbAnnotationKey:  "_dns 1 dns.example.org alpn=dot",
+			},
+			expected: []*endpoint.Endpoint{
+				{DNSName: "sip.example.org", RecordType: endpoint.RecordTypeHTTPS, Targets: endpoint.Targets{"1 . alpn=h2,h3 port=443", "2 backup.example.org"}, ProviderSpecific: endpoint.ProviderSpecific{}},
+				{DNSName: "_8443._https.sip.example.org", RecordType: endpoint.RecordTypeHTTPS, Targets: endpoint.Targets{"1 . port=8443"}, ProviderSpecific: endpoint.ProviderSpecific{}},
+				{DNSName: "_dns.sip.example.org", RecordType: endpoint.RecordTypeSVCB, Targets: endpoint.Targets{"1 dns.example.org alpn=dot"}, ProviderSpecific: endpoint.ProviderSpecific{}},
+			},
+		},
+		{
+			title: "tlsa",
+			annotations: map[string]string{
+				tlsaAnnotationKey: "_443._tcp 3 1 1 0D6FCE3368BF9E6E78BC3F0153B31E676E3D91E1B1BC5A3D75D5E3BC7B0C1F22, _25._tcp 2 0 1 0d6fce3368bf9e6e78bc3f0153b31e676e3d91e1b1bc5a3d75d5e3bc7b0c1f22, _https._tcp 3 1 1 0d6f, 3 1 1 0d6f",
+			},
+			expected: []*endpoint.Endpoint{
+				{DNSName: "_443._tcp.sip.example.org", RecordType: endpoint.RecordTypeTLSA, Targets: endpoint.Targets{"3 1 1 0d6fce3368bf9e6e78bc3f0153b31e676e3d91e1b1bc5a3d75d5e3bc7b0c1f22"}, ProviderSpecific: endpoint.ProviderSpecific{}},
+				{DNSName: "_25._tcp.sip.example.org", RecordType: endpoint.RecordTypeTLSA, Targets: endpoint.Targets{"2 0 1 0d6fce3368bf9e6e78bc3f0153b31e676e3d91e1b1bc5a3d75d5e3bc7b0c1f22"}, ProviderSpecific: endpoint.ProviderSpecific{}},
+			},
+		},
+		{
+			title: "mx",
+			annotations: map[string]string{
+				mxAnnotationKey: "10 mx1.example.org., 20 MX2.example.org, mx3.example.org",
+			},
+			targets: endpoint.Targets{"192.0.2.1"},
+			expected: []*endpoint.Endpoint{
+				{DNSName: "sip.example.org", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"192.0.2.1"}, ProviderSpecific: endpoint.ProviderSpecific{}},
+				{DNSName: "sip.example.org", RecordType: endpoint.RecordTypeMX, Targets: endpoint.Targets{"10 mx1.example.org", "20 mx2.example.org"}, ProviderSpecific: endpoint.ProviderSpecific{}},
+			},
+		},
+		{
+			title: "naptr",
+			annotations: map[string]string{
+				naptrAnnotationKey: `10 0 "s" "SIP+D2U" "" _sip._udp.example.org.; 20 0 "s" "SIP+D2T" "" _sip._tcp.example.org; 30 0 "s" "SIP+D2S" "!^.*$!sip:info@example.org!" _sips._tcp.example.org`,
+			},
+			expected: []*endpoint.Endpoint{
+				{DNSName: "sip.example.org", RecordType: endpoint.RecordTypeNAPTR, Targets: endpoint.Targets{`10 0 "S" "SIP+D2U" "" _sip._udp.example.org`, `20 0 "S" "SIP+D2T" "" _sip._tcp.example.org`}, ProviderSpecific: endpoint.ProviderSpecific{}},
+			},
+		},
+		{
+			title: "invalid srv entries",
+			annotations: map[string]string{
+				srvAnnotationKey: "10 5 sip.example.org, sip._udp 10 5 5060 sip.example.org, 10 5 65536 sip.example.org, 10 x 5060 sip.example.org",
+			},
+			targets: endpoint.Targets{"lb.example.org"},
+			expected: []*endpoint.Endpoint{
+				{DNSName: "sip.example.org", RecordType: endpoint.RecordTypeCNAME, Targets: endpoint.Targets{"lb.example.org"}, ProviderSpecific: endpoint.ProviderSpecific{}},
+			},
+		},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			providerSpecific, setIdentifier := getProviderSpecificAnnotations(tc.annotations)
+			endpoints := endpointsForHostname("sip.example.org.", tc.targets, 0, providerSpecific, setIdentifier)
+
+			validateEndpoints(t, endpoints, tc.expected)
+			for i := range endpoints {
+				assert.Equal(t, tc.expected[i].ProviderSpecific, endpoints[i].ProviderSpecific)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/endpoint/validation"
+)
+
+// remoteSource is an implementation of Source that provides endpoints by polling
+// an HTTP(S) URL which returns a JSON encoded list of endpoints.
+type remoteSource struct {
+	url        string
+	authHeader string
+	client     *http.Client
+}
+
+// NewRemoteSource creates a new remoteSource polling the given URL. If authHeader is
+// non-empty it is sent as the value of the Authorization header on every request.
+func NewRemoteSource(remoteURL, authHeader string, timeout time.Duration) (Source, error) {
+	u, err := url.Parse(remoteURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid remote source url %q: %w", remoteURL, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid remote source url %q: scheme must be http or https", remoteURL)
+	}
+
+	return &remoteSource{
+		url:        remoteURL,
+		authHeader: authHeader,
+		client:     &http.Client{Timeout: timeout},
+	}, nil
+}
+
+// Endpoints returns endpoint objects.
+func (rs *remoteSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rs.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if rs.authHeader != "" {
+		req.Header.Set("Authorization", rs.authHeader)
+	}
+
+	resp, err := rs.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch endpoints from %s: %w", rs.url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch endpoints from %s: unexpected status %s", rs.url, resp.Status)
+	}
+
+	var remoteEndpoints []*endpoint.Endpoint
+	if err := json.NewDecoder(resp.Body).Decode(&remoteEndpoints); err != nil {
+		return nil, fmt.Errorf("failed to decode endpoints from %s: %w", rs.url, err)
+	}
+
+	endpoints := []*endpoint.Endpoint{}
+	for _, ep := range remoteEndpoints {
+		if err := validateRemoteEndpoint(ep); err != nil {
+			log.Warnf("Skipping endpoint received from %s: %v", rs.url, err)
+			continue
+		}
+		if ep.Labels == nil {
+			ep.Labels = endpoint.NewLabels()
+		}
+		if ep.Labels[endpoint.ResourceLabelKey] == "" {
+			ep.Labels[endpoint.ResourceLabelKey] = fmt.Sprintf("remote/%s", ep.DNSName)
+		}
+		endpoints = append(endpoints, ep)
+	}
+
+	log.Debugf("Received endpoints from %s: %#v", rs.url, endpoints)
+
+	return endpoints, nil
+}
+
+func (rs *remoteSource) AddEventHandler(ctx context.Context, handler func()) {
+}
+
+// validateRemoteEndpoint checks that an endpoint received from a remote server has a supported
+// record type and the fields required to be planned, with targets valid for its record type.
+func validateRemoteEndpoint(ep *endpoint.Endpoint) error {
+	if ep == nil {
+		return fmt.Errorf("endpoint is null")
+	}
+
+	switch ep.RecordType {
+	case endpoint.RecordTypeA, "AAAA", endpoint.RecordTypeCNAME, endpoint.RecordTypeTXT, endpoint.RecordTypeSRV, endpoint.RecordTypeNS, endpoint.RecordTypePTR,
+		endpoint.RecordTypeMX, endpoint.RecordTypeNAPTR, endpoint.RecordTypeSVCB, endpoint.RecordTypeHTTPS, endpoint.RecordTypeTLSA:
+	default:
+		return fmt.Errorf("endpoint %s has an unsupported record type %q", ep.DNSName, ep.RecordType)
+	}
+
+	return validation.ValidateEndpoint(ep)
+}
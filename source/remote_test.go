@@ -0,0 +1,211 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+func TestRemoteSource(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Interface", testRemoteSourceImplementsSource)
+	t.Run("NewRemoteSource", testRemoteSourceNewRemoteSource)
+	t.Run("Endpoints", testRemoteSourceEndpoints)
+	t.Run("ValidateRemoteEndpoint", testValidateRemoteEndpoint)
+}
+
+// testRemoteSourceImplementsSource tests that remoteSource is a valid Source.
+func testRemoteSourceImplementsSource(t *testing.T) {
+	assert.Implements(t, (*Source)(nil), new(remoteSource))
+}
+
+// testRemoteSourceNewRemoteSource tests that NewRemoteSource validates the given URL.
+func testRemoteSourceNewRemoteSource(t *testing.T) {
+	for _, ti := range []struct {
+		title       string
+		url         string
+		expectError bool
+	}{
+		{title: "https url", url: "https://inventory.example.org/endpoints"},
+		{title: "http url", url: "http://localhost:8080/endpoints"},
+		{title: "empty url", url: "", expectError: true},
+		{title: "unsupported scheme", url: "ftp://inventory.example.org/endpoints", expectError: true},
+		{title: "unparsable url", url: "http://[::1", expectError: true},
+	} {
+		ti := ti
+		t.Run(ti.title, func(t *testing.T) {
+			_, err := NewRemoteSource(ti.url, "", time.Second)
+			if ti.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+// testRemoteSourceEndpoints tests that endpoints served by the remote URL are decoded and validated.
+func testRemoteSourceEndpoints(t *testing.T) {
+	for _, ti := range []struct {
+		title       string
+		authHeader  string
+		status      int
+		body        string
+		expected    []*endpoint.Endpoint
+		expectError bool
+	}{
+		{
+			title:    "no endpoints",
+			status:   http.StatusOK,
+			body:     `[]`,
+			expected: []*endpoint.Endpoint{},
+		},
+		{
+			title:  "valid endpoints",
+			status: http.StatusOK,
+			body: `[
+				{"dnsName": "abc.example.org", "targets": ["1.2.3.4"], "recordType": "A", "recordTTL": 180},
+				{"dnsName": "xyz.example.org", "targets": ["abc.example.org"], "recordType": "CNAME"},
+				{"dnsName": "v6.example.org", "targets": ["fd00::2"], "recordType": "AAAA"}
+			]`,
+			expected: []*endpoint.Endpoint{
+				{DNSName: "abc.example.org", Targets: endpoint.Targets{"1.2.3.4"}, RecordType: endpoint.RecordTypeA, RecordTTL: 180},
+				{DNSName: "xyz.example.org", Targets: endpoint.Targets{"abc.example.org"}, RecordType: endpoint.RecordTypeCNAME},
+				{DNSName: "v6.example.org", Targets: endpoint.Targets{"fd00::2"}, RecordType: "AAAA"},
+			},
+		},
+		{
+			title:  "resource label of the server is kept",
+			status: http.StatusOK,
+			body:   `[{"dnsName": "abc.example.org", "targets": ["1.2.3.4"], "recordType": "A", "labels": {"resource": "inventory/host-1"}}]`,
+			expected: []*endpoint.Endpoint{
+				{DNSName: "abc.example.org", Targets: endpoint.Targets{"1.2.3.4"}, RecordType: endpoint.RecordTypeA, Labels: endpoint.Labels{endpoint.ResourceLabelKey: "inventory/host-1"}},
+			},
+		},
+		{
+			title:      "auth header is sent",
+			authHeader: "Bearer secret",
+			status:     http.StatusOK,
+			body:       `[{"dnsName": "abc.example.org", "targets": ["1.2.3.4"], "recordType": "A"}]`,
+			expected: []*endpoint.Endpoint{
+				{DNSName: "abc.example.org", Targets: endpoint.Targets{"1.2.3.4"}, RecordType: endpoint.RecordTypeA},
+			},
+		},
+		{
+			title:  "invalid endpoints are skipped",
+			status: http.StatusOK,
+			body: `[
+				{"dnsName": "", "targets": ["1.2.3.4"], "recordType": "A"},
+				{"dnsName": "no-targets.example.org", "recordType": "A"},
+				{"dnsName": "bad-ip.example.org", "targets": ["abc.example.org"], "recordType": "A"},
+				{"dnsName": "bad-ipv4.example.org", "targets": ["fd00::2"], "recordType": "A"},
+				{"dnsName": "bad-ipv6.example.org", "targets": ["1.2.3.4"], "recordType": "AAAA"},
+				{"dnsName": "bad-type.example.org", "targets": ["1.2.3.4"], "recordType": "FOO"},
+				{"dnsName": "bad-ttl.example.org", "targets": ["1.2.3.4"], "recordType": "A", "recordTTL": -1},
+				null,
+				{"dnsName": "good.example.org", "targets": ["1.2.3.4"], "recordType": "A"}
+			]`,
+			expected: []*endpoint.Endpoint{
+				{DNSName: "good.example.org", Targets: endpoint.Targets{"1.2.3.4"}, RecordType: endpoint.RecordTypeA},
+			},
+		},
+		{
+			title:       "unexpected status",
+			status:      http.StatusInternalServerError,
+			body:        `[]`,
+			expectError: true,
+		},
+		{
+			title:       "malformed body",
+			status:      http.StatusOK,
+			body:        `{"dnsName": "abc.example.org"}`,
+			expectError: true,
+		},
+	} {
+		ti := ti
+		t.Run(ti.title, func(t *testing.T) {
+			t.Parallel()
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if ti.authHeader != "" && r.Header.Get("Authorization") != ti.authHeader {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				w.WriteHeader(ti.status)
+				w.Write([]byte(ti.body))
+			}))
+			defer server.Close()
+
+			rs, err := NewRemoteSource(server.URL, ti.authHeader, time.Second)
+			require.NoError(t, err)
+
+			endpoints, err := rs.Endpoints(context.Background())
+			if ti.expectError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			validateEndpoints(t, endpoints, ti.expected)
+			for i, ep := range endpoints {
+				if _, ok := ti.expected[i].Labels[endpoint.ResourceLabelKey]; !ok {
+					assert.Equal(t, "remote/"+ep.DNSName, ep.Labels[endpoint.ResourceLabelKey])
+				}
+			}
+		})
+	}
+}
+
+// testValidateRemoteEndpoint tests that the targets of each supported record type are validated.
+func testValidateRemoteEndpoint(t *testing.T) {
+	for _, ti := range []struct {
+		recordType string
+		valid      string
+		invalid    string
+	}{
+		{recordType: endpoint.RecordTypeA, valid: "192.0.2.1", invalid: "fd00::2"},
+		{recordType: "AAAA", valid: "fd00::2", invalid: "192.0.2.1"},
+		{recordType: endpoint.RecordTypeCNAME, valid: "lb.example.org", invalid: "lb..example.org"},
+		{recordType: endpoint.RecordTypeTXT, valid: "v=spf1 -all", invalid: " "},
+		{recordType: endpoint.RecordTypeSRV, valid: "10 5 5060 sip.example.org", invalid: "10 5 sip.example.org"},
+		{recordType: endpoint.RecordTypeNS, valid: "ns1.example.org", invalid: "ns1..example.org"},
+		{recordType: endpoint.RecordTypePTR, valid: "host.example.org", invalid: "host..example.org"},
+		{recordType: endpoint.RecordTypeMX, valid: "10 mx1.example.org", invalid: "mx1.example.org"},
+		{recordType: endpoint.RecordTypeNAPTR, valid: `10 0 "s" "SIP+D2U" "" _sip._udp.example.org`, invalid: `10 0 "s" "SIP+D2U" ""`},
+		{recordType: endpoint.RecordTypeSVCB, valid: "1 svc.example.org port=443", invalid: "0 . alpn=h2"},
+		{recordType: endpoint.RecordTypeHTTPS, valid: `1 . alpn="h2,h3"`, invalid: "1 . port=https"},
+		{recordType: endpoint.RecordTypeTLSA, valid: "2 0 0 30820122300d06092a864886f70d01010105", invalid: "3 1 1 xyz"},
+	} {
+		ti := ti
+		t.Run(ti.recordType, func(t *testing.T) {
+			assert.NoError(t, validateRemoteEndpoint(endpoint.NewEndpoint("www.example.org", ti.recordType, ti.valid)))
+			assert.Error(t, validateRemoteEndpoint(endpoint.NewEndpoint("www.example.org", ti.recordType, ti.invalid)))
+		})
+	}
+
+	assert.Error(t, validateRemoteEndpoint(endpoint.NewEndpoint("www.example.org", "FOO", "192.0.2.1")))
+}
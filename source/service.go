@@ -160,7 +160,7 @@ func (sc *serviceSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, e
 
 	for _, svc := range services {
 		// Check controller annotation to see if we are responsible.
-		controller, ok := svc.Annotations[controllerAnnotationKey]
+		controller, ok := lookupAnnotation(svc.Annotations, controllerAnnotationKey)
 		if ok && controller != controllerAnnotationValue {
 			log.Debugf("Skipping service %s/%s because controller value does not match, found: %s, required: %s",
 				svc.Namespace, svc.Name, controller, controllerAnnotationValue)
@@ -508,11 +508,12 @@ func (sc *serviceSource) generateEndpoints(svc *v1.Service, hostname string, pro
 	if len(epCNAME.Targets) > 0 {
 		endpoints = append(endpoints, epCNAME)
 	}
+	recordEndpoints, providerSpecific := endpointsForRecordAnnotations(hostname, ttl, providerSpecific, setIdentifier)
 	for _, endpoint := range endpoints {
 		endpoint.ProviderSpecific = providerSpecific
 		endpoint.SetIdentifier = setIdentifier
 	}
-	return endpoints
+	return append(endpoints, recordEndpoints...)
 }
 
 func extractServiceIps(svc *v1.Service) endpoint.Targets {
@@ -251,7 +251,7 @@ func (sc *routeGroupSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint
 	endpoints := []*endpoint.Endpoint{}
 	for _, rg := range rgList.Items {
 		// Check controller annotation to see if we are responsible.
-		controller, ok := rg.Metadata.Annotations[controllerAnnotationKey]
+		controller, ok := lookupAnnotation(rg.Metadata.Annotations, controllerAnnotationKey)
 		if ok && controller != controllerAnnotationValue {
 			log.Debugf("Skipping routegroup %s/%s because controller value does not match, found: %s, required: %s",
 				rg.Metadata.Namespace, rg.Metadata.Name, controller, controllerAnnotationValue)
@@ -70,8 +70,19 @@ const (
 const (
 	// The annotation used for determining if traffic will go through Cloudflare
 	CloudflareProxiedKey = "external-dns.alpha.kubernetes.io/cloudflare-proxied"
+	// The annotation used for setting the comment of the records in Cloudflare
+	CloudflareCommentKey = "external-dns.alpha.kubernetes.io/cloudflare-comment"
 
 	SetIdentifierKey = "external-dns.alpha.kubernetes.io/set-identifier"
+
+	// The annotation used for routing records to the internal or external provider, or both
+	SplitHorizonKey = "external-dns.alpha.kubernetes.io/split-horizon"
+	// The annotation used for defining the weight of each target, as a comma separated list of
+	// target=weight entries, when distributing the targets into weighted record sets
+	TargetWeightsKey = "external-dns.alpha.kubernetes.io/target-weights"
+	// The annotation used for pinning the owner id the records are registered with, instead of the
+	// owner id of the instance creating them, e.g. to migrate records to another instance
+	OwnerKey = "external-dns.alpha.kubernetes.io/owner"
 )
 
 const (
@@ -88,7 +99,7 @@ type Source interface {
 
 func getTTLFromAnnotations(annotations map[string]string) (endpoint.TTL, error) {
 	ttlNotConfigured := endpoint.TTL(0)
-	ttlAnnotation, exists := annotations[ttlAnnotationKey]
+	ttlAnnotation, exists := lookupAnnotation(annotations, ttlAnnotationKey)
 	if !exists {
 		return ttlNotConfigured, nil
 	}
@@ -122,18 +133,31 @@ type kubeObject interface {
 	metav1.Object
 }
 
-func execTemplate(tmpl *template.Template, obj kubeObject) (hostnames []string, err error) {
+// execTemplate returns the comma separated hostnames compiled from obj via the FQDN template.
+// The errors are described by the kind, namespace and name of Kubernetes objects, other callers
+// add the description of obj to the error.
+func execTemplate(tmpl *template.Template, obj interface{}) (hostnames []string, err error) {
 	var buf bytes.Buffer
 	if err := tmpl.Execute(&buf, obj); err != nil {
-		kind := obj.GetObjectKind().GroupVersionKind().Kind
-		return nil, fmt.Errorf("failed to apply template on %s %s/%s: %w", kind, obj.GetNamespace(), obj.GetName(), err)
+		if obj, ok := obj.(kubeObject); ok {
+			kind := obj.GetObjectKind().GroupVersionKind().Kind
+			return nil, fmt.Errorf("failed to apply template on %s %s/%s: %w", kind, obj.GetNamespace(), obj.GetName(), err)
+		}
+		return nil, fmt.Errorf("failed to apply template: %w", err)
 	}
-	for _, name := range strings.Split(buf.String(), ",") {
+	return splitHostnames(buf.String()), nil
+}
+
+// splitHostnames returns the hostnames of a comma separated list, without the empty ones.
+func splitHostnames(value string) (hostnames []string) {
+	for _, name := range strings.Split(value, ",") {
 		name = strings.TrimFunc(name, unicode.IsSpace)
 		name = strings.TrimSuffix(name, ".")
-		hostnames = append(hostnames, name)
+		if name != "" {
+			hostnames = append(hostnames, name)
+		}
 	}
-	return hostnames, nil
+	return hostnames
 }
 
 func parseTemplate(fqdnTemplate string) (tmpl *template.Template, err error) {
@@ -147,7 +171,7 @@ func parseTemplate(fqdnTemplate string) (tmpl *template.Template, err error) {
 }
 
 func getHostnamesFromAnnotations(annotations map[string]string) []string {
-	hostnameAnnotation, exists := annotations[hostnameAnnotationKey]
+	hostnameAnnotation, exists := lookupAnnotation(annotations, hostnameAnnotationKey)
 	if !exists {
 		return nil
 	}
@@ -155,15 +179,17 @@ func getHostnamesFromAnnotations(annotations map[string]string) []string {
 }
 
 func getAccessFromAnnotations(annotations map[string]string) string {
-	return annotations[accessAnnotationKey]
+	access, _ := lookupAnnotation(annotations, accessAnnotationKey)
+	return access
 }
 
 func getEndpointsTypeFromAnnotations(annotations map[string]string) string {
-	return annotations[endpointsTypeAnnotationKey]
+	endpointsType, _ := lookupAnnotation(annotations, endpointsTypeAnnotationKey)
+	return endpointsType
 }
 
 func getInternalHostnamesFromAnnotations(annotations map[string]string) []string {
-	internalHostnameAnnotation, exists := annotations[internalHostnameAnnotationKey]
+	internalHostnameAnnotation, exists := lookupAnnotation(annotations, internalHostnameAnnotationKey)
 	if !exists {
 		return nil
 	}
@@ -171,12 +197,13 @@ func getInternalHostnamesFromAnnotations(annotations map[string]string) []string
 }
 
 func getAliasFromAnnotations(annotations map[string]string) bool {
-	aliasAnnotation, exists := annotations[aliasAnnotationKey]
+	aliasAnnotation, exists := lookupAnnotation(annotations, aliasAnnotationKey)
 	return exists && aliasAnnotation == "true"
 }
 
 func getProviderSpecificAnnotations(annotations map[string]string) (endpoint.ProviderSpecific, string) {
 	providerSpecificAnnotations := endpoint.ProviderSpecific{}
+	annotations = aliasedAnnotations(annotations)
 
 	v, exists := annotations[CloudflareProxiedKey]
 	if exists {
@@ -185,12 +212,37 @@ func getProviderSpecificAnnotations(annotations map[string]string) (endpoint.Pro
 			Value: v,
 		})
 	}
+	if v, exists := annotations[CloudflareCommentKey]; exists {
+		providerSpecificAnnotations = append(providerSpecificAnnotations, endpoint.ProviderSpecificProperty{
+			Name:  CloudflareCommentKey,
+			Value: v,
+		})
+	}
+	if v, exists := annotations[SplitHorizonKey]; exists {
+		providerSpecificAnnotations = append(providerSpecificAnnotations, endpoint.ProviderSpecificProperty{
+			Name:  SplitHorizonKey,
+			Value: v,
+		})
+	}
+	if v, exists := annotations[TargetWeightsKey]; exists {
+		providerSpecificAnnotations = append(providerSpecificAnnotations, endpoint.ProviderSpecificProperty{
+			Name:  TargetWeightsKey,
+			Value: v,
+		})
+	}
+	if v, exists := annotations[OwnerKey]; exists {
+		providerSpecificAnnotations = append(providerSpecificAnnotations, endpoint.ProviderSpecificProperty{
+			Name:  OwnerKey,
+			Value: v,
+		})
+	}
 	if getAliasFromAnnotations(annotations) {
 		providerSpecificAnnotations = append(providerSpecificAnnotations, endpoint.ProviderSpecificProperty{
 			Name:  "alias",
 			Value: "true",
 		})
 	}
+	providerSpecificAnnotations = append(providerSpecificAnnotations, getRecordAnnotations(annotations)...)
 	setIdentifier := ""
 	for k, v := range annotations {
 		if k == SetIdentifierKey {
@@ -207,6 +259,12 @@ func getProviderSpecificAnnotations(annotations map[string]string) (endpoint.Pro
 				Name:  fmt.Sprintf("scw/%s", attr),
 				Value: v,
 			})
+		} else if strings.HasPrefix(k, "external-dns.alpha.kubernetes.io/ns1-") {
+			attr := strings.TrimPrefix(k, "external-dns.alpha.kubernetes.io/ns1-")
+			providerSpecificAnnotations = append(providerSpecificAnnotations, endpoint.ProviderSpecificProperty{
+				Name:  fmt.Sprintf("ns1/%s", attr),
+				Value: v,
+			})
 		} else if strings.HasPrefix(k, "external-dns.alpha.kubernetes.io/ibmcloud-") {
 			attr := strings.TrimPrefix(k, "external-dns.alpha.kubernetes.io/ibmcloud-")
 			providerSpecificAnnotations = append(providerSpecificAnnotations, endpoint.ProviderSpecificProperty{
@@ -224,7 +282,7 @@ func getTargetsFromTargetAnnotation(annotations map[string]string) endpoint.Targ
 	var targets endpoint.Targets
 
 	// Get the desired hostname of the ingress from the annotation.
-	targetAnnotation, exists := annotations[targetAnnotationKey]
+	targetAnnotation, exists := lookupAnnotation(annotations, targetAnnotationKey)
 	if exists && targetAnnotation != "" {
 		// splits the hostname annotation and removes the trailing periods
 		targetsList := strings.Split(strings.Replace(targetAnnotation, " ", "", -1), ",")
@@ -247,7 +305,7 @@ func suitableType(target string) string {
 
 // endpointsForHostname returns the endpoint objects for each host-target combination.
 func endpointsForHostname(hostname string, targets endpoint.Targets, ttl endpoint.TTL, providerSpecific endpoint.ProviderSpecific, setIdentifier string) []*endpoint.Endpoint {
-	var endpoints []*endpoint.Endpoint
+	endpoints, providerSpecific := endpointsForRecordAnnotations(hostname, ttl, providerSpecific, setIdentifier)
 
 	var aTargets endpoint.Targets
 	var cnameTargets endpoint.Targets
@@ -58,6 +58,46 @@ type Config struct {
 	PublishHostIP                  bool
 	AlwaysPublishNotReadyAddresses bool
 	ConnectorServer                string
+	RemoteSourceURL                string
+	RemoteSourceAuthHeader         string
+	PluginSourceURL                string
+	HostSourceDomain               string
+	HostSourceInterfaces           []string
+	HostSourceUnitDir              string
+	LibvirtSocket                  string
+	LibvirtURI                     string
+	LibvirtAddressSource           string
+	ProxmoxURL                     string
+	ProxmoxToken                   string
+	ProxmoxInsecure                bool
+	ProxmoxNodes                   []string
+	ProxmoxPools                   []string
+	ProxmoxTagPrefix               string
+	NetBoxURL                      string
+	NetBoxToken                    string
+	NetBoxTags                     []string
+	NetBoxCustomFields             []string
+	NetBoxCreatePTR                bool
+	MQTTBroker                     string
+	MQTTTopic                      string
+	MQTTClientID                   string
+	MQTTUsername                   string
+	MQTTPassword                   string
+	PrometheusSDURL                string
+	PrometheusSDAuthHeader         string
+	PrometheusSDHostnameLabel      string
+	PrometheusSDTTLLabel           string
+	ComposeFiles                   []string
+	ComposeDefaultTargets          []string
+	PodmanSocket                   string
+	PodmanDefaultTargets           []string
+	ContainerdNerdctl              string
+	ContainerdAddress              string
+	ContainerdNamespaces           []string
+	ContainerdDefaultTargets       []string
+	DNSEndpointPaths               []string
+	SourceDomainFilters            []string
+	SourceExcludeDomains           []string
 	CRDSourceAPIVersion            string
 	CRDSourceKind                  string
 	KubeConfig                     string
@@ -194,6 +234,9 @@ func ByNames(ctx context.Context, p ClientGenerator, names []string, cfg *Config
 		if err != nil {
 			return nil, err
 		}
+		if domainFilter, ok := sourceDomainFilter(name, cfg.SourceDomainFilters, cfg.SourceExcludeDomains); ok {
+			source = NewDomainFilterSource(source, domainFilter)
+		}
 		sources = append(sources, source)
 	}
 
@@ -297,6 +340,34 @@ func BuildWithConfig(ctx context.Context, source string, p ClientGenerator, cfg
 		return NewFakeSource(cfg.FQDNTemplate)
 	case "connector":
 		return NewConnectorSource(cfg.ConnectorServer)
+	case "remote":
+		return NewRemoteSource(cfg.RemoteSourceURL, cfg.RemoteSourceAuthHeader, cfg.RequestTimeout)
+	case "plugin":
+		return NewPluginSource(cfg.PluginSourceURL, cfg.RequestTimeout)
+	case "host":
+		return NewHostSource(cfg.HostSourceDomain, cfg.HostSourceInterfaces, cfg.HostSourceUnitDir)
+	case "libvirt":
+		client, err := NewLibvirtClient(cfg.LibvirtSocket, cfg.LibvirtURI, cfg.RequestTimeout)
+		if err != nil {
+			return nil, err
+		}
+		return NewLibvirtSource(client, cfg.LibvirtURI, cfg.LibvirtAddressSource, cfg.FQDNTemplate)
+	case "proxmox":
+		return NewProxmoxSource(cfg.ProxmoxURL, cfg.ProxmoxToken, cfg.ProxmoxInsecure, cfg.ProxmoxNodes, cfg.ProxmoxPools, cfg.ProxmoxTagPrefix, cfg.FQDNTemplate, cfg.RequestTimeout)
+	case "netbox":
+		return NewNetBoxSource(cfg.NetBoxURL, cfg.NetBoxToken, cfg.NetBoxTags, cfg.NetBoxCustomFields, cfg.NetBoxCreatePTR, cfg.FQDNTemplate, cfg.RequestTimeout)
+	case "mqtt":
+		return NewMQTTSource(ctx, cfg.MQTTBroker, cfg.MQTTTopic, cfg.MQTTClientID, cfg.MQTTUsername, cfg.MQTTPassword, cfg.RequestTimeout)
+	case "prometheus-sd":
+		return NewPrometheusSDSource(cfg.PrometheusSDURL, cfg.PrometheusSDAuthHeader, cfg.PrometheusSDHostnameLabel, cfg.PrometheusSDTTLLabel, cfg.FQDNTemplate, cfg.RequestTimeout)
+	case "compose":
+		return NewComposeSource(cfg.ComposeFiles, cfg.ComposeDefaultTargets, cfg.AnnotationFilter)
+	case "podman":
+		return NewPodmanSource(cfg.PodmanSocket, cfg.PodmanDefaultTargets, cfg.AnnotationFilter, cfg.RequestTimeout)
+	case "containerd":
+		return NewContainerdSource(cfg.ContainerdNerdctl, cfg.ContainerdAddress, cfg.ContainerdNamespaces, cfg.ContainerdDefaultTargets, cfg.AnnotationFilter)
+	case "dnsendpoint-file":
+		return NewDNSEndpointFileSource(cfg.DNSEndpointPaths, cfg.CRDSourceAPIVersion, cfg.CRDSourceKind, cfg.Namespace, cfg.AnnotationFilter, cfg.LabelFilter)
 	case "crd":
 		client, err := p.KubeClient()
 		if err != nil {
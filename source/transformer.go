@@ -0,0 +1,394 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v2"
+
+	"sigs.k8s.io/external-dns/endpoint"
+)
+
+// Types of the transformer steps.
+const (
+	TransformerDedup         = "dedup"
+	TransformerLowercase     = "lowercase"
+	TransformerSuffix        = "suffix"
+	TransformerRewriteTarget = "rewrite-target"
+	TransformerCoerceType    = "coerce-type"
+	TransformerNAT           = "nat"
+)
+
+// privateNetworks are the RFC1918 networks rewritten by the nat step without explicit mappings.
+var privateNetworks = []string{"10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16"}
+
+// EndpointTransformer modifies the endpoints returned by the sources before they are planned.
+type EndpointTransformer interface {
+	Transform(endpoints []*endpoint.Endpoint) ([]*endpoint.Endpoint, error)
+}
+
+// TransformerChain is an EndpointTransformer applying its transformers in order.
+type TransformerChain []EndpointTransformer
+
+// Transform applies all transformers of the chain.
+func (c TransformerChain) Transform(endpoints []*endpoint.Endpoint) ([]*endpoint.Endpoint, error) {
+	var err error
+	for _, t := range c {
+		endpoints, err = t.Transform(endpoints)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return endpoints, nil
+}
+
+// TransformerConfig configures a transformer step, as read from the transformer config file.
+type TransformerConfig struct {
+	// Type is the type of the step, e.g. suffix.
+	Type string `yaml:"type"`
+	// Suffix is the domain appended by the suffix step.
+	Suffix string `yaml:"suffix,omitempty"`
+	// From is the target rewritten by the rewrite-target step or the record type
+	// changed by the coerce-type step.
+	From string `yaml:"from,omitempty"`
+	// To is the replacement of From. For the nat step it is the public address of all
+	// RFC1918 targets not matching any of the mappings.
+	To string `yaml:"to,omitempty"`
+	// Mappings are the public addresses of the private subnets rewritten by the nat step.
+	Mappings []NATMapping `yaml:"mappings,omitempty"`
+	// KeepInternal makes the nat step emit the endpoints with their original targets in
+	// addition to the rewritten ones, labeled for the internal and external views. The internal
+	// endpoints are dropped when no internal provider is configured.
+	KeepInternal bool `yaml:"keepInternal,omitempty"`
+}
+
+// NATMapping maps the addresses of a subnet to a public address.
+type NATMapping struct {
+	CIDR   string `yaml:"cidr"`
+	Target string `yaml:"target"`
+}
+
+// ParseTransformerConfig parses a transformer step given on the command line, in the
+// form <type>[=<argument>], e.g. dedup, suffix=example.org, rewrite-target=10.0.0.1=203.0.113.1,
+// coerce-type=CNAME=A, nat=203.0.113.1 or nat=10.1.0.0/16=203.0.113.1.
+func ParseTransformerConfig(spec string) (TransformerConfig, error) {
+	transformerType, arg, _ := strings.Cut(spec, "=")
+	cfg := TransformerConfig{Type: transformerType}
+
+	switch transformerType {
+	case TransformerSuffix:
+		cfg.Suffix = arg
+	case TransformerRewriteTarget, TransformerCoerceType:
+		from, to, found := strings.Cut(arg, "=")
+		if !found {
+			return cfg, fmt.Errorf("invalid transformer %q: expected %s=<from>=<to>", spec, transformerType)
+		}
+		cfg.From, cfg.To = from, to
+	case TransformerNAT:
+		if cidr, target, found := strings.Cut(arg, "="); found {
+			cfg.Mappings = []NATMapping{{CIDR: cidr, Target: target}}
+		} else {
+			cfg.To = arg
+		}
+	default:
+		if arg != "" {
+			return cfg, fmt.Errorf("invalid transformer %q: %s takes no argument", spec, transformerType)
+		}
+	}
+	return cfg, nil
+}
+
+// LoadTransformerConfigs reads the list of transformer steps from a YAML file.
+func LoadTransformerConfigs(file string) ([]TransformerConfig, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read transformer config: %w", err)
+	}
+
+	var configs []TransformerConfig
+	if err := yaml.UnmarshalStrict(data, &configs); err != nil {
+		return nil, fmt.Errorf("failed to parse transformer config %s: %w", file, err)
+	}
+	return configs, nil
+}
+
+// NewTransformer creates the transformer configured by cfg.
+func NewTransformer(cfg TransformerConfig) (EndpointTransformer, error) {
+	switch cfg.Type {
+	case TransformerDedup:
+		return dedupTransformer{}, nil
+	case TransformerLowercase:
+		return lowercaseTransformer{}, nil
+	case TransformerSuffix:
+		suffix := strings.Trim(cfg.Suffix, ".")
+		if suffix == "" {
+			return nil, fmt.Errorf("transformer %s requires a suffix", cfg.Type)
+		}
+		return suffixTransformer{suffix: suffix}, nil
+	case TransformerRewriteTarget:
+		if cfg.From == "" || cfg.To == "" {
+			return nil, fmt.Errorf("transformer %s requires a from and to target", cfg.Type)
+		}
+		return rewriteTargetTransformer{from: cfg.From, to: cfg.To}, nil
+	case TransformerCoerceType:
+		if cfg.From == "" || cfg.To == "" {
+			return nil, fmt.Errorf("transformer %s requires a from and to record type", cfg.Type)
+		}
+		return coerceTypeTransformer{from: strings.ToUpper(cfg.From), to: strings.ToUpper(cfg.To)}, nil
+	case TransformerNAT:
+		return newNATTransformer(cfg)
+	default:
+		return nil, fmt.Errorf("unknown transformer %q", cfg.Type)
+	}
+}
+
+// BuildTransformers creates the transformer chain of the steps read from the config file,
+// if any, followed by the steps given on the command line.
+func BuildTransformers(specs []string, configFile string) (TransformerChain, error) {
+	var configs []TransformerConfig
+	if configFile != "" {
+		fileConfigs, err := LoadTransformerConfigs(configFile)
+		if err != nil {
+			return nil, err
+		}
+		configs = append(configs, fileConfigs...)
+	}
+	for _, spec := range specs {
+		cfg, err := ParseTransformerConfig(spec)
+		if err != nil {
+			return nil, err
+		}
+		configs = append(configs, cfg)
+	}
+
+	chain := TransformerChain{}
+	for _, cfg := range configs {
+		t, err := NewTransformer(cfg)
+		if err != nil {
+			return nil, err
+		}
+		chain = append(chain, t)
+	}
+	return chain, nil
+}
+
+// transformerSource is a Source that applies an EndpointTransformer to the endpoints of its wrapped source.
+type transformerSource struct {
+	source      Source
+	transformer EndpointTransformer
+}
+
+// NewTransformerSource creates a new transformerSource wrapping the provided Source.
+func NewTransformerSource(source Source, transformer EndpointTransformer) Source {
+	return &transformerSource{source: source, transformer: transformer}
+}
+
+// Endpoints collects endpoints from its wrapped source and returns them transformed.
+func (ts *transformerSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	endpoints, err := ts.source.Endpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return ts.transformer.Transform(endpoints)
+}
+
+func (ts *transformerSource) AddEventHandler(ctx context.Context, handler func()) {
+	ts.source.AddEventHandler(ctx, handler)
+}
+
+// dedupTransformer removes duplicate endpoints, e.g. introduced by previous steps.
+type dedupTransformer struct{}
+
+func (dedupTransformer) Transform(endpoints []*endpoint.Endpoint) ([]*endpoint.Endpoint, error) {
+	result := []*endpoint.Endpoint{}
+	collected := map[string]bool{}
+
+	for _, ep := range endpoints {
+		identifier := ep.DNSName + " / " + ep.RecordType + " / " + ep.SetIdentifier + " / " + ep.Targets.String()
+		if collected[identifier] {
+			log.Debugf("Removing duplicate endpoint %s", ep)
+			continue
+		}
+		collected[identifier] = true
+		result = append(result, ep)
+	}
+	return result, nil
+}
+
+// lowercaseTransformer lowercases the DNS names and CNAME targets.
+type lowercaseTransformer struct{}
+
+func (lowercaseTransformer) Transform(endpoints []*endpoint.Endpoint) ([]*endpoint.Endpoint, error) {
+	for _, ep := range endpoints {
+		ep.DNSName = strings.ToLower(ep.DNSName)
+		if ep.RecordType == endpoint.RecordTypeCNAME {
+			for i, target := range ep.Targets {
+				ep.Targets[i] = strings.ToLower(target)
+			}
+		}
+	}
+	return endpoints, nil
+}
+
+// suffixTransformer appends a domain to the DNS names not already ending with it.
+type suffixTransformer struct {
+	suffix string
+}
+
+func (t suffixTransformer) Transform(endpoints []*endpoint.Endpoint) ([]*endpoint.Endpoint, error) {
+	for _, ep := range endpoints {
+		name := strings.TrimSuffix(ep.DNSName, ".")
+		if strings.EqualFold(name, t.suffix) || strings.HasSuffix(strings.ToLower(name), "."+strings.ToLower(t.suffix)) {
+			continue
+		}
+		ep.DNSName = name + "." + t.suffix
+	}
+	return endpoints, nil
+}
+
+// rewriteTargetTransformer replaces a target by another one.
+type rewriteTargetTransformer struct {
+	from string
+	to   string
+}
+
+func (t rewriteTargetTransformer) Transform(endpoints []*endpoint.Endpoint) ([]*endpoint.Endpoint, error) {
+	for _, ep := range endpoints {
+		for i, target := range ep.Targets {
+			if target == t.from {
+				ep.Targets[i] = t.to
+			}
+		}
+	}
+	return endpoints, nil
+}
+
+// coerceTypeTransformer changes the record type of the endpoints of a type.
+type coerceTypeTransformer struct {
+	from string
+	to   string
+}
+
+func (t coerceTypeTransformer) Transform(endpoints []*endpoint.Endpoint) ([]*endpoint.Endpoint, error) {
+	for _, ep := range endpoints {
+		if ep.RecordType == t.from {
+			ep.RecordType = t.to
+		}
+	}
+	return endpoints, nil
+}
+
+// natTransformer rewrites private targets of A endpoints to public addresses, e.g. before
+// the endpoints are pushed to a public provider.
+type natTransformer struct {
+	mappings     []natMapping
+	keepInternal bool
+}
+
+type natMapping struct {
+	network *net.IPNet
+	target  string
+}
+
+func newNATTransformer(cfg TransformerConfig) (EndpointTransformer, error) {
+	t := natTransformer{keepInternal: cfg.KeepInternal}
+
+	mappings := cfg.Mappings
+	if cfg.To != "" {
+		for _, cidr := range privateNetworks {
+			mappings = append(mappings, NATMapping{CIDR: cidr, Target: cfg.To})
+		}
+	}
+	if len(mappings) == 0 {
+		return nil, fmt.Errorf("transformer %s requires a target or mappings", cfg.Type)
+	}
+
+	for _, m := range mappings {
+		_, network, err := net.ParseCIDR(m.CIDR)
+		if err != nil {
+			return nil, fmt.Errorf("transformer %s has an invalid subnet: %w", cfg.Type, err)
+		}
+		if ip := net.ParseIP(m.Target); ip == nil {
+			return nil, fmt.Errorf("transformer %s has an invalid target %q for subnet %s", cfg.Type, m.Target, m.CIDR)
+		}
+		t.mappings = append(t.mappings, natMapping{network: network, target: m.Target})
+	}
+	return t, nil
+}
+
+func (t natTransformer) Transform(endpoints []*endpoint.Endpoint) ([]*endpoint.Endpoint, error) {
+	result := make([]*endpoint.Endpoint, 0, len(endpoints))
+	for _, ep := range endpoints {
+		if ep.RecordType != endpoint.RecordTypeA {
+			result = append(result, ep)
+			continue
+		}
+
+		targets := endpoint.Targets{}
+		rewritten := false
+		for _, target := range ep.Targets {
+			if public, ok := t.publicTarget(target); ok {
+				target = public
+				rewritten = true
+			}
+			if !containsTarget(targets, target) {
+				targets = append(targets, target)
+			}
+		}
+		if !rewritten {
+			result = append(result, ep)
+			continue
+		}
+
+		if t.keepInternal {
+			internal := ep.DeepCopy()
+			if internal.Labels == nil {
+				internal.Labels = endpoint.NewLabels()
+			}
+			internal.Labels[endpoint.SplitHorizonLabelKey] = endpoint.SplitHorizonInternal
+			result = append(result, internal)
+
+			if ep.Labels == nil {
+				ep.Labels = endpoint.NewLabels()
+			}
+			ep.Labels[endpoint.SplitHorizonLabelKey] = endpoint.SplitHorizonExternal
+		}
+		ep.Targets = targets
+		result = append(result, ep)
+	}
+	return result, nil
+}
+
+// publicTarget returns the public address of the first mapping matching the target.
+func (t natTransformer) publicTarget(target string) (string, bool) {
+	ip := net.ParseIP(target)
+	if ip == nil {
+		return "", false
+	}
+	for _, m := range t.mappings {
+		if m.network.Contains(ip) {
+			return m.target, true
+		}
+	}
+	return "", false
+}
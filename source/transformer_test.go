@@ -0,0 +1,267 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/internal/testutils"
+)
+
+// Validates that transformerSource is a Source
+var _ Source = &transformerSource{}
+
+func TestTransformers(t *testing.T) {
+	t.Run("ParseTransformerConfig", testParseTransformerConfig)
+	t.Run("BuildTransformers", testBuildTransformers)
+	t.Run("Transform", testTransformersTransform)
+	t.Run("NAT", testNATTransformer)
+	t.Run("Source", testTransformerSource)
+}
+
+func testParseTransformerConfig(t *testing.T) {
+	for _, tc := range []struct {
+		spec        string
+		expected    TransformerConfig
+		expectError bool
+	}{
+		{spec: "dedup", expected: TransformerConfig{Type: TransformerDedup}},
+		{spec: "lowercase", expected: TransformerConfig{Type: TransformerLowercase}},
+		{spec: "suffix=example.org", expected: TransformerConfig{Type: TransformerSuffix, Suffix: "example.org"}},
+		{spec: "rewrite-target=10.0.0.1=203.0.113.1", expected: TransformerConfig{Type: TransformerRewriteTarget, From: "10.0.0.1", To: "203.0.113.1"}},
+		{spec: "coerce-type=CNAME=A", expected: TransformerConfig{Type: TransformerCoerceType, From: "CNAME", To: "A"}},
+		{spec: "nat=203.0.113.1", expected: TransformerConfig{Type: TransformerNAT, To: "203.0.113.1"}},
+		{spec: "nat=10.1.0.0/16=203.0.113.1", expected: TransformerConfig{Type: TransformerNAT, Mappings: []NATMapping{{CIDR: "10.1.0.0/16", Target: "203.0.113.1"}}}},
+		{spec: "rewrite-target=10.0.0.1", expectError: true},
+		{spec: "dedup=true", expectError: true},
+	} {
+		t.Run(tc.spec, func(t *testing.T) {
+			cfg, err := ParseTransformerConfig(tc.spec)
+			if tc.expectError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, cfg)
+		})
+	}
+}
+
+func testBuildTransformers(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "transformers.yaml")
+	require.NoError(t, os.WriteFile(configFile, []byte("- type: lowercase\n- type: suffix\n  suffix: example.org.\n"), 0o644))
+	invalidFile := filepath.Join(t.TempDir(), "invalid.yaml")
+	require.NoError(t, os.WriteFile(invalidFile, []byte("- type: suffix\n  domain: example.org\n"), 0o644))
+
+	chain, err := BuildTransformers([]string{"dedup"}, configFile)
+	require.NoError(t, err)
+	assert.Equal(t, TransformerChain{lowercaseTransformer{}, suffixTransformer{suffix: "example.org"}, dedupTransformer{}}, chain)
+
+	chain, err = BuildTransformers(nil, "")
+	require.NoError(t, err)
+	assert.Empty(t, chain)
+
+	for _, tc := range []struct {
+		title      string
+		specs      []string
+		configFile string
+	}{
+		{title: "unknown type", specs: []string{"uppercase"}},
+		{title: "missing suffix", specs: []string{"suffix"}},
+		{title: "missing target", specs: []string{"rewrite-target==203.0.113.1"}},
+		{title: "missing record type", specs: []string{"coerce-type=CNAME="}},
+		{title: "nat without target", specs: []string{"nat"}},
+		{title: "nat with invalid subnet", specs: []string{"nat=10.1.0.0=203.0.113.1"}},
+		{title: "nat with invalid target", specs: []string{"nat=lb.example.net"}},
+		{title: "missing config file", configFile: filepath.Join(t.TempDir(), "missing.yaml")},
+		{title: "unknown config field", configFile: invalidFile},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			_, err := BuildTransformers(tc.specs, tc.configFile)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func testTransformersTransform(t *testing.T) {
+	for _, tc := range []struct {
+		title     string
+		specs     []string
+		endpoints []*endpoint.Endpoint
+		expected  []*endpoint.Endpoint
+	}{
+		{
+			title: "dedup",
+			specs: []string{"dedup"},
+			endpoints: []*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"1.2.3.4"}},
+				{DNSName: "foo.example.org", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"1.2.3.4"}},
+				{DNSName: "foo.example.org", RecordType: endpoint.RecordTypeTXT, Targets: endpoint.Targets{"1.2.3.4"}},
+			},
+			expected: []*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"1.2.3.4"}},
+				{DNSName: "foo.example.org", RecordType: endpoint.RecordTypeTXT, Targets: endpoint.Targets{"1.2.3.4"}},
+			},
+		},
+		{
+			title: "lowercase then dedup",
+			specs: []string{"lowercase", "dedup"},
+			endpoints: []*endpoint.Endpoint{
+				{DNSName: "Foo.Example.org", RecordType: endpoint.RecordTypeCNAME, Targets: endpoint.Targets{"LB.example.net"}},
+				{DNSName: "foo.example.org", RecordType: endpoint.RecordTypeCNAME, Targets: endpoint.Targets{"lb.example.net"}},
+				{DNSName: "Foo.Example.org", RecordType: endpoint.RecordTypeTXT, Targets: endpoint.Targets{"Text"}},
+			},
+			expected: []*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: endpoint.RecordTypeCNAME, Targets: endpoint.Targets{"lb.example.net"}},
+				{DNSName: "foo.example.org", RecordType: endpoint.RecordTypeTXT, Targets: endpoint.Targets{"Text"}},
+			},
+		},
+		{
+			title: "suffix",
+			specs: []string{"suffix=example.org"},
+			endpoints: []*endpoint.Endpoint{
+				{DNSName: "foo", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"1.2.3.4"}},
+				{DNSName: "bar.example.org", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"1.2.3.5"}},
+				{DNSName: "example.org", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"1.2.3.6"}},
+				{DNSName: "badexample.org", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"1.2.3.7"}},
+			},
+			expected: []*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"1.2.3.4"}},
+				{DNSName: "bar.example.org", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"1.2.3.5"}},
+				{DNSName: "example.org", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"1.2.3.6"}},
+				{DNSName: "badexample.org.example.org", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"1.2.3.7"}},
+			},
+		},
+		{
+			title: "rewrite target and coerce type",
+			specs: []string{"rewrite-target=ingress.internal=lb.example.net", "coerce-type=cname=ALIAS"},
+			endpoints: []*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: endpoint.RecordTypeCNAME, Targets: endpoint.Targets{"ingress.internal"}},
+				{DNSName: "bar.example.org", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"1.2.3.4"}},
+			},
+			expected: []*endpoint.Endpoint{
+				{DNSName: "foo.example.org", RecordType: "ALIAS", Targets: endpoint.Targets{"lb.example.net"}},
+				{DNSName: "bar.example.org", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"1.2.3.4"}},
+			},
+		},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			chain, err := BuildTransformers(tc.specs, "")
+			require.NoError(t, err)
+
+			endpoints, err := chain.Transform(tc.endpoints)
+			require.NoError(t, err)
+
+			validateEndpoints(t, endpoints, tc.expected)
+		})
+	}
+}
+
+// testTransformerSource tests that the endpoints of the wrapped source are transformed.
+func testTransformerSource(t *testing.T) {
+	mockSource := new(testutils.MockSource)
+	mockSource.On("Endpoints").Return([]*endpoint.Endpoint{
+		{DNSName: "FOO", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"1.2.3.4"}},
+	}, nil)
+
+	chain, err := BuildTransformers([]string{"lowercase", "suffix=example.org"}, "")
+	require.NoError(t, err)
+
+	endpoints, err := NewTransformerSource(mockSource, chain).Endpoints(context.Background())
+	require.NoError(t, err)
+
+	validateEndpoints(t, endpoints, []*endpoint.Endpoint{
+		{DNSName: "foo.example.org", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"1.2.3.4"}},
+	})
+	mockSource.AssertExpectations(t)
+}
+
+func testNATTransformer(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "transformers.yaml")
+	require.NoError(t, os.WriteFile(configFile, []byte(`
+- type: nat
+  to: 203.0.113.1
+  keepInternal: true
+  mappings:
+  - cidr: 10.1.0.0/16
+    target: 203.0.113.2
+`), 0o644))
+
+	for _, tc := range []struct {
+		title      string
+		specs      []string
+		configFile string
+		expected   []*endpoint.Endpoint
+	}{
+		{
+			title: "private targets are rewritten to the public address",
+			specs: []string{"nat=203.0.113.1"},
+			expected: []*endpoint.Endpoint{
+				{DNSName: "web.example.org", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"203.0.113.1"}},
+				{DNSName: "mixed.example.org", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"203.0.113.1", "198.51.100.1"}},
+				{DNSName: "public.example.org", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"198.51.100.2"}},
+				{DNSName: "www.example.org", RecordType: endpoint.RecordTypeCNAME, Targets: endpoint.Targets{"web.example.org"}},
+			},
+		},
+		{
+			title: "subnet mapping",
+			specs: []string{"nat=10.1.0.0/16=203.0.113.2"},
+			expected: []*endpoint.Endpoint{
+				{DNSName: "web.example.org", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"203.0.113.2", "192.168.1.10"}},
+				{DNSName: "mixed.example.org", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"172.16.0.1", "198.51.100.1"}},
+				{DNSName: "public.example.org", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"198.51.100.2"}},
+				{DNSName: "www.example.org", RecordType: endpoint.RecordTypeCNAME, Targets: endpoint.Targets{"web.example.org"}},
+			},
+		},
+		{
+			title:      "internal endpoints are kept",
+			configFile: configFile,
+			expected: []*endpoint.Endpoint{
+				{DNSName: "web.example.org", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"10.1.0.10", "192.168.1.10"}, Labels: endpoint.Labels{endpoint.SplitHorizonLabelKey: endpoint.SplitHorizonInternal}},
+				{DNSName: "web.example.org", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"203.0.113.2", "203.0.113.1"}, Labels: endpoint.Labels{endpoint.SplitHorizonLabelKey: endpoint.SplitHorizonExternal}},
+				{DNSName: "mixed.example.org", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"172.16.0.1", "198.51.100.1"}, Labels: endpoint.Labels{endpoint.SplitHorizonLabelKey: endpoint.SplitHorizonInternal}},
+				{DNSName: "mixed.example.org", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"203.0.113.1", "198.51.100.1"}, Labels: endpoint.Labels{endpoint.SplitHorizonLabelKey: endpoint.SplitHorizonExternal}},
+				{DNSName: "public.example.org", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"198.51.100.2"}},
+				{DNSName: "www.example.org", RecordType: endpoint.RecordTypeCNAME, Targets: endpoint.Targets{"web.example.org"}},
+			},
+		},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			endpoints := []*endpoint.Endpoint{
+				{DNSName: "web.example.org", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"10.1.0.10", "192.168.1.10"}},
+				{DNSName: "mixed.example.org", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"172.16.0.1", "198.51.100.1"}},
+				{DNSName: "public.example.org", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"198.51.100.2"}},
+				{DNSName: "www.example.org", RecordType: endpoint.RecordTypeCNAME, Targets: endpoint.Targets{"web.example.org"}},
+			}
+
+			chain, err := BuildTransformers(tc.specs, tc.configFile)
+			require.NoError(t, err)
+
+			endpoints, err = chain.Transform(endpoints)
+			require.NoError(t, err)
+
+			validateEndpoints(t, endpoints, tc.expected)
+		})
+	}
+}
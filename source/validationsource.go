@@ -0,0 +1,71 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/endpoint/validation"
+)
+
+// validationSource is a Source that checks the endpoints of its wrapped source before they are planned.
+type validationSource struct {
+	source Source
+	mode   string
+}
+
+// NewValidationSource creates a new validationSource wrapping the provided Source. Invalid endpoints
+// are dropped with a warning in validation.ModeWarn and fail the call to Endpoints in validation.ModeFail.
+func NewValidationSource(source Source, mode string) Source {
+	return &validationSource{source: source, mode: mode}
+}
+
+// Endpoints collects endpoints from its wrapped source and returns the valid ones.
+func (vs *validationSource) Endpoints(ctx context.Context) ([]*endpoint.Endpoint, error) {
+	endpoints, err := vs.source.Endpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*endpoint.Endpoint, 0, len(endpoints))
+	invalid := 0
+	for _, ep := range endpoints {
+		if err := validation.ValidateEndpoint(ep); err != nil {
+			invalid++
+			if vs.mode == validation.ModeFail {
+				log.Error(err)
+				continue
+			}
+			log.Warnf("Dropping %v", err)
+			continue
+		}
+		result = append(result, ep)
+	}
+
+	if invalid > 0 && vs.mode == validation.ModeFail {
+		return nil, fmt.Errorf("%d invalid endpoints, refusing to synchronize", invalid)
+	}
+	return result, nil
+}
+
+func (vs *validationSource) AddEventHandler(ctx context.Context, handler func()) {
+	vs.source.AddEventHandler(ctx, handler)
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"sigs.k8s.io/external-dns/endpoint"
+	"sigs.k8s.io/external-dns/endpoint/validation"
+	"sigs.k8s.io/external-dns/internal/testutils"
+)
+
+// Validates that validationSource is a Source
+var _ Source = &validationSource{}
+
+func TestValidationSource(t *testing.T) {
+	valid := []*endpoint.Endpoint{
+		{DNSName: "foo.example.org", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"192.0.2.1"}},
+		{DNSName: "_sip._udp.example.org", RecordType: endpoint.RecordTypeSRV, Targets: endpoint.Targets{"10 5 5060 sip.example.org"}},
+	}
+	invalid := []*endpoint.Endpoint{
+		{DNSName: "bar.example.org", RecordType: endpoint.RecordTypeA, Targets: endpoint.Targets{"lb.example.org"}},
+		{DNSName: "-baz.example.org", RecordType: endpoint.RecordTypeCNAME, Targets: endpoint.Targets{"lb.example.org"}},
+	}
+
+	for _, tc := range []struct {
+		title       string
+		mode        string
+		endpoints   []*endpoint.Endpoint
+		expected    []*endpoint.Endpoint
+		expectError bool
+	}{
+		{title: "warn keeps valid endpoints", mode: validation.ModeWarn, endpoints: valid, expected: valid},
+		{title: "warn drops invalid endpoints", mode: validation.ModeWarn, endpoints: append(invalid, valid...), expected: valid},
+		{title: "fail keeps valid endpoints", mode: validation.ModeFail, endpoints: valid, expected: valid},
+		{title: "fail on invalid endpoints", mode: validation.ModeFail, endpoints: append(invalid, valid...), expectError: true},
+	} {
+		t.Run(tc.title, func(t *testing.T) {
+			mockSource := new(testutils.MockSource)
+			mockSource.On("Endpoints").Return(tc.endpoints, nil)
+
+			endpoints, err := NewValidationSource(mockSource, tc.mode).Endpoints(context.Background())
+			if tc.expectError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+
+			validateEndpoints(t, endpoints, tc.expected)
+			mockSource.AssertExpectations(t)
+		})
+	}
+}